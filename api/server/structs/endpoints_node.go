@@ -10,6 +10,10 @@ type SyncStatusResponseData struct {
 	IsSyncing    bool   `json:"is_syncing"`
 	IsOptimistic bool   `json:"is_optimistic"`
 	ElOffline    bool   `json:"el_offline"`
+	// IsHeadSynced and IsFullySynced distinguish a node that has synced to head from one that has also
+	// backfilled the history before its checkpoint sync origin.
+	IsHeadSynced  bool `json:"is_head_synced"`
+	IsFullySynced bool `json:"is_fully_synced"`
 }
 
 type GetIdentityResponse struct {
@@ -71,3 +75,57 @@ type AddrRequest struct {
 type PeersResponse struct {
 	Peers []*Peer `json:"peers"`
 }
+
+type BackfillHealthResponse struct {
+	Data *BackfillHealth `json:"data"`
+}
+
+type BackfillHealth struct {
+	Status          string `json:"status"`
+	Enabled         bool   `json:"enabled"`
+	Running         bool   `json:"running"`
+	Complete        bool   `json:"complete"`
+	GenesisSync     bool   `json:"genesis_sync"`
+	LowSlot         string `json:"low_slot"`
+	OriginSlot      string `json:"origin_slot"`
+	TargetSlot      string `json:"target_slot"`
+	PercentComplete string `json:"percent_complete"`
+	Peers           string `json:"peers"`
+	LastAdvance     string `json:"last_advance"`
+}
+
+type BackfillAvailableSinceResponse struct {
+	Data *BackfillAvailableSince `json:"data"`
+}
+
+type BackfillAvailableSince struct {
+	Epoch string `json:"epoch"`
+	Slot  string `json:"slot"`
+}
+
+type BackfillEpochRangeRequest struct {
+	StartEpoch string `json:"start_epoch"`
+	EndEpoch   string `json:"end_epoch"`
+}
+
+type BackfillRangeRequest struct {
+	StartSlot string `json:"start_slot"`
+	EndSlot   string `json:"end_slot"`
+}
+
+type BackfillConsistencyResponse struct {
+	Data *BackfillConsistency `json:"data"`
+}
+
+type BackfillConsistency struct {
+	Consistent       bool     `json:"consistent"`
+	GenesisSync      bool     `json:"genesis_sync"`
+	LowSlot          string   `json:"low_slot"`
+	LowRoot          string   `json:"low_root"`
+	LowParentRoot    string   `json:"low_parent_root"`
+	LowBlockFound    bool     `json:"low_block_found"`
+	OriginSlot       string   `json:"origin_slot"`
+	OriginRoot       string   `json:"origin_root"`
+	OriginBlockFound bool     `json:"origin_block_found"`
+	Mismatches       []string `json:"mismatches"`
+}
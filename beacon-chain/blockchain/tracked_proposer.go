@@ -1,11 +1,16 @@
 package blockchain
 
 import (
+	"time"
+
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/cache"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/helpers"
+	forkchoicetypes "github.com/prysmaticlabs/prysm/v5/beacon-chain/forkchoice/types"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/state"
 	"github.com/prysmaticlabs/prysm/v5/config/features"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+	"github.com/prysmaticlabs/prysm/v5/time/slots"
 )
 
 // trackedProposer returns whether the beacon node was informed, via the
@@ -25,3 +30,53 @@ func (s *Service) trackedProposer(st state.ReadOnlyBeaconState, slot primitives.
 	}
 	return val, val.Active
 }
+
+// ProposalImminent returns true if a validator that was registered via the validators/prepare_proposer endpoint
+// is due to propose in the current slot, or in a slot that starts within the given duration. Proposers are read
+// from the proposer indices cache, which block processing fills at each epoch boundary, so slots in an epoch the
+// cache has no proposers for are never considered imminent.
+func (s *Service) ProposalImminent(within time.Duration) bool {
+	if !s.validating() {
+		return false
+	}
+	s.headLock.RLock()
+	if !s.hasHeadState() {
+		s.headLock.RUnlock()
+		return false
+	}
+	headRoot := s.headRoot()
+	s.headLock.RUnlock()
+
+	deadline := prysmTime.Now().Add(within)
+	for slot := s.CurrentSlot(); !slots.BeginsAt(slot, s.genesisTime).After(deadline); slot++ {
+		id, ok := s.cachedProposer(headRoot, slot)
+		if !ok {
+			return false
+		}
+		if val, ok := s.cfg.TrackedValidatorsCache.Validator(id); ok && val.Active {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedProposer looks up the proposer of the slot on the chain of the given head in the proposer indices cache,
+// without computing the proposer indices when they are missing. Like blob sidecar proposer verification, the cache
+// is keyed by the target checkpoint of the epoch before the slot.
+func (s *Service) cachedProposer(head [32]byte, slot primitives.Slot) (primitives.ValidatorIndex, bool) {
+	e := slots.ToEpoch(slot)
+	if e > 0 {
+		e = e - 1
+	}
+	s.cfg.ForkChoiceStore.RLock()
+	target, err := s.cfg.ForkChoiceStore.TargetRootForEpoch(head, e)
+	s.cfg.ForkChoiceStore.RUnlock()
+	if err != nil {
+		return 0, false
+	}
+	id, err := helpers.ProposerIndexAtSlotFromCheckpoint(&forkchoicetypes.Checkpoint{Epoch: e, Root: target}, slot)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
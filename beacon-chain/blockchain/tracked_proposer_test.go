@@ -0,0 +1,62 @@
+package blockchain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/cache"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/helpers"
+	forkchoicetypes "github.com/prysmaticlabs/prysm/v5/beacon-chain/forkchoice/types"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/prysmaticlabs/prysm/v5/testing/util"
+)
+
+func TestService_ProposalImminent(t *testing.T) {
+	helpers.ClearCache()
+	t.Cleanup(helpers.ClearCache)
+	ctx := context.Background()
+	service, _ := minimalTestService(t, WithTrackedValidatorsCache(cache.NewTrackedValidatorsCache()))
+	require.Equal(t, false, service.ProposalImminent(time.Minute))
+
+	// With a single active validator, it is the proposer of every slot.
+	st, err := util.NewBeaconState()
+	require.NoError(t, err)
+	require.NoError(t, st.SetValidators([]*ethpb.Validator{{
+		PublicKey:             make([]byte, 48),
+		WithdrawalCredentials: make([]byte, 32),
+		EffectiveBalance:      params.BeaconConfig().MaxEffectiveBalance,
+		ExitEpoch:             params.BeaconConfig().FarFutureEpoch,
+		WithdrawableEpoch:     params.BeaconConfig().FarFutureEpoch,
+	}}))
+	require.NoError(t, st.SetBalances([]uint64{params.BeaconConfig().MaxEffectiveBalance}))
+	epochStart, err := params.BeaconConfig().SlotsPerEpoch.SafeMul(4)
+	require.NoError(t, err)
+	require.NoError(t, st.SetSlot(epochStart))
+
+	// The head block is from an earlier epoch, so it is the target checkpoint of the epoch before the current one.
+	headRoot := [32]byte{'h'}
+	cp := &ethpb.Checkpoint{Root: params.BeaconConfig().ZeroHash[:]}
+	fst, roblock, err := prepareForkchoiceState(ctx, epochStart/2, headRoot, [32]byte{}, params.BeaconConfig().ZeroHash, cp, cp)
+	require.NoError(t, err)
+	require.NoError(t, service.cfg.ForkChoiceStore.InsertNode(ctx, fst, roblock))
+	service.head = &head{state: st, root: headRoot}
+	secondsPerSlot := time.Duration(params.BeaconConfig().SecondsPerSlot) * time.Second
+	service.SetGenesisTime(time.Now().Add(-time.Duration(epochStart) * secondsPerSlot))
+	// No validator is tracked.
+	require.Equal(t, false, service.ProposalImminent(time.Minute))
+
+	// The proposers of the current epoch are not in the cache yet, and are not computed.
+	service.cfg.TrackedValidatorsCache.Set(cache.TrackedValidator{Active: true, Index: 0})
+	require.Equal(t, false, service.ProposalImminent(0))
+
+	// Fill the cache the way block processing does at the epoch boundary.
+	require.NoError(t, helpers.UpdateProposerIndicesInCache(ctx, st, 4))
+	require.NoError(t, helpers.UpdateCachedCheckpointToStateRoot(st, &forkchoicetypes.Checkpoint{Epoch: 3, Root: headRoot}))
+	require.Equal(t, true, service.ProposalImminent(0))
+
+	service.cfg.TrackedValidatorsCache.Set(cache.TrackedValidator{Active: false, Index: 0})
+	require.Equal(t, false, service.ProposalImminent(time.Minute))
+}
@@ -116,11 +116,25 @@ func (bs *BlobStorage) WarmCache() {
 	}()
 }
 
+// PruneBefore removes the blobs for slots before the given slot, and returns the number of blob files removed. This
+// allows pruning to a floor other than the one derived from the retention epochs. Like the pruning triggered by Save,
+// it waits for a prune that is already underway, and holds back blobs in the range of an open BlobSnapshot.
+func (bs *BlobStorage) PruneBefore(sl primitives.Slot) (int, error) {
+	if bs.pruner == nil {
+		return 0, nil
+	}
+	return bs.pruner.pruneBefore(sl)
+}
+
 // ErrBlobStorageSummarizerUnavailable is a sentinel error returned when there is no pruner/cache available.
 // This should be used by code that optionally uses the summarizer to optimize rpc requests. Being able to
 // fallback when there is no summarizer allows client code to avoid test complexity where the summarizer doesn't matter.
 var ErrBlobStorageSummarizerUnavailable = errors.New("BlobStorage not initialized with a pruner or cache")
 
+// ErrBlobStorageCacheNotReady is returned by methods that don't wait for the blob storage cache to be populated, when
+// it is still being populated at startup.
+var ErrBlobStorageCacheNotReady = errors.New("BlobStorage cache is not populated yet")
+
 // WaitForSummarizer blocks until the BlobStorageSummarizer is ready to use.
 // BlobStorageSummarizer is not ready immediately on node startup because it needs to sample the blob filesystem to
 // determine which blobs are available.
@@ -131,6 +145,36 @@ func (bs *BlobStorage) WaitForSummarizer(ctx context.Context) (BlobStorageSummar
 	return bs.pruner.waitForCache(ctx)
 }
 
+// CountBlobsSidecarsBySlot returns the number of blob sidecars stored for the blocks at the given slot. The count
+// comes from the slot index of the blob storage cache, so no sidecars are read from disk. Like WaitForSummarizer,
+// it blocks until the cache has been populated.
+func (bs *BlobStorage) CountBlobsSidecarsBySlot(ctx context.Context, slot primitives.Slot) (int, error) {
+	if bs == nil || bs.pruner == nil {
+		return 0, ErrBlobStorageSummarizerUnavailable
+	}
+	c, err := bs.pruner.waitForCache(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return c.countBySlot(slot), nil
+}
+
+// NextBlobSlot returns the lowest slot >= from with blob sidecars in storage, and false if no sidecars are stored
+// at or after from. It uses the slot index of the blob storage cache, so the lookup is a binary search. Unlike
+// CountBlobsSidecarsBySlot it does not wait for the cache to be populated, it returns ErrBlobStorageCacheNotReady
+// instead, so that callers on the request path can fall back to reading every slot while the cache is warming up.
+func (bs *BlobStorage) NextBlobSlot(from primitives.Slot) (primitives.Slot, bool, error) {
+	if bs == nil || bs.pruner == nil {
+		return 0, false, ErrBlobStorageSummarizerUnavailable
+	}
+	c, ok := bs.pruner.readyCache()
+	if !ok {
+		return 0, false, ErrBlobStorageCacheNotReady
+	}
+	next, ok := c.nextSlot(from)
+	return next, ok, nil
+}
+
 // Save saves blobs given a list of sidecars.
 func (bs *BlobStorage) Save(sidecar blocks.VerifiedROBlob) error {
 	startTime := time.Now()
@@ -303,6 +347,11 @@ func (bs *BlobStorage) Clear() error {
 	return nil
 }
 
+// RetentionEpochs returns the number of epochs that blobs are kept for, see WithBlobRetentionEpochs.
+func (bs *BlobStorage) RetentionEpochs() primitives.Epoch {
+	return bs.retentionEpochs
+}
+
 // WithinRetentionPeriod checks if the requested epoch is within the blob retention period.
 func (bs *BlobStorage) WithinRetentionPeriod(requested, current primitives.Epoch) bool {
 	if requested > math.MaxUint64-bs.retentionEpochs {
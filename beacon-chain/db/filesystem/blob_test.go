@@ -2,6 +2,7 @@ package filesystem
 
 import (
 	"bytes"
+	"context"
 	"math"
 	"os"
 	"path"
@@ -11,6 +12,7 @@ import (
 	ssz "github.com/prysmaticlabs/fastssz"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
 	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
 	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
@@ -292,3 +294,62 @@ func TestConfig_WithinRetentionPeriod(t *testing.T) {
 		require.Equal(t, true, storage.WithinRetentionPeriod(1, 1))
 	})
 }
+
+func TestBlobStorage_CountBlobsSidecarsBySlot(t *testing.T) {
+	bs := NewEphemeralBlobStorage(t)
+	save := func(parent [32]byte, slot primitives.Slot, n int) {
+		_, sidecars := util.GenerateTestDenebBlockWithSidecar(t, parent, slot, n)
+		verified, err := verification.BlobSidecarSliceNoop(sidecars)
+		require.NoError(t, err)
+		for i := range verified {
+			require.NoError(t, bs.Save(verified[i]))
+		}
+	}
+	// Two competing blocks at slot 1, so both of their sidecars are counted.
+	save([32]byte{}, 1, 3)
+	save([32]byte{1}, 1, 2)
+	save([32]byte{}, 2, 1)
+
+	ctx := context.Background()
+	for slot, expected := range map[primitives.Slot]int{0: 0, 1: 5, 2: 1, 3: 0} {
+		n, err := bs.CountBlobsSidecarsBySlot(ctx, slot)
+		require.NoError(t, err)
+		require.Equal(t, expected, n, "unexpected count at slot %d", slot)
+	}
+
+	var unavailable *BlobStorage
+	_, err := unavailable.CountBlobsSidecarsBySlot(ctx, 1)
+	require.ErrorIs(t, err, ErrBlobStorageSummarizerUnavailable)
+}
+
+func TestBlobStorage_NextBlobSlot(t *testing.T) {
+	bs := NewEphemeralBlobStorage(t)
+	for _, slot := range []primitives.Slot{5, 100} {
+		_, sidecars := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, slot, 1)
+		verified, err := verification.BlobSidecarSliceNoop(sidecars)
+		require.NoError(t, err)
+		require.NoError(t, bs.Save(verified[0]))
+	}
+
+	next, ok, err := bs.NextBlobSlot(0)
+	require.NoError(t, err)
+	require.Equal(t, true, ok)
+	require.Equal(t, primitives.Slot(5), next)
+	next, ok, err = bs.NextBlobSlot(6)
+	require.NoError(t, err)
+	require.Equal(t, true, ok)
+	require.Equal(t, primitives.Slot(100), next)
+	_, ok, err = bs.NextBlobSlot(101)
+	require.NoError(t, err)
+	require.Equal(t, false, ok)
+
+	var unavailable *BlobStorage
+	_, _, err = unavailable.NextBlobSlot(0)
+	require.ErrorIs(t, err, ErrBlobStorageSummarizerUnavailable)
+
+	// A cache that is still warming up is reported instead of waited for.
+	cold, err := newBlobPruner(afero.NewMemMapFs(), params.BeaconConfig().MinEpochsForBlobsSidecarsRequest)
+	require.NoError(t, err)
+	_, _, err = (&BlobStorage{pruner: cold}).NextBlobSlot(0)
+	require.ErrorIs(t, err, ErrBlobStorageCacheNotReady)
+}
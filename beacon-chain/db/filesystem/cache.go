@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"sort"
 	"sync"
 
 	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
@@ -11,6 +12,16 @@ import (
 // blobIndexMask is a bitmask representing the set of blob indices that are currently set.
 type blobIndexMask [fieldparams.MaxBlobsPerBlock]bool
 
+func (m blobIndexMask) count() int {
+	n := 0
+	for i := range m {
+		if m[i] {
+			n++
+		}
+	}
+	return n
+}
+
 // BlobStorageSummary represents cached information about the BlobSidecars on disk for each root the cache knows about.
 type BlobStorageSummary struct {
 	slot primitives.Slot
@@ -49,6 +60,11 @@ type blobStorageCache struct {
 	mu     sync.RWMutex
 	nBlobs float64
 	cache  map[[32]byte]BlobStorageSummary
+	// slots indexes the roots in cache by slot, so that the sidecars at a given slot can be counted without a scan.
+	slots map[primitives.Slot]map[[32]byte]struct{}
+	// sorted holds the keys of slots in ascending order, so that the next slot with sidecars can be found with a
+	// binary search.
+	sorted []primitives.Slot
 }
 
 var _ BlobStorageSummarizer = &blobStorageCache{}
@@ -56,6 +72,7 @@ var _ BlobStorageSummarizer = &blobStorageCache{}
 func newBlobStorageCache() *blobStorageCache {
 	return &blobStorageCache{
 		cache: make(map[[32]byte]BlobStorageSummary, params.BeaconConfig().MinEpochsForBlobsSidecarsRequest*fieldparams.SlotsPerEpoch),
+		slots: make(map[primitives.Slot]map[[32]byte]struct{}),
 	}
 }
 
@@ -73,8 +90,12 @@ func (s *blobStorageCache) ensure(key [32]byte, slot primitives.Slot, idx uint64
 	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	v := s.cache[key]
+	v, ok := s.cache[key]
+	if ok && v.slot != slot {
+		s.unindex(key, v.slot)
+	}
 	v.slot = slot
+	s.index(key, slot)
 	if !v.mask[idx] {
 		s.updateMetrics(1)
 	}
@@ -98,11 +119,8 @@ func (s *blobStorageCache) evict(key [32]byte) {
 	s.mu.Lock()
 	v, ok := s.cache[key]
 	if ok {
-		for i := range v.mask {
-			if v.mask[i] {
-				deleted += 1
-			}
-		}
+		deleted = float64(v.mask.count())
+		s.unindex(key, v.slot)
 	}
 	delete(s.cache, key)
 	s.mu.Unlock()
@@ -111,6 +129,68 @@ func (s *blobStorageCache) evict(key [32]byte) {
 	}
 }
 
+// countBySlot returns the number of blob sidecars in the cache for all roots at the given slot.
+func (s *blobStorageCache) countBySlot(slot primitives.Slot) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := 0
+	for root := range s.slots[slot] {
+		n += s.cache[root].mask.count()
+	}
+	return n
+}
+
+// nextSlot returns the lowest slot >= from that has sidecars in the cache, and false if there is no such slot.
+func (s *blobStorageCache) nextSlot(from primitives.Slot) (primitives.Slot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := sort.Search(len(s.sorted), func(i int) bool { return s.sorted[i] >= from })
+	if i == len(s.sorted) {
+		return 0, false
+	}
+	return s.sorted[i], true
+}
+
+// usage returns the number of blob sidecars in the cache, and the lowest and highest slot that has sidecars. ok is
+// false if the cache is empty.
+func (s *blobStorageCache) usage() (n float64, oldest, latest primitives.Slot, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.sorted) == 0 {
+		return s.nBlobs, 0, 0, false
+	}
+	return s.nBlobs, s.sorted[0], s.sorted[len(s.sorted)-1], true
+}
+
+// index and unindex must be called with the write lock held.
+func (s *blobStorageCache) index(key [32]byte, slot primitives.Slot) {
+	roots, ok := s.slots[slot]
+	if !ok {
+		roots = make(map[[32]byte]struct{})
+		s.slots[slot] = roots
+		i := sort.Search(len(s.sorted), func(i int) bool { return s.sorted[i] >= slot })
+		s.sorted = append(s.sorted, 0)
+		copy(s.sorted[i+1:], s.sorted[i:])
+		s.sorted[i] = slot
+	}
+	roots[key] = struct{}{}
+}
+
+func (s *blobStorageCache) unindex(key [32]byte, slot primitives.Slot) {
+	roots, ok := s.slots[slot]
+	if !ok {
+		return
+	}
+	delete(roots, key)
+	if len(roots) == 0 {
+		delete(s.slots, slot)
+		i := sort.Search(len(s.sorted), func(i int) bool { return s.sorted[i] >= slot })
+		if i < len(s.sorted) && s.sorted[i] == slot {
+			s.sorted = append(s.sorted[:i], s.sorted[i+1:]...)
+		}
+	}
+}
+
 func (s *blobStorageCache) updateMetrics(delta float64) {
 	s.nBlobs += delta
 	blobDiskCount.Set(s.nBlobs)
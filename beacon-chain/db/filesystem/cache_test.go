@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
 	"github.com/prysmaticlabs/prysm/v5/testing/require"
 )
@@ -148,3 +149,70 @@ func TestAllAvailable(t *testing.T) {
 		})
 	}
 }
+
+func TestCountBySlot(t *testing.T) {
+	sc := newBlobStorageCache()
+	a, b := [32]byte{'a'}, [32]byte{'b'}
+	require.NoError(t, sc.ensure(a, 1, 0))
+	require.NoError(t, sc.ensure(a, 1, 1))
+	require.NoError(t, sc.ensure(b, 1, 0))
+	require.Equal(t, 3, sc.countBySlot(1))
+	require.Equal(t, 0, sc.countBySlot(2))
+
+	// A root seen at a different slot is moved in the slot index.
+	require.NoError(t, sc.ensure(b, 2, 1))
+	require.Equal(t, 2, sc.countBySlot(1))
+	require.Equal(t, 2, sc.countBySlot(2))
+
+	sc.evict(a)
+	require.Equal(t, 0, sc.countBySlot(1))
+	require.Equal(t, 0, len(sc.slots[1]))
+	sc.evict(b)
+	require.Equal(t, 0, len(sc.slots))
+	require.Equal(t, 0, len(sc.sorted))
+}
+
+func TestSortedSlotIndex(t *testing.T) {
+	sc := newBlobStorageCache()
+	for i, slot := range []primitives.Slot{42, 10, 99, 10, 7} {
+		require.NoError(t, sc.ensure([32]byte{byte(i)}, slot, 0))
+	}
+	require.DeepEqual(t, []primitives.Slot{7, 10, 42, 99}, sc.sorted)
+	// Moving a root to another slot drops its old slot once no other root is at it.
+	require.NoError(t, sc.ensure([32]byte{0}, 50, 0))
+	require.DeepEqual(t, []primitives.Slot{7, 10, 50, 99}, sc.sorted)
+	sc.evict([32]byte{1})
+	require.DeepEqual(t, []primitives.Slot{7, 10, 50, 99}, sc.sorted)
+	sc.evict([32]byte{3})
+	require.DeepEqual(t, []primitives.Slot{7, 50, 99}, sc.sorted)
+}
+
+func TestNextSlot(t *testing.T) {
+	sc := newBlobStorageCache()
+	_, ok := sc.nextSlot(0)
+	require.Equal(t, false, ok)
+
+	require.NoError(t, sc.ensure([32]byte{'a'}, 10, 0))
+	require.NoError(t, sc.ensure([32]byte{'b'}, 42, 0))
+	cases := []struct {
+		from primitives.Slot
+		next primitives.Slot
+		ok   bool
+	}{
+		{from: 0, next: 10, ok: true},
+		{from: 10, next: 10, ok: true},
+		{from: 11, next: 42, ok: true},
+		{from: 42, next: 42, ok: true},
+		{from: 43, ok: false},
+	}
+	for _, c := range cases {
+		next, ok := sc.nextSlot(c.from)
+		require.Equal(t, c.ok, ok, "from slot %d", c.from)
+		require.Equal(t, c.next, next, "from slot %d", c.from)
+	}
+
+	sc.evict([32]byte{'a'})
+	next, ok := sc.nextSlot(0)
+	require.Equal(t, true, ok)
+	require.Equal(t, primitives.Slot(42), next)
+}
@@ -33,4 +33,16 @@ var (
 		Name: "blob_disk_bytes",
 		Help: "Approximate number of bytes occupied by blobs in storage",
 	})
+	blobsDBGrowth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "blobs_db_growth_bytes_per_hour",
+		Help: "Rate at which blob_disk_bytes grew over the last hour.",
+	})
+	blobsDBProjectedBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "blobs_db_projected_stable_bytes",
+		Help: "Projected size of the blob sidecars in storage once the retention window is full and pruning keeps the size stable.",
+	})
+	blobsDBSlotsUntilStable = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "blobs_db_slots_until_stable",
+		Help: "Number of slots until the retention window is full and pruning keeps the size of blob storage stable.",
+	})
 )
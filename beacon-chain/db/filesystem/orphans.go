@@ -0,0 +1,92 @@
+package filesystem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/sirupsen/logrus"
+)
+
+// OrphanScanResult summarizes the blob sidecars found by ScanOrphans whose block is not in the block database.
+type OrphanScanResult struct {
+	// Roots is the number of block roots with orphaned sidecars.
+	Roots int
+	// Sidecars is the number of orphaned sidecars.
+	Sidecars int
+	// Removed is the number of orphaned sidecars that were deleted, which is 0 unless the scan was asked to remove them.
+	Removed int
+}
+
+// ScanOrphans finds blob sidecars whose block is not known to hasBlock, for instance because the block was deleted by
+// an earlier version of the node without its sidecars. The roots are taken from the slot index of the blob storage
+// cache, so no sidecars are read from disk, and like NextBlobSlot it blocks until the cache has been populated. The
+// orphans are logged, and deleted as well if remove is true.
+func (bs *BlobStorage) ScanOrphans(ctx context.Context, hasBlock func(context.Context, [32]byte) bool, remove bool) (OrphanScanResult, error) {
+	var res OrphanScanResult
+	if bs == nil || bs.pruner == nil {
+		return res, ErrBlobStorageSummarizerUnavailable
+	}
+	c, err := bs.pruner.waitForCache(ctx)
+	if err != nil {
+		return res, err
+	}
+	for _, e := range c.rootsBySlot() {
+		if ctx.Err() != nil {
+			return res, ctx.Err()
+		}
+		if hasBlock(ctx, e.root) {
+			continue
+		}
+		n := c.Summary(e.root).mask.count()
+		res.Roots++
+		res.Sidecars += n
+		log.WithFields(logrus.Fields{
+			"root":     rootString(e.root),
+			"slot":     e.slot,
+			"sidecars": n,
+		}).Debug("Found blob sidecars without a block")
+		if !remove {
+			continue
+		}
+		if err := bs.removeOrphan(e.root); err != nil {
+			return res, errors.Wrapf(err, "could not remove orphaned blob sidecars for root %s", rootString(e.root))
+		}
+		res.Removed += n
+	}
+	return res, nil
+}
+
+// removeOrphan holds the prune lock while it removes the sidecars, so that it doesn't race with a prune of the same
+// directory, and evicts the root from the cache.
+func (bs *BlobStorage) removeOrphan(root [32]byte) error {
+	bs.pruner.Lock()
+	defer bs.pruner.Unlock()
+	if err := bs.Remove(root); err != nil {
+		return err
+	}
+	bs.pruner.cache.evict(root)
+	return nil
+}
+
+type slotRoot struct {
+	slot primitives.Slot
+	root [32]byte
+}
+
+// rootsBySlot returns every root in the cache, ordered by slot.
+func (s *blobStorageCache) rootsBySlot() []slotRoot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := make([]slotRoot, 0, len(s.cache))
+	for slot, roots := range s.slots {
+		for root := range roots {
+			entries = append(entries, slotRoot{slot: slot, root: root})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].slot < entries[j].slot
+	})
+	return entries
+}
@@ -0,0 +1,45 @@
+package filesystem
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestBlobStorage_ScanOrphans(t *testing.T) {
+	ctx := context.Background()
+	bs := NewEphemeralBlobStorage(t)
+	kept := saveTestSidecars(t, bs, 10, 2)
+	orphaned := saveTestSidecars(t, bs, 11, 3)
+	known := map[[32]byte]bool{kept[0].BlockRoot(): true}
+	hasBlock := func(_ context.Context, root [32]byte) bool {
+		return known[root]
+	}
+
+	// By default the orphans are only reported.
+	res, err := bs.ScanOrphans(ctx, hasBlock, false)
+	require.NoError(t, err)
+	require.Equal(t, OrphanScanResult{Roots: 1, Sidecars: 3}, res)
+	idxs, err := bs.Indices(orphaned[0].BlockRoot())
+	require.NoError(t, err)
+	require.Equal(t, true, idxs[2])
+
+	res, err = bs.ScanOrphans(ctx, hasBlock, true)
+	require.NoError(t, err)
+	require.Equal(t, OrphanScanResult{Roots: 1, Sidecars: 3, Removed: 3}, res)
+	idxs, err = bs.Indices(orphaned[0].BlockRoot())
+	require.NoError(t, err)
+	require.Equal(t, false, idxs[0])
+	n, err := bs.CountBlobsSidecarsBySlot(ctx, 11)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+	// Sidecars with a block are left alone.
+	idxs, err = bs.Indices(kept[0].BlockRoot())
+	require.NoError(t, err)
+	require.Equal(t, true, idxs[1])
+
+	res, err = bs.ScanOrphans(ctx, hasBlock, true)
+	require.NoError(t, err)
+	require.Equal(t, OrphanScanResult{}, res)
+}
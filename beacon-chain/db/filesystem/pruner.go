@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/binary"
 	"io"
+	"math"
 	"path"
 	"path/filepath"
 	"strconv"
@@ -35,6 +36,7 @@ type blobPruner struct {
 	cacheReady   chan struct{}
 	warmed       bool
 	fs           afero.Fs
+	pinned       pinnedSlots
 }
 
 type prunerOpt func(*blobPruner) error
@@ -66,7 +68,7 @@ func (p *blobPruner) notify(root [32]byte, latest primitives.Slot, idx uint64) e
 	if err := p.cache.ensure(root, latest, idx); err != nil {
 		return err
 	}
-	pruned := uint64(windowMin(latest, p.windowSize))
+	pruned := uint64(p.pruneFloor(latest))
 	if p.prunedBefore.Swap(pruned) == pruned {
 		return nil
 	}
@@ -80,6 +82,30 @@ func (p *blobPruner) notify(root [32]byte, latest primitives.Slot, idx uint64) e
 	return nil
 }
 
+// pin holds back pruning of blobs at or after the given slot, until unpin is called with the same slot.
+// It doesn't wait for a prune that is underway, which checks the pins again before removing each directory.
+func (p *blobPruner) pin(sl primitives.Slot) {
+	p.pinned.add(sl)
+}
+
+func (p *blobPruner) unpin(sl primitives.Slot) {
+	p.pinned.remove(sl)
+}
+
+// pruneFloor returns the slot before which blobs are pruned, given the latest slot seen. Blobs are kept for the
+// retention window of the pruner, or for the retention of their fork in the config if that is longer.
+func (p *blobPruner) pruneFloor(latest primitives.Slot) primitives.Slot {
+	floor := windowMin(latest, p.windowSize)
+	if params.BeaconConfig().DenebForkEpoch == math.MaxUint64 {
+		return floor
+	}
+	spec, err := slots.EpochStart(params.BeaconConfig().BlobRetentionFloor(slots.ToEpoch(latest)))
+	if err == nil && spec < floor {
+		return spec
+	}
+	return floor
+}
+
 func windowMin(latest, offset primitives.Slot) primitives.Slot {
 	// Safely compute the first slot in the epoch for the latest slot
 	latest = latest - latest%params.BeaconConfig().SlotsPerEpoch
@@ -104,6 +130,16 @@ func (p *blobPruner) warmCache() error {
 	return nil
 }
 
+// readyCache returns the cache without waiting, and false if it has not been populated yet.
+func (p *blobPruner) readyCache() (*blobStorageCache, bool) {
+	select {
+	case <-p.cacheReady:
+		return p.cache, true
+	default:
+		return nil, false
+	}
+}
+
 func (p *blobPruner) waitForCache(ctx context.Context) (*blobStorageCache, error) {
 	select {
 	case <-p.cacheReady:
@@ -113,10 +149,32 @@ func (p *blobPruner) waitForCache(ctx context.Context) (*blobStorageCache, error
 	}
 }
 
+// pruneBefore takes the prune lock, so that it waits for a prune that is underway, and removes the blobs for slots
+// before the given slot. It returns the number of blob files that were removed.
+func (p *blobPruner) pruneBefore(pruneBefore primitives.Slot) (int, error) {
+	p.Lock()
+	defer p.Unlock()
+	return p.pruneCounted(pruneBefore)
+}
+
 // Prune prunes blobs in the base directory based on the retention epoch.
 // It deletes blobs older than currentEpoch - (retentionEpochs+bufferEpochs).
 // This is so that we keep a slight buffer and blobs are deleted after n+2 epochs.
 func (p *blobPruner) prune(pruneBefore primitives.Slot) error {
+	_, err := p.pruneCounted(pruneBefore)
+	return err
+}
+
+func (p *blobPruner) pruneCounted(pruneBefore primitives.Slot) (int, error) {
+	// Blobs that are being read through a BlobSnapshot are retained until the snapshot is released.
+	// The held back blobs are removed by the next prune after the release.
+	if low, ok := p.pinned.lowest(); ok && low < pruneBefore {
+		log.WithField("pruneBefore", pruneBefore).WithField("pinnedSlot", low).Debug("Holding back blob pruning for open snapshot")
+		if low == 0 {
+			return 0, nil
+		}
+		pruneBefore = low
+	}
 	start := time.Now()
 	totalPruned, totalErr := 0, 0
 	// Customize logging/metrics behavior for the initial cache warmup when slot=0.
@@ -138,7 +196,7 @@ func (p *blobPruner) prune(pruneBefore primitives.Slot) error {
 
 	entries, err := listDir(p.fs, ".")
 	if err != nil {
-		return errors.Wrap(err, "unable to list root blobs directory")
+		return 0, errors.Wrap(err, "unable to list root blobs directory")
 	}
 	dirs := filter(entries, filterRoot)
 	for _, dir := range dirs {
@@ -151,9 +209,9 @@ func (p *blobPruner) prune(pruneBefore primitives.Slot) error {
 	}
 
 	if totalErr > 0 {
-		return errors.Wrapf(errPruningFailures, "pruning failed for %d root directories", totalErr)
+		return totalPruned, errors.Wrapf(errPruningFailures, "pruning failed for %d root directories", totalErr)
 	}
-	return nil
+	return totalPruned, nil
 }
 
 func shouldRetain(slot, pruneBefore primitives.Slot) bool {
@@ -203,6 +261,13 @@ func (p *blobPruner) tryPruneDir(dir string, pruneBefore primitives.Slot) (int,
 		}
 	}
 
+	// Holding the pins lock while the directory is removed means that a snapshot opened during a prune either
+	// sees the directory whole, or not at all.
+	p.pinned.Lock()
+	defer p.pinned.Unlock()
+	if p.pinned.coversLocked(slot) {
+		return 0, nil
+	}
 	removed := 0
 	for _, fname := range entries {
 		fullName := path.Join(dir, fname)
@@ -13,10 +13,13 @@ import (
 
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
 	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
+	"github.com/prysmaticlabs/prysm/v5/runtime/version"
 	"github.com/prysmaticlabs/prysm/v5/testing/require"
 	"github.com/prysmaticlabs/prysm/v5/testing/util"
+	"github.com/prysmaticlabs/prysm/v5/time/slots"
 	"github.com/spf13/afero"
 )
 
@@ -392,3 +395,25 @@ func TestRootFromDir(t *testing.T) {
 		})
 	}
 }
+
+func TestPruneFloor(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.DenebForkEpoch = 0
+	cfg.ElectraForkEpoch = 100
+	cfg.MinEpochsForBlobsSidecarsRequest = 10
+	params.OverrideBeaconConfig(cfg)
+
+	pr, err := newBlobPruner(afero.NewMemMapFs(), 10)
+	require.NoError(t, err)
+	latest, err := slots.EpochStart(120)
+	require.NoError(t, err)
+	require.Equal(t, windowMin(latest, pr.windowSize), pr.pruneFloor(latest))
+
+	// A longer retention for electra keeps the electra blobs beyond the retention window of the pruner.
+	cfg.MinEpochsForBlobsSidecarsRequestByFork = map[int]primitives.Epoch{version.Electra: 50}
+	params.OverrideBeaconConfig(cfg)
+	electra, err := slots.EpochStart(100)
+	require.NoError(t, err)
+	require.Equal(t, electra, pr.pruneFloor(latest))
+}
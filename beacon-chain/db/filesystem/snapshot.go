@@ -0,0 +1,101 @@
+package filesystem
+
+import (
+	"sync"
+
+	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+)
+
+// BlobSnapshot gives a consistent view of the blobs stored for a range of slots while they are read, for example
+// when serving a range of sidecars to a peer. Until Release is called, the pruner will not remove blobs for slots
+// at or after the start of the range, so a long running read does not observe a range that is partially deleted.
+type BlobSnapshot struct {
+	bs      *BlobStorage
+	start   primitives.Slot
+	release sync.Once
+}
+
+// SlotRangeSnapshot opens a BlobSnapshot covering the range of slots beginning at start. It doesn't wait for a prune
+// that is underway; the prune stops removing blobs in the range as soon as the snapshot is open.
+// The caller must call Release once it is done reading from the snapshot.
+func (bs *BlobStorage) SlotRangeSnapshot(start primitives.Slot) *BlobSnapshot {
+	s := &BlobSnapshot{bs: bs, start: start}
+	if bs.pruner != nil {
+		bs.pruner.pin(start)
+	}
+	return s
+}
+
+// Get retrieves a single BlobSidecar by its root and index, see BlobStorage.Get.
+func (s *BlobSnapshot) Get(root [32]byte, idx uint64) (blocks.VerifiedROBlob, error) {
+	return s.bs.Get(root, idx)
+}
+
+// Indices returns a bitmap of the BlobSidecar.Index values that are present for a given root, see BlobStorage.Indices.
+func (s *BlobSnapshot) Indices(root [32]byte) ([fieldparams.MaxBlobsPerBlock]bool, error) {
+	return s.bs.Indices(root)
+}
+
+// NextBlobSlot returns the lowest slot >= from with blob sidecars in storage, see BlobStorage.NextBlobSlot.
+func (s *BlobSnapshot) NextBlobSlot(from primitives.Slot) (primitives.Slot, bool, error) {
+	return s.bs.NextBlobSlot(from)
+}
+
+// Release allows the pruner to remove blobs in the range of the snapshot again. It is safe to call more than once.
+func (s *BlobSnapshot) Release() {
+	s.release.Do(func() {
+		if s.bs.pruner != nil {
+			s.bs.pruner.unpin(s.start)
+		}
+	})
+}
+
+// pinnedSlots counts the open snapshots by their starting slot.
+type pinnedSlots struct {
+	sync.Mutex
+	counts map[primitives.Slot]int
+}
+
+func (p *pinnedSlots) add(sl primitives.Slot) {
+	p.Lock()
+	defer p.Unlock()
+	if p.counts == nil {
+		p.counts = make(map[primitives.Slot]int)
+	}
+	p.counts[sl] += 1
+}
+
+func (p *pinnedSlots) remove(sl primitives.Slot) {
+	p.Lock()
+	defer p.Unlock()
+	p.counts[sl] -= 1
+	if p.counts[sl] <= 0 {
+		delete(p.counts, sl)
+	}
+}
+
+// coversLocked reports whether an open snapshot starts at or before the given slot. The caller must hold the lock.
+func (p *pinnedSlots) coversLocked(sl primitives.Slot) bool {
+	for pinned := range p.counts {
+		if pinned <= sl {
+			return true
+		}
+	}
+	return false
+}
+
+// lowest returns the lowest pinned slot, and false if there are no open snapshots.
+func (p *pinnedSlots) lowest() (primitives.Slot, bool) {
+	p.Lock()
+	defer p.Unlock()
+	var low primitives.Slot
+	found := false
+	for sl := range p.counts {
+		if !found || sl < low {
+			low, found = sl, true
+		}
+	}
+	return low, found
+}
@@ -0,0 +1,117 @@
+package filesystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/prysmaticlabs/prysm/v5/testing/util"
+)
+
+func saveTestSidecars(t *testing.T, bs *BlobStorage, slot primitives.Slot, n int) []blocks.VerifiedROBlob {
+	_, sidecars := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, slot, n)
+	scs, err := verification.BlobSidecarSliceNoop(sidecars)
+	require.NoError(t, err)
+	for i := range scs {
+		require.NoError(t, bs.Save(scs[i]))
+	}
+	return scs
+}
+
+func TestSlotRangeSnapshotHoldsBackPruning(t *testing.T) {
+	bs := NewEphemeralBlobStorage(t)
+	old := saveTestSidecars(t, bs, 10, 2)
+	older := saveTestSidecars(t, bs, 5, 1)
+	oldRoot := old[0].BlockRoot()
+
+	snap := bs.SlotRangeSnapshot(10)
+	// A prune that runs while the range is being served must not remove the sidecars in the range.
+	require.NoError(t, bs.pruner.prune(100))
+	idxs, err := snap.Indices(oldRoot)
+	require.NoError(t, err)
+	require.Equal(t, true, idxs[0])
+	require.Equal(t, true, idxs[1])
+	sc, err := snap.Get(oldRoot, 1)
+	require.NoError(t, err)
+	require.Equal(t, oldRoot, sc.BlockRoot())
+	// Sidecars before the start of the snapshot can still be pruned.
+	idxs, err = bs.Indices(older[0].BlockRoot())
+	require.NoError(t, err)
+	require.Equal(t, false, idxs[0])
+
+	snap.Release()
+	// Releasing more than once is harmless.
+	snap.Release()
+	require.NoError(t, bs.pruner.prune(100))
+	idxs, err = bs.Indices(oldRoot)
+	require.NoError(t, err)
+	require.Equal(t, false, idxs[0])
+}
+
+func TestSlotRangeSnapshotDuringPrune(t *testing.T) {
+	bs := NewEphemeralBlobStorage(t)
+	scs := saveTestSidecars(t, bs, 10, 2)
+	root := scs[0].BlockRoot()
+	// Simulate a prune that is underway by holding the prune lock. Opening a snapshot must not wait for it.
+	bs.pruner.Lock()
+	defer bs.pruner.Unlock()
+	opened := make(chan *BlobSnapshot)
+	go func() {
+		opened <- bs.SlotRangeSnapshot(10)
+	}()
+	var snap *BlobSnapshot
+	select {
+	case snap = <-opened:
+	case <-time.After(time.Second):
+		t.Fatal("snapshot waited for a prune that was underway")
+	}
+	// The prune checks the pins before removing each directory, so it keeps the range even though it started
+	// before the snapshot was opened.
+	removed, err := bs.pruner.tryPruneDir(rootString(root), 100)
+	require.NoError(t, err)
+	require.Equal(t, 0, removed)
+	idxs, err := snap.Indices(root)
+	require.NoError(t, err)
+	require.Equal(t, true, idxs[0])
+
+	snap.Release()
+	_, pinned := bs.pruner.pinned.lowest()
+	require.Equal(t, false, pinned)
+	removed, err = bs.pruner.tryPruneDir(rootString(root), 100)
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+}
+
+func TestPruneBeforeRespectsSnapshot(t *testing.T) {
+	bs := NewEphemeralBlobStorage(t)
+	pinned := saveTestSidecars(t, bs, 10, 2)
+	older := saveTestSidecars(t, bs, 5, 1)
+	newer := saveTestSidecars(t, bs, 50, 1)
+
+	snap := bs.SlotRangeSnapshot(10)
+	n, err := bs.PruneBefore(20)
+	require.NoError(t, err)
+	// Only the sidecar before the snapshot is removed.
+	require.Equal(t, 1, n)
+	idxs, err := bs.Indices(older[0].BlockRoot())
+	require.NoError(t, err)
+	require.Equal(t, false, idxs[0])
+	idxs, err = bs.Indices(pinned[0].BlockRoot())
+	require.NoError(t, err)
+	require.Equal(t, true, idxs[0])
+
+	snap.Release()
+	n, err = bs.PruneBefore(20)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	idxs, err = bs.Indices(pinned[0].BlockRoot())
+	require.NoError(t, err)
+	require.Equal(t, false, idxs[0])
+	// Sidecars at or after the prune slot are kept.
+	idxs, err = bs.Indices(newer[0].BlockRoot())
+	require.NoError(t, err)
+	require.Equal(t, true, idxs[0])
+}
@@ -0,0 +1,139 @@
+package filesystem
+
+import (
+	"context"
+	"time"
+
+	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// blobUsageInterval is the time between measurements of the size of blob storage by ReportUsage.
+	blobUsageInterval = 5 * time.Minute
+	// blobUsageGrowthWindow is the period over which the growth rate of blob storage is computed.
+	blobUsageGrowthWindow = time.Hour
+)
+
+// BlobStorageUsage describes the space used by blob storage, and when pruning will keep it from growing further.
+type BlobStorageUsage struct {
+	// Bytes is the size of the stored blob sidecars.
+	Bytes uint64
+	// Oldest and Latest are the lowest and highest slot with stored sidecars.
+	Oldest, Latest primitives.Slot
+	// StableAt is the slot from which the sidecars of the oldest stored slot are pruned, so that the retention window
+	// is full and pruning removes about as many sidecars as are saved.
+	StableAt primitives.Slot
+}
+
+// Stable reports whether the retention window is already full, so that the size of blob storage no longer grows with
+// the time the node has been running.
+func (u BlobStorageUsage) Stable() bool {
+	return u.Latest >= u.StableAt
+}
+
+// ProjectedStableBytes estimates the size of blob storage once the retention window is full, as the average size of
+// the slots held so far times the number of slots in the window. It is the current size if the window is already full.
+func (u BlobStorageUsage) ProjectedStableBytes() uint64 {
+	if u.Bytes == 0 || u.Stable() {
+		return u.Bytes
+	}
+	held := float64(u.Latest-u.Oldest) + 1
+	window := float64(u.StableAt-u.Oldest) + 1
+	return uint64(float64(u.Bytes) / held * window)
+}
+
+// Usage measures the size of blob storage. The size is derived from the number of sidecars in the blob storage cache,
+// which is kept up to date as sidecars are saved and pruned, so no files are read or listed. Like NextBlobSlot, it
+// blocks until the cache has been populated.
+func (bs *BlobStorage) Usage(ctx context.Context) (BlobStorageUsage, error) {
+	var u BlobStorageUsage
+	if bs == nil || bs.pruner == nil {
+		return u, ErrBlobStorageSummarizerUnavailable
+	}
+	c, err := bs.pruner.waitForCache(ctx)
+	if err != nil {
+		return u, err
+	}
+	n, oldest, latest, ok := c.usage()
+	if !ok {
+		return u, nil
+	}
+	u.Bytes = uint64(n) * fieldparams.BlobSidecarSize
+	u.Oldest, u.Latest = oldest, latest
+	// Sidecars before the prune floor of the latest slot have already been pruned, so the window between the two is
+	// what storage holds once it is full.
+	u.StableAt = oldest + latest - bs.pruner.pruneFloor(latest)
+	return u, nil
+}
+
+// ReportUsage periodically measures the size of blob storage with Usage, and updates the blobs_db_* metrics with the
+// growth rate of the size, and the size it is projected to stabilize at once pruning keeps up with new sidecars. The
+// size itself is already reported by blob_disk_bytes. It returns when the context is canceled.
+func (bs *BlobStorage) ReportUsage(ctx context.Context) {
+	g := &usageGrowth{window: blobUsageGrowthWindow}
+	ticker := time.NewTicker(blobUsageInterval)
+	defer ticker.Stop()
+	for {
+		u, err := bs.Usage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.WithError(err).Debug("Could not measure blob storage usage")
+		} else {
+			reportBlobUsage(u, g.rate(time.Now(), u.Bytes))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func reportBlobUsage(u BlobStorageUsage, growth float64) {
+	var remaining primitives.Slot
+	if !u.Stable() {
+		remaining = u.StableAt - u.Latest
+	}
+	projected := u.ProjectedStableBytes()
+	blobsDBGrowth.Set(growth)
+	blobsDBProjectedBytes.Set(float64(projected))
+	blobsDBSlotsUntilStable.Set(float64(remaining))
+	log.WithFields(logrus.Fields{
+		"bytes":              u.Bytes,
+		"growthBytesPerHour": growth,
+		"projectedBytes":     projected,
+		"slotsUntilStable":   remaining,
+	}).Debug("Measured blob storage usage")
+}
+
+type usageSample struct {
+	at    time.Time
+	bytes uint64
+}
+
+// usageGrowth computes the growth rate of blob storage from the measurements taken over a window of time.
+type usageGrowth struct {
+	window  time.Duration
+	samples []usageSample
+}
+
+// rate records a measurement, and returns the growth in bytes per hour since the oldest measurement in the window.
+// The rate is negative if storage shrank, for instance after the retention was lowered, and 0 until a second
+// measurement has been recorded.
+func (g *usageGrowth) rate(now time.Time, bytes uint64) float64 {
+	g.samples = append(g.samples, usageSample{at: now, bytes: bytes})
+	// Keep one sample at or before the start of the window, so that the rate always covers the full window.
+	for len(g.samples) > 2 && now.Sub(g.samples[1].at) >= g.window {
+		g.samples = g.samples[1:]
+	}
+	first := g.samples[0]
+	elapsed := now.Sub(first.at)
+	if elapsed <= 0 {
+		return 0
+	}
+	return (float64(bytes) - float64(first.bytes)) / elapsed.Hours()
+}
@@ -0,0 +1,54 @@
+package filesystem
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestBlobStorage_Usage(t *testing.T) {
+	ctx := context.Background()
+	bs := NewEphemeralBlobStorage(t)
+	u, err := bs.Usage(ctx)
+	require.NoError(t, err)
+	require.Equal(t, BlobStorageUsage{}, u)
+
+	saveTestSidecars(t, bs, 10, 2)
+	saveTestSidecars(t, bs, 11, 3)
+	u, err = bs.Usage(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5*fieldparams.BlobSidecarSize), u.Bytes)
+	require.Equal(t, primitives.Slot(10), u.Oldest)
+	require.Equal(t, primitives.Slot(11), u.Latest)
+	// Nothing has been pruned yet, so storage keeps growing until the oldest slot falls out of the window.
+	require.Equal(t, u.Oldest+u.Latest-bs.pruner.pruneFloor(u.Latest), u.StableAt)
+	require.Equal(t, false, u.Stable())
+
+	_, err = (&BlobStorage{}).Usage(ctx)
+	require.ErrorIs(t, err, ErrBlobStorageSummarizerUnavailable)
+}
+
+func TestBlobStorageUsage_ProjectedStableBytes(t *testing.T) {
+	// 10 slots are held, in a window of 40 slots.
+	u := BlobStorageUsage{Bytes: 1000, Oldest: 100, Latest: 109, StableAt: 139}
+	require.Equal(t, uint64(4000), u.ProjectedStableBytes())
+	// Once the window is full, the projection is the current size.
+	u.Latest = 139
+	require.Equal(t, uint64(1000), u.ProjectedStableBytes())
+	require.Equal(t, uint64(0), BlobStorageUsage{}.ProjectedStableBytes())
+}
+
+func TestUsageGrowth(t *testing.T) {
+	start := time.Now()
+	g := &usageGrowth{window: time.Hour}
+	require.Equal(t, float64(0), g.rate(start, 100))
+	require.Equal(t, float64(200), g.rate(start.Add(30*time.Minute), 200))
+	require.Equal(t, float64(100), g.rate(start.Add(time.Hour), 200))
+	// The first sample is dropped once a later one covers the whole window.
+	require.Equal(t, float64(-100), g.rate(start.Add(2*time.Hour), 100))
+	require.Equal(t, 2, len(g.samples))
+}
@@ -154,6 +154,60 @@ func NewKVStore(ctx context.Context, dirPath string, opts ...KVStoreOption) (*St
 		return nil, err
 	}
 	boltDB.AllocSize = boltAllocSize
+	kv, err := newStore(ctx, dirPath, boltDB)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range opts {
+		o(kv)
+	}
+	if err := kv.db.Update(func(tx *bolt.Tx) error {
+		return createBuckets(tx, Buckets...)
+	}); err != nil {
+		return nil, err
+	}
+	if err = prometheus.Register(createBoltCollector(kv.db)); err != nil {
+		return nil, err
+	}
+	// Setup the type of block storage used depending on whether or not this is a fresh database.
+	if err := kv.setupBlockStorageType(ctx); err != nil {
+		return nil, err
+	}
+
+	return kv, nil
+}
+
+// NewReadOnlyKVStore opens the existing boltDB key-value store at the directory path specified without modifying it,
+// for tools that inspect the db of a node. Buckets are not created, and methods that write to the db return
+// bolt.ErrDatabaseReadOnly. The db can't be opened while a node has it open.
+func NewReadOnlyKVStore(ctx context.Context, dirPath string) (*Store, error) {
+	datafile := StoreDatafilePath(dirPath)
+	exists, err := file.Exists(datafile, file.Regular)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("no db file at %s", datafile)
+	}
+	log.WithField("path", datafile).Info("Opening Bolt DB read-only")
+	boltDB, err := bolt.Open(
+		datafile,
+		params.BeaconIoConfig().ReadWritePermissions,
+		&bolt.Options{
+			Timeout:  1 * time.Second,
+			ReadOnly: true,
+		},
+	)
+	if err != nil {
+		if errors.Is(err, bolt.ErrTimeout) {
+			return nil, errors.New("cannot obtain database lock, database may be in use by another process")
+		}
+		return nil, err
+	}
+	return newStore(ctx, dirPath, boltDB)
+}
+
+func newStore(ctx context.Context, dirPath string, boltDB *bolt.DB) (*Store, error) {
 	blockCache, err := ristretto.NewCache(&ristretto.Config{
 		NumCounters: 1000,           // number of keys to track frequency of (1000).
 		MaxCost:     BlockCacheSize, // maximum cost of cache (1000 Blocks).
@@ -180,22 +234,6 @@ func NewKVStore(ctx context.Context, dirPath string, opts ...KVStoreOption) (*St
 		stateSummaryCache:   newStateSummaryCache(),
 		ctx:                 ctx,
 	}
-	for _, o := range opts {
-		o(kv)
-	}
-	if err := kv.db.Update(func(tx *bolt.Tx) error {
-		return createBuckets(tx, Buckets...)
-	}); err != nil {
-		return nil, err
-	}
-	if err = prometheus.Register(createBoltCollector(kv.db)); err != nil {
-		return nil, err
-	}
-	// Setup the type of block storage used depending on whether or not this is a fresh database.
-	if err := kv.setupBlockStorageType(ctx); err != nil {
-		return nil, err
-	}
-
 	return kv, nil
 }
 
@@ -219,8 +257,10 @@ func (s *Store) Close() error {
 	prometheus.Unregister(createBoltCollector(s.db))
 
 	// Before DB closes, we should dump the cached state summary objects to DB.
-	if err := s.saveCachedStateSummariesDB(s.ctx); err != nil {
-		return err
+	if !s.db.IsReadOnly() {
+		if err := s.saveCachedStateSummariesDB(s.ctx); err != nil {
+			return err
+		}
 	}
 
 	return s.db.Close()
@@ -213,3 +213,24 @@ func Test_setupBlockStorageType(t *testing.T) {
 		require.ErrorContains(t, fmt.Sprintf(errMsg, features.SaveFullExecutionPayloads.Name), err)
 	})
 }
+
+func TestNewReadOnlyKVStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	_, err := NewReadOnlyKVStore(ctx, dir)
+	require.ErrorContains(t, "no db file", err)
+
+	db, err := NewKVStore(ctx, dir)
+	require.NoError(t, err)
+	root := [32]byte{'r'}
+	require.NoError(t, db.SaveOriginCheckpointBlockRoot(ctx, root))
+	require.NoError(t, db.Close())
+
+	ro, err := NewReadOnlyKVStore(ctx, dir)
+	require.NoError(t, err)
+	got, err := ro.OriginCheckpointBlockRoot(ctx)
+	require.NoError(t, err)
+	require.Equal(t, root, got)
+	require.ErrorIs(t, ro.SaveOriginCheckpointBlockRoot(ctx, [32]byte{'x'}), bolt.ErrDatabaseReadOnly)
+	require.NoError(t, ro.Close())
+}
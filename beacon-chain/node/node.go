@@ -16,6 +16,7 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
@@ -44,6 +45,7 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/peers"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/rpc"
+	ethnode "github.com/prysmaticlabs/prysm/v5/beacon-chain/rpc/eth/node"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/slasher"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/startup"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/state"
@@ -57,6 +59,7 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
 	"github.com/prysmaticlabs/prysm/v5/cmd"
 	"github.com/prysmaticlabs/prysm/v5/cmd/beacon-chain/flags"
+	bflags "github.com/prysmaticlabs/prysm/v5/cmd/beacon-chain/sync/backfill/flags"
 	"github.com/prysmaticlabs/prysm/v5/config/features"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
@@ -119,6 +122,8 @@ type BeaconNode struct {
 	initialSyncComplete     chan struct{}
 	BlobStorage             *filesystem.BlobStorage
 	BlobStorageOptions      []filesystem.BlobStorageOption
+	blobOrphanScan          bool
+	blobOrphanRemove        bool
 	verifyInitWaiter        *verification.InitializerWaiter
 	syncChecker             *initialsync.SyncChecker
 }
@@ -237,7 +242,9 @@ func configureBeacon(cliCtx *cli.Context) error {
 		return errors.Wrap(err, "could not configure beacon chain")
 	}
 
-	flags.ConfigureGlobalFlags(cliCtx)
+	if err := flags.ConfigureGlobalFlags(cliCtx); err != nil {
+		return errors.Wrap(err, "could not configure global flags")
+	}
 
 	if err := configureChainConfig(cliCtx); err != nil {
 		return errors.Wrap(err, "could not configure chain config")
@@ -279,6 +286,10 @@ func startBaseServices(cliCtx *cli.Context, beacon *BeaconNode, depositAddress s
 		return nil, errors.Wrap(err, "could not start DB")
 	}
 	beacon.BlobStorage.WarmCache()
+	if beacon.blobOrphanScan {
+		beacon.scanOrphanedBlobs(ctx)
+	}
+	go beacon.BlobStorage.ReportUsage(ctx)
 
 	log.Debugln("Starting Slashing DB")
 	if err := beacon.startSlasherDB(cliCtx); err != nil {
@@ -356,7 +367,7 @@ func registerServices(cliCtx *cli.Context, beacon *BeaconNode, synchronizer *sta
 
 	log.Debugln("Registering RPC Service")
 	router := http.NewServeMux()
-	if err := beacon.registerRPCService(router); err != nil {
+	if err := beacon.registerRPCService(router, bfs); err != nil {
 		return errors.Wrap(err, "could not register RPC service")
 	}
 
@@ -492,6 +503,28 @@ func (b *BeaconNode) clearDB(clearDB, forceClearDB bool, d *kv.Store, dbPath str
 	return d, nil
 }
 
+// scanOrphanedBlobs reports, and removes if requested, the blob sidecars whose block is not in the database. It runs
+// before any service is started, so that sidecars which are saved ahead of their block can't be mistaken for orphans.
+func (b *BeaconNode) scanOrphanedBlobs(ctx context.Context) {
+	start := time.Now()
+	res, err := b.BlobStorage.ScanOrphans(ctx, b.db.HasBlock, b.blobOrphanRemove)
+	if err != nil {
+		log.WithError(err).Error("Could not scan blob storage for orphaned blob sidecars")
+		return
+	}
+	l := log.WithFields(logrus.Fields{
+		"roots":    res.Roots,
+		"sidecars": res.Sidecars,
+		"removed":  res.Removed,
+		"elapsed":  time.Since(start),
+	})
+	if res.Sidecars > 0 && !b.blobOrphanRemove {
+		l.Warn("Found blob sidecars without a block, they were reported but not removed")
+		return
+	}
+	l.Info("Scanned blob storage for orphaned blob sidecars")
+}
+
 func (b *BeaconNode) checkAndSaveDepositContract(depositAddress string) error {
 	knownContract, err := b.db.DepositContractAddress(b.ctx)
 	if err != nil {
@@ -816,8 +849,7 @@ func (b *BeaconNode) registerSyncService(initialSyncComplete chan struct{}, bFil
 		return err
 	}
 
-	rs := regularsync.NewService(
-		b.ctx,
+	opts := []regularsync.Option{
 		regularsync.WithDatabase(b.db),
 		regularsync.WithP2P(b.fetchP2P()),
 		regularsync.WithChainService(chainService),
@@ -840,7 +872,12 @@ func (b *BeaconNode) registerSyncService(initialSyncComplete chan struct{}, bFil
 		regularsync.WithBlobStorage(b.BlobStorage),
 		regularsync.WithVerifierWaiter(b.verifyInitWaiter),
 		regularsync.WithAvailableBlocker(bFillStore),
-	)
+		regularsync.WithProposalDutyChecker(chainService),
+	}
+	if b.cliCtx.Bool(bflags.BackfillSkipBlobs.Name) || b.cliCtx.IsSet(bflags.BackfillBlobPruneMargin.Name) {
+		opts = append(opts, regularsync.WithAvailableBlobber(bFillStore))
+	}
+	rs := regularsync.NewService(b.ctx, opts...)
 	return b.services.RegisterService(rs)
 }
 
@@ -898,7 +935,7 @@ func (b *BeaconNode) registerSlasherService() error {
 	return b.services.RegisterService(slasherSrv)
 }
 
-func (b *BeaconNode) registerRPCService(router *http.ServeMux) error {
+func (b *BeaconNode) registerRPCService(router *http.ServeMux, bfs *backfill.Store) error {
 	var chainService *blockchain.Service
 	if err := b.services.FetchService(&chainService); err != nil {
 		return err
@@ -914,6 +951,17 @@ func (b *BeaconNode) registerRPCService(router *http.ServeMux) error {
 		return err
 	}
 
+	var backfillService *backfill.Service
+	if err := b.services.FetchService(&backfillService); err != nil {
+		return err
+	}
+	var backfillReadiness ethnode.BackfillReadiness
+	if b.cliCtx.Bool(bflags.ReadyRequiresBackfill.Name) {
+		backfillReadiness = backfill.NewReadiness(bfs, primitives.Slot(b.cliCtx.Uint64(bflags.ReadyBackfillSlot.Name)))
+	}
+	// The node is only fully synced once backfill has completed, regardless of the readiness slot.
+	fullSync := regularsync.NewFullSync(syncService, backfill.NewReadiness(bfs, 0))
+
 	var slasherService *slasher.Service
 	if features.Get().EnableSlasher {
 		if err := b.services.FetchService(&slasherService); err != nil {
@@ -997,6 +1045,13 @@ func (b *BeaconNode) registerRPCService(router *http.ServeMux) error {
 		BlobStorage:               b.BlobStorage,
 		TrackedValidatorsCache:    b.trackedValidatorsCache,
 		PayloadIDCache:            b.payloadIDCache,
+		BackfillFetcher:           bfs,
+		BackfillReadiness:         backfillReadiness,
+		BackfillHealthFetcher:     backfillService,
+		BackfillStatusChecker:     bfs,
+		BackfillCoverageFetcher:   bfs,
+		BackfillRangeRequester:    backfillService,
+		FullSyncChecker:           fullSync,
 	})
 
 	return b.services.RegisterService(rpcService)
@@ -50,3 +50,13 @@ func WithBlobStorageOptions(opt ...filesystem.BlobStorageOption) Option {
 		return nil
 	}
 }
+
+// WithBlobOrphanScan makes the node scan blob storage at startup for sidecars whose block is not in the database. The
+// orphaned sidecars are only reported, unless remove is true.
+func WithBlobOrphanScan(remove bool) Option {
+	return func(bn *BeaconNode) error {
+		bn.blobOrphanScan = true
+		bn.blobOrphanRemove = remove
+		return nil
+	}
+}
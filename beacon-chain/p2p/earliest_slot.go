@@ -0,0 +1,53 @@
+package p2p
+
+import (
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+)
+
+// earliestAvailableSlotEnrKey is the ENR entry that advertises the earliest slot the node can serve range requests
+// for. Peers that don't know the entry ignore it, and a peer without the entry is assumed to serve the whole
+// retention window. A field in the metadata would need a new version of the metadata schema and req/resp protocol,
+// and could only be read after connecting and asking for it, while the ENR is available from discovery before
+// dialing, so a node looking for history can skip peers that don't have it without connecting to them.
+const earliestAvailableSlotEnrKey = "eas"
+
+// SetEarliestAvailableSlot updates the earliest slot that the node advertises it can serve blocks and blob sidecars
+// for. The ENR is only updated when the value changes. The value is kept if discovery is not running yet, so that it
+// is advertised once the local node is created.
+func (s *Service) SetEarliestAvailableSlot(slot primitives.Slot) {
+	s.earliestSlotLock.Lock()
+	defer s.earliestSlotLock.Unlock()
+	if s.earliestSlot != nil && *s.earliestSlot == slot {
+		return
+	}
+	s.earliestSlot = &slot
+	if s.dv5Listener == nil {
+		return
+	}
+	s.dv5Listener.LocalNode().Set(enr.WithEntry(earliestAvailableSlotEnrKey, uint64(slot)))
+}
+
+// initializeEarliestAvailableSlot adds the earliest available slot entry to a new local node, if it has been set.
+func (s *Service) initializeEarliestAvailableSlot(node *enode.LocalNode) *enode.LocalNode {
+	s.earliestSlotLock.Lock()
+	defer s.earliestSlotLock.Unlock()
+	if s.earliestSlot != nil {
+		node.Set(enr.WithEntry(earliestAvailableSlotEnrKey, uint64(*s.earliestSlot)))
+	}
+	return node
+}
+
+// EarliestAvailableSlot reads the earliest slot that a peer advertises it can serve from its ENR. It returns false if
+// the record is nil or doesn't have the entry.
+func EarliestAvailableSlot(record *enr.Record) (primitives.Slot, bool) {
+	if record == nil {
+		return 0, false
+	}
+	var slot uint64
+	if err := record.Load(enr.WithEntry(earliestAvailableSlotEnrKey, &slot)); err != nil {
+		return 0, false
+	}
+	return primitives.Slot(slot), true
+}
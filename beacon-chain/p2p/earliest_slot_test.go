@@ -0,0 +1,40 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestEarliestAvailableSlot(t *testing.T) {
+	db, err := enode.OpenDB(t.TempDir())
+	require.NoError(t, err)
+	_, key := createAddrAndPrivKey(t)
+
+	// The slot is kept until the local node is created.
+	s := &Service{}
+	s.SetEarliestAvailableSlot(100)
+	node := s.initializeEarliestAvailableSlot(enode.NewLocalNode(db, key))
+	slot, ok := EarliestAvailableSlot(node.Node().Record())
+	require.Equal(t, true, ok)
+	require.Equal(t, primitives.Slot(100), slot)
+
+	// Once discovery is running, the record is updated when the slot changes.
+	s.dv5Listener = mockListener{localNode: node}
+	seq := node.Seq()
+	s.SetEarliestAvailableSlot(200)
+	slot, ok = EarliestAvailableSlot(node.Node().Record())
+	require.Equal(t, true, ok)
+	require.Equal(t, primitives.Slot(200), slot)
+	require.Equal(t, seq+1, node.Seq())
+	s.SetEarliestAvailableSlot(200)
+	require.Equal(t, seq+1, node.Seq())
+
+	// A record without the entry, or no record, is not an error.
+	_, ok = EarliestAvailableSlot(enode.NewLocalNode(db, key).Node().Record())
+	require.Equal(t, false, ok)
+	_, ok = EarliestAvailableSlot(nil)
+	require.Equal(t, false, ok)
+}
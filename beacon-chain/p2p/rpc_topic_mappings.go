@@ -43,6 +43,12 @@ const BlobSidecarsByRangeName = "/blob_sidecars_by_range"
 // BlobSidecarsByRootName is the name for the BlobSidecarsByRoot v1 message topic.
 const BlobSidecarsByRootName = "/blob_sidecars_by_root"
 
+// BlobSidecarCountsByRangeName is the name for the BlobSidecarCountsByRange v1 message topic.
+const BlobSidecarCountsByRangeName = "/blob_sidecar_counts_by_range"
+
+// BlobSidecarsByRangeReverseName is the name for the BlobSidecarsByRangeReverse v1 message topic.
+const BlobSidecarsByRangeReverseName = "/blob_sidecars_by_range_reverse"
+
 const (
 	// V1 RPC Topics
 	// RPCStatusTopicV1 defines the v1 topic for the status rpc method.
@@ -65,6 +71,15 @@ const (
 	// RPCBlobSidecarsByRootTopicV1 is a topic for requesting blob sidecars by their block root. New in deneb.
 	// /eth2/beacon_chain/req/blob_sidecars_by_root/1/
 	RPCBlobSidecarsByRootTopicV1 = protocolPrefix + BlobSidecarsByRootName + SchemaVersionV1
+	// RPCBlobSidecarCountsByRangeTopicV1 is a topic for requesting the number of blob sidecars for each block
+	// in the slot range [start_slot, start_slot + count), without the sidecars themselves. This is not part of the spec.
+	// /eth2/beacon_chain/req/blob_sidecar_counts_by_range/1/
+	RPCBlobSidecarCountsByRangeTopicV1 = protocolPrefix + BlobSidecarCountsByRangeName + SchemaVersionV1
+	// RPCBlobSidecarsByRangeReverseTopicV1 is a topic for requesting the same blob sidecars as BlobSidecarsByRange,
+	// written newest first: slots in descending order, and the sidecars of each slot in ascending index order.
+	// This is not part of the spec.
+	// /eth2/beacon_chain/req/blob_sidecars_by_range_reverse/1/
+	RPCBlobSidecarsByRangeReverseTopicV1 = protocolPrefix + BlobSidecarsByRangeReverseName + SchemaVersionV1
 
 	// V2 RPC Topics
 	// RPCBlocksByRangeTopicV2 defines v2 the topic for the blocks by range rpc method.
@@ -101,6 +116,10 @@ var RPCTopicMappings = map[string]interface{}{
 	RPCBlobSidecarsByRangeTopicV1: new(pb.BlobSidecarsByRangeRequest),
 	// BlobSidecarsByRoot v1 Message
 	RPCBlobSidecarsByRootTopicV1: new(p2ptypes.BlobSidecarsByRootReq),
+	// BlobSidecarCountsByRange v1 Message
+	RPCBlobSidecarCountsByRangeTopicV1: new(pb.BlobSidecarsByRangeRequest),
+	// BlobSidecarsByRangeReverse v1 Message
+	RPCBlobSidecarsByRangeReverseTopicV1: new(pb.BlobSidecarsByRangeRequest),
 }
 
 // Maps all registered protocol prefixes.
@@ -119,6 +138,8 @@ var messageMapping = map[string]bool{
 	MetadataMessageName:            true,
 	BlobSidecarsByRangeName:        true,
 	BlobSidecarsByRootName:         true,
+	BlobSidecarCountsByRangeName:   true,
+	BlobSidecarsByRangeReverseName: true,
 }
 
 // Maps all the RPC messages which are to updated in altair.
@@ -144,6 +165,8 @@ var OmitContextBytesV1 = map[string]bool{
 	BeaconBlocksByRootsMessageName: true,
 	PingMessageName:                true,
 	MetadataMessageName:            true,
+	// Counts are the same for every fork, so the response does not need a schema identifier.
+	BlobSidecarCountsByRangeName: true,
 }
 
 // VerifyTopicMapping verifies that the topic and its accompanying
@@ -192,20 +215,22 @@ func TopicDeconstructor(topic string) (string, string, string, error) {
 		return "", "", "", errors.Errorf("unable to find a valid protocol prefix for %s", origTopic)
 	}
 
+	// Use the longest matching message name, because some names are prefixes of others,
+	// eg blob_sidecars_by_range and blob_sidecars_by_range_reverse.
 	for k := range messageMapping {
 		keyLen := len(k)
-		if keyLen > len(topic) {
+		if keyLen > len(topic) || keyLen <= len(message) {
 			continue
 		}
 		if topic[:keyLen] == k {
 			message = k
-			topic = topic[keyLen:]
 		}
 	}
 
 	if message == "" {
 		return "", "", "", errors.Errorf("unable to find a valid message for %s", origTopic)
 	}
+	topic = topic[len(message):]
 
 	for k := range versionMapping {
 		keyLen := len(k)
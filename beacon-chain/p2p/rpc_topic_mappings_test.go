@@ -57,6 +57,12 @@ func TestTopicDeconstructor(t *testing.T) {
 			expectedError: "",
 			output:        []string{protocolPrefix, BeaconBlocksByRangeMessageName, SchemaVersionV1},
 		},
+		{
+			name:          "message name that another message name is a prefix of",
+			topic:         protocolPrefix + BlobSidecarsByRangeReverseName + SchemaVersionV1 + "/ssz_snappy",
+			expectedError: "",
+			output:        []string{protocolPrefix, BlobSidecarsByRangeReverseName, SchemaVersionV1},
+		},
 		{
 			name:          "beacon block by range topic with malformed version",
 			topic:         protocolPrefix + BeaconBlocksByRangeMessageName + "/v" + "/ssz_snappy",
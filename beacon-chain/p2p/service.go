@@ -26,6 +26,7 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/types"
 	"github.com/prysmaticlabs/prysm/v5/config/features"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	leakybucket "github.com/prysmaticlabs/prysm/v5/container/leaky-bucket"
 	"github.com/prysmaticlabs/prysm/v5/monitoring/tracing/trace"
 	prysmnetwork "github.com/prysmaticlabs/prysm/v5/network"
@@ -78,6 +79,8 @@ type Service struct {
 	genesisTime           time.Time
 	genesisValidatorsRoot []byte
 	activeValidatorCount  uint64
+	earliestSlot          *primitives.Slot
+	earliestSlotLock      sync.Mutex
 }
 
 // NewService initializes a new p2p service compatible with shared.Service interface. No
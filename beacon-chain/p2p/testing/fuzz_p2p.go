@@ -12,6 +12,7 @@ import (
 	"github.com/multiformats/go-multiaddr"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/encoder"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/peers"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
 	"github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1/metadata"
 	"google.golang.org/protobuf/proto"
@@ -68,6 +69,9 @@ func (_ *FakeP2P) FindPeersWithSubnet(_ context.Context, _ string, _ uint64, _ i
 // RefreshENR mocks the p2p func.
 func (_ *FakeP2P) RefreshENR() {}
 
+// SetEarliestAvailableSlot -- fake.
+func (_ *FakeP2P) SetEarliestAvailableSlot(_ primitives.Slot) {}
+
 // LeaveTopic -- fake.
 func (_ *FakeP2P) LeaveTopic(_ string) error {
 	return nil
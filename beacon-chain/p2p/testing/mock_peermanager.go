@@ -8,6 +8,7 @@ import (
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/multiformats/go-multiaddr"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 )
 
 // MockPeerManager is mock of the PeerManager interface.
@@ -50,6 +51,9 @@ func (m MockPeerManager) DiscoveryAddresses() ([]multiaddr.Multiaddr, error) {
 // RefreshENR .
 func (_ MockPeerManager) RefreshENR() {}
 
+// SetEarliestAvailableSlot .
+func (_ MockPeerManager) SetEarliestAvailableSlot(_ primitives.Slot) {}
+
 // FindPeersWithSubnet .
 func (_ MockPeerManager) FindPeersWithSubnet(_ context.Context, _ string, _ uint64, _ int) (bool, error) {
 	return true, nil
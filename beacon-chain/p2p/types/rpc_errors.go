@@ -15,4 +15,5 @@ var (
 	ErrBlobLTMinRequest    = errors.New("blob slot < minimum_request_epoch")
 	ErrMaxBlobReqExceeded  = errors.New("requested more than MAX_REQUEST_BLOB_SIDECARS")
 	ErrResourceUnavailable = errors.New("resource requested unavailable")
+	ErrBlobIndexOutOfRange = errors.New("blob index out of range of block kzg commitments")
 )
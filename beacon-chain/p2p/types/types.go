@@ -10,6 +10,7 @@ import (
 	"github.com/pkg/errors"
 	ssz "github.com/prysmaticlabs/fastssz"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	eth "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
 )
 
@@ -183,6 +184,47 @@ func (b *BlobSidecarsByRootReq) UnmarshalSSZ(buf []byte) error {
 	return nil
 }
 
+// BlobSidecarCount is a response chunk for a BlobSidecarCountsByRange RPC request. It gives the number of blob sidecars
+// the responder has for the canonical block at a slot, without the sidecars themselves.
+type BlobSidecarCount struct {
+	Slot      primitives.Slot
+	BlockRoot [rootLength]byte
+	Count     uint64
+}
+
+// blobSidecarCountSize is the size of the fixed size serialized BlobSidecarCount: slot, root and count.
+const blobSidecarCountSize = 8 + rootLength + 8
+
+// SizeSSZ returns the size of the serialized representation.
+func (c *BlobSidecarCount) SizeSSZ() int {
+	return blobSidecarCountSize
+}
+
+// MarshalSSZTo appends the serialized BlobSidecarCount value to the provided byte slice.
+func (c *BlobSidecarCount) MarshalSSZTo(dst []byte) ([]byte, error) {
+	dst = ssz.MarshalUint64(dst, uint64(c.Slot))
+	dst = append(dst, c.BlockRoot[:]...)
+	dst = ssz.MarshalUint64(dst, c.Count)
+	return dst, nil
+}
+
+// MarshalSSZ serializes the BlobSidecarCount value to a byte slice.
+func (c *BlobSidecarCount) MarshalSSZ() ([]byte, error) {
+	return c.MarshalSSZTo(make([]byte, 0, blobSidecarCountSize))
+}
+
+// UnmarshalSSZ unmarshals the provided bytes buffer into the
+// BlobSidecarCount value.
+func (c *BlobSidecarCount) UnmarshalSSZ(buf []byte) error {
+	if len(buf) != blobSidecarCountSize {
+		return errors.Wrapf(ssz.ErrIncorrectByteSize, "size=%d", len(buf))
+	}
+	c.Slot = primitives.Slot(ssz.UnmarshallUint64(buf[0:8]))
+	copy(c.BlockRoot[:], buf[8:8+rootLength])
+	c.Count = ssz.UnmarshallUint64(buf[8+rootLength:])
+	return nil
+}
+
 var _ sort.Interface = BlobSidecarsByRootReq{}
 
 // Less reports whether the element with index i must sort before the element with index j.
@@ -142,6 +142,18 @@ func roundTripTestErrorMessage(t *testing.T) {
 	assert.DeepEqual(t, []byte(newVal), errMsg)
 }
 
+func TestBlobSidecarCount_RoundTrip(t *testing.T) {
+	c := &BlobSidecarCount{Slot: 123, BlockRoot: [32]byte{0x01, 0x02}, Count: 3}
+	enc, err := c.MarshalSSZ()
+	require.NoError(t, err)
+	require.Equal(t, c.SizeSSZ(), len(enc))
+	got := &BlobSidecarCount{}
+	require.NoError(t, got.UnmarshalSSZ(enc))
+	require.DeepEqual(t, c, got)
+
+	require.ErrorIs(t, got.UnmarshalSSZ(enc[1:]), ssz.ErrIncorrectByteSize)
+}
+
 func TestSSZBytes_HashTreeRoot(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -72,6 +72,7 @@ func (s *Service) endpoints(
 	endpoints = append(endpoints, s.prysmValidatorEndpoints(stater, coreService)...)
 	if enableDebug {
 		endpoints = append(endpoints, s.debugEndpoints(stater)...)
+		endpoints = append(endpoints, s.prysmNodeAdminEndpoints()...)
 	}
 	return endpoints
 }
@@ -395,6 +396,8 @@ func (s *Service) nodeEndpoints() []endpoint {
 		MetadataProvider:          s.cfg.MetadataProvider,
 		HeadFetcher:               s.cfg.HeadFetcher,
 		ExecutionChainInfoFetcher: s.cfg.ExecutionChainInfoFetcher,
+		BackfillReadiness:         s.cfg.BackfillReadiness,
+		FullSyncChecker:           s.cfg.FullSyncChecker,
 	}
 
 	const namespace = "node"
@@ -1093,6 +1096,9 @@ func (s *Service) prysmNodeEndpoints() []endpoint {
 		MetadataProvider:          s.cfg.MetadataProvider,
 		HeadFetcher:               s.cfg.HeadFetcher,
 		ExecutionChainInfoFetcher: s.cfg.ExecutionChainInfoFetcher,
+		BackfillHealthFetcher:     s.cfg.BackfillHealthFetcher,
+		BackfillStatusChecker:     s.cfg.BackfillStatusChecker,
+		BackfillCoverageFetcher:   s.cfg.BackfillCoverageFetcher,
 	}
 
 	const namespace = "prysm.node"
@@ -1153,6 +1159,90 @@ func (s *Service) prysmNodeEndpoints() []endpoint {
 			handler: server.RemoveTrustedPeer,
 			methods: []string{http.MethodDelete},
 		},
+		{
+			template: "/prysm/node/backfill/health",
+			name:     namespace + ".BackfillHealth",
+			middleware: []middleware.Middleware{
+				middleware.AcceptHeaderHandler([]string{api.JsonMediaType}),
+			},
+			handler: server.BackfillHealth,
+			methods: []string{http.MethodGet},
+		},
+		{
+			template: "/prysm/v1/node/backfill/health",
+			name:     namespace + ".BackfillHealth",
+			middleware: []middleware.Middleware{
+				middleware.AcceptHeaderHandler([]string{api.JsonMediaType}),
+			},
+			handler: server.BackfillHealth,
+			methods: []string{http.MethodGet},
+		},
+		{
+			template: "/prysm/node/backfill/consistency",
+			name:     namespace + ".BackfillConsistency",
+			middleware: []middleware.Middleware{
+				middleware.AcceptHeaderHandler([]string{api.JsonMediaType}),
+			},
+			handler: server.BackfillConsistency,
+			methods: []string{http.MethodGet},
+		},
+		{
+			template: "/prysm/v1/node/backfill/consistency",
+			name:     namespace + ".BackfillConsistency",
+			middleware: []middleware.Middleware{
+				middleware.AcceptHeaderHandler([]string{api.JsonMediaType}),
+			},
+			handler: server.BackfillConsistency,
+			methods: []string{http.MethodGet},
+		},
+		{
+			template: "/prysm/node/backfill/available_since",
+			name:     namespace + ".BackfillAvailableSince",
+			middleware: []middleware.Middleware{
+				middleware.AcceptHeaderHandler([]string{api.JsonMediaType}),
+			},
+			handler: server.BackfillAvailableSince,
+			methods: []string{http.MethodGet},
+		},
+		{
+			template: "/prysm/v1/node/backfill/available_since",
+			name:     namespace + ".BackfillAvailableSince",
+			middleware: []middleware.Middleware{
+				middleware.AcceptHeaderHandler([]string{api.JsonMediaType}),
+			},
+			handler: server.BackfillAvailableSince,
+			methods: []string{http.MethodGet},
+		},
+	}
+}
+
+// prysmNodeAdminEndpoints change what the node does rather than report on it, so they are registered alongside the
+// debug endpoints instead of with the rest of the node API.
+func (s *Service) prysmNodeAdminEndpoints() []endpoint {
+	server := &nodeprysm.Server{
+		BackfillRangeRequester: s.cfg.BackfillRangeRequester,
+	}
+
+	const namespace = "prysm.node"
+	return []endpoint{
+		{
+			template: "/prysm/v1/node/backfill/epochs",
+			name:     namespace + ".BackfillEpochRange",
+			middleware: []middleware.Middleware{
+				middleware.ContentTypeHandler([]string{api.JsonMediaType}),
+			},
+			handler: server.BackfillEpochRange,
+			methods: []string{http.MethodPost},
+		},
+		{
+			template: "/prysm/v1/node/backfill/slots",
+			name:     namespace + ".BackfillRange",
+			middleware: []middleware.Middleware{
+				middleware.ContentTypeHandler([]string{api.JsonMediaType}),
+			},
+			handler: server.BackfillRange,
+			methods: []string{http.MethodPost},
+		},
 	}
 }
 
@@ -124,10 +124,18 @@ func Test_endpoints(t *testing.T) {
 	}
 
 	prysmNodeRoutes := map[string][]string{
-		"/prysm/node/trusted_peers":              {http.MethodGet, http.MethodPost},
-		"/prysm/v1/node/trusted_peers":           {http.MethodGet, http.MethodPost},
-		"/prysm/node/trusted_peers/{peer_id}":    {http.MethodDelete},
-		"/prysm/v1/node/trusted_peers/{peer_id}": {http.MethodDelete},
+		"/prysm/node/trusted_peers":               {http.MethodGet, http.MethodPost},
+		"/prysm/v1/node/trusted_peers":            {http.MethodGet, http.MethodPost},
+		"/prysm/node/trusted_peers/{peer_id}":     {http.MethodDelete},
+		"/prysm/v1/node/trusted_peers/{peer_id}":  {http.MethodDelete},
+		"/prysm/node/backfill/health":             {http.MethodGet},
+		"/prysm/v1/node/backfill/health":          {http.MethodGet},
+		"/prysm/node/backfill/consistency":        {http.MethodGet},
+		"/prysm/v1/node/backfill/consistency":     {http.MethodGet},
+		"/prysm/node/backfill/available_since":    {http.MethodGet},
+		"/prysm/v1/node/backfill/available_since": {http.MethodGet},
+		"/prysm/v1/node/backfill/epochs":          {http.MethodPost},
+		"/prysm/v1/node/backfill/slots":           {http.MethodPost},
 	}
 
 	prysmValidatorRoutes := map[string][]string{
@@ -37,14 +37,21 @@ func (s *Server) GetSyncStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	headSynced := s.SyncChecker.Synced()
+	fullySynced := headSynced
+	if s.FullSyncChecker != nil {
+		headSynced, fullySynced = s.FullSyncChecker.HeadSynced(), s.FullSyncChecker.FullySynced()
+	}
 	headSlot := s.HeadFetcher.HeadSlot()
 	response := &structs.SyncStatusResponse{
 		Data: &structs.SyncStatusResponseData{
-			HeadSlot:     strconv.FormatUint(uint64(headSlot), 10),
-			SyncDistance: strconv.FormatUint(uint64(s.GenesisTimeFetcher.CurrentSlot()-headSlot), 10),
-			IsSyncing:    s.SyncChecker.Syncing(),
-			IsOptimistic: isOptimistic,
-			ElOffline:    !s.ExecutionChainInfoFetcher.ExecutionClientConnected(),
+			HeadSlot:      strconv.FormatUint(uint64(headSlot), 10),
+			SyncDistance:  strconv.FormatUint(uint64(s.GenesisTimeFetcher.CurrentSlot()-headSlot), 10),
+			IsSyncing:     s.SyncChecker.Syncing(),
+			IsOptimistic:  isOptimistic,
+			ElOffline:     !s.ExecutionChainInfoFetcher.ExecutionClientConnected(),
+			IsHeadSynced:  headSynced,
+			IsFullySynced: fullySynced,
 		},
 	}
 	httputil.WriteJson(w, response)
@@ -107,6 +114,8 @@ func (*Server) GetVersion(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetHealth returns node health status in http status codes. Useful for load balancers.
+// If the node is configured to require backfill for readiness, a synced node is reported as syncing until
+// backfill has progressed far enough to serve historical data.
 func (s *Server) GetHealth(w http.ResponseWriter, r *http.Request) {
 	ctx, span := trace.StartSpan(r.Context(), "node.GetHealth")
 	defer span.End()
@@ -123,10 +132,11 @@ func (s *Server) GetHealth(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		httputil.HandleError(w, "Could not check optimistic status: "+err.Error(), http.StatusInternalServerError)
 	}
-	if s.SyncChecker.Synced() && !optimistic {
+	backfilling := s.BackfillReadiness != nil && !s.BackfillReadiness.BackfillReady()
+	if s.SyncChecker.Synced() && !optimistic && !backfilling {
 		return
 	}
-	if s.SyncChecker.Syncing() || optimistic {
+	if s.SyncChecker.Syncing() || optimistic || backfilling {
 		if rawSyncingStatus != "" {
 			w.WriteHeader(intSyncingStatus)
 		} else {
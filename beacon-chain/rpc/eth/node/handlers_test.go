@@ -19,6 +19,7 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
 	mockp2p "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/testing"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/rpc/testutil"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync"
 	syncmock "github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/initial-sync/testing"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/wrapper"
@@ -68,6 +69,46 @@ func TestSyncStatus(t *testing.T) {
 	assert.Equal(t, true, resp.Data.IsSyncing)
 	assert.Equal(t, true, resp.Data.IsOptimistic)
 	assert.Equal(t, false, resp.Data.ElOffline)
+	assert.Equal(t, false, resp.Data.IsHeadSynced)
+	assert.Equal(t, false, resp.Data.IsFullySynced)
+}
+
+func TestSyncStatus_Backfill(t *testing.T) {
+	currentSlot := new(primitives.Slot)
+	*currentSlot = 100
+	state, err := util.NewBeaconState()
+	require.NoError(t, err)
+	require.NoError(t, state.SetSlot(100))
+	chainService := &mock.ChainService{Slot: currentSlot, State: state}
+	syncChecker := &syncmock.Sync{IsSynced: true}
+	readiness := &mockBackfillReadiness{}
+	s := &Server{
+		HeadFetcher:               chainService,
+		GenesisTimeFetcher:        chainService,
+		OptimisticModeFetcher:     chainService,
+		SyncChecker:               syncChecker,
+		ExecutionChainInfoFetcher: &testutil.MockExecutionChainInfoFetcher{},
+		FullSyncChecker:           sync.NewFullSync(syncChecker, readiness),
+	}
+
+	status := func() *structs.SyncStatusResponseData {
+		request := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		writer := httptest.NewRecorder()
+		writer.Body = &bytes.Buffer{}
+		s.GetSyncStatus(writer, request)
+		require.Equal(t, http.StatusOK, writer.Code)
+		resp := &structs.SyncStatusResponse{}
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), resp))
+		return resp.Data
+	}
+	d := status()
+	assert.Equal(t, true, d.IsHeadSynced)
+	assert.Equal(t, false, d.IsFullySynced)
+
+	readiness.ready = true
+	d = status()
+	assert.Equal(t, true, d.IsHeadSynced)
+	assert.Equal(t, true, d.IsFullySynced)
 }
 
 func TestGetVersion(t *testing.T) {
@@ -127,6 +168,39 @@ func TestGetHealth(t *testing.T) {
 	writer.Body = &bytes.Buffer{}
 	s.GetHealth(writer, request)
 	assert.Equal(t, http.StatusPartialContent, writer.Code)
+
+	t.Run("backfill readiness", func(t *testing.T) {
+		checker := &syncmock.Sync{IsSynced: true}
+		readiness := &mockBackfillReadiness{}
+		s := &Server{
+			SyncChecker:           checker,
+			OptimisticModeFetcher: &mock.ChainService{},
+			BackfillReadiness:     readiness,
+		}
+		request := httptest.NewRequest(http.MethodGet, "http://example.com/eth/v1/node/health", nil)
+		writer := httptest.NewRecorder()
+		s.GetHealth(writer, request)
+		assert.Equal(t, http.StatusPartialContent, writer.Code)
+
+		request = httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://example.com/eth/v1/node/health?syncing_status=%d", http.StatusPaymentRequired), nil)
+		writer = httptest.NewRecorder()
+		s.GetHealth(writer, request)
+		assert.Equal(t, http.StatusPaymentRequired, writer.Code)
+
+		readiness.ready = true
+		request = httptest.NewRequest(http.MethodGet, "http://example.com/eth/v1/node/health", nil)
+		writer = httptest.NewRecorder()
+		s.GetHealth(writer, request)
+		assert.Equal(t, http.StatusOK, writer.Code)
+	})
+}
+
+type mockBackfillReadiness struct {
+	ready bool
+}
+
+func (m *mockBackfillReadiness) BackfillReady() bool {
+	return m.ready
 }
 
 func TestGetIdentity(t *testing.T) {
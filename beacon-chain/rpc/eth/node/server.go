@@ -26,4 +26,16 @@ type Server struct {
 	GenesisTimeFetcher        blockchain.TimeFetcher
 	HeadFetcher               blockchain.HeadFetcher
 	ExecutionChainInfoFetcher execution.ChainInfoFetcher
+	// BackfillReadiness is optional, when set the health endpoint does not report the node as ready
+	// until backfill has progressed far enough to serve historical data.
+	BackfillReadiness BackfillReadiness
+	// FullSyncChecker is optional, when set the sync status reports whether backfill has completed in addition
+	// to whether the node has synced to head.
+	FullSyncChecker sync.FullSyncChecker
+}
+
+// BackfillReadiness is satisfied by backfill.Readiness, and reports whether backfill has downloaded enough history
+// for the node to serve historical data.
+type BackfillReadiness interface {
+	BackfillReady() bool
 }
@@ -0,0 +1,195 @@
+package node
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/api"
+	"github.com/prysmaticlabs/prysm/v5/api/server/structs"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/rpc/eth/shared"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/backfill"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/monitoring/tracing/trace"
+	"github.com/prysmaticlabs/prysm/v5/network/httputil"
+	"github.com/prysmaticlabs/prysm/v5/time/slots"
+)
+
+// BackfillHealth reports whether backfill is progressing, combining the backfill status with the state of the
+// backfill scheduler. The response code is 200 when backfill is healthy, 206 when it is degraded and 503 when
+// it is stalled, so that the endpoint can be used directly by load balancers and readiness probes.
+func (s *Server) BackfillHealth(w http.ResponseWriter, r *http.Request) {
+	_, span := trace.StartSpan(r.Context(), "node.BackfillHealth")
+	defer span.End()
+
+	if s.BackfillHealthFetcher == nil {
+		httputil.HandleError(w, "Backfill service is not available", http.StatusServiceUnavailable)
+		return
+	}
+	h := s.BackfillHealthFetcher.Health()
+	data := &structs.BackfillHealth{
+		Status:          string(h.Status),
+		Enabled:         h.Enabled,
+		Running:         h.Running,
+		Complete:        h.Complete,
+		GenesisSync:     h.Progress.GenesisSync,
+		LowSlot:         strconv.FormatUint(uint64(h.Progress.LowSlot), 10),
+		OriginSlot:      strconv.FormatUint(uint64(h.Progress.OriginSlot), 10),
+		TargetSlot:      strconv.FormatUint(uint64(h.Progress.TargetSlot), 10),
+		PercentComplete: strconv.FormatFloat(h.Progress.PercentComplete(), 'f', 2, 64),
+		Peers:           strconv.Itoa(h.Peers),
+	}
+	if !h.LastAdvance.IsZero() {
+		data.LastAdvance = h.LastAdvance.UTC().Format(time.RFC3339)
+	}
+
+	w.Header().Set("Content-Type", api.JsonMediaType)
+	w.WriteHeader(backfillHealthCode(h.Status))
+	if err := json.NewEncoder(w).Encode(&structs.BackfillHealthResponse{Data: data}); err != nil {
+		log.WithError(err).Error("Could not write backfill health response")
+	}
+}
+
+// BackfillConsistency checks that the blocks at either end of the backfilled range of history, as tracked by the
+// backfill status, are in the db with the roots and slots given by the status, and reports any mismatches found.
+// It is meant for debugging and does not modify the db.
+func (s *Server) BackfillConsistency(w http.ResponseWriter, r *http.Request) {
+	ctx, span := trace.StartSpan(r.Context(), "node.BackfillConsistency")
+	defer span.End()
+
+	if s.BackfillStatusChecker == nil {
+		httputil.HandleError(w, "Backfill service is not available", http.StatusServiceUnavailable)
+		return
+	}
+	report, err := s.BackfillStatusChecker.CheckConsistency(ctx)
+	if err != nil {
+		httputil.HandleError(w, "Could not check backfill consistency: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data := &structs.BackfillConsistency{
+		Consistent:       !report.Corrupt(),
+		GenesisSync:      report.GenesisSync,
+		LowSlot:          strconv.FormatUint(uint64(report.LowSlot), 10),
+		LowRoot:          hexutil.Encode(report.LowRoot[:]),
+		LowParentRoot:    hexutil.Encode(report.LowParentRoot[:]),
+		LowBlockFound:    report.LowBlockFound,
+		OriginSlot:       strconv.FormatUint(uint64(report.OriginSlot), 10),
+		OriginRoot:       hexutil.Encode(report.OriginRoot[:]),
+		OriginBlockFound: report.OriginBlockFound,
+		Mismatches:       report.Violations,
+	}
+	if data.Mismatches == nil {
+		data.Mismatches = []string{}
+	}
+	httputil.WriteJson(w, &structs.BackfillConsistencyResponse{Data: data})
+}
+
+// BackfillAvailableSince returns the earliest epoch from which the node has the blocks for every slot, with every later
+// epoch also available, so that block explorers can show how far back the history of the node goes. The epoch moves
+// back as backfill progresses. A node synced from genesis reports epoch 0.
+func (s *Server) BackfillAvailableSince(w http.ResponseWriter, r *http.Request) {
+	_, span := trace.StartSpan(r.Context(), "node.BackfillAvailableSince")
+	defer span.End()
+
+	if s.BackfillCoverageFetcher == nil {
+		httputil.HandleError(w, "Backfill service is not available", http.StatusServiceUnavailable)
+		return
+	}
+	epoch := s.BackfillCoverageFetcher.EarliestCoveredEpoch()
+	slot, err := slots.EpochStart(epoch)
+	if err != nil {
+		httputil.HandleError(w, "Could not compute start slot of epoch: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	httputil.WriteJson(w, &structs.BackfillAvailableSinceResponse{Data: &structs.BackfillAvailableSince{
+		Epoch: strconv.FormatUint(uint64(epoch), 10),
+		Slot:  strconv.FormatUint(uint64(slot), 10),
+	}})
+}
+
+// BackfillEpochRange restarts backfill to download the history for a range of epochs before the checkpoint sync
+// origin. Backfill history is a single contiguous range, so the range must reach the lowest backfilled block, and
+// ranges separated from it by a gap are rejected with a 400. The
+// response is sent once backfill has been restarted, BackfillHealth reports on its progress. This endpoint changes
+// what the node downloads, so it is only registered when the debug endpoints are enabled.
+func (s *Server) BackfillEpochRange(w http.ResponseWriter, r *http.Request) {
+	_, span := trace.StartSpan(r.Context(), "node.BackfillEpochRange")
+	defer span.End()
+
+	if s.BackfillRangeRequester == nil {
+		httputil.HandleError(w, "Backfill service is not available", http.StatusServiceUnavailable)
+		return
+	}
+	var req structs.BackfillEpochRangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.HandleError(w, "Could not decode JSON request body", http.StatusBadRequest)
+		return
+	}
+	start, ok := shared.ValidateUint(w, "start_epoch", req.StartEpoch)
+	if !ok {
+		return
+	}
+	end, ok := shared.ValidateUint(w, "end_epoch", req.EndEpoch)
+	if !ok {
+		return
+	}
+	if err := s.BackfillRangeRequester.BackfillEpochRange(primitives.Epoch(start), primitives.Epoch(end)); err != nil {
+		if errors.Is(err, backfill.ErrInvalidEpochRange) || errors.Is(err, backfill.ErrRangeNotContiguous) {
+			httputil.HandleError(w, "Could not backfill epoch range: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		httputil.HandleError(w, "Could not backfill epoch range: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// BackfillRange restarts backfill to download the blocks for the slots [start_slot, end_slot), and responds once they
+// have been imported. Like BackfillEpochRange, the range must reach the lowest backfilled block, and the endpoint is
+// only registered when the debug endpoints are enabled. The wait ends early if the request is canceled, in which case
+// backfill carries on downloading the range.
+func (s *Server) BackfillRange(w http.ResponseWriter, r *http.Request) {
+	ctx, span := trace.StartSpan(r.Context(), "node.BackfillRange")
+	defer span.End()
+
+	if s.BackfillRangeRequester == nil {
+		httputil.HandleError(w, "Backfill service is not available", http.StatusServiceUnavailable)
+		return
+	}
+	var req structs.BackfillRangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.HandleError(w, "Could not decode JSON request body", http.StatusBadRequest)
+		return
+	}
+	start, ok := shared.ValidateUint(w, "start_slot", req.StartSlot)
+	if !ok {
+		return
+	}
+	end, ok := shared.ValidateUint(w, "end_slot", req.EndSlot)
+	if !ok {
+		return
+	}
+	if err := s.BackfillRangeRequester.BackfillRange(ctx, primitives.Slot(start), primitives.Slot(end)); err != nil {
+		if errors.Is(err, backfill.ErrInvalidSlotRange) || errors.Is(err, backfill.ErrRangeNotContiguous) {
+			httputil.HandleError(w, "Could not backfill slot range: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		httputil.HandleError(w, "Could not backfill slot range: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func backfillHealthCode(status backfill.HealthStatus) int {
+	switch status {
+	case backfill.HealthHealthy:
+		return http.StatusOK
+	case backfill.HealthDegraded:
+		return http.StatusPartialContent
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
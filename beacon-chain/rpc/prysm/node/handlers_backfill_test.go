@@ -0,0 +1,242 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/api/server/structs"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/backfill"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/testing/assert"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+type mockBackfillHealth struct {
+	h backfill.Health
+}
+
+func (m *mockBackfillHealth) Health() backfill.Health {
+	return m.h
+}
+
+func TestBackfillHealth(t *testing.T) {
+	advance := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name   string
+		status backfill.HealthStatus
+		code   int
+	}{
+		{name: "healthy", status: backfill.HealthHealthy, code: http.StatusOK},
+		{name: "degraded", status: backfill.HealthDegraded, code: http.StatusPartialContent},
+		{name: "stalled", status: backfill.HealthStalled, code: http.StatusServiceUnavailable},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := Server{BackfillHealthFetcher: &mockBackfillHealth{h: backfill.Health{
+				Status:      c.status,
+				Enabled:     true,
+				Running:     true,
+				Peers:       3,
+				LastAdvance: advance,
+				Progress:    backfill.Progress{LowSlot: 50, OriginSlot: 100},
+			}}}
+			request := httptest.NewRequest(http.MethodGet, "http://example.com/prysm/v1/node/backfill/health", nil)
+			writer := httptest.NewRecorder()
+			s.BackfillHealth(writer, request)
+			require.Equal(t, c.code, writer.Code)
+			resp := &structs.BackfillHealthResponse{}
+			require.NoError(t, json.Unmarshal(writer.Body.Bytes(), resp))
+			require.NotNil(t, resp.Data)
+			assert.Equal(t, string(c.status), resp.Data.Status)
+			assert.Equal(t, true, resp.Data.Running)
+			assert.Equal(t, "50", resp.Data.LowSlot)
+			assert.Equal(t, "100", resp.Data.OriginSlot)
+			assert.Equal(t, "50.00", resp.Data.PercentComplete)
+			assert.Equal(t, "3", resp.Data.Peers)
+			assert.Equal(t, "2024-05-01T12:00:00Z", resp.Data.LastAdvance)
+		})
+	}
+	t.Run("unavailable", func(t *testing.T) {
+		s := Server{}
+		request := httptest.NewRequest(http.MethodGet, "http://example.com/prysm/v1/node/backfill/health", nil)
+		writer := httptest.NewRecorder()
+		s.BackfillHealth(writer, request)
+		require.Equal(t, http.StatusServiceUnavailable, writer.Code)
+	})
+}
+
+type mockBackfillStatusChecker struct {
+	r   *backfill.StatusReport
+	err error
+}
+
+func (m *mockBackfillStatusChecker) CheckConsistency(context.Context) (*backfill.StatusReport, error) {
+	return m.r, m.err
+}
+
+func TestBackfillConsistency(t *testing.T) {
+	check := func(t *testing.T, s Server, code int) *structs.BackfillConsistency {
+		request := httptest.NewRequest(http.MethodGet, "http://example.com/prysm/v1/node/backfill/consistency", nil)
+		writer := httptest.NewRecorder()
+		s.BackfillConsistency(writer, request)
+		require.Equal(t, code, writer.Code)
+		if code != http.StatusOK {
+			return nil
+		}
+		resp := &structs.BackfillConsistencyResponse{}
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), resp))
+		require.NotNil(t, resp.Data)
+		return resp.Data
+	}
+	t.Run("consistent", func(t *testing.T) {
+		data := check(t, Server{BackfillStatusChecker: &mockBackfillStatusChecker{r: &backfill.StatusReport{
+			LowSlot:          50,
+			LowRoot:          [32]byte{0x01},
+			LowBlockFound:    true,
+			OriginSlot:       100,
+			OriginBlockFound: true,
+		}}}, http.StatusOK)
+		assert.Equal(t, true, data.Consistent)
+		assert.Equal(t, "50", data.LowSlot)
+		assert.Equal(t, "0x0100000000000000000000000000000000000000000000000000000000000000", data.LowRoot)
+		assert.Equal(t, "100", data.OriginSlot)
+		assert.Equal(t, true, data.LowBlockFound)
+		assert.Equal(t, 0, len(data.Mismatches))
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		mismatch := "low block root=0x01 not found in db"
+		data := check(t, Server{BackfillStatusChecker: &mockBackfillStatusChecker{r: &backfill.StatusReport{
+			LowSlot:    50,
+			OriginSlot: 100,
+			Violations: []string{mismatch},
+		}}}, http.StatusOK)
+		assert.Equal(t, false, data.Consistent)
+		assert.DeepEqual(t, []string{mismatch}, data.Mismatches)
+	})
+	t.Run("db error", func(t *testing.T) {
+		check(t, Server{BackfillStatusChecker: &mockBackfillStatusChecker{err: errors.New("db closed")}}, http.StatusInternalServerError)
+	})
+	t.Run("unavailable", func(t *testing.T) {
+		check(t, Server{}, http.StatusServiceUnavailable)
+	})
+}
+
+type mockBackfillCoverage struct {
+	epoch primitives.Epoch
+}
+
+func (m *mockBackfillCoverage) EarliestCoveredEpoch() primitives.Epoch {
+	return m.epoch
+}
+
+func TestBackfillAvailableSince(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		s := Server{BackfillCoverageFetcher: &mockBackfillCoverage{epoch: 11}}
+		request := httptest.NewRequest(http.MethodGet, "http://example.com/prysm/v1/node/backfill/available_since", nil)
+		writer := httptest.NewRecorder()
+		s.BackfillAvailableSince(writer, request)
+		require.Equal(t, http.StatusOK, writer.Code)
+		resp := &structs.BackfillAvailableSinceResponse{}
+		require.NoError(t, json.Unmarshal(writer.Body.Bytes(), resp))
+		require.NotNil(t, resp.Data)
+		assert.Equal(t, "11", resp.Data.Epoch)
+		assert.Equal(t, strconv.FormatUint(11*uint64(params.BeaconConfig().SlotsPerEpoch), 10), resp.Data.Slot)
+	})
+	t.Run("unavailable", func(t *testing.T) {
+		s := Server{}
+		request := httptest.NewRequest(http.MethodGet, "http://example.com/prysm/v1/node/backfill/available_since", nil)
+		writer := httptest.NewRecorder()
+		s.BackfillAvailableSince(writer, request)
+		require.Equal(t, http.StatusServiceUnavailable, writer.Code)
+	})
+}
+
+type mockBackfillRange struct {
+	start, end         primitives.Epoch
+	startSlot, endSlot primitives.Slot
+	err                error
+}
+
+func (m *mockBackfillRange) BackfillEpochRange(start, end primitives.Epoch) error {
+	m.start, m.end = start, end
+	return m.err
+}
+
+func (m *mockBackfillRange) BackfillRange(_ context.Context, start, end primitives.Slot) error {
+	m.startSlot, m.endSlot = start, end
+	return m.err
+}
+
+func TestBackfillEpochRange(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		err  error
+		code int
+	}{
+		{name: "ok", body: `{"start_epoch":"10","end_epoch":"20"}`, code: http.StatusOK},
+		{name: "invalid range", body: `{"start_epoch":"20","end_epoch":"10"}`, err: backfill.ErrInvalidEpochRange, code: http.StatusBadRequest},
+		{name: "not contiguous", body: `{"start_epoch":"10","end_epoch":"20"}`, err: backfill.ErrRangeNotContiguous, code: http.StatusBadRequest},
+		{name: "service error", body: `{"start_epoch":"10","end_epoch":"20"}`, err: errors.New("oops"), code: http.StatusInternalServerError},
+		{name: "bad epoch", body: `{"start_epoch":"ten","end_epoch":"20"}`, code: http.StatusBadRequest},
+		{name: "bad body", body: `{`, code: http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := &mockBackfillRange{err: c.err}
+			s := Server{BackfillRangeRequester: m}
+			request := httptest.NewRequest(http.MethodPost, "http://example.com/prysm/v1/node/backfill/epochs", strings.NewReader(c.body))
+			writer := httptest.NewRecorder()
+			s.BackfillEpochRange(writer, request)
+			require.Equal(t, c.code, writer.Code)
+			if c.code == http.StatusOK {
+				assert.Equal(t, primitives.Epoch(10), m.start)
+				assert.Equal(t, primitives.Epoch(20), m.end)
+			}
+		})
+	}
+	t.Run("unavailable", func(t *testing.T) {
+		s := Server{}
+		request := httptest.NewRequest(http.MethodPost, "http://example.com/prysm/v1/node/backfill/epochs", strings.NewReader(`{}`))
+		writer := httptest.NewRecorder()
+		s.BackfillEpochRange(writer, request)
+		require.Equal(t, http.StatusServiceUnavailable, writer.Code)
+	})
+}
+
+func TestBackfillRange(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		err  error
+		code int
+	}{
+		{name: "ok", body: `{"start_slot":"100","end_slot":"200"}`, code: http.StatusOK},
+		{name: "invalid range", body: `{"start_slot":"200","end_slot":"100"}`, err: backfill.ErrInvalidSlotRange, code: http.StatusBadRequest},
+		{name: "not contiguous", body: `{"start_slot":"100","end_slot":"200"}`, err: backfill.ErrRangeNotContiguous, code: http.StatusBadRequest},
+		{name: "interrupted", body: `{"start_slot":"100","end_slot":"200"}`, err: errors.New("interrupted"), code: http.StatusInternalServerError},
+		{name: "bad slot", body: `{"start_slot":"100","end_slot":"-1"}`, code: http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := &mockBackfillRange{err: c.err}
+			s := Server{BackfillRangeRequester: m}
+			request := httptest.NewRequest(http.MethodPost, "http://example.com/prysm/v1/node/backfill/slots", strings.NewReader(c.body))
+			writer := httptest.NewRecorder()
+			s.BackfillRange(writer, request)
+			require.Equal(t, c.code, writer.Code)
+			if c.code == http.StatusOK {
+				assert.Equal(t, primitives.Slot(100), m.startSlot)
+				assert.Equal(t, primitives.Slot(200), m.endSlot)
+			}
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package node
 
 import (
+	"context"
+
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/blockchain"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/execution"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/backfill"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 )
 
 type Server struct {
@@ -18,4 +22,35 @@ type Server struct {
 	GenesisTimeFetcher        blockchain.TimeFetcher
 	HeadFetcher               blockchain.HeadFetcher
 	ExecutionChainInfoFetcher execution.ChainInfoFetcher
+	// BackfillHealthFetcher is optional, when unset the backfill health endpoint reports the service as unavailable.
+	BackfillHealthFetcher BackfillHealthFetcher
+	// BackfillStatusChecker is optional, when unset the backfill consistency endpoint reports the service as
+	// unavailable.
+	BackfillStatusChecker BackfillStatusChecker
+	// BackfillCoverageFetcher is optional, when unset the backfill available since endpoint reports the service as
+	// unavailable.
+	BackfillCoverageFetcher BackfillCoverageFetcher
+	// BackfillRangeRequester is optional, when unset the backfill range endpoint reports the service as unavailable.
+	BackfillRangeRequester BackfillRangeRequester
+}
+
+// BackfillHealthFetcher is satisfied by backfill.Service, and reports on whether backfill is progressing.
+type BackfillHealthFetcher interface {
+	Health() backfill.Health
+}
+
+// BackfillStatusChecker is satisfied by backfill.Store, and compares the backfill status to the blocks in the db.
+type BackfillStatusChecker interface {
+	CheckConsistency(ctx context.Context) (*backfill.StatusReport, error)
+}
+
+// BackfillRangeRequester is satisfied by backfill.Service, and restarts backfill to download a range of history.
+type BackfillRangeRequester interface {
+	BackfillEpochRange(start, end primitives.Epoch) error
+	BackfillRange(ctx context.Context, start, end primitives.Slot) error
+}
+
+// BackfillCoverageFetcher is satisfied by backfill.Store, and reports the range of history covered by the node.
+type BackfillCoverageFetcher interface {
+	EarliestCoveredEpoch() primitives.Epoch
 }
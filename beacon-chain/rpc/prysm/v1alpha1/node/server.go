@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/libp2p/go-libp2p/core/network"
@@ -20,6 +21,7 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/execution"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/backfill"
 	"github.com/prysmaticlabs/prysm/v5/io/logs"
 	"github.com/prysmaticlabs/prysm/v5/monitoring/tracing/trace"
 	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
@@ -47,6 +49,13 @@ type Server struct {
 	POWChainInfoFetcher  execution.ChainInfoFetcher
 	BeaconMonitoringHost string
 	BeaconMonitoringPort int
+	BackfillFetcher      BackfillProgressFetcher
+}
+
+// BackfillProgressFetcher is satisfied by the backfill.Store, which tracks the progress of backfilling
+// block history for checkpoint synced nodes.
+type BackfillProgressFetcher interface {
+	Progress() backfill.Progress
 }
 
 // GetHealth checks the health of the node
@@ -268,6 +277,23 @@ func (ns *Server) GetETH1ConnectionStatus(_ context.Context, _ *empty.Empty) (*e
 	}, nil
 }
 
+// GetBackfillStatus reports the progress of backfilling block history for a checkpoint synced node.
+func (ns *Server) GetBackfillStatus(_ context.Context, _ *empty.Empty) (*ethpb.BackfillStatus, error) {
+	if ns.BackfillFetcher == nil {
+		return nil, status.Error(codes.Unavailable, "Backfill status is not available")
+	}
+	p := ns.BackfillFetcher.Progress()
+	return &ethpb.BackfillStatus{
+		GenesisSync:     p.GenesisSync,
+		LowSlot:         uint64(p.LowSlot),
+		LowRoot:         hexutil.Encode(p.LowRoot[:]),
+		OriginSlot:      uint64(p.OriginSlot),
+		OriginRoot:      hexutil.Encode(p.OriginRoot[:]),
+		TargetSlot:      uint64(p.TargetSlot),
+		PercentComplete: p.PercentComplete(),
+	}, nil
+}
+
 // StreamBeaconLogs from the beacon node via a gRPC server-side stream.
 // DEPRECATED: This endpoint doesn't appear to be used and have been marked for deprecation.
 func (ns *Server) StreamBeaconLogs(_ *empty.Empty, stream ethpb.Health_StreamBeaconLogsServer) error {
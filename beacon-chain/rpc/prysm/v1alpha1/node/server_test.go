@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	mock "github.com/prysmaticlabs/prysm/v5/beacon-chain/blockchain/testing"
@@ -14,6 +15,7 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
 	mockP2p "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/testing"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/rpc/testutil"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/backfill"
 	mockSync "github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/initial-sync/testing"
 	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
 	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
@@ -188,6 +190,44 @@ func TestNodeServer_GetETH1ConnectionStatus(t *testing.T) {
 	assert.Equal(t, errStr, res.CurrentConnectionError)
 }
 
+type mockBackfillProgress struct {
+	p backfill.Progress
+}
+
+func (m *mockBackfillProgress) Progress() backfill.Progress {
+	return m.p
+}
+
+func TestNodeServer_GetBackfillStatus(t *testing.T) {
+	ns := &Server{}
+	_, err := ns.GetBackfillStatus(context.Background(), &emptypb.Empty{})
+	require.ErrorContains(t, "Backfill status is not available", err)
+
+	low, origin := [32]byte{0x01}, [32]byte{0x02}
+	ns.BackfillFetcher = &mockBackfillProgress{p: backfill.Progress{
+		LowSlot:    150,
+		LowRoot:    low,
+		OriginSlot: 200,
+		OriginRoot: origin,
+		TargetSlot: 100,
+	}}
+	res, err := ns.GetBackfillStatus(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, false, res.GenesisSync)
+	assert.Equal(t, uint64(150), res.LowSlot)
+	assert.Equal(t, hexutil.Encode(low[:]), res.LowRoot)
+	assert.Equal(t, uint64(200), res.OriginSlot)
+	assert.Equal(t, hexutil.Encode(origin[:]), res.OriginRoot)
+	assert.Equal(t, uint64(100), res.TargetSlot)
+	assert.Equal(t, float64(50), res.PercentComplete)
+
+	ns.BackfillFetcher = &mockBackfillProgress{p: backfill.Progress{GenesisSync: true}}
+	res, err = ns.GetBackfillStatus(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, true, res.GenesisSync)
+	assert.Equal(t, float64(100), res.PercentComplete)
+}
+
 func TestNodeServer_GetHealth(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -33,8 +33,10 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/operations/voluntaryexits"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/rpc/core"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/rpc/eth/node"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/rpc/eth/rewards"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/rpc/lookup"
+	nodeprysm "github.com/prysmaticlabs/prysm/v5/beacon-chain/rpc/prysm/node"
 	beaconv1alpha1 "github.com/prysmaticlabs/prysm/v5/beacon-chain/rpc/prysm/v1alpha1/beacon"
 	debugv1alpha1 "github.com/prysmaticlabs/prysm/v5/beacon-chain/rpc/prysm/v1alpha1/debug"
 	nodev1alpha1 "github.com/prysmaticlabs/prysm/v5/beacon-chain/rpc/prysm/v1alpha1/node"
@@ -140,6 +142,13 @@ type Config struct {
 	BlobStorage               *filesystem.BlobStorage
 	TrackedValidatorsCache    *cache.TrackedValidatorsCache
 	PayloadIDCache            *cache.PayloadIDCache
+	BackfillFetcher           nodev1alpha1.BackfillProgressFetcher
+	BackfillReadiness         node.BackfillReadiness
+	BackfillHealthFetcher     nodeprysm.BackfillHealthFetcher
+	BackfillStatusChecker     nodeprysm.BackfillStatusChecker
+	BackfillCoverageFetcher   nodeprysm.BackfillCoverageFetcher
+	BackfillRangeRequester    nodeprysm.BackfillRangeRequester
+	FullSyncChecker           chainSync.FullSyncChecker
 }
 
 // NewService instantiates a new RPC service instance that will
@@ -282,6 +291,7 @@ func NewService(ctx context.Context, cfg *Config) *Service {
 		POWChainInfoFetcher:  s.cfg.ExecutionChainInfoFetcher,
 		BeaconMonitoringHost: s.cfg.BeaconMonitoringHost,
 		BeaconMonitoringPort: s.cfg.BeaconMonitoringPort,
+		BackfillFetcher:      s.cfg.BackfillFetcher,
 	}
 	beaconChainServer := &beaconv1alpha1.Server{
 		Ctx:                         s.ctx,
@@ -0,0 +1,80 @@
+package backfill
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
+	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+	"github.com/sirupsen/logrus"
+)
+
+var errTrustedAnchorMismatch = errors.New("backfilled chain does not contain the trusted anchor block")
+
+// trustedAnchor is a block root that the operator trusts independently of the checkpoint sync origin, such as a
+// weak subjectivity checkpoint. Backfill only accepts a chain of blocks that passes through the anchor, so that a
+// compromised checkpoint sync source can't lead the node to backfill a history that doesn't contain it.
+type trustedAnchor struct {
+	root [32]byte
+	slot primitives.Slot
+}
+
+func (a *trustedAnchor) logFields() logrus.Fields {
+	return logrus.Fields{
+		"trustedRoot": fmt.Sprintf("%#x", a.root),
+		"trustedSlot": a.slot,
+	}
+}
+
+// check verifies that the blocks of a batch covering the anchor slot include the anchor block. The blocks are
+// chained together by the verifier, and each batch is chained to the one imported before it, so the highest block
+// at or below the anchor slot must be the anchor block itself. If the batch has no blocks at or below the anchor
+// slot, the anchor block is missing from the range the peer was asked for.
+func (a *trustedAnchor) check(b batch, vb verifiedROBlocks) error {
+	if a == nil || !b.slotRange().Contains(a.slot) {
+		return nil
+	}
+	for i := len(vb) - 1; i >= 0; i-- {
+		slot := vb[i].Block().Slot()
+		if slot > a.slot {
+			continue
+		}
+		if slot != a.slot || vb[i].Root() != a.root {
+			return errors.Wrapf(errTrustedAnchorMismatch, "trusted root=%#x, trusted slot=%d, block root=%#x, block slot=%d",
+				a.root, a.slot, vb[i].Root(), slot)
+		}
+		return nil
+	}
+	return errors.Wrapf(errTrustedAnchorMismatch, "no block at or below trusted slot=%d in batch %s", a.slot, b.id())
+}
+
+// validate compares the anchor to the backfill status loaded from the db, and returns false if backfill can't
+// verify it. An anchor at the origin slot is compared to the origin root, and an anchor that has already been
+// backfilled is compared to the block in the db. Mismatches are logged as warnings, since they indicate that the
+// checkpoint sync source or the anchor can't be trusted; it is up to the operator to decide which.
+func (a *trustedAnchor) validate(ctx context.Context, db BeaconDB, status *dbval.BackfillStatus) bool {
+	origin := primitives.Slot(status.OriginSlot)
+	switch {
+	case a.slot > origin:
+		log.WithFields(a.logFields()).WithField("originSlot", origin).
+			Warn("Trusted backfill anchor is above the checkpoint sync origin and can't be verified by backfill")
+		return false
+	case a.slot == origin:
+		if bytesutil.ToBytes32(status.OriginRoot) != a.root {
+			log.WithFields(a.logFields()).WithField("originRoot", fmt.Sprintf("%#x", status.OriginRoot)).
+				Warn("Trusted backfill anchor does not match the checkpoint sync origin block")
+		}
+		return false
+	case a.slot >= primitives.Slot(status.LowSlot):
+		blk, err := db.Block(ctx, a.root)
+		if err != nil || blocks.BeaconBlockIsNil(blk) != nil || blk.Block().Slot() != a.slot {
+			log.WithFields(a.logFields()).WithField("lowSlot", status.LowSlot).
+				Warn("Trusted backfill anchor is not part of the history that has already been backfilled")
+		}
+		return false
+	}
+	return true
+}
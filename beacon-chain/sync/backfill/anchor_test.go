@@ -0,0 +1,73 @@
+package backfill
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/prysmaticlabs/prysm/v5/testing/util"
+)
+
+// anchorTestChain builds a chain of blocks at the given slots, in ascending order, each the parent of the next.
+func anchorTestChain(t *testing.T, slots ...primitives.Slot) verifiedROBlocks {
+	chain := make(verifiedROBlocks, len(slots))
+	var parent [32]byte
+	for i, sl := range slots {
+		bRaw := util.NewBeaconBlock()
+		bRaw.Block.Slot = sl
+		bRaw.Block.ParentRoot = parent[:]
+		b, err := blocks.NewSignedBeaconBlock(bRaw)
+		require.NoError(t, err)
+		chain[i], err = blocks.NewROBlock(b)
+		require.NoError(t, err)
+		parent = chain[i].Root()
+	}
+	return chain
+}
+
+func TestTrustedAnchorCheck(t *testing.T) {
+	chain := anchorTestChain(t, 10, 12, 15, 18)
+	b := batch{begin: 10, end: 20}
+	var nilAnchor *trustedAnchor
+	require.NoError(t, nilAnchor.check(b, chain))
+
+	cases := []struct {
+		name   string
+		anchor *trustedAnchor
+		blocks verifiedROBlocks
+		err    error
+	}{
+		{name: "outside batch", anchor: &trustedAnchor{slot: 25, root: [32]byte{1}}, blocks: chain},
+		{name: "matching block", anchor: &trustedAnchor{slot: 15, root: chain[2].Root()}, blocks: chain},
+		{name: "different root", anchor: &trustedAnchor{slot: 15, root: [32]byte{1}}, blocks: chain, err: errTrustedAnchorMismatch},
+		{name: "chain skips anchor slot", anchor: &trustedAnchor{slot: 14, root: chain[2].Root()}, blocks: chain, err: errTrustedAnchorMismatch},
+		{name: "no block at or below anchor", anchor: &trustedAnchor{slot: 11, root: chain[0].Root()}, blocks: chain[1:], err: errTrustedAnchorMismatch},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.anchor.check(b, c.blocks)
+			if c.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, c.err)
+		})
+	}
+}
+
+func TestTrustedAnchorValidate(t *testing.T) {
+	ctx := context.Background()
+	chain := anchorTestChain(t, 50, 60)
+	mdb := &mockBackfillDB{blocks: map[[32]byte]blocks.ROBlock{chain[0].Root(): chain[0]}}
+	status := &dbval.BackfillStatus{LowSlot: 40, OriginSlot: 100, OriginRoot: make([]byte, 32)}
+
+	// Only an anchor below the low slot can be verified by backfill.
+	require.Equal(t, true, (&trustedAnchor{slot: 30, root: [32]byte{1}}).validate(ctx, mdb, status))
+	require.Equal(t, false, (&trustedAnchor{slot: 150, root: [32]byte{1}}).validate(ctx, mdb, status))
+	require.Equal(t, false, (&trustedAnchor{slot: 100}).validate(ctx, mdb, status))
+	require.Equal(t, false, (&trustedAnchor{slot: 50, root: chain[0].Root()}).validate(ctx, mdb, status))
+	require.Equal(t, false, (&trustedAnchor{slot: 60, root: chain[1].Root()}).validate(ctx, mdb, status))
+}
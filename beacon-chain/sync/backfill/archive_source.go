@@ -0,0 +1,95 @@
+package backfill
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/filesystem"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/filters"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/interfaces"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/runtime/version"
+)
+
+// ArchiveSource is an ImportSource that reads the history of another node from its beacon db and blob storage, for
+// instance a copy of the data directory of an archive node. Only finalized blocks are read, so that blocks from forks
+// that the archive node also stored are not mistaken for the canonical chain.
+type ArchiveSource struct {
+	db    db.ReadOnlyDatabase
+	blobs *filesystem.BlobStorage
+}
+
+var _ ImportSource = &ArchiveSource{}
+var _ io.Closer = &ArchiveSource{}
+
+// NewArchiveSource returns an ArchiveSource that reads blocks from the db and blob sidecars from the blob storage.
+func NewArchiveSource(d db.ReadOnlyDatabase, blobs *filesystem.BlobStorage) *ArchiveSource {
+	return &ArchiveSource{db: d, blobs: blobs}
+}
+
+// Close closes the archive db, if it can be closed. The blob storage holds no open files between reads.
+func (a *ArchiveSource) Close() error {
+	if c, ok := a.db.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// BlocksByRange returns the finalized blocks in the slot range [start, end), in ascending slot order.
+func (a *ArchiveSource) BlocksByRange(ctx context.Context, start, end primitives.Slot) ([]interfaces.ReadOnlySignedBeaconBlock, error) {
+	if end <= start {
+		return nil, nil
+	}
+	blks, roots, err := a.db.Blocks(ctx, filters.NewFilter().SetStartSlot(start).SetEndSlot(end-1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read archive blocks for slots [%d, %d)", start, end)
+	}
+	finalized := make([]interfaces.ReadOnlySignedBeaconBlock, 0, len(blks))
+	for i := range blks {
+		if a.db.IsFinalizedBlock(ctx, roots[i]) {
+			finalized = append(finalized, blks[i])
+		}
+	}
+	sort.Slice(finalized, func(i, j int) bool {
+		return finalized[i].Block().Slot() < finalized[j].Block().Slot()
+	})
+	return finalized, nil
+}
+
+// BlobSidecarsByRange returns the blob sidecars for the finalized blocks in the slot range [start, end), ordered by
+// slot and then by index. Every sidecar committed to by the blocks must be in the archive blob storage.
+func (a *ArchiveSource) BlobSidecarsByRange(ctx context.Context, start, end primitives.Slot) ([]blocks.ROBlob, error) {
+	blks, err := a.BlocksByRange(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	var scs []blocks.ROBlob
+	for _, b := range blks {
+		if b.Version() < version.Deneb {
+			continue
+		}
+		c, err := b.Block().Body().BlobKzgCommitments()
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read kzg commitments of archive block at slot %d", b.Block().Slot())
+		}
+		if len(c) == 0 {
+			continue
+		}
+		root, err := b.Block().HashTreeRoot()
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not compute root of archive block at slot %d", b.Block().Slot())
+		}
+		for i := range c {
+			sc, err := a.blobs.Get(root, uint64(i))
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not read archive blob sidecar, root=%#x, index=%d", root, i)
+			}
+			scs = append(scs, sc.ROBlob)
+		}
+	}
+	return scs, nil
+}
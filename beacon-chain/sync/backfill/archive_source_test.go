@@ -0,0 +1,83 @@
+package backfill
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/filesystem"
+	dbtest "github.com/prysmaticlabs/prysm/v5/beacon-chain/db/testing"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/prysmaticlabs/prysm/v5/testing/util"
+)
+
+// archiveChain saves a finalized chain of deneb blocks at slots 1 to n, each with nblobs sidecars, and a fork block
+// at slot 2 that is not finalized.
+func archiveChain(t *testing.T, n int, nblobs int) (db.Database, *filesystem.BlobStorage, []blocks.ROBlock) {
+	ctx := context.Background()
+	d := dbtest.SetupDB(t)
+	bs := filesystem.NewEphemeralBlobStorage(t)
+
+	genesis := util.NewBeaconBlock()
+	gr, err := genesis.Block.HashTreeRoot()
+	require.NoError(t, err)
+	util.SaveBlock(t, ctx, d, genesis)
+	require.NoError(t, d.SaveGenesisBlockRoot(ctx, gr))
+
+	chain := make([]blocks.ROBlock, n)
+	parent := gr
+	for i := range chain {
+		blk, scs := util.GenerateTestDenebBlockWithSidecar(t, parent, primitives.Slot(i+1), nblobs)
+		require.NoError(t, d.SaveBlock(ctx, blk))
+		for _, sc := range scs {
+			require.NoError(t, bs.Save(blocks.VerifiedROBlob{ROBlob: sc}))
+		}
+		chain[i] = blk
+		parent = blk.Root()
+	}
+	fork, _ := util.GenerateTestDenebBlockWithSidecar(t, chain[0].Root(), 2, 0)
+	require.NoError(t, d.SaveBlock(ctx, fork))
+
+	head := chain[n-1].Root()
+	require.NoError(t, d.SaveStateSummary(ctx, &ethpb.StateSummary{Slot: chain[n-1].Block().Slot(), Root: head[:]}))
+	require.NoError(t, d.SaveFinalizedCheckpoint(ctx, &ethpb.Checkpoint{Epoch: 1, Root: head[:]}))
+	return d, bs, chain
+}
+
+func TestArchiveSource_BlocksByRange(t *testing.T) {
+	d, bs, chain := archiveChain(t, 4, 0)
+	src := NewArchiveSource(d, bs)
+
+	blks, err := src.BlocksByRange(context.Background(), 2, 4)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(blks))
+	for i, b := range blks {
+		r, err := b.Block().HashTreeRoot()
+		require.NoError(t, err)
+		require.Equal(t, chain[i+1].Root(), r)
+	}
+
+	blks, err = src.BlocksByRange(context.Background(), 4, 4)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(blks))
+}
+
+func TestArchiveSource_BlobSidecarsByRange(t *testing.T) {
+	d, bs, chain := archiveChain(t, 3, 2)
+	src := NewArchiveSource(d, bs)
+
+	scs, err := src.BlobSidecarsByRange(context.Background(), 1, 4)
+	require.NoError(t, err)
+	require.Equal(t, 6, len(scs))
+	for i, sc := range scs {
+		require.Equal(t, chain[i/2].Root(), sc.BlockRoot())
+		require.Equal(t, uint64(i%2), sc.Index)
+	}
+
+	require.NoError(t, bs.Remove(chain[1].Root()))
+	_, err = src.BlobSidecarsByRange(context.Background(), 1, 4)
+	require.ErrorContains(t, "could not read archive blob sidecar", err)
+}
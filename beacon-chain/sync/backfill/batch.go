@@ -2,7 +2,6 @@ package backfill
 
 import (
 	"context"
-	"fmt"
 	"sort"
 	"time"
 
@@ -10,9 +9,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/das"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/backfill/coverage"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	eth "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
 	"github.com/sirupsen/logrus"
 )
 
@@ -56,7 +57,27 @@ const (
 	batchEndSequence
 )
 
-var retryDelay = time.Second
+// A failed batch is retried after retryDelay, which doubles with each subsequent retry of the batch, up to maxRetryDelay.
+var (
+	retryDelay    = time.Second
+	maxRetryDelay = time.Minute
+)
+
+// retryBackoff computes the delay before the given retry of a batch.
+func retryBackoff(retries int) time.Duration {
+	d := retryDelay
+	for i := 1; i < retries && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	if d > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return d
+}
+
+// maxFailedPeers bounds the number of peers that are remembered as having failed to serve the blocks for a batch.
+// The oldest failure is forgotten once the limit is reached, so that a peer becomes eligible for the range again.
+const maxFailedPeers = 4
 
 type batchId string
 
@@ -69,12 +90,18 @@ type batch struct {
 	begin          primitives.Slot
 	end            primitives.Slot // half-open interval, [begin, end), ie >= start, < end.
 	results        verifiedROBlocks
+	bytes          uint64 // approximate size of the downloaded blocks and blobs held by the batch
 	err            error
 	state          batchState
 	busy           peer.ID
+	witnesses      []peer.ID // peers that confirm the blocks of the batch when a quorum is required
 	blockPid       peer.ID
 	blobPid        peer.ID
+	failedPeers    []peer.ID // peers that recently failed to serve the blocks for this batch, most recent last
 	bs             *blobSync
+	// wall is the clock of the sequencer that created the batch. Scheduling and retry times are read from it, so that
+	// they can be compared with the clock that ready and waitUntilReady are given.
+	wall prysmTime.Clock
 }
 
 func (b batch) logFields() logrus.Fields {
@@ -90,6 +117,9 @@ func (b batch) logFields() logrus.Fields {
 		"blockPid":  b.blockPid,
 		"blobPid":   b.blobPid,
 	}
+	if len(b.failedPeers) > 0 {
+		f["failedPeers"] = len(b.failedPeers)
+	}
 	if b.retries > 0 {
 		f["retryAfter"] = b.retryAfter.String()
 	}
@@ -110,7 +140,12 @@ func (b batch) replaces(r batch) bool {
 }
 
 func (b batch) id() batchId {
-	return batchId(fmt.Sprintf("%d:%d", b.begin, b.end))
+	return batchId(b.slotRange().String())
+}
+
+// slotRange returns the range of slots covered by the batch.
+func (b batch) slotRange() coverage.SlotRange {
+	return coverage.SlotRange{Start: b.begin, End: b.end}
 }
 
 func (b batch) ensureParent(expected [32]byte) error {
@@ -124,7 +159,7 @@ func (b batch) ensureParent(expected [32]byte) error {
 func (b batch) blockRequest() *eth.BeaconBlocksByRangeRequest {
 	return &eth.BeaconBlocksByRangeRequest{
 		StartSlot: b.begin,
-		Count:     uint64(b.end - b.begin),
+		Count:     b.slotRange().Len(),
 		Step:      1,
 	}
 }
@@ -132,7 +167,7 @@ func (b batch) blockRequest() *eth.BeaconBlocksByRangeRequest {
 func (b batch) blobRequest() *eth.BlobSidecarsByRangeRequest {
 	return &eth.BlobSidecarsByRangeRequest{
 		StartSlot: b.begin,
-		Count:     uint64(b.end - b.begin),
+		Count:     b.slotRange().Len(),
 	}
 }
 
@@ -150,6 +185,7 @@ func (b batch) postBlobSync() batch {
 		log.WithFields(b.logFields()).WithField("blobsMissing", b.blobsNeeded()).Error("Batch still missing blobs after downloading from peer")
 		b.bs = nil
 		b.results = []blocks.ROBlock{}
+		b.bytes = 0
 		return b.withState(batchErrRetryable)
 	}
 	return b.withState(batchImportable)
@@ -157,18 +193,18 @@ func (b batch) postBlobSync() batch {
 
 func (b batch) withState(s batchState) batch {
 	if s == batchSequenced {
-		b.scheduled = time.Now()
+		b.scheduled = b.wall.Now()
 		switch b.state {
 		case batchErrRetryable:
 			b.retries += 1
-			b.retryAfter = time.Now().Add(retryDelay)
+			b.retryAfter = b.scheduled.Add(retryBackoff(b.retries))
 			log.WithFields(b.logFields()).Info("Sequencing batch for retry after delay")
 		case batchInit, batchNil:
 			b.firstScheduled = b.scheduled
 		}
 	}
 	if s == batchImportComplete {
-		backfillBatchTimeRoundtrip.Observe(float64(time.Since(b.firstScheduled).Milliseconds()))
+		backfillBatchTimeRoundtrip.Observe(float64(b.wall.Now().Sub(b.firstScheduled).Milliseconds()))
 		log.WithFields(b.logFields()).Debug("Backfill batch imported")
 	}
 	b.state = s
@@ -176,9 +212,11 @@ func (b batch) withState(s batchState) batch {
 	return b
 }
 
-func (b batch) withPeer(p peer.ID) batch {
+// withPeer records the peer the batch is assigned to, at the given time, which should be read from the same clock as
+// the clock of the batch.
+func (b batch) withPeer(p peer.ID, now time.Time) batch {
 	b.blockPid = p
-	backfillBatchTimeWaiting.Observe(float64(time.Since(b.scheduled).Milliseconds()))
+	backfillBatchTimeWaiting.Observe(float64(now.Sub(b.scheduled).Milliseconds()))
 	return b
 }
 
@@ -187,6 +225,30 @@ func (b batch) withRetryableError(err error) batch {
 	return b.withState(batchErrRetryable)
 }
 
+// withBlockPeerFailure marks the batch for retry and records that the peer assigned to download its blocks failed,
+// so that the retry can be assigned to a different peer.
+func (b batch) withBlockPeerFailure(err error) batch {
+	backfillBlockRequestFailures.Inc()
+	failed := make([]peer.ID, 0, maxFailedPeers)
+	if len(b.failedPeers) >= maxFailedPeers {
+		failed = append(failed, b.failedPeers[len(b.failedPeers)-maxFailedPeers+1:]...)
+	} else {
+		failed = append(failed, b.failedPeers...)
+	}
+	b.failedPeers = append(failed, b.blockPid)
+	return b.withRetryableError(err)
+}
+
+// failedWith returns true if the given peer recently failed to serve the blocks for this batch.
+func (b batch) failedWith(pid peer.ID) bool {
+	for _, f := range b.failedPeers {
+		if f == pid {
+			return true
+		}
+	}
+	return false
+}
+
 func (b batch) blobsNeeded() int {
 	return b.bs.blobsNeeded()
 }
@@ -199,25 +261,30 @@ func (b batch) availabilityStore() das.AvailabilityStore {
 	return b.bs.store
 }
 
-var batchBlockUntil = func(ctx context.Context, untilRetry time.Duration, b batch) error {
+var batchBlockUntil = func(ctx context.Context, c prysmTime.Clock, untilRetry time.Duration, b batch) error {
 	log.WithFields(b.logFields()).WithField("untilRetry", untilRetry.String()).
 		Debug("Sleeping for retry backoff delay")
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
-	case <-time.After(untilRetry):
+	case <-c.After(untilRetry):
 		return nil
 	}
 }
 
-func (b batch) waitUntilReady(ctx context.Context) error {
+// ready returns false if the batch is waiting for its retry backoff delay.
+func (b batch) ready(now time.Time) bool {
+	return b.retries == 0 || !now.Before(b.retryAfter)
+}
+
+func (b batch) waitUntilReady(ctx context.Context, c prysmTime.Clock) error {
 	// Wait to retry a failed batch to avoid hammering peers
 	// if we've hit a state where batches will consistently fail.
 	// Avoids spamming requests and logs.
 	if b.retries > 0 {
-		untilRetry := time.Until(b.retryAfter)
+		untilRetry := b.retryAfter.Sub(c.Now())
 		if untilRetry > time.Millisecond {
-			return batchBlockUntil(ctx, untilRetry, b)
+			return batchBlockUntil(ctx, c, untilRetry, b)
 		}
 	}
 	return nil
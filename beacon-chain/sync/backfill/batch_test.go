@@ -2,12 +2,16 @@ package backfill
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/pkg/errors"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+	prysmTesting "github.com/prysmaticlabs/prysm/v5/time/testing"
 )
 
 func TestSortBatchDesc(t *testing.T) {
@@ -24,20 +28,79 @@ func TestSortBatchDesc(t *testing.T) {
 }
 
 func TestWaitUntilReady(t *testing.T) {
-	b := batch{}.withState(batchErrRetryable)
+	clock := prysmTesting.NewClock(time.Now())
+	b := batch{wall: clock}.withState(batchErrRetryable)
 	require.Equal(t, time.Time{}, b.retryAfter)
 	var got time.Duration
 	wur := batchBlockUntil
+	defer func() { batchBlockUntil = wur }()
 	var errDerp = errors.New("derp")
-	batchBlockUntil = func(_ context.Context, ur time.Duration, _ batch) error {
+	batchBlockUntil = func(_ context.Context, _ prysmTime.Clock, ur time.Duration, _ batch) error {
 		got = ur
 		return errDerp
 	}
 	// retries counter and timestamp are set when we mark the batch for sequencing, if it is in the retry state
 	b = b.withState(batchSequenced)
-	require.ErrorIs(t, b.waitUntilReady(context.Background()), errDerp)
-	require.Equal(t, true, retryDelay-time.Until(b.retryAfter) < time.Millisecond)
-	require.Equal(t, true, got < retryDelay && got > retryDelay-time.Millisecond)
+	require.ErrorIs(t, b.waitUntilReady(context.Background(), clock), errDerp)
+	require.Equal(t, clock.Now().Add(retryDelay), b.retryAfter)
+	require.Equal(t, retryDelay, got)
 	require.Equal(t, 1, b.retries)
-	batchBlockUntil = wur
+}
+
+func TestWaitUntilReadyClock(t *testing.T) {
+	clock := prysmTesting.NewClock(time.Now())
+	// The retry delay is counted from the clock of the batch, which is the clock it waits on.
+	b := batch{wall: clock}.withRetryableError(errBatchTimeout).withState(batchSequenced)
+	require.Equal(t, clock.Now().Add(retryDelay), b.retryAfter)
+	require.Equal(t, false, b.ready(clock.Now()))
+
+	done := make(chan error)
+	go func() {
+		done <- b.waitUntilReady(context.Background(), clock)
+	}()
+	require.NoError(t, waitForWaiters(clock, 1))
+	clock.Advance(retryDelay - time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("batch ready before its retry delay")
+	default:
+	}
+	clock.Advance(time.Millisecond)
+	require.NoError(t, <-done)
+	require.Equal(t, true, b.ready(clock.Now()))
+}
+
+// waitForWaiters polls until n timers of the simulated clock are pending, so that the clock is only advanced once
+// the code under test is waiting on it.
+func waitForWaiters(clock *prysmTesting.Clock, n int) error {
+	for i := 0; i < 1000; i++ {
+		if clock.Waiters() >= n {
+			return nil
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return errors.New("timed out waiting for timers")
+}
+
+func TestWithBlockPeerFailure(t *testing.T) {
+	b := batch{begin: 0, end: 10, state: batchSequenced}
+	for i := 0; i < maxFailedPeers; i++ {
+		b.blockPid = peer.ID(fmt.Sprintf("peer-%d", i))
+		b = b.withBlockPeerFailure(errBatchTimeout)
+		require.Equal(t, batchErrRetryable, b.state)
+		require.ErrorIs(t, b.err, errBatchTimeout)
+		require.Equal(t, i+1, len(b.failedPeers))
+		require.Equal(t, true, b.failedWith(b.blockPid))
+	}
+	// Once the limit is reached, the oldest failure is forgotten.
+	c := b
+	c.blockPid = "peer-last"
+	c = c.withBlockPeerFailure(errBatchTimeout)
+	require.Equal(t, maxFailedPeers, len(c.failedPeers))
+	require.Equal(t, false, c.failedWith("peer-0"))
+	require.Equal(t, true, c.failedWith("peer-1"))
+	require.Equal(t, true, c.failedWith("peer-last"))
+	// The previous copy of the batch is not modified.
+	require.Equal(t, true, b.failedWith("peer-0"))
+	require.Equal(t, false, b.failedWith("peer-last"))
 }
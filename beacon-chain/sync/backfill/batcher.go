@@ -3,6 +3,7 @@ package backfill
 import (
 	"github.com/pkg/errors"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
 )
 
 var errMaxBatches = errors.New("backfill batch requested in excess of max outstanding batches")
@@ -55,6 +56,20 @@ func (c *batchSequencer) sequence() ([]batch, error) {
 	return s, nil
 }
 
+// sequenceRetries is like sequence, except that only batches that need to be retried are returned.
+// This allows batches blocking the import of already downloaded batches to make progress
+// without adding new batches to the set held in memory.
+func (c *batchSequencer) sequenceRetries() []batch {
+	s := make([]batch, 0)
+	for i := range c.seq {
+		if c.seq[i].state == batchErrRetryable {
+			c.seq[i] = c.seq[i].withState(batchSequenced)
+			s = append(s, c.seq[i])
+		}
+	}
+	return s
+}
+
 // update serves 2 roles.
 //   - updating batchSequencer's copy of the given batch.
 //   - removing batches that are completely imported from the sequence,
@@ -137,6 +152,32 @@ func (c *batchSequencer) countWithState(s batchState) int {
 	return n
 }
 
+// bufferedBytes computes the approximate size of the batches that have been downloaded and verified,
+// but are waiting to be imported.
+func (c *batchSequencer) bufferedBytes() uint64 {
+	var n uint64
+	for i := 0; i < len(c.seq); i++ {
+		if c.seq[i].state == batchImportable {
+			n += c.seq[i].bytes
+		}
+	}
+	return n
+}
+
+// activeLow returns the lowest slot of the batches that are being downloaded or are waiting to be imported, and
+// false if there are none.
+func (c *batchSequencer) activeLow() (primitives.Slot, bool) {
+	for i := len(c.seq) - 1; i >= 0; i-- {
+		switch c.seq[i].state {
+		case batchNil, batchInit, batchImportComplete, batchEndSequence:
+			continue
+		default:
+			return c.seq[i].begin, true
+		}
+	}
+	return 0, false
+}
+
 // numTodo computes the number of remaining batches for metrics and logging purposes.
 func (c *batchSequencer) numTodo() int {
 	if len(c.seq) == 0 {
@@ -158,8 +199,8 @@ func (c *batchSequencer) numTodo() int {
 	return todo
 }
 
-func newBatchSequencer(seqLen int, min, max, size primitives.Slot) *batchSequencer {
-	b := batcher{min: min, max: max, size: size}
+func newBatchSequencer(seqLen int, min, max, size primitives.Slot, wall prysmTime.Clock) *batchSequencer {
+	b := batcher{min: min, max: max, size: size, wall: wall}
 	seq := make([]batch, seqLen)
 	return &batchSequencer{batcher: b, seq: seq}
 }
@@ -168,6 +209,7 @@ type batcher struct {
 	min  primitives.Slot
 	max  primitives.Slot
 	size primitives.Slot
+	wall prysmTime.Clock
 }
 
 func (r batcher) remaining(upTo primitives.Slot) int {
@@ -189,7 +231,7 @@ func (r batcher) before(upTo primitives.Slot) batch {
 	// upTo is an exclusive upper bound. Requesting a batch before the lower bound of backfill signals the end of the
 	// backfill process.
 	if upTo <= r.min {
-		return batch{begin: upTo, end: upTo, state: batchEndSequence}
+		return batch{begin: upTo, end: upTo, state: batchEndSequence, wall: r.wall}
 	}
 	begin := r.min
 	if upTo > r.size+r.min {
@@ -197,5 +239,5 @@ func (r batcher) before(upTo primitives.Slot) batch {
 	}
 
 	// batch.end is exclusive, .begin is inclusive, so the prev.end = next.begin
-	return batch{begin: begin, end: upTo, state: batchInit}
+	return batch{begin: begin, end: upTo, state: batchInit, wall: r.wall}
 }
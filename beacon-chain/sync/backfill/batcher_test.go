@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
 )
 
 func TestBatcherBefore(t *testing.T) {
@@ -17,7 +19,7 @@ func TestBatcherBefore(t *testing.T) {
 	}{
 		{
 			name: "size 10",
-			b:    batcher{min: 0, size: 10},
+			b:    batcher{min: 0, size: 10, wall: prysmTime.RealClock{}},
 			upTo: []primitives.Slot{33, 30, 10, 6},
 			expect: []batch{
 				{begin: 23, end: 33, state: batchInit},
@@ -28,7 +30,7 @@ func TestBatcherBefore(t *testing.T) {
 		},
 		{
 			name: "size 4",
-			b:    batcher{min: 0, size: 4},
+			b:    batcher{min: 0, size: 4, wall: prysmTime.RealClock{}},
 			upTo: []primitives.Slot{33, 6, 4},
 			expect: []batch{
 				{begin: 29, end: 33, state: batchInit},
@@ -38,7 +40,7 @@ func TestBatcherBefore(t *testing.T) {
 		},
 		{
 			name: "trigger end",
-			b:    batcher{min: 20, size: 10},
+			b:    batcher{min: 20, size: 10, wall: prysmTime.RealClock{}},
 			upTo: []primitives.Slot{33, 30, 25, 21, 20, 19},
 			expect: []batch{
 				{begin: 23, end: 33, state: batchInit},
@@ -71,7 +73,7 @@ func TestBatchSingleItem(t *testing.T) {
 	min = 0
 	max = 11235
 	size = 64
-	seq := newBatchSequencer(seqLen, min, max, size)
+	seq := newBatchSequencer(seqLen, min, max, size, prysmTime.RealClock{})
 	got, err := seq.sequence()
 	require.NoError(t, err)
 	require.Equal(t, 1, len(got))
@@ -99,7 +101,7 @@ func TestBatchSequencer(t *testing.T) {
 	min = 0
 	max = 11235
 	size = 64
-	seq := newBatchSequencer(seqLen, min, max, size)
+	seq := newBatchSequencer(seqLen, min, max, size, prysmTime.RealClock{})
 	expected := []batch{
 		{begin: 11171, end: 11235},
 		{begin: 11107, end: 11171},
@@ -235,3 +237,50 @@ func TestBatchSequencer(t *testing.T) {
 	//require.ErrorIs(t, err, errEndSequence)
 	require.Equal(t, batchEndSequence, end.state)
 }
+
+func TestBatchSequencerBufferedBytes(t *testing.T) {
+	seq := newBatchSequencer(4, 0, 1000, 10, prysmTime.RealClock{})
+	got, err := seq.sequence()
+	require.NoError(t, err)
+	require.Equal(t, 4, len(got))
+	require.Equal(t, uint64(0), seq.bufferedBytes())
+
+	// Only batches that are waiting to be imported count toward the buffer.
+	seq.seq[1] = seq.seq[1].withState(batchImportable)
+	seq.seq[1].bytes = 100
+	seq.seq[2] = seq.seq[2].withState(batchImportable)
+	seq.seq[2].bytes = 200
+	seq.seq[3].bytes = 400
+	require.Equal(t, uint64(300), seq.bufferedBytes())
+
+	seq.seq[0] = seq.seq[0].withRetryableError(errors.New("test"))
+	retries := seq.sequenceRetries()
+	require.Equal(t, 1, len(retries))
+	require.Equal(t, seq.seq[0].begin, retries[0].begin)
+	require.Equal(t, batchSequenced, retries[0].state)
+	require.Equal(t, batchSequenced, seq.seq[0].state)
+	require.Equal(t, 0, len(seq.sequenceRetries()))
+}
+
+func TestBatchSequencerActiveLow(t *testing.T) {
+	seq := newBatchSequencer(3, 0, 1000, 10, prysmTime.RealClock{})
+	_, ok := seq.activeLow()
+	require.Equal(t, false, ok)
+
+	_, err := seq.sequence()
+	require.NoError(t, err)
+	low, ok := seq.activeLow()
+	require.Equal(t, true, ok)
+	require.Equal(t, primitives.Slot(970), low)
+
+	// Batches at the end of the sequence don't extend the active range.
+	seq.seq[2] = seq.seq[2].withState(batchEndSequence)
+	low, ok = seq.activeLow()
+	require.Equal(t, true, ok)
+	require.Equal(t, primitives.Slot(980), low)
+
+	seq.seq[0] = seq.seq[0].withState(batchImportComplete)
+	seq.seq[1] = seq.seq[1].withState(batchImportComplete)
+	_, ok = seq.activeLow()
+	require.Equal(t, false, ok)
+}
@@ -0,0 +1,166 @@
+package backfill
+
+import (
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// Tuning for peerBreaker. A peer is excluded from backfill for breakerCooldown after breakerFailureThreshold failed
+// batches, counting only failures that are less than breakerFailureWindow apart.
+var (
+	breakerFailureThreshold = 3
+	breakerFailureWindow    = 5 * time.Minute
+	breakerCooldown         = 2 * time.Minute
+)
+
+type breakerState int
+
+const (
+	// breakerClosed peers are assigned batches as usual.
+	breakerClosed breakerState = iota
+	// breakerOpen peers are not assigned batches until the cooldown has passed.
+	breakerOpen
+	// breakerHalfOpen peers are given another batch after the cooldown. If it fails, the breaker opens again,
+	// otherwise it closes.
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerClosed:
+		return "closed"
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+type peerCircuit struct {
+	state       breakerState
+	failures    int
+	lastFailure time.Time
+	opened      time.Time
+}
+
+// expired reports whether the circuit no longer holds anything worth remembering about the peer: a closed circuit
+// whose failures are too old to add up, or a half-open circuit that was not given its trial batch within a failure
+// window of the cooldown ending, for instance because the peer disconnected. Open circuits don't expire, since they
+// move to half-open once the cooldown has passed.
+func (c *peerCircuit) expired(now time.Time) bool {
+	switch c.state {
+	case breakerClosed:
+		return now.Sub(c.lastFailure) > breakerFailureWindow
+	case breakerHalfOpen:
+		return now.Sub(c.opened) > breakerCooldown+breakerFailureWindow
+	default:
+		return false
+	}
+}
+
+// peerBreaker is a circuit breaker for the peers that backfill downloads batches from. Peers that keep timing out or
+// returning bad batches are excluded from peer assignment for a cooldown, instead of being handed batch after batch.
+// Like inFlightRanges, it is owned by the Service and shared by every worker pool, so restarting the runloop does not
+// give a bad peer a clean slate.
+type peerBreaker struct {
+	sync.Mutex
+	circuits map[peer.ID]*peerCircuit
+}
+
+func newPeerBreaker() *peerBreaker {
+	return &peerBreaker{circuits: make(map[peer.ID]*peerCircuit)}
+}
+
+// failure records a failed batch for the peer, opening the breaker if the peer has failed too often.
+func (pb *peerBreaker) failure(pid peer.ID, now time.Time) {
+	pb.Lock()
+	defer pb.Unlock()
+	defer pb.updateMetrics()
+	c, ok := pb.circuits[pid]
+	if !ok {
+		c = &peerCircuit{}
+		pb.circuits[pid] = c
+	}
+	if c.state == breakerHalfOpen {
+		// The trial batch after the cooldown failed.
+		c.state, c.opened, c.lastFailure = breakerOpen, now, now
+		backfillPeerBreakerOpened.Inc()
+		return
+	}
+	if now.Sub(c.lastFailure) > breakerFailureWindow {
+		c.failures = 0
+	}
+	c.failures++
+	c.lastFailure = now
+	if c.state == breakerClosed && c.failures >= breakerFailureThreshold {
+		c.state, c.opened = breakerOpen, now
+		backfillPeerBreakerOpened.Inc()
+		log.WithField("peer", pid).WithField("failures", c.failures).WithField("cooldown", breakerCooldown).
+			Debug("Excluding peer from backfill after repeated batch failures")
+	}
+}
+
+// success records a batch that the peer served successfully, which closes its breaker.
+func (pb *peerBreaker) success(pid peer.ID) {
+	pb.Lock()
+	defer pb.Unlock()
+	if _, ok := pb.circuits[pid]; !ok {
+		return
+	}
+	delete(pb.circuits, pid)
+	pb.updateMetrics()
+}
+
+// exclude returns a copy of the busy map, with every peer whose breaker is open added. Peers whose cooldown has passed
+// move to half-open and are not excluded, so that they can be given a trial batch. Expired circuits are dropped, so
+// that peers which fail a few batches and then leave are not tracked forever.
+func (pb *peerBreaker) exclude(busy map[peer.ID]bool, now time.Time) map[peer.ID]bool {
+	pb.Lock()
+	defer pb.Unlock()
+	defer pb.updateMetrics()
+	ex := make(map[peer.ID]bool, len(busy)+len(pb.circuits))
+	for pid, b := range busy {
+		ex[pid] = b
+	}
+	for pid, c := range pb.circuits {
+		if c.expired(now) {
+			delete(pb.circuits, pid)
+			continue
+		}
+		if c.state != breakerOpen {
+			continue
+		}
+		if now.Sub(c.opened) >= breakerCooldown {
+			c.state = breakerHalfOpen
+			continue
+		}
+		ex[pid] = true
+	}
+	return ex
+}
+
+// counts returns the number of peers in each breaker state. Peers without recent failures are not tracked, so they
+// are not counted as closed.
+func (pb *peerBreaker) counts() map[breakerState]int {
+	pb.Lock()
+	defer pb.Unlock()
+	return pb.countsLocked()
+}
+
+func (pb *peerBreaker) countsLocked() map[breakerState]int {
+	c := map[breakerState]int{breakerClosed: 0, breakerOpen: 0, breakerHalfOpen: 0}
+	for _, pc := range pb.circuits {
+		c[pc.state]++
+	}
+	return c
+}
+
+func (pb *peerBreaker) updateMetrics() {
+	for state, n := range pb.countsLocked() {
+		backfillPeerBreakerPeers.WithLabelValues(state.String()).Set(float64(n))
+	}
+}
@@ -0,0 +1,101 @@
+package backfill
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+)
+
+func TestPeerBreaker(t *testing.T) {
+	bad, good := peer.ID("bad"), peer.ID("good")
+	now := time.Now()
+	pb := newPeerBreaker()
+	busy := map[peer.ID]bool{good: true}
+
+	for i := 0; i < breakerFailureThreshold-1; i++ {
+		pb.failure(bad, now)
+	}
+	ex := pb.exclude(busy, now)
+	require.Equal(t, false, ex[bad])
+	// The busy map is copied, not modified.
+	require.Equal(t, true, ex[good])
+	require.Equal(t, 1, len(busy))
+
+	pb.failure(bad, now)
+	require.Equal(t, true, pb.exclude(busy, now)[bad])
+	require.Equal(t, 1, pb.counts()[breakerOpen])
+	require.Equal(t, true, pb.exclude(busy, now.Add(breakerCooldown-time.Second))[bad])
+
+	// After the cooldown the peer is half-open and can be assigned a trial batch.
+	now = now.Add(breakerCooldown)
+	require.Equal(t, false, pb.exclude(busy, now)[bad])
+	require.Equal(t, 1, pb.counts()[breakerHalfOpen])
+	// A single failure in the half-open state opens the breaker again.
+	pb.failure(bad, now)
+	require.Equal(t, true, pb.exclude(busy, now)[bad])
+
+	now = now.Add(breakerCooldown)
+	require.Equal(t, false, pb.exclude(busy, now)[bad])
+	pb.success(bad)
+	require.Equal(t, 0, pb.counts()[breakerHalfOpen])
+	require.Equal(t, 0, len(pb.circuits))
+}
+
+func TestPeerBreakerFailureWindow(t *testing.T) {
+	pid := peer.ID("flaky")
+	now := time.Now()
+	pb := newPeerBreaker()
+	// Failures that are further apart than the window don't add up.
+	for i := 0; i < breakerFailureThreshold*2; i++ {
+		pb.failure(pid, now)
+		now = now.Add(breakerFailureWindow + time.Second)
+	}
+	require.Equal(t, false, pb.exclude(nil, now.Add(-time.Second))[pid])
+	require.Equal(t, 1, pb.counts()[breakerClosed])
+	// Once the last failure is older than the window, the circuit is dropped.
+	require.Equal(t, false, pb.exclude(nil, now)[pid])
+	require.Equal(t, 0, len(pb.circuits))
+}
+
+func TestPeerBreakerHalfOpenExpires(t *testing.T) {
+	pid := peer.ID("gone")
+	now := time.Now()
+	pb := newPeerBreaker()
+	for i := 0; i < breakerFailureThreshold; i++ {
+		pb.failure(pid, now)
+	}
+	// Open circuits are kept for the whole cooldown.
+	require.Equal(t, true, pb.exclude(nil, now.Add(breakerCooldown-time.Second))[pid])
+	require.Equal(t, false, pb.exclude(nil, now.Add(breakerCooldown))[pid])
+	require.Equal(t, 1, pb.counts()[breakerHalfOpen])
+	// The peer never got its trial batch, for instance because it disconnected.
+	pb.exclude(nil, now.Add(breakerCooldown+breakerFailureWindow))
+	require.Equal(t, 1, len(pb.circuits))
+	pb.exclude(nil, now.Add(breakerCooldown+breakerFailureWindow+time.Second))
+	require.Equal(t, 0, len(pb.circuits))
+}
+
+func TestPoolRecordOutcome(t *testing.T) {
+	pid := peer.ID("peer")
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &p2pBatchWorkerPool{breaker: newPeerBreaker(), ctx: ctx, wall: prysmTime.RealClock{}}
+	failed := batch{busy: pid}.withRetryableError(errors.New("failed"))
+	for i := 0; i < breakerFailureThreshold; i++ {
+		p.recordOutcome(failed)
+	}
+	require.Equal(t, 1, p.breaker.counts()[breakerOpen])
+	p.recordOutcome(batch{busy: pid}.withState(batchImportable))
+	require.Equal(t, 0, p.breaker.counts()[breakerOpen])
+
+	// Batches abandoned during shutdown are not counted as failures.
+	cancel()
+	for i := 0; i < breakerFailureThreshold; i++ {
+		p.recordOutcome(failed)
+	}
+	require.Equal(t, 0, len(p.breaker.circuits))
+}
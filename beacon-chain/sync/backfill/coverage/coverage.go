@@ -7,3 +7,9 @@ import "github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 type AvailableBlocker interface {
 	AvailableBlock(primitives.Slot) bool
 }
+
+// AvailableBlobber can be used to check whether the blob sidecars for the given slot have been backfilled.
+// This interface is typically fulfilled by backfill.Store.
+type AvailableBlobber interface {
+	BlobSlotCovered(primitives.Slot) bool
+}
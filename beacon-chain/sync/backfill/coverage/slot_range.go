@@ -0,0 +1,41 @@
+package coverage
+
+import (
+	"fmt"
+
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+)
+
+// SlotRange is a half-open range of slots, [Start, End). A range with End <= Start is empty.
+type SlotRange struct {
+	Start primitives.Slot
+	End   primitives.Slot
+}
+
+// NewSlotRangeCount returns the range of count slots beginning at start.
+func NewSlotRangeCount(start primitives.Slot, count uint64) SlotRange {
+	return SlotRange{Start: start, End: start + primitives.Slot(count)}
+}
+
+// Contains returns true if the slot is in the range.
+func (r SlotRange) Contains(s primitives.Slot) bool {
+	return s >= r.Start && s < r.End
+}
+
+// Len returns the number of slots in the range.
+func (r SlotRange) Len() uint64 {
+	if r.End <= r.Start {
+		return 0
+	}
+	return uint64(r.End - r.Start)
+}
+
+// Overlaps returns true if the two ranges have at least one slot in common.
+func (r SlotRange) Overlaps(o SlotRange) bool {
+	return r.Start < o.End && o.Start < r.End
+}
+
+// String formats the range as "start:end", matching the ids used for backfill batches.
+func (r SlotRange) String() string {
+	return fmt.Sprintf("%d:%d", r.Start, r.End)
+}
@@ -0,0 +1,42 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestSlotRange(t *testing.T) {
+	r := SlotRange{Start: 10, End: 20}
+	require.Equal(t, uint64(10), r.Len())
+	require.Equal(t, false, r.Contains(9))
+	require.Equal(t, true, r.Contains(10))
+	require.Equal(t, true, r.Contains(19))
+	require.Equal(t, false, r.Contains(20))
+	require.Equal(t, "10:20", r.String())
+	require.Equal(t, r, NewSlotRangeCount(10, 10))
+
+	empty := SlotRange{Start: 20, End: 10}
+	require.Equal(t, uint64(0), empty.Len())
+	require.Equal(t, false, empty.Contains(15))
+}
+
+func TestSlotRangeOverlaps(t *testing.T) {
+	r := SlotRange{Start: 10, End: 20}
+	cases := []struct {
+		o        SlotRange
+		overlaps bool
+	}{
+		{o: SlotRange{Start: 0, End: 10}, overlaps: false},
+		{o: SlotRange{Start: 0, End: 11}, overlaps: true},
+		{o: SlotRange{Start: 12, End: 15}, overlaps: true},
+		{o: SlotRange{Start: 19, End: 30}, overlaps: true},
+		{o: SlotRange{Start: 20, End: 30}, overlaps: false},
+	}
+	for _, c := range cases {
+		t.Run(c.o.String(), func(t *testing.T) {
+			require.Equal(t, c.overlaps, r.Overlaps(c.o))
+			require.Equal(t, c.overlaps, c.o.Overlaps(r))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package backfill
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/crypto/rand"
+	"github.com/sirupsen/logrus"
+)
+
+var errCoverageMissingBlocks = errors.New("no blocks found in db for range reported as available by backfill status")
+
+// verifyCoverage checks that the db holds at least one block in the range of slots starting at the given slot
+// and spanning one epoch, clipped to the backfilled range. The beacon chain can have empty slots, so a single
+// slot without a block is not a discrepancy, but a full epoch without any blocks is very unlikely in practice.
+func (s *Store) verifyCoverage(ctx context.Context, sl primitives.Slot) error {
+	status := s.status()
+	end := sl + params.BeaconConfig().SlotsPerEpoch
+	if origin := primitives.Slot(status.OriginSlot); end > origin+1 {
+		end = origin + 1
+	}
+	for i := sl; i < end; i++ {
+		found, _, err := s.store.BlockRootsBySlot(ctx, i)
+		if err != nil {
+			return errors.Wrapf(err, "error looking up block roots for slot %d", i)
+		}
+		if found {
+			return nil
+		}
+	}
+	return errors.Wrapf(errCoverageMissingBlocks, "start=%d, end=%d", sl, end)
+}
+
+// sampleCoverage picks a random slot from the range of history that has been backfilled and checks that blocks for
+// that range are actually present in the db. This is a safety net to detect when the backfill status has diverged
+// from the contents of the db, eg due to pruning or a bug, which would cause SlotCovered to report false positives.
+// Discrepancies are logged and counted in metrics, rather than returned, because the check is purely informational.
+func (s *Store) sampleCoverage(ctx context.Context, r *rand.Rand) {
+	if s.isGenesisSync() {
+		return
+	}
+	status := s.status()
+	if status.OriginSlot <= status.LowSlot {
+		return
+	}
+	sl := primitives.Slot(status.LowSlot + uint64(r.Int63n(int64(status.OriginSlot-status.LowSlot))))
+	backfillCoverageChecks.Inc()
+	if err := s.verifyCoverage(ctx, sl); err != nil {
+		if errors.Is(err, errCoverageMissingBlocks) {
+			backfillCoverageDiscrepancies.Inc()
+		}
+		log.WithError(err).WithFields(logrus.Fields{
+			"slot":       sl,
+			"lowSlot":    status.LowSlot,
+			"originSlot": status.OriginSlot,
+		}).Warn("Backfill coverage check failed")
+	}
+}
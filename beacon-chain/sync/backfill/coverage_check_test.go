@@ -0,0 +1,54 @@
+package backfill
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/crypto/rand"
+	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestVerifyCoverage(t *testing.T) {
+	ctx := context.Background()
+	b, err := setupTestBlock(90)
+	require.NoError(t, err)
+	rob, err := blocks.NewROBlock(b)
+	require.NoError(t, err)
+	mdb := &mockBackfillDB{}
+	require.NoError(t, mdb.SaveROBlocks(ctx, []blocks.ROBlock{rob}, false))
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 80, OriginSlot: 200}, store: mdb}
+
+	// Empty slots are expected, so any block within an epoch of the sampled slot is enough.
+	require.NoError(t, s.verifyCoverage(ctx, 80))
+	require.NoError(t, s.verifyCoverage(ctx, 90))
+	require.ErrorIs(t, s.verifyCoverage(ctx, 91), errCoverageMissingBlocks)
+	// The window is clipped to the origin slot.
+	s.bs.OriginSlot = 95
+	require.ErrorIs(t, s.verifyCoverage(ctx, 91), errCoverageMissingBlocks)
+
+	dbErr := errors.New("db failure")
+	mdb.blockRootsBySlot = func(context.Context, primitives.Slot) (bool, [][32]byte, error) {
+		return false, nil, dbErr
+	}
+	require.ErrorIs(t, s.verifyCoverage(ctx, 90), dbErr)
+}
+
+func TestSampleCoverage(t *testing.T) {
+	ctx := context.Background()
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 80, OriginSlot: 200}, store: &mockBackfillDB{}}
+	checks := testutil.ToFloat64(backfillCoverageChecks)
+	discrepancies := testutil.ToFloat64(backfillCoverageDiscrepancies)
+	s.sampleCoverage(ctx, rand.NewDeterministicGenerator())
+	require.Equal(t, checks+1, testutil.ToFloat64(backfillCoverageChecks))
+	require.Equal(t, discrepancies+1, testutil.ToFloat64(backfillCoverageDiscrepancies))
+
+	// Nodes synced from genesis have no backfilled range to check.
+	s.genesisSync = true
+	s.sampleCoverage(ctx, rand.NewDeterministicGenerator())
+	require.Equal(t, checks+1, testutil.ToFloat64(backfillCoverageChecks))
+}
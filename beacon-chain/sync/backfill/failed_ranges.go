@@ -0,0 +1,94 @@
+package backfill
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/backfill/coverage"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+)
+
+// maxBatchAttempts is the number of times a batch range can fail before it is considered unrecoverable. Backfill
+// can't skip a range, because every batch has to connect to the one after it, so unrecoverable ranges are still
+// retried at the maximum backoff delay. They are counted in the backfill_unrecoverable_ranges metric so that
+// operators can see that backfill is stuck on them.
+var maxBatchAttempts = 10
+
+// FailedRange describes a backfill batch range that has failed to download, verify or import,
+// and is waiting to be retried. The embedded SlotRange is the range of the batch.
+type FailedRange struct {
+	coverage.SlotRange
+	Attempts      int
+	LastError     string
+	LastFailure   time.Time
+	Unrecoverable bool
+}
+
+// failedRanges tracks the batch ranges that have failed at least once and have not been imported yet. Like
+// inFlightRanges, it is owned by the Service and shared across restarts of the runloop, so that attempts are
+// counted for the lifetime of the Service.
+type failedRanges struct {
+	sync.Mutex
+	ranges map[batchId]*FailedRange
+	wall   prysmTime.Clock
+}
+
+func newFailedRanges(wall prysmTime.Clock) *failedRanges {
+	return &failedRanges{ranges: make(map[batchId]*FailedRange), wall: wall}
+}
+
+// update records a failed attempt if the batch is in the retryable state, and forgets the range once
+// the batch has been imported.
+func (f *failedRanges) update(b batch) {
+	f.Lock()
+	defer f.Unlock()
+	switch b.state {
+	case batchErrRetryable:
+		r, ok := f.ranges[b.id()]
+		if !ok {
+			r = &FailedRange{SlotRange: b.slotRange()}
+			f.ranges[b.id()] = r
+		}
+		r.Attempts += 1
+		r.LastFailure = f.wall.Now()
+		if b.err != nil {
+			r.LastError = b.err.Error()
+		}
+		if !r.Unrecoverable && r.Attempts >= maxBatchAttempts {
+			r.Unrecoverable = true
+			log.WithFields(b.logFields()).WithField("attempts", r.Attempts).
+				Warn("Backfill batch range has exceeded the maximum number of attempts, retrying at the maximum backoff delay")
+		}
+	case batchImportComplete:
+		delete(f.ranges, b.id())
+	default:
+		return
+	}
+	f.updateMetrics()
+}
+
+// list returns a copy of the failed ranges, in descending slot order like the batches they track.
+func (f *failedRanges) list() []FailedRange {
+	f.Lock()
+	defer f.Unlock()
+	l := make([]FailedRange, 0, len(f.ranges))
+	for _, r := range f.ranges {
+		l = append(l, *r)
+	}
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].End > l[j].End
+	})
+	return l
+}
+
+func (f *failedRanges) updateMetrics() {
+	unrecoverable := 0
+	for _, r := range f.ranges {
+		if r.Unrecoverable {
+			unrecoverable += 1
+		}
+	}
+	backfillFailedRanges.Set(float64(len(f.ranges)))
+	backfillUnrecoverableRanges.Set(float64(unrecoverable))
+}
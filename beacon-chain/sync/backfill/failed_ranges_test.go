@@ -0,0 +1,66 @@
+package backfill
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	prysmTesting "github.com/prysmaticlabs/prysm/v5/time/testing"
+)
+
+func TestFailedRanges(t *testing.T) {
+	defer func(n int) { maxBatchAttempts = n }(maxBatchAttempts)
+	maxBatchAttempts = 3
+	errDerp := errors.New("derp")
+	clock := prysmTesting.NewClock(time.Now())
+	f := newFailedRanges(clock)
+	low := batch{begin: 0, end: 10, state: batchSequenced, wall: clock}
+	high := batch{begin: 10, end: 20, state: batchSequenced, wall: clock}
+
+	// Batches that have not failed are not tracked.
+	f.update(high.withState(batchImportable))
+	require.Equal(t, 0, len(f.list()))
+
+	f.update(low.withRetryableError(errDerp))
+	f.update(high.withRetryableError(errDerp))
+	l := f.list()
+	require.Equal(t, 2, len(l))
+	require.Equal(t, high.begin, l[0].Start)
+	require.Equal(t, low.begin, l[1].Start)
+	require.Equal(t, 1, l[1].Attempts)
+	require.Equal(t, errDerp.Error(), l[1].LastError)
+	require.Equal(t, false, l[1].Unrecoverable)
+	require.Equal(t, clock.Now(), l[1].LastFailure)
+
+	// The range is flagged once it reaches the maximum number of attempts.
+	f.update(low.withRetryableError(errDerp))
+	require.Equal(t, false, f.list()[1].Unrecoverable)
+	f.update(low.withRetryableError(errDerp))
+	l = f.list()
+	require.Equal(t, 3, l[1].Attempts)
+	require.Equal(t, true, l[1].Unrecoverable)
+
+	// Imported ranges are forgotten.
+	f.update(high.withState(batchImportComplete))
+	l = f.list()
+	require.Equal(t, 1, len(l))
+	require.Equal(t, low.begin, l[0].Start)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	require.Equal(t, retryDelay, retryBackoff(1))
+	require.Equal(t, 2*retryDelay, retryBackoff(2))
+	require.Equal(t, 4*retryDelay, retryBackoff(3))
+	require.Equal(t, maxRetryDelay, retryBackoff(100))
+}
+
+func TestBatchReady(t *testing.T) {
+	clock := prysmTesting.NewClock(time.Now())
+	b := batch{begin: 0, end: 10, state: batchSequenced, wall: clock}
+	require.Equal(t, true, b.ready(clock.Now()))
+	b = b.withRetryableError(errBatchTimeout).withState(batchSequenced)
+	require.Equal(t, false, b.ready(clock.Now()))
+	clock.Advance(retryDelay)
+	require.Equal(t, true, b.ready(clock.Now()))
+}
@@ -0,0 +1,102 @@
+package backfill
+
+import (
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// defaultRequestsPerPeer is the number of batches that can be requested from a single peer at the same time by
+// default, so that every worker downloads from a different peer.
+const defaultRequestsPerPeer = 1
+
+// peerFanout tracks the peers that the worker pool has batches in flight with, and limits how many peers are used at
+// the same time, how many batches are requested from each peer, and how many batches are in flight in total. It is only
+// used by the batchRouter goroutine.
+type peerFanout struct {
+	// maxPeers is the largest number of peers with batches in flight. 0 means the number of workers is the only limit.
+	maxPeers int
+	// perPeer is the largest number of batches in flight with a single peer.
+	perPeer int
+	// target is the largest number of batches in flight, scaled with the number of suitable peers, see setTarget.
+	target int
+	active map[peer.ID]int
+}
+
+func newPeerFanout(maxPeers, perPeer int) *peerFanout {
+	if perPeer < 1 {
+		perPeer = defaultRequestsPerPeer
+	}
+	if maxPeers < 0 {
+		maxPeers = 0
+	}
+	return &peerFanout{maxPeers: maxPeers, perPeer: perPeer, active: make(map[peer.ID]int)}
+}
+
+// add records a batch assigned to the peer.
+func (f *peerFanout) add(pid peer.ID) {
+	f.active[pid]++
+	f.updateMetrics()
+}
+
+// remove records that a batch assigned to the peer has been returned by a worker.
+func (f *peerFanout) remove(pid peer.ID) {
+	if f.active[pid] <= 1 {
+		delete(f.active, pid)
+	} else {
+		f.active[pid]--
+	}
+	f.updateMetrics()
+}
+
+// busy returns the peers that can't be assigned another batch, because they have reached the per-peer limit, in the
+// form expected by PeerAssigner.
+func (f *peerFanout) busy() map[peer.ID]bool {
+	busy := make(map[peer.ID]bool, len(f.active))
+	for pid := range f.active {
+		if f.full(pid) {
+			busy[pid] = true
+		}
+	}
+	return busy
+}
+
+// full reports whether the peer has reached the per-peer limit.
+func (f *peerFanout) full(pid peer.ID) bool {
+	return f.active[pid] >= f.perPeer
+}
+
+// allows reports whether a batch can be assigned to the peer without exceeding maxPeers. Once maxPeers peers have
+// batches in flight, only those peers can be assigned more batches. The per-peer limit is enforced by PeerAssigner,
+// which does not pick busy peers.
+func (f *peerFanout) allows(pid peer.ID) bool {
+	if _, ok := f.active[pid]; ok {
+		return true
+	}
+	return f.maxPeers == 0 || len(f.active) < f.maxPeers
+}
+
+// setTarget scales the number of batches that can be in flight with the number of suitable peers, bounded by max.
+// With few peers, requesting more batches than they can serve at the same time only leads to timeouts, while with
+// many peers, every worker can be kept busy.
+func (f *peerFanout) setTarget(suitable, max int) {
+	if f.maxPeers > 0 && suitable > f.maxPeers {
+		suitable = f.maxPeers
+	}
+	f.target = suitable * f.perPeer
+	if f.target > max {
+		f.target = max
+	}
+	backfillTargetConcurrency.Set(float64(f.target))
+}
+
+// belowTarget reports whether another batch can be put in flight without exceeding the target set by setTarget.
+func (f *peerFanout) belowTarget() bool {
+	n := 0
+	for _, c := range f.active {
+		n += c
+	}
+	return n < f.target
+}
+
+func (f *peerFanout) updateMetrics() {
+	backfillPeerFanout.Set(float64(len(f.active)))
+}
@@ -0,0 +1,71 @@
+package backfill
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestPeerFanout(t *testing.T) {
+	a, b, c := peer.ID("a"), peer.ID("b"), peer.ID("c")
+	f := newPeerFanout(2, 2)
+
+	require.Equal(t, true, f.allows(a))
+	f.add(a)
+	// a is below the per-peer limit, so it is not busy.
+	require.Equal(t, false, f.busy()[a])
+	require.Equal(t, true, f.allows(a))
+	f.add(a)
+	require.Equal(t, true, f.busy()[a])
+
+	f.add(b)
+	// The peer limit is reached, so c can't be assigned, but b still can.
+	require.Equal(t, false, f.allows(c))
+	require.Equal(t, true, f.allows(b))
+
+	f.remove(a)
+	require.Equal(t, false, f.busy()[a])
+	require.Equal(t, false, f.allows(c))
+	f.remove(a)
+	require.Equal(t, true, f.allows(c))
+	require.Equal(t, 1, len(f.active))
+}
+
+func TestPeerFanoutDefaults(t *testing.T) {
+	f := newPeerFanout(-1, 0)
+	require.Equal(t, 0, f.maxPeers)
+	require.Equal(t, defaultRequestsPerPeer, f.perPeer)
+	for _, pid := range []peer.ID{"a", "b", "c", "d"} {
+		require.Equal(t, true, f.allows(pid))
+		f.add(pid)
+		// With the default of one request per peer, every peer with a batch is busy, as before limits were added.
+		require.Equal(t, true, f.busy()[pid])
+	}
+}
+
+func TestPeerFanoutTarget(t *testing.T) {
+	f := newPeerFanout(0, 2)
+	// Without suitable peers, nothing can be put in flight.
+	f.setTarget(0, 8)
+	require.Equal(t, false, f.belowTarget())
+
+	// Two suitable peers can serve two batches each.
+	f.setTarget(2, 8)
+	require.Equal(t, 4, f.target)
+	for _, pid := range []peer.ID{"a", "a", "b"} {
+		f.add(pid)
+	}
+	require.Equal(t, true, f.belowTarget())
+	f.add("b")
+	require.Equal(t, false, f.belowTarget())
+
+	// The target never exceeds the max, however many peers are suitable.
+	f.setTarget(10, 8)
+	require.Equal(t, 8, f.target)
+
+	// Suitable peers beyond maxPeers can't be used, so they don't raise the target.
+	f = newPeerFanout(3, 1)
+	f.setTarget(10, 8)
+	require.Equal(t, 3, f.target)
+}
@@ -0,0 +1,54 @@
+package backfill
+
+import (
+	"github.com/prysmaticlabs/prysm/v5/async/event"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+)
+
+// NearlyComplete is sent to subscribers of Store.SubscribeNearlyComplete when backfill enters the final epoch before
+// its target slot, ie the lowest backfilled block is no more than an epoch above the target.
+type NearlyComplete struct {
+	LowSlot    primitives.Slot
+	TargetSlot primitives.Slot
+}
+
+// SubscribeNearlyComplete sends a NearlyComplete event on the channel each time backfill enters the final epoch
+// before its target. The event is sent once, and is only sent again if the gap widens past an epoch, eg because the
+// target is lowered by BackfillEpochRange, and is then closed again. Sends block until every subscriber has received
+// the event, so the channel should be buffered or read promptly.
+func (s *Store) SubscribeNearlyComplete(ch chan<- NearlyComplete) event.Subscription {
+	return s.frontierFeed.Subscribe(ch)
+}
+
+// checkFrontier sends the NearlyComplete event if backfill has just entered the final epoch before its target. It must
+// be called without holding the updating lock, so that a subscriber that is slow to receive the event doesn't hold up
+// status updates. The methods that move the low slot or the target call it once they have released their locks.
+func (s *Store) checkFrontier() {
+	s.Lock()
+	ev, entered := s.updateFrontier()
+	s.Unlock()
+	if entered {
+		s.notifyFrontier(ev)
+	}
+}
+
+// updateFrontier tracks whether backfill is within an epoch of its target. It returns the event to send, and true,
+// when backfill has just entered the final epoch. It must be called with the write lock held.
+func (s *Store) updateFrontier() (NearlyComplete, bool) {
+	if s.genesisSync || s.bs == nil {
+		return NearlyComplete{}, false
+	}
+	low := primitives.Slot(s.bs.LowSlot)
+	near := low <= s.target.Add(uint64(params.BeaconConfig().SlotsPerEpoch))
+	// A node that is already backfilled to the target is not nearly complete, so it doesn't get the event.
+	entered := near && !s.nearFrontier && low > s.target
+	s.nearFrontier = near
+	return NearlyComplete{LowSlot: low, TargetSlot: s.target}, entered
+}
+
+// notifyFrontier logs and sends the event returned by updateFrontier.
+func (s *Store) notifyFrontier(ev NearlyComplete) {
+	log.WithField("lowSlot", ev.LowSlot).WithField("targetSlot", ev.TargetSlot).Info("Backfill nearly complete")
+	s.frontierFeed.Send(ev)
+}
@@ -0,0 +1,85 @@
+package backfill
+
+import (
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestSubscribeNearlyComplete(t *testing.T) {
+	spe := params.BeaconConfig().SlotsPerEpoch
+	s := &Store{}
+	ch := make(chan NearlyComplete, 4)
+	sub := s.SubscribeNearlyComplete(ch)
+	defer sub.Unsubscribe()
+	status := func(low primitives.Slot) *dbval.BackfillStatus {
+		return &dbval.BackfillStatus{LowSlot: uint64(low), OriginSlot: uint64(100 * spe)}
+	}
+	target := 10 * spe
+
+	update := func(low primitives.Slot) {
+		s.swapStatus(status(low))
+		s.checkFrontier()
+	}
+
+	s.setTarget(target)
+	update(50 * spe)
+	require.Equal(t, 0, len(ch))
+	update(target + spe + 1)
+	require.Equal(t, 0, len(ch))
+	update(target + spe)
+	require.Equal(t, 1, len(ch))
+	require.DeepEqual(t, NearlyComplete{LowSlot: target + spe, TargetSlot: target}, <-ch)
+
+	// The event is only sent once while backfill stays within the final epoch, including when it completes.
+	update(target + 1)
+	update(target)
+	require.Equal(t, 0, len(ch))
+
+	// Lowering the target widens the gap again, so the event is sent again when the gap closes.
+	s.setTarget(target - 5*spe)
+	require.Equal(t, 0, len(ch))
+	update(target - 5*spe + 2)
+	require.Equal(t, 1, len(ch))
+}
+
+func TestSubscribeNearlyCompleteAlreadyComplete(t *testing.T) {
+	s := &Store{}
+	ch := make(chan NearlyComplete, 1)
+	sub := s.SubscribeNearlyComplete(ch)
+	defer sub.Unsubscribe()
+	s.swapStatus(&dbval.BackfillStatus{LowSlot: 100, OriginSlot: 1000})
+	s.checkFrontier()
+	s.setTarget(200)
+	require.Equal(t, 0, len(ch))
+}
+
+func TestNearlyCompleteSentWithoutLocks(t *testing.T) {
+	s := &Store{}
+	// The subscriber doesn't read the event, so the send blocks.
+	ch := make(chan NearlyComplete)
+	sub := s.SubscribeNearlyComplete(ch)
+	defer sub.Unsubscribe()
+	s.setTarget(200)
+	s.swapStatus(&dbval.BackfillStatus{LowSlot: 201, OriginSlot: 1000})
+	sent := make(chan struct{})
+	go func() {
+		s.checkFrontier()
+		close(sent)
+	}()
+	// Once nearFrontier is set, the event is being sent.
+	for near := false; !near; {
+		s.RLock()
+		near = s.nearFrontier
+		s.RUnlock()
+	}
+	// Status updates and reads can proceed while the subscriber is slow to receive the event.
+	s.updating.Lock()
+	s.updating.Unlock()
+	require.Equal(t, uint64(201), s.status().LowSlot)
+	<-ch
+	<-sent
+}
@@ -0,0 +1,87 @@
+package backfill
+
+import (
+	"time"
+)
+
+// HealthStatus is a coarse classification of backfill health, suitable for load balancers and readiness probes.
+type HealthStatus string
+
+const (
+	// HealthHealthy means backfill is complete, not needed, or advancing at a reasonable pace.
+	HealthHealthy HealthStatus = "healthy"
+	// HealthDegraded means backfill is running but has no peers or has not advanced recently.
+	HealthDegraded HealthStatus = "degraded"
+	// HealthStalled means backfill is incomplete and either not running or has not advanced for a long time.
+	HealthStalled HealthStatus = "stalled"
+)
+
+var (
+	// healthDegradedAfter is how long backfill can go without advancing before it is considered degraded.
+	healthDegradedAfter = 2 * time.Minute
+	// healthStalledAfter is how long backfill can go without advancing before it is considered stalled.
+	healthStalledAfter = 10 * time.Minute
+)
+
+// Health combines the backfill status with the state of the scheduler.
+type Health struct {
+	Status   HealthStatus
+	Progress Progress
+	// Enabled is false when the backfill service is disabled, in which case Status is always HealthHealthy.
+	Enabled bool
+	// Running is true while the backfill runloop is active.
+	Running bool
+	// Complete is true when backfill has reached its target slot, or the node synced from genesis.
+	Complete bool
+	// Peers is the number of connected peers that backfill can request batches from.
+	Peers int
+	// LastAdvance is the time of the most recent batch import. It is the zero value if no batch has been
+	// imported since the node started.
+	LastAdvance time.Time
+}
+
+// Health reports on the health of the backfill service.
+func (s *Service) Health() Health {
+	h := Health{
+		Progress: s.store.Progress(),
+		Enabled:  s.enabled,
+		Running:  s.run.running(),
+	}
+	h.Complete = h.Progress.PercentComplete() >= 100
+	if s.p2p != nil {
+		h.Peers = len(s.p2p.Peers().Connected())
+	}
+	if advances := s.store.RecentAdvances(); len(advances) > 0 {
+		h.LastAdvance = advances[len(advances)-1].Time
+	}
+	h.Status = h.classify(s.run.scheduledAt(), s.wall.Now())
+	return h
+}
+
+// classify determines the HealthStatus. Time since the last advance is measured from when the scheduler started
+// if no batch has been imported since then, so that a freshly started scheduler is not immediately considered
+// degraded.
+func (h Health) classify(scheduled, now time.Time) HealthStatus {
+	if !h.Enabled || h.Complete {
+		return HealthHealthy
+	}
+	if !h.Running {
+		return HealthStalled
+	}
+	// The runloop is still waiting for its dependencies, eg initial-sync, before scheduling any batches.
+	if scheduled.IsZero() {
+		return HealthDegraded
+	}
+	last := h.LastAdvance
+	if last.Before(scheduled) {
+		last = scheduled
+	}
+	since := now.Sub(last)
+	if since > healthStalledAfter {
+		return HealthStalled
+	}
+	if h.Peers == 0 || since > healthDegradedAfter {
+		return HealthDegraded
+	}
+	return HealthHealthy
+}
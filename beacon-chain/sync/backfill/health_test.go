@@ -0,0 +1,120 @@
+package backfill
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	p2ptest "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/testing"
+	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	prysmTesting "github.com/prysmaticlabs/prysm/v5/time/testing"
+)
+
+func TestHealthClassify(t *testing.T) {
+	now := time.Now()
+	scheduled := now.Add(-time.Hour)
+	cases := []struct {
+		name      string
+		h         Health
+		scheduled time.Time
+		expected  HealthStatus
+	}{
+		{
+			name:     "disabled",
+			h:        Health{},
+			expected: HealthHealthy,
+		},
+		{
+			name:     "complete",
+			h:        Health{Enabled: true, Complete: true},
+			expected: HealthHealthy,
+		},
+		{
+			name:     "not running",
+			h:        Health{Enabled: true},
+			expected: HealthStalled,
+		},
+		{
+			name:     "waiting to schedule",
+			h:        Health{Enabled: true, Running: true, Peers: 1},
+			expected: HealthDegraded,
+		},
+		{
+			name:      "recent advance",
+			h:         Health{Enabled: true, Running: true, Peers: 1, LastAdvance: now.Add(-time.Second)},
+			scheduled: scheduled,
+			expected:  HealthHealthy,
+		},
+		{
+			name:      "no peers",
+			h:         Health{Enabled: true, Running: true, LastAdvance: now.Add(-time.Second)},
+			scheduled: scheduled,
+			expected:  HealthDegraded,
+		},
+		{
+			name:      "slow advance",
+			h:         Health{Enabled: true, Running: true, Peers: 1, LastAdvance: now.Add(-healthDegradedAfter - time.Second)},
+			scheduled: scheduled,
+			expected:  HealthDegraded,
+		},
+		{
+			name:      "no recent advance",
+			h:         Health{Enabled: true, Running: true, Peers: 1, LastAdvance: now.Add(-healthStalledAfter - time.Second)},
+			scheduled: scheduled,
+			expected:  HealthStalled,
+		},
+		{
+			name:      "recently scheduled, no advance",
+			h:         Health{Enabled: true, Running: true, Peers: 1},
+			scheduled: now.Add(-time.Second),
+			expected:  HealthHealthy,
+		},
+		{
+			name:      "advance before restart",
+			h:         Health{Enabled: true, Running: true, Peers: 1, LastAdvance: now.Add(-time.Hour)},
+			scheduled: now.Add(-time.Second),
+			expected:  HealthHealthy,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.expected, c.h.classify(c.scheduled, now))
+		})
+	}
+}
+
+func TestServiceHealth(t *testing.T) {
+	clock := prysmTesting.NewClock(time.Now())
+	s := &Service{
+		enabled: true,
+		p2p:     p2ptest.NewTestP2P(t),
+		store:   &Store{bs: &dbval.BackfillStatus{LowSlot: 100, OriginSlot: 200}},
+		wall:    clock,
+	}
+	h := s.Health()
+	require.Equal(t, HealthStalled, h.Status)
+	require.Equal(t, false, h.Running)
+	require.Equal(t, false, h.Complete)
+
+	ctx, finish, ok := s.run.begin(context.Background())
+	require.Equal(t, true, ok)
+	defer finish()
+	require.NoError(t, ctx.Err())
+	require.Equal(t, HealthDegraded, s.Health().Status)
+
+	s.run.markScheduled(clock.Now())
+	advanced := clock.Now()
+	s.store.recordAdvance(100, advanced)
+	h = s.Health()
+	require.Equal(t, true, h.Running)
+	require.Equal(t, advanced, h.LastAdvance)
+	// The test p2p service has no connected peers.
+	require.Equal(t, 0, h.Peers)
+	require.Equal(t, HealthDegraded, h.Status)
+	clock.Advance(healthStalledAfter + time.Second)
+	require.Equal(t, HealthStalled, s.Health().Status)
+
+	s.store = &Store{genesisSync: true}
+	require.Equal(t, HealthHealthy, s.Health().Status)
+}
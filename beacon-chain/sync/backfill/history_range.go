@@ -0,0 +1,64 @@
+package backfill
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+)
+
+var errInvalidHistoryRange = errors.New("invalid encoded history range")
+
+// HistoryRange is a range of slots, [Earliest, Latest], that a node has block history for.
+type HistoryRange struct {
+	Earliest primitives.Slot
+	Latest   primitives.Slot
+}
+
+// MarshalENR encodes the range as two unsigned varints, Earliest followed by Latest,
+// which keeps the value small enough to be published as an ENR entry.
+func (r HistoryRange) MarshalENR() []byte {
+	b := make([]byte, 0, 2*binary.MaxVarintLen64)
+	b = binary.AppendUvarint(b, uint64(r.Earliest))
+	return binary.AppendUvarint(b, uint64(r.Latest))
+}
+
+// Contains returns true if the given slot is within the range.
+func (r HistoryRange) Contains(sl primitives.Slot) bool {
+	return r.Earliest <= sl && sl <= r.Latest
+}
+
+// ParseHistoryRange decodes a HistoryRange encoded by MarshalENR, eg from a peer's ENR.
+func ParseHistoryRange(b []byte) (HistoryRange, error) {
+	earliest, n := binary.Uvarint(b)
+	if n <= 0 {
+		return HistoryRange{}, errors.Wrap(errInvalidHistoryRange, "could not decode earliest slot")
+	}
+	latest, m := binary.Uvarint(b[n:])
+	if m <= 0 {
+		return HistoryRange{}, errors.Wrap(errInvalidHistoryRange, "could not decode latest slot")
+	}
+	if n+m != len(b) {
+		return HistoryRange{}, errors.Wrapf(errInvalidHistoryRange, "%d unexpected trailing bytes", len(b)-n-m)
+	}
+	if earliest > latest {
+		return HistoryRange{}, errors.Wrapf(errInvalidHistoryRange, "earliest slot %d > latest slot %d", earliest, latest)
+	}
+	return HistoryRange{Earliest: primitives.Slot(earliest), Latest: primitives.Slot(latest)}, nil
+}
+
+// HistoryRange returns the range of block history available to serve to peers. The Store does not track the head of
+// the chain, so the caller provides the latest slot, typically the head slot. The earliest slot is the lowest
+// backfilled slot, or 0 if the node was synced from genesis.
+func (s *Store) HistoryRange(latest primitives.Slot) HistoryRange {
+	s.RLock()
+	defer s.RUnlock()
+	if s.genesisSync || s.bs == nil {
+		return HistoryRange{Earliest: 0, Latest: latest}
+	}
+	earliest := primitives.Slot(s.bs.LowSlot)
+	if earliest > latest {
+		earliest = latest
+	}
+	return HistoryRange{Earliest: earliest, Latest: latest}
+}
@@ -0,0 +1,62 @@
+package backfill
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestHistoryRangeRoundTrip(t *testing.T) {
+	cases := []HistoryRange{
+		{Earliest: 0, Latest: 0},
+		{Earliest: 0, Latest: 9000000},
+		{Earliest: 4700013, Latest: 9000000},
+		{Earliest: math.MaxUint64, Latest: math.MaxUint64},
+	}
+	for _, c := range cases {
+		enc := c.MarshalENR()
+		require.Equal(t, true, len(enc) <= 20)
+		got, err := ParseHistoryRange(enc)
+		require.NoError(t, err)
+		require.Equal(t, c, got)
+	}
+	require.Equal(t, 2, len(HistoryRange{}.MarshalENR()))
+}
+
+func TestParseHistoryRangeErrors(t *testing.T) {
+	valid := HistoryRange{Earliest: 10, Latest: 20}.MarshalENR()
+	cases := []struct {
+		name string
+		b    []byte
+	}{
+		{name: "empty", b: []byte{}},
+		{name: "missing latest", b: valid[:1]},
+		{name: "truncated varint", b: []byte{0x0a, 0x80}},
+		{name: "trailing bytes", b: append(append([]byte{}, valid...), 0x01)},
+		{name: "earliest after latest", b: HistoryRange{Earliest: 20, Latest: 10}.MarshalENR()},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseHistoryRange(c.b)
+			require.ErrorIs(t, err, errInvalidHistoryRange)
+		})
+	}
+}
+
+func TestStoreHistoryRange(t *testing.T) {
+	s := &Store{genesisSync: true}
+	require.Equal(t, HistoryRange{Earliest: 0, Latest: 100}, s.HistoryRange(100))
+
+	s = &Store{bs: &dbval.BackfillStatus{LowSlot: 50, OriginSlot: 90}}
+	hr := s.HistoryRange(100)
+	require.Equal(t, HistoryRange{Earliest: 50, Latest: 100}, hr)
+	require.Equal(t, true, hr.Contains(50))
+	require.Equal(t, true, hr.Contains(100))
+	require.Equal(t, false, hr.Contains(49))
+	require.Equal(t, false, hr.Contains(101))
+	// The range is never inverted, even if the latest slot given is behind the backfill status.
+	require.Equal(t, HistoryRange{Earliest: primitives.Slot(40), Latest: 40}, s.HistoryRange(40))
+}
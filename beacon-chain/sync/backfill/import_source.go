@@ -0,0 +1,175 @@
+package backfill
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/filesystem"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/startup"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/interfaces"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+)
+
+// ImportSource supplies the history that backfill needs from a trusted archive, such as a local copy of the blocks and
+// blobs of another node, so that it can be imported without downloading it from peers. The archive is trusted to be
+// available, not to be correct: batches read from it are verified and connected to the existing history exactly like
+// batches downloaded from peers.
+type ImportSource interface {
+	// BlocksByRange returns the blocks in the slot range [start, end), in ascending slot order.
+	BlocksByRange(ctx context.Context, start, end primitives.Slot) ([]interfaces.ReadOnlySignedBeaconBlock, error)
+	// BlobSidecarsByRange returns the blob sidecars for the blocks in the slot range [start, end), ordered by slot and
+	// then by index, like a BlobSidecarsByRange response.
+	BlobSidecarsByRange(ctx context.Context, start, end primitives.Slot) ([]blocks.ROBlob, error)
+}
+
+// importBatchWorkerPool is a batchWorkerPool that reads batches from an ImportSource. There are no peers to assign, so
+// batches go straight to the workers, which wait out the retry backoff of a failed batch before reading it again.
+type importBatchWorkerPool struct {
+	maxBatches  int
+	newWorker   newWorker
+	toWorkers   chan batch
+	fromWorkers chan batch
+	endSeq      []batch
+	ctx         context.Context
+	cancel      func()
+}
+
+var _ batchWorkerPool = &importBatchWorkerPool{}
+
+// newImportBatchWorkerPool creates a worker pool that reads batches from the source. The inFlight ranges should be
+// shared with any other pool created for the same backfill process, see newP2PBatchWorkerPool.
+func newImportBatchWorkerPool(src ImportSource, maxBatches int, inFlight *inFlightRanges, wall prysmTime.Clock, skipBlobs bool) *importBatchWorkerPool {
+	return &importBatchWorkerPool{
+		newWorker: func(id workerId, in, out chan batch, c *startup.Clock, v *verifier, _ sync.ContextByteVersions, nbv verification.NewBlobVerifier, bfs *filesystem.BlobStorage) worker {
+			return &importWorker{id: id, todo: in, done: out, src: src, v: v, c: c, nbv: nbv, bfs: bfs, wall: wall, inFlight: inFlight, skipBlobs: skipBlobs}
+		},
+		// Buffered so that todo does not block the runloop while every worker is busy.
+		toWorkers:   make(chan batch, maxBatches),
+		fromWorkers: make(chan batch),
+		maxBatches:  maxBatches,
+	}
+}
+
+func (p *importBatchWorkerPool) spawn(ctx context.Context, n int, c *startup.Clock, _ PeerAssigner, v *verifier, cm sync.ContextByteVersions, nbv verification.NewBlobVerifier, bfs *filesystem.BlobStorage) {
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	for i := 0; i < n; i++ {
+		go p.newWorker(workerId(i), p.toWorkers, p.fromWorkers, c, v, cm, nbv, bfs).run(p.ctx)
+	}
+}
+
+func (p *importBatchWorkerPool) todo(b batch) {
+	// Like p2pBatchWorkerPool, batchEndSequence batches are held back from the workers, see complete.
+	if b.state == batchEndSequence {
+		p.endSeq = append(p.endSeq, b)
+		return
+	}
+	p.toWorkers <- b
+}
+
+func (p *importBatchWorkerPool) complete() (batch, error) {
+	if len(p.endSeq) == p.maxBatches {
+		return p.endSeq[0], errEndSequence
+	}
+	select {
+	case b := <-p.fromWorkers:
+		return b, nil
+	case <-p.ctx.Done():
+		log.WithError(p.ctx.Err()).Info("importBatchWorkerPool context canceled, shutting down")
+		return batch{}, p.ctx.Err()
+	}
+}
+
+type importWorker struct {
+	id        workerId
+	todo      chan batch
+	done      chan batch
+	src       ImportSource
+	v         *verifier
+	c         *startup.Clock
+	nbv       verification.NewBlobVerifier
+	bfs       *filesystem.BlobStorage
+	wall      prysmTime.Clock
+	inFlight  *inFlightRanges
+	skipBlobs bool
+}
+
+func (w *importWorker) run(ctx context.Context) {
+	for {
+		select {
+		case b := <-w.todo:
+			if err := b.waitUntilReady(ctx, w.wall); err != nil {
+				return
+			}
+			w.inFlight.add(b)
+			b = w.handle(ctx, b)
+			w.inFlight.remove(b)
+			select {
+			case w.done <- b:
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
+			log.WithField("backfillWorker", w.id).Info("Backfill import worker exiting after context canceled")
+			return
+		}
+	}
+}
+
+// handle reads the blocks of the batch from the source, and then its blobs, applying the same checks to them as
+// p2pWorker applies to the responses of peers.
+func (w *importWorker) handle(ctx context.Context, b batch) batch {
+	cs := w.c.CurrentSlot()
+	blobRetentionStart, err := sync.BlobRPCMinValidSlot(cs)
+	if err != nil {
+		return b.withRetryableError(errors.Wrap(err, "configuration issue, could not compute minimum blob retention slot"))
+	}
+	results, err := w.src.BlocksByRange(ctx, b.begin, b.end)
+	if err != nil {
+		return b.withRetryableError(errors.Wrap(err, "could not read blocks from import source"))
+	}
+	// An empty range is valid, since every slot in it may have been skipped. The importer checks that the next
+	// batch with blocks still links up with the chain.
+	vb, err := w.v.verify(results)
+	if err != nil {
+		log.WithError(err).WithFields(b.logFields()).Warn("Blocks from backfill import source failed validation")
+		return b.withRetryableError(err)
+	}
+	if err := w.v.anchor.check(b, vb); err != nil {
+		backfillTrustedAnchorMismatches.Inc()
+		log.WithError(err).WithFields(b.logFields()).Warn("Backfill import source batch does not contain the trusted anchor block")
+		return b.withRetryableError(err)
+	}
+	for i := range vb {
+		b.bytes += uint64(vb[i].SizeSSZ())
+	}
+	if w.skipBlobs {
+		return b.withResults(vb, &blobSync{})
+	}
+	bs, err := newBlobSync(cs, vb, &blobSyncConfig{retentionStart: blobRetentionStart, nbv: w.nbv, store: w.bfs})
+	if err != nil {
+		return b.withRetryableError(err)
+	}
+	b = b.withResults(vb, bs)
+	if b.state != batchBlobSync {
+		return b
+	}
+	blobs, err := w.src.BlobSidecarsByRange(ctx, b.begin, b.end)
+	if err != nil {
+		b.bs = nil
+		return b.withRetryableError(errors.Wrap(err, "could not read blob sidecars from import source"))
+	}
+	validate := b.blobResponseValidator()
+	for i := range blobs {
+		if err := validate(blobs[i]); err != nil {
+			log.WithError(err).WithFields(b.logFields()).Warn("Blob sidecars from backfill import source failed validation")
+			b.bs = nil
+			return b.withRetryableError(err)
+		}
+		b.bytes += uint64(blobs[i].SizeSSZ())
+	}
+	return b.postBlobSync()
+}
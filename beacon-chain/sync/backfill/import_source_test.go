@@ -0,0 +1,109 @@
+package backfill
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/startup"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/interfaces"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+)
+
+type mockImportSource struct {
+	blocks    []interfaces.ReadOnlySignedBeaconBlock
+	blocksErr error
+	requested [][2]primitives.Slot
+}
+
+var _ ImportSource = &mockImportSource{}
+
+func (m *mockImportSource) BlocksByRange(_ context.Context, start, end primitives.Slot) ([]interfaces.ReadOnlySignedBeaconBlock, error) {
+	m.requested = append(m.requested, [2]primitives.Slot{start, end})
+	return m.blocks, m.blocksErr
+}
+
+func (m *mockImportSource) BlobSidecarsByRange(_ context.Context, _, _ primitives.Slot) ([]blocks.ROBlob, error) {
+	return nil, nil
+}
+
+func TestImportWorkerHandleErrors(t *testing.T) {
+	clock := startup.NewClock(time.Now(), [32]byte{})
+	b := batch{begin: 100, end: 164, state: batchSequenced}
+	t.Run("source error", func(t *testing.T) {
+		srcErr := errors.New("archive unavailable")
+		src := &mockImportSource{blocksErr: srcErr}
+		w := &importWorker{src: src, c: clock}
+		done := w.handle(context.Background(), b)
+		require.ErrorIs(t, done.err, srcErr)
+		require.Equal(t, batchErrRetryable, done.state)
+		require.Equal(t, 1, len(src.requested))
+		require.Equal(t, [2]primitives.Slot{100, 164}, src.requested[0])
+	})
+	t.Run("no blocks", func(t *testing.T) {
+		// Every slot in the range may have been skipped, so an empty range is imported like any other.
+		w := &importWorker{src: &mockImportSource{}, c: clock, v: &verifier{}}
+		done := w.handle(context.Background(), b)
+		require.NoError(t, done.err)
+		require.Equal(t, batchImportable, done.state)
+		require.Equal(t, 0, len(done.results))
+	})
+}
+
+func TestImportBatchWorkerPool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nw := 2
+	srcErr := errors.New("archive unavailable")
+	pool := newImportBatchWorkerPool(&mockImportSource{blocksErr: srcErr}, nw, newInFlightRanges(), prysmTime.RealClock{}, true)
+	pool.spawn(ctx, nw, startup.NewClock(time.Now(), [32]byte{}), nil, nil, nil, nil, nil)
+
+	// Batches are handed to the workers without a peer assigned.
+	pool.todo(batch{begin: 100, end: 164, state: batchSequenced})
+	b, err := pool.complete()
+	require.NoError(t, err)
+	require.ErrorIs(t, b.err, srcErr)
+	require.Equal(t, batchErrRetryable, b.state)
+
+	br := batcher{min: 10, size: 10, wall: prysmTime.RealClock{}}
+	endSeq := br.before(0)
+	for i := 0; i < nw; i++ {
+		pool.todo(endSeq)
+	}
+	b, err = pool.complete()
+	require.ErrorIs(t, err, errEndSequence)
+	require.Equal(t, endSeq.end, b.end)
+}
+
+func TestWithImportSource(t *testing.T) {
+	s, err := NewService(context.Background(), &Store{}, nil, nil, nil, nil, WithWorkerCount(1), WithImportSource(&mockImportSource{}))
+	require.NoError(t, err)
+	_, ok := s.newPool().(*importBatchWorkerPool)
+	require.Equal(t, true, ok)
+}
+
+type closingImportSource struct {
+	mockImportSource
+	closed int
+}
+
+func (c *closingImportSource) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestStopClosesImportSource(t *testing.T) {
+	src := &closingImportSource{}
+	s := &Service{ctx: context.Background(), enabled: true, importSource: src}
+	require.NoError(t, s.Stop())
+	require.Equal(t, 1, src.closed)
+	// The source can't be read once it is closed, so the runloop is not resumed, and the source is closed only once.
+	s.Resume()
+	require.Equal(t, false, s.run.running())
+	require.NoError(t, s.Stop())
+	require.Equal(t, 1, src.closed)
+}
@@ -0,0 +1,44 @@
+package backfill
+
+import (
+	"sync"
+)
+
+// inFlightRanges tracks the slot ranges of the batches that workers are currently downloading. It is owned by the
+// Service and shared by every worker pool the Service creates, so that when the runloop is restarted, a range that a
+// worker from the previous pool is still requesting is not handed to a new worker until the old request finishes.
+// The Store remains the source of truth for backfill progress, this only prevents duplicate requests to peers.
+type inFlightRanges struct {
+	sync.Mutex
+	ranges map[batchId]batch
+}
+
+func newInFlightRanges() *inFlightRanges {
+	return &inFlightRanges{ranges: make(map[batchId]batch)}
+}
+
+// add marks the range of the batch as being downloaded.
+func (f *inFlightRanges) add(b batch) {
+	f.Lock()
+	defer f.Unlock()
+	f.ranges[b.id()] = b
+}
+
+// remove clears the range of the batch, once the download has completed or failed.
+func (f *inFlightRanges) remove(b batch) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.ranges, b.id())
+}
+
+// overlaps returns true if the range of the batch overlaps with any range that is being downloaded.
+func (f *inFlightRanges) overlaps(b batch) bool {
+	f.Lock()
+	defer f.Unlock()
+	for _, r := range f.ranges {
+		if b.slotRange().Overlaps(r.slotRange()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package backfill
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+)
+
+func TestInFlightRangesOverlaps(t *testing.T) {
+	f := newInFlightRanges()
+	b := batch{begin: 10, end: 20}
+	require.Equal(t, false, f.overlaps(b))
+	f.add(b)
+	cases := []struct {
+		name     string
+		b        batch
+		overlaps bool
+	}{
+		{name: "same range", b: batch{begin: 10, end: 20}, overlaps: true},
+		{name: "contained", b: batch{begin: 12, end: 18}, overlaps: true},
+		{name: "overlaps start", b: batch{begin: 5, end: 11}, overlaps: true},
+		{name: "overlaps end", b: batch{begin: 19, end: 25}, overlaps: true},
+		{name: "adjacent below", b: batch{begin: 0, end: 10}, overlaps: false},
+		{name: "adjacent above", b: batch{begin: 20, end: 30}, overlaps: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.overlaps, f.overlaps(c.b))
+		})
+	}
+	f.remove(b)
+	require.Equal(t, false, f.overlaps(b))
+}
+
+func TestPoolSkipsInFlightBatches(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inFlight := newInFlightRanges()
+	// Simulate a worker from a previous pool that is still downloading this range.
+	stale := batch{begin: 10, end: 20}
+	inFlight.add(stale)
+
+	pool := newP2PBatchWorkerPool(nil, 2, inFlight, newPeerBreaker(), 0, defaultRequestsPerPeer, false, 1, prysmTime.RealClock{})
+	pool.ctx, pool.cancel = context.WithCancel(ctx)
+	// Two peers, so that the target concurrency allows both batches to be in flight.
+	go pool.batchRouter(&mockAssigner{assign: []peer.ID{"a", "b"}})
+	pool.todo(batch{begin: 10, end: 20, state: batchInit})
+	pool.todo(batch{begin: 0, end: 10, state: batchInit})
+
+	select {
+	case b := <-pool.toWorkers:
+		require.Equal(t, batchId("0:10"), b.id())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the batch that is not in flight")
+	}
+	select {
+	case b := <-pool.toWorkers:
+		t.Fatalf("batch %s assigned while its range was still in flight", b.id())
+	case <-time.After(1500 * time.Millisecond):
+	}
+	inFlight.remove(stale)
+	select {
+	case b := <-pool.toWorkers:
+		require.Equal(t, stale.id(), b.id())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the batch after its range was cleared")
+	}
+}
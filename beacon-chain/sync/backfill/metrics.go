@@ -21,18 +21,133 @@ var (
 			Help: "Number of batches that are ready to be imported once they can be connected to the existing chain.",
 		},
 	)
+	backfillBufferedBytes = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "backfill_buffered_bytes",
+			Help: "Approximate size of the blocks and blobs in batches that have been downloaded but not yet imported.",
+		},
+	)
+	backfillStatusSaveFailures = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "backfill_status_save_failures",
+			Help: "Number of times the backfill status could not be saved to the db, including failed retries.",
+		},
+	)
 	backfillRemainingBatches = promauto.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "backfill_remaining_batches",
 			Help: "Backfill remaining batches.",
 		},
 	)
+	backfillRemainingBytesEstimate = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "backfill_remaining_bytes_estimate",
+			Help: "Estimate of the bytes of blocks and blobs that backfill still needs to download, based on an average size per slot.",
+		},
+	)
 	backfillBatchesImported = promauto.NewCounter(
 		prometheus.CounterOpts{
 			Name: "backfill_batches_imported",
 			Help: "Number of backfill batches downloaded and imported.",
 		},
 	)
+	backfillCoverageChecks = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "backfill_coverage_checks",
+			Help: "Number of sampled checks that backfilled history is present in the db.",
+		},
+	)
+	backfillCoverageDiscrepancies = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "backfill_coverage_discrepancies",
+			Help: "Number of sampled coverage checks that found no blocks in a range the backfill status reports as available.",
+		},
+	)
+	backfillBlobCoverageMismatch = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "backfill_blob_coverage_mismatch",
+			Help: "Number of backfill batches that had blocks ready to import, but were missing blobs, so the batch was not imported.",
+		},
+	)
+	backfillBlobsPruned = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "backfill_blobs_pruned",
+			Help: "Number of blob sidecar files pruned for being older than the blob retention floor plus the prune margin.",
+		},
+	)
+	backfillBlockRequestFailures = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "backfill_block_request_failures",
+			Help: "Number of backfill block by range requests that failed or timed out, and will be retried.",
+		},
+	)
+	backfillBatchTimeouts = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "backfill_batch_timeouts",
+			Help: "Number of backfill batches abandoned and retried because a worker did not finish them before the batch timeout.",
+		},
+	)
+	backfillFailedRanges = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "backfill_failed_ranges",
+			Help: "Number of backfill batch ranges that have failed at least once and are waiting to be retried.",
+		},
+	)
+	backfillUnrecoverableRanges = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "backfill_unrecoverable_ranges",
+			Help: "Number of backfill batch ranges that have failed the maximum number of attempts, and are only retried at the maximum backoff delay.",
+		},
+	)
+	backfillTrustedAnchorMismatches = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "backfill_trusted_anchor_mismatches",
+			Help: "Number of backfill batches rejected because they cover the trusted anchor slot without containing the trusted anchor block.",
+		},
+	)
+	backfillQuorumDisagreements = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "backfill_quorum_disagreements",
+			Help: "Number of backfill batches for which the peers asked to reach a quorum returned different blocks.",
+		},
+	)
+	backfillBlockPeerRotations = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "backfill_block_peer_rotations",
+			Help: "Number of failed backfill batches that were retried with a peer that had not recently failed them.",
+		},
+	)
+	backfillPeerBreakerOpened = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "backfill_peer_breaker_opened",
+			Help: "Number of times a peer was excluded from backfill after repeated batch failures.",
+		},
+	)
+	backfillPeerBreakerPeers = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backfill_peer_breaker_peers",
+			Help: "Number of peers with recent backfill batch failures, by circuit breaker state.",
+		},
+		[]string{"state"},
+	)
+	backfillMode = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "backfill_mode",
+			Help: "How the node was synced: 0 from genesis, 1 from a checkpoint with backfill complete, 2 from a checkpoint with backfill in progress.",
+		},
+	)
+	backfillPeerFanout = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "backfill_peer_fanout",
+			Help: "Number of peers that backfill batches are currently being requested from.",
+		},
+	)
+	backfillTargetConcurrency = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "backfill_target_concurrency",
+			Help: "Number of backfill batches that can be requested at the same time, given the number of suitable peers.",
+		},
+	)
 	backfillBlocksApproximateBytes = promauto.NewCounter(
 		prometheus.CounterOpts{
 			Name: "backfill_blocks_bytes_downloaded",
@@ -14,6 +14,7 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
 )
 
 type batchWorkerPool interface {
@@ -28,9 +29,9 @@ type worker interface {
 
 type newWorker func(id workerId, in, out chan batch, c *startup.Clock, v *verifier, cm sync.ContextByteVersions, nbv verification.NewBlobVerifier, bfs *filesystem.BlobStorage) worker
 
-func defaultNewWorker(p p2p.P2P) newWorker {
+func defaultNewWorker(p p2p.P2P, inFlight *inFlightRanges, skipBlobs bool, quorum int, wall prysmTime.Clock) newWorker {
 	return func(id workerId, in, out chan batch, c *startup.Clock, v *verifier, cm sync.ContextByteVersions, nbv verification.NewBlobVerifier, bfs *filesystem.BlobStorage) worker {
-		return newP2pWorker(id, p, in, out, c, v, cm, nbv, bfs)
+		return newP2pWorker(id, p, in, out, c, v, cm, nbv, bfs, inFlight, skipBlobs, quorum, wall)
 	}
 }
 
@@ -43,14 +44,29 @@ type p2pBatchWorkerPool struct {
 	fromRouter  chan batch
 	shutdownErr chan error
 	endSeq      []batch
+	inFlight    *inFlightRanges
+	breaker     *peerBreaker
+	maxPeers    int
+	perPeer     int
+	quorum      int
+	wall        prysmTime.Clock
+	p2p         p2p.P2P
+	clock       *startup.Clock
 	ctx         context.Context
 	cancel      func()
 }
 
 var _ batchWorkerPool = &p2pBatchWorkerPool{}
 
-func newP2PBatchWorkerPool(p p2p.P2P, maxBatches int) *p2pBatchWorkerPool {
-	nw := defaultNewWorker(p)
+// newP2PBatchWorkerPool creates a worker pool. The inFlight ranges should be shared by all pools created for the
+// same backfill process, so that a new pool does not request batches that workers of a stopped pool are still downloading.
+// The breaker should be shared in the same way, see peerBreaker. maxPeers and perPeer limit the peers batches are
+// requested from, see peerFanout. The number of batches in flight is scaled with the number of suitable peers, up to
+// maxBatches, see peerFanout.setTarget. If skipBlobs is set, the workers only download blocks. A quorum greater than 1 makes
+// the workers confirm the blocks of every batch with witness peers picked by the router, see witnessesFor. The router
+// and the workers take the time from wall.
+func newP2PBatchWorkerPool(p p2p.P2P, maxBatches int, inFlight *inFlightRanges, breaker *peerBreaker, maxPeers, perPeer int, skipBlobs bool, quorum int, wall prysmTime.Clock) *p2pBatchWorkerPool {
+	nw := defaultNewWorker(p, inFlight, skipBlobs, quorum, wall)
 	return &p2pBatchWorkerPool{
 		newWorker:   nw,
 		toRouter:    make(chan batch, maxBatches),
@@ -58,12 +74,21 @@ func newP2PBatchWorkerPool(p p2p.P2P, maxBatches int) *p2pBatchWorkerPool {
 		toWorkers:   make(chan batch),
 		fromWorkers: make(chan batch),
 		maxBatches:  maxBatches,
-		shutdownErr: make(chan error),
+		inFlight:    inFlight,
+		breaker:     breaker,
+		maxPeers:    maxPeers,
+		perPeer:     perPeer,
+		quorum:      quorum,
+		wall:        wall,
+		p2p:         p,
+		// Buffered so that shutdown never blocks the router if the pool is stopped while no one is waiting on complete.
+		shutdownErr: make(chan error, 1),
 	}
 }
 
 func (p *p2pBatchWorkerPool) spawn(ctx context.Context, n int, c *startup.Clock, a PeerAssigner, v *verifier, cm sync.ContextByteVersions, nbv verification.NewBlobVerifier, bfs *filesystem.BlobStorage) {
 	p.ctx, p.cancel = context.WithCancel(ctx)
+	p.clock = c
 	go p.batchRouter(a)
 	for i := 0; i < n; i++ {
 		go p.newWorker(workerId(i), p.toWorkers, p.fromWorkers, c, v, cm, nbv, bfs).run(p.ctx)
@@ -100,9 +125,10 @@ func (p *p2pBatchWorkerPool) complete() (batch, error) {
 }
 
 func (p *p2pBatchWorkerPool) batchRouter(pa PeerAssigner) {
-	busy := make(map[peer.ID]bool)
+	fanout := newPeerFanout(p.maxPeers, p.perPeer)
 	todo := make([]batch, 0)
-	rt := time.NewTicker(time.Second)
+	rt := p.wall.NewTimer(time.Second)
+	defer rt.Stop()
 	earliest := primitives.Slot(math.MaxUint64)
 	for {
 		select {
@@ -110,13 +136,18 @@ func (p *p2pBatchWorkerPool) batchRouter(pa PeerAssigner) {
 			todo = append(todo, b)
 			// sort batches in descending order so that we'll always process the dependent batches first
 			sortBatchDesc(todo)
-		case <-rt.C:
+		case <-rt.C():
 			// Worker assignments can fail if assignBatch can't find a suitable peer.
-			// This ticker exists to periodically break out of the channel select
+			// This timer exists to periodically break out of the channel select
 			// to retry failed assignments.
+			rt.Reset(time.Second)
 		case b := <-p.fromWorkers:
-			pid := b.busy
-			busy[pid] = false
+			fanout.remove(b.busy)
+			for _, pid := range b.witnesses {
+				fanout.remove(pid)
+			}
+			b.witnesses = nil
+			p.recordOutcome(b)
 			if b.state == batchBlobSync {
 				todo = append(todo, b)
 				sortBatchDesc(todo)
@@ -132,32 +163,149 @@ func (p *p2pBatchWorkerPool) batchRouter(pa PeerAssigner) {
 			continue
 		}
 		// Try to assign as many outstanding batches as possible to peers and feed the assigned batches to workers.
-		assigned, err := pa.Assign(busy, len(todo))
+		// Peers whose circuit breaker is open are excluded from assignment, and busy peers are skipped below, after
+		// the suitable peers have been counted to scale the number of batches in flight.
+		suitable, err := pa.Assign(p.breaker.exclude(map[peer.ID]bool{}, p.wall.Now()), p.maxBatches)
 		if err != nil {
 			if errors.Is(err, peers.ErrInsufficientSuitable) {
 				// Transient error resulting from insufficient number of connected peers. Leave batches in
 				// queue and get to them whenever the peer situation is resolved.
+				fanout.setTarget(0, p.maxBatches)
 				continue
 			}
 			p.shutdown(err)
 			return
 		}
-		for _, pid := range assigned {
-			if err := todo[0].waitUntilReady(p.ctx); err != nil {
+		fanout.setTarget(len(suitable), p.maxBatches)
+		busy := fanout.busy()
+		for _, pid := range suitable {
+			if len(todo) == 0 || !fanout.belowTarget() {
+				break
+			}
+			if busy[pid] || !fanout.allows(pid) {
+				// The peer has reached the per-peer limit, or the limit on the number of peers backfill requests
+				// batches from at the same time has been reached.
+				continue
+			}
+			i := p.nextAssignable(todo, pid)
+			if i < 0 {
+				// The assigned peer may not be able to serve any of the batches, another peer still could.
+				continue
+			}
+			if todo[i].failedWith(pid) {
+				log.WithFields(todo[i].logFields()).WithField("peer", pid).
+					Debug("No other peer available, retrying batch with a peer that recently failed it")
+			} else if len(todo[i].failedPeers) > 0 {
+				backfillBlockPeerRotations.Inc()
+			}
+			if err := todo[i].waitUntilReady(p.ctx, p.wall); err != nil {
 				log.WithError(p.ctx.Err()).Info("p2pBatchWorkerPool context canceled, shutting down")
 				p.shutdown(p.ctx.Err())
 				return
 			}
-			busy[pid] = true
-			todo[0].busy = pid
-			p.toWorkers <- todo[0].withPeer(pid)
-			if todo[0].begin < earliest {
-				earliest = todo[0].begin
+			fanout.add(pid)
+			todo[i].busy = pid
+			witnesses, ok := p.witnessesFor(fanout, suitable, todo[i])
+			if !ok {
+				// Not enough witnesses are available to reach the quorum, so the batch stays in todo until there are.
+				fanout.remove(pid)
+				todo[i].busy = ""
+				continue
+			}
+			todo[i].witnesses = witnesses
+			p.toWorkers <- todo[i].withPeer(pid, p.wall.Now())
+			if todo[i].begin < earliest {
+				earliest = todo[i].begin
 				oldestBatch.Set(float64(earliest))
 			}
-			todo = todo[1:]
+			todo = append(todo[:i], todo[i+1:]...)
+		}
+	}
+}
+
+// witnessesFor picks the peers that the worker asks to confirm the blocks of the batch when a quorum is required, see
+// p2pWorker.withQuorum. Witnesses are drawn from the suitable peers that the batch was assigned from, subject to the
+// same fan-out limits and earliest slot filter, and count against those limits until the batch is returned. Up to
+// 2*quorum-2 witnesses are picked, enough to reach a majority if some of them disagree or fail to respond. If fewer
+// than quorum-1 witnesses are available, the quorum can't be reached, so none are reserved and false is returned.
+// Batches that are only missing blobs need no witnesses, since their blocks were already confirmed.
+func (p *p2pBatchWorkerPool) witnessesFor(fanout *peerFanout, suitable []peer.ID, b batch) ([]peer.ID, bool) {
+	if p.quorum < 2 || b.state == batchBlobSync {
+		return nil, true
+	}
+	max := 2*p.quorum - 2
+	witnesses := make([]peer.ID, 0, max)
+	for _, pid := range suitable {
+		if len(witnesses) == max {
+			break
+		}
+		if pid == b.busy || fanout.full(pid) || !fanout.allows(pid) || !p.peerServes(pid, b) {
+			continue
+		}
+		fanout.add(pid)
+		witnesses = append(witnesses, pid)
+	}
+	if len(witnesses) < p.quorum-1 {
+		for _, pid := range witnesses {
+			fanout.remove(pid)
 		}
+		return nil, false
+	}
+	return witnesses, true
+}
+
+// recordOutcome updates the circuit breaker of the peer that a worker returned the batch from. Batches that were
+// abandoned because the pool is shutting down don't count against the peer.
+func (p *p2pBatchWorkerPool) recordOutcome(b batch) {
+	if b.state != batchErrRetryable {
+		p.breaker.success(b.busy)
+		return
+	}
+	if p.ctx.Err() != nil {
+		return
+	}
+	p.breaker.failure(b.busy, p.wall.Now())
+}
+
+// nextAssignable returns the index of the batch that should be assigned to the given peer, or -1 if none can be.
+// Batches that overlap a range still being downloaded by a worker from a previous pool, or that are waiting for their
+// retry backoff delay, are skipped, they stay in the queue and are retried when the ticker fires. This lets workers
+// make progress on other ranges while a failing range is set aside. Batches that the peer recently failed are only assigned to it
+// if there is no other batch available, so that a retry normally rotates to a different peer. Batches before the
+// earliest slot the peer advertises are not assigned to it.
+func (p *p2pBatchWorkerPool) nextAssignable(todo []batch, pid peer.ID) int {
+	fallback := -1
+	for i := range todo {
+		if !todo[i].ready(p.wall.Now()) || p.inFlight.overlaps(todo[i]) || !p.peerServes(pid, todo[i]) {
+			continue
+		}
+		if !todo[i].failedWith(pid) {
+			return i
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+	}
+	return fallback
+}
+
+// peerServes reports whether the peer can be expected to serve the batch, based on the earliest available slot in
+// its ENR. Peers advertise the higher of their lowest block and the block retention floor, so the advertised slot
+// can only rule out batches that begin inside the retention window. Peers that don't advertise a slot are assumed
+// to serve the whole window.
+func (p *p2pBatchWorkerPool) peerServes(pid peer.ID, b batch) bool {
+	if p.p2p == nil || p.clock == nil {
+		return true
+	}
+	record, err := p.p2p.Peers().ENR(pid)
+	if err != nil {
+		return true
+	}
+	earliest, ok := p2p.EarliestAvailableSlot(record)
+	if !ok || earliest <= b.begin {
+		return true
 	}
+	return b.begin < minimumBackfillSlot(p.clock.CurrentSlot())
 }
 
 func (p *p2pBatchWorkerPool) shutdown(err error) {
@@ -5,16 +5,22 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/helpers"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/filesystem"
 	p2ptest "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/testing"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/startup"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
 	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
 	"github.com/prysmaticlabs/prysm/v5/testing/require"
 	"github.com/prysmaticlabs/prysm/v5/testing/util"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+	"github.com/prysmaticlabs/prysm/v5/time/slots"
 )
 
 type mockAssigner struct {
@@ -42,7 +48,7 @@ func TestPoolDetectAllEnded(t *testing.T) {
 	p2p := p2ptest.NewTestP2P(t)
 	ctx := context.Background()
 	ma := &mockAssigner{}
-	pool := newP2PBatchWorkerPool(p2p, nw)
+	pool := newP2PBatchWorkerPool(p2p, nw, newInFlightRanges(), newPeerBreaker(), 0, defaultRequestsPerPeer, false, 1, prysmTime.RealClock{})
 	st, err := util.NewBeaconState()
 	require.NoError(t, err)
 	keys, err := st.PublicKeys()
@@ -54,7 +60,7 @@ func TestPoolDetectAllEnded(t *testing.T) {
 	require.NoError(t, err)
 	bfs := filesystem.NewEphemeralBlobStorage(t)
 	pool.spawn(ctx, nw, startup.NewClock(time.Now(), [32]byte{}), ma, v, ctxMap, mockNewBlobVerifier, bfs)
-	br := batcher{min: 10, size: 10}
+	br := batcher{min: 10, size: 10, wall: prysmTime.RealClock{}}
 	endSeq := br.before(0)
 	require.Equal(t, batchEndSequence, endSeq.state)
 	for i := 0; i < nw; i++ {
@@ -70,9 +76,11 @@ type mockPool struct {
 	finishedChan chan batch
 	finishedErr  chan error
 	todoChan     chan batch
+	ctx          context.Context
 }
 
-func (m *mockPool) spawn(_ context.Context, _ int, _ *startup.Clock, _ PeerAssigner, _ *verifier, _ sync.ContextByteVersions, _ verification.NewBlobVerifier, _ *filesystem.BlobStorage) {
+func (m *mockPool) spawn(ctx context.Context, _ int, _ *startup.Clock, _ PeerAssigner, _ *verifier, _ sync.ContextByteVersions, _ verification.NewBlobVerifier, _ *filesystem.BlobStorage) {
+	m.ctx = ctx
 }
 
 func (m *mockPool) todo(b batch) {
@@ -85,7 +93,119 @@ func (m *mockPool) complete() (batch, error) {
 		return b, nil
 	case err := <-m.finishedErr:
 		return batch{}, err
+	case <-m.ctx.Done():
+		return batch{}, m.ctx.Err()
 	}
 }
 
 var _ batchWorkerPool = &mockPool{}
+
+func TestNextAssignable(t *testing.T) {
+	inFlight := newInFlightRanges()
+	pool := newP2PBatchWorkerPool(nil, 3, inFlight, newPeerBreaker(), 0, defaultRequestsPerPeer, false, 1, prysmTime.RealClock{})
+	failed := batch{begin: 20, end: 30, failedPeers: []peer.ID{"bad"}, wall: prysmTime.RealClock{}}
+	todo := []batch{failed, {begin: 10, end: 20}, {begin: 0, end: 10}}
+
+	// A retry is rotated to a different peer.
+	require.Equal(t, 1, pool.nextAssignable(todo, "bad"))
+	require.Equal(t, 0, pool.nextAssignable(todo, "good"))
+	// Batches overlapping a range still in flight are skipped.
+	inFlight.add(todo[1])
+	require.Equal(t, 2, pool.nextAssignable(todo, "bad"))
+	inFlight.add(todo[2])
+	// If there is no other batch, the peer that failed the batch can be asked again.
+	require.Equal(t, 0, pool.nextAssignable(todo, "bad"))
+	inFlight.add(todo[0])
+	require.Equal(t, -1, pool.nextAssignable(todo, "good"))
+	// Batches waiting for their retry backoff are set aside, so other batches can be assigned.
+	inFlight.remove(todo[1])
+	todo[0] = todo[0].withRetryableError(errBatchTimeout).withState(batchSequenced)
+	inFlight.remove(todo[0])
+	require.Equal(t, 1, pool.nextAssignable(todo, "good"))
+}
+
+func TestWitnessesFor(t *testing.T) {
+	b := batch{begin: 0, end: 10, busy: "a"}
+	suitable := []peer.ID{"a", "b", "c", "d", "e"}
+
+	pool := newP2PBatchWorkerPool(nil, 3, newInFlightRanges(), newPeerBreaker(), 0, defaultRequestsPerPeer, false, 1, prysmTime.RealClock{})
+	w, ok := pool.witnessesFor(newPeerFanout(0, defaultRequestsPerPeer), suitable, b)
+	require.Equal(t, true, ok)
+	require.Equal(t, 0, len(w))
+
+	// Up to 2*quorum-2 witnesses are picked, skipping the peer the batch is assigned to and busy peers.
+	pool = newP2PBatchWorkerPool(nil, 3, newInFlightRanges(), newPeerBreaker(), 0, defaultRequestsPerPeer, false, 2, prysmTime.RealClock{})
+	fanout := newPeerFanout(0, defaultRequestsPerPeer)
+	fanout.add("a")
+	fanout.add("b")
+	w, ok = pool.witnessesFor(fanout, suitable, b)
+	require.Equal(t, true, ok)
+	require.DeepEqual(t, []peer.ID{"c", "d"}, w)
+	// The witnesses count against the fan-out limits until the batch is returned.
+	require.Equal(t, true, fanout.full("c"))
+	w, ok = pool.witnessesFor(fanout, suitable, b)
+	require.Equal(t, true, ok)
+	require.DeepEqual(t, []peer.ID{"e"}, w)
+	// Every peer is busy, so the quorum can't be reached.
+	_, ok = pool.witnessesFor(fanout, suitable, b)
+	require.Equal(t, false, ok)
+
+	// Witnesses are subject to the limit on the number of peers in use.
+	fanout = newPeerFanout(2, defaultRequestsPerPeer)
+	fanout.add("a")
+	w, ok = pool.witnessesFor(fanout, suitable, b)
+	require.Equal(t, true, ok)
+	require.DeepEqual(t, []peer.ID{"b"}, w)
+
+	// With a quorum of 3, at least 2 witnesses are needed. When only one is available, it is not reserved.
+	pool = newP2PBatchWorkerPool(nil, 3, newInFlightRanges(), newPeerBreaker(), 0, defaultRequestsPerPeer, false, 3, prysmTime.RealClock{})
+	fanout = newPeerFanout(0, defaultRequestsPerPeer)
+	fanout.add("a")
+	fanout.add("b")
+	fanout.add("c")
+	fanout.add("d")
+	_, ok = pool.witnessesFor(fanout, suitable, b)
+	require.Equal(t, false, ok)
+	require.Equal(t, false, fanout.full("e"))
+
+	// Batches that are only missing blobs were already confirmed, and get no witnesses.
+	bs := b
+	bs.state = batchBlobSync
+	w, ok = pool.witnessesFor(newPeerFanout(0, defaultRequestsPerPeer), suitable, bs)
+	require.Equal(t, true, ok)
+	require.Equal(t, 0, len(w))
+}
+
+func TestPeerServes(t *testing.T) {
+	// Put the block retention floor at slot 1000.
+	offset := slots.UnsafeEpochStart(helpers.MinEpochsForBlockRequests())
+	elapsed := time.Duration(offset+1000) * time.Duration(params.BeaconConfig().SecondsPerSlot) * time.Second
+	clock := startup.NewClock(time.Now().Add(-elapsed), [32]byte{})
+	floor := minimumBackfillSlot(clock.CurrentSlot())
+	require.Equal(t, true, floor >= 1000 && floor < 1010)
+
+	p := p2ptest.NewTestP2P(t)
+	advertise := func(pid peer.ID, slot uint64) {
+		record := &enr.Record{}
+		// The entry is set the same way as p2p.Service.SetEarliestAvailableSlot.
+		record.Set(enr.WithEntry("eas", slot))
+		p.Peers().Add(record, pid, nil, network.DirOutbound)
+	}
+	advertise("behind", 3000)
+	advertise("ahead", 1500)
+	p.Peers().Add(new(enr.Record), "legacy", nil, network.DirOutbound)
+
+	pool := newP2PBatchWorkerPool(p, 3, newInFlightRanges(), newPeerBreaker(), 0, defaultRequestsPerPeer, false, 1, prysmTime.RealClock{})
+	pool.clock = clock
+	recent := batch{begin: 2000, end: 2100}
+	old := batch{begin: 500, end: 600}
+	require.Equal(t, false, pool.peerServes("behind", recent))
+	require.Equal(t, true, pool.peerServes("ahead", recent))
+	require.Equal(t, true, pool.peerServes("legacy", recent))
+	require.Equal(t, true, pool.peerServes("unknown", recent))
+	// Peers don't advertise below the retention floor, so batches before it can't be ruled out.
+	require.Equal(t, true, pool.peerServes("behind", old))
+
+	require.Equal(t, 1, pool.nextAssignable([]batch{recent, old}, "behind"))
+	require.Equal(t, -1, pool.nextAssignable([]batch{recent}, "behind"))
+}
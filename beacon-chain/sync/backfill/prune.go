@@ -0,0 +1,112 @@
+package backfill
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/startup"
+	prysmsync "github.com/prysmaticlabs/prysm/v5/beacon-chain/sync"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+	"github.com/prysmaticlabs/prysm/v5/time/slots"
+)
+
+// blobPruneStorage is the subset of filesystem.BlobStorage used by blobPruner.
+type blobPruneStorage interface {
+	PruneBefore(sl primitives.Slot) (int, error)
+}
+
+// blobPruner deletes blob sidecars that are older than the blob retention floor by more than a grace margin.
+// Blobs in the range of slots that backfill is downloading or importing are never deleted. Before blobs are
+// deleted, the blob low slot of the backfill status is raised past them, so that the Store reports them as
+// unavailable while they are removed.
+type blobPruner struct {
+	store     *Store
+	blobs     blobPruneStorage
+	margin    primitives.Epoch
+	retention primitives.Epoch
+	once      sync.Once
+}
+
+// pruneBefore computes the slot that blobs are pruned up to, which is the start of the epoch margin epochs before
+// the retention floor. The floor is the lower of the spec floor and the floor for the configured retention epochs,
+// so the margin never cuts into blobs that the node was told to keep. It returns false if there is nothing to prune yet.
+func (p *blobPruner) pruneBefore(current primitives.Slot) (primitives.Slot, bool) {
+	floor, err := prysmsync.BlobRPCMinValidSlot(current)
+	if err != nil || floor > current {
+		return 0, false
+	}
+	if p.retention > 0 {
+		epoch := slots.ToEpoch(current)
+		if epoch <= p.retention {
+			return 0, false
+		}
+		kept, err := slots.EpochStart(epoch - p.retention)
+		if err != nil {
+			return 0, false
+		}
+		if kept < floor {
+			floor = kept
+		}
+	}
+	margin := primitives.Slot(p.margin) * params.BeaconConfig().SlotsPerEpoch
+	if margin >= floor {
+		return 0, false
+	}
+	return floor - margin, true
+}
+
+// prune removes the blobs before the prune slot for the current slot, see pruneBefore.
+func (p *blobPruner) prune(ctx context.Context, current primitives.Slot) error {
+	before, ok := p.pruneBefore(current)
+	if !ok {
+		return nil
+	}
+	// The range that backfill is working on can straddle the prune slot, when the retention floor moves past a
+	// batch after its blobs were requested.
+	before, release := p.store.holdPrune(before)
+	defer release()
+	if before == 0 {
+		return nil
+	}
+	if err := p.store.raiseBlobLowSlot(ctx, before); err != nil {
+		return errors.Wrapf(err, "could not update blob low slot to %d", before)
+	}
+	n, err := p.blobs.PruneBefore(before)
+	backfillBlobsPruned.Add(float64(n))
+	if err != nil {
+		return errors.Wrapf(err, "could not prune blobs before slot %d", before)
+	}
+	if n > 0 {
+		log.WithField("pruneBefore", before).WithField("filesRemoved", n).Debug("Pruned blobs below the retention floor")
+	}
+	return nil
+}
+
+// start runs the pruner in a new goroutine. Only the first call has an effect.
+func (p *blobPruner) start(ctx context.Context, clock *startup.Clock, wall prysmTime.Clock) {
+	p.once.Do(func() {
+		go p.run(ctx, clock, wall)
+	})
+}
+
+// run prunes blobs once per epoch, as measured by wall, until the context is canceled.
+func (p *blobPruner) run(ctx context.Context, clock *startup.Clock, wall prysmTime.Clock) {
+	epoch := time.Duration(params.BeaconConfig().SlotsPerEpoch) * time.Duration(params.BeaconConfig().SecondsPerSlot) * time.Second
+	t := wall.NewTimer(epoch)
+	defer t.Stop()
+	for {
+		if err := p.prune(ctx, clock.CurrentSlot()); err != nil {
+			log.WithError(err).Error("Failed to prune blobs below the retention floor")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C():
+			t.Reset(epoch)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package backfill
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+type mockBlobPruneStorage struct {
+	before  []primitives.Slot
+	removed int
+	err     error
+}
+
+func (m *mockBlobPruneStorage) PruneBefore(sl primitives.Slot) (int, error) {
+	m.before = append(m.before, sl)
+	return m.removed, m.err
+}
+
+// slotBlobStorage holds a single blob for each slot, and deletes them like BlobStorage.PruneBefore.
+type slotBlobStorage struct {
+	sync.Mutex
+	slots map[primitives.Slot]bool
+}
+
+func (m *slotBlobStorage) save(sl primitives.Slot) {
+	m.Lock()
+	defer m.Unlock()
+	m.slots[sl] = true
+}
+
+func (m *slotBlobStorage) has(sl primitives.Slot) bool {
+	m.Lock()
+	defer m.Unlock()
+	return m.slots[sl]
+}
+
+func (m *slotBlobStorage) PruneBefore(before primitives.Slot) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+	n := 0
+	for sl := range m.slots {
+		if sl < before {
+			delete(m.slots, sl)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestBlobPrunerPruneBefore(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.DenebForkEpoch = 0
+	cfg.MinEpochsForBlobsSidecarsRequest = 10
+	params.OverrideBeaconConfig(cfg)
+	spe := params.BeaconConfig().SlotsPerEpoch
+
+	p := &blobPruner{margin: 2}
+	// The retention floor is still at the fork epoch.
+	_, ok := p.pruneBefore(5 * spe)
+	require.Equal(t, false, ok)
+	// The floor is at epoch 2, which is not beyond the margin.
+	_, ok = p.pruneBefore(12 * spe)
+	require.Equal(t, false, ok)
+	before, ok := p.pruneBefore(20*spe + 3)
+	require.Equal(t, true, ok)
+	require.Equal(t, 8*spe, before)
+
+	p.margin = 0
+	before, ok = p.pruneBefore(20 * spe)
+	require.Equal(t, true, ok)
+	require.Equal(t, 10*spe, before)
+
+	// A retention longer than the spec minimum moves the floor back.
+	p.retention = 15
+	before, ok = p.pruneBefore(20 * spe)
+	require.Equal(t, true, ok)
+	require.Equal(t, 5*spe, before)
+	_, ok = p.pruneBefore(15 * spe)
+	require.Equal(t, false, ok)
+	// A shorter one doesn't move it forward.
+	p.retention = 5
+	before, ok = p.pruneBefore(20 * spe)
+	require.Equal(t, true, ok)
+	require.Equal(t, 10*spe, before)
+}
+
+func TestBlobPrunerPrune(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.DenebForkEpoch = 0
+	cfg.MinEpochsForBlobsSidecarsRequest = 10
+	params.OverrideBeaconConfig(cfg)
+	spe := params.BeaconConfig().SlotsPerEpoch
+
+	ctx := context.Background()
+	mdb := &mockBackfillDB{}
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: uint64(spe), OriginSlot: uint64(100 * spe)}, store: mdb}
+	blobs := &mockBlobPruneStorage{removed: 3}
+	p := &blobPruner{store: s, blobs: blobs, margin: 1}
+
+	require.NoError(t, p.prune(ctx, 20*spe))
+	require.DeepEqual(t, []primitives.Slot{9 * spe}, blobs.before)
+	// The blobs before the prune slot are no longer reported as covered.
+	require.Equal(t, false, s.BlobSlotCovered(9*spe-1))
+	require.Equal(t, true, s.BlobSlotCovered(9*spe))
+	saved, err := mdb.BackfillStatus(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(9*spe), saved.BlobLowSlot)
+
+	// The blob low slot is not lowered when the floor is behind it.
+	s.bs.BlobLowSlot = uint64(15 * spe)
+	require.NoError(t, p.prune(ctx, 20*spe))
+	require.Equal(t, uint64(15*spe), s.status().BlobLowSlot)
+
+	blobs.err = errors.New("prune failed")
+	require.ErrorIs(t, p.prune(ctx, 21*spe), blobs.err)
+
+	// Nodes synced from genesis don't track blob coverage, but still prune.
+	g := &blobPruner{store: &Store{genesisSync: true}, blobs: &mockBlobPruneStorage{}, margin: 1}
+	require.NoError(t, g.prune(ctx, 20*spe))
+	require.Equal(t, true, g.store.BlobSlotCovered(0))
+}
+
+func TestBlobPrunerSkipsActiveBackfill(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.DenebForkEpoch = 0
+	cfg.MinEpochsForBlobsSidecarsRequest = 10
+	params.OverrideBeaconConfig(cfg)
+	spe := params.BeaconConfig().SlotsPerEpoch
+
+	ctx := context.Background()
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: uint64(16 * spe), OriginSlot: uint64(100 * spe)}, store: &mockBackfillDB{}}
+	blobs := &slotBlobStorage{slots: make(map[primitives.Slot]bool)}
+	p := &blobPruner{store: s, blobs: blobs, margin: 0}
+	// The pruner deletes blobs before epoch 10, in the middle of the range that backfill writes blobs for.
+	current := 20 * spe
+
+	var missing atomic.Int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for high := 16 * spe; high > 4*spe; high -= spe {
+			low := high - spe
+			s.setActiveLow(low)
+			for sl := low; sl < high; sl++ {
+				blobs.save(sl)
+			}
+			// Every blob in the batch must still be there when the batch is imported.
+			for sl := low; sl < high; sl++ {
+				if !blobs.has(sl) {
+					missing.Add(1)
+				}
+			}
+		}
+	}()
+	pruning := true
+	for pruning {
+		select {
+		case <-done:
+			pruning = false
+		default:
+			require.NoError(t, p.prune(ctx, current))
+		}
+	}
+	require.Equal(t, int64(0), missing.Load())
+	// Nothing at or after the lowest batch is pruned while backfill is working on it.
+	require.NoError(t, p.prune(ctx, current))
+	require.Equal(t, true, blobs.has(4*spe))
+
+	// Once backfill is no longer writing blobs, the blobs outside the retention window are pruned.
+	s.clearActiveLow()
+	require.NoError(t, p.prune(ctx, current))
+	require.Equal(t, false, blobs.has(10*spe-1))
+	require.Equal(t, true, blobs.has(10*spe))
+	require.Equal(t, true, blobs.has(16*spe-1))
+}
+
+// blockingBlobPruneStorage blocks in PruneBefore until release is closed.
+type blockingBlobPruneStorage struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (m *blockingBlobPruneStorage) PruneBefore(primitives.Slot) (int, error) {
+	close(m.started)
+	<-m.release
+	return 0, nil
+}
+
+func TestBlobPrunerDoesNotHoldBackfill(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.DenebForkEpoch = 0
+	cfg.MinEpochsForBlobsSidecarsRequest = 10
+	params.OverrideBeaconConfig(cfg)
+	spe := params.BeaconConfig().SlotsPerEpoch
+
+	ctx := context.Background()
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: uint64(16 * spe), OriginSlot: uint64(100 * spe)}, store: &mockBackfillDB{}}
+	blobs := &blockingBlobPruneStorage{started: make(chan struct{}), release: make(chan struct{})}
+	p := &blobPruner{store: s, blobs: blobs, margin: 0}
+	pruned := make(chan error)
+	go func() {
+		pruned <- p.prune(ctx, 20*spe)
+	}()
+	<-blobs.started
+
+	// Batches above the prune slot are scheduled while blobs are being deleted.
+	s.setActiveLow(12 * spe)
+	s.setActiveLow(10 * spe)
+	// Moving below the prune slot waits until the prune is done.
+	moved := make(chan struct{})
+	go func() {
+		s.setActiveLow(9 * spe)
+		close(moved)
+	}()
+	select {
+	case <-moved:
+		t.Fatal("backfill moved into the range being pruned")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(blobs.release)
+	require.NoError(t, <-pruned)
+	<-moved
+}
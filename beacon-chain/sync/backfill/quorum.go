@@ -0,0 +1,146 @@
+package backfill
+
+import (
+	"context"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/interfaces"
+)
+
+var errNoQuorum = errors.New("backfill peers did not agree on the blocks for the batch")
+
+// blockQuorum tallies the responses of several peers to the same blocks by range request. Responses are grouped by
+// the root of their last block, which commits to every block before it in the batch once the batch is verified, and
+// a group wins once size peers are in it. At most 2*size-1 responses are counted, at which point a majority of them
+// would have had to agree.
+type blockQuorum struct {
+	size      int
+	total     int
+	votes     map[[32]byte][]peer.ID
+	responses map[[32]byte][]interfaces.ReadOnlySignedBeaconBlock
+}
+
+func newBlockQuorum(size int) *blockQuorum {
+	return &blockQuorum{
+		size:      size,
+		votes:     make(map[[32]byte][]peer.ID),
+		responses: make(map[[32]byte][]interfaces.ReadOnlySignedBeaconBlock),
+	}
+}
+
+// add counts the response of the peer. An empty response is counted as a vote for the zero root.
+func (q *blockQuorum) add(pid peer.ID, results []interfaces.ReadOnlySignedBeaconBlock) error {
+	var root [32]byte
+	if len(results) > 0 {
+		r, err := results[len(results)-1].Block().HashTreeRoot()
+		if err != nil {
+			return errors.Wrap(err, "could not compute root of last block in response")
+		}
+		root = r
+	}
+	if _, ok := q.responses[root]; !ok {
+		q.responses[root] = results
+	}
+	q.votes[root] = append(q.votes[root], pid)
+	q.total++
+	return nil
+}
+
+// winner returns the response that size peers agree on, and the peers that returned it.
+func (q *blockQuorum) winner() ([]interfaces.ReadOnlySignedBeaconBlock, []peer.ID, bool) {
+	for root, pids := range q.votes {
+		if len(pids) >= q.size {
+			return q.responses[root], pids, true
+		}
+	}
+	return nil, nil, false
+}
+
+// needed returns the number of further responses that could produce a winner, if they all agree with the largest
+// group of responses so far.
+func (q *blockQuorum) needed() int {
+	largest := 0
+	for _, pids := range q.votes {
+		if len(pids) > largest {
+			largest = len(pids)
+		}
+	}
+	if n := q.size - largest; n > 0 {
+		return n
+	}
+	return 0
+}
+
+// done is true once there is a winner, or no more responses are needed to know there won't be one.
+func (q *blockQuorum) done() bool {
+	if _, _, ok := q.winner(); ok {
+		return true
+	}
+	return q.total >= 2*q.size-1
+}
+
+// disagreed is true if the peers returned more than one distinct response.
+func (q *blockQuorum) disagreed() bool {
+	return len(q.votes) > 1
+}
+
+type witnessResponse struct {
+	pid     peer.ID
+	results []interfaces.ReadOnlySignedBeaconBlock
+	err     error
+}
+
+// withQuorum requests the blocks for the batch from the witnesses the router assigned to it, until quorum peers,
+// counting the peer that returned results, agree on the same blocks. Witnesses are requested concurrently, as many at a
+// time as could still produce a winner. The agreed blocks are returned with the peer that served them, which is not
+// the original peer if it was outvoted. Witnesses that fail to respond are not counted.
+func (w *p2pWorker) withQuorum(ctx context.Context, b batch, results []interfaces.ReadOnlySignedBeaconBlock) ([]interfaces.ReadOnlySignedBeaconBlock, peer.ID, error) {
+	q := newBlockQuorum(w.quorum)
+	if err := q.add(b.blockPid, results); err != nil {
+		return nil, "", err
+	}
+	pending := b.witnesses
+	for !q.done() && len(pending) > 0 && ctx.Err() == nil {
+		n := q.needed()
+		if n > len(pending) {
+			n = len(pending)
+		}
+		round := pending[:n]
+		pending = pending[n:]
+		responses := make(chan witnessResponse, len(round))
+		for _, pid := range round {
+			go func(pid peer.ID) {
+				wr, err := sync.SendBeaconBlocksByRangeRequest(ctx, w.c, w.p2p, pid, b.blockRequest(), blockValidationMetrics)
+				responses <- witnessResponse{pid: pid, results: wr, err: err}
+			}(pid)
+		}
+		for range round {
+			r := <-responses
+			if r.err != nil {
+				log.WithError(r.err).WithFields(b.logFields()).WithField("witness", r.pid).Debug("Backfill quorum witness request failed")
+				continue
+			}
+			if err := q.add(r.pid, r.results); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if q.disagreed() {
+		backfillQuorumDisagreements.Inc()
+		log.WithFields(b.logFields()).WithField("responses", len(q.votes)).WithField("peers", q.total).
+			Warn("Backfill peers returned different blocks for the same batch")
+	}
+	agreed, pids, ok := q.winner()
+	if !ok {
+		return nil, "", errors.Wrapf(errNoQuorum, "quorum=%d, responses=%d", w.quorum, q.total)
+	}
+	for _, pid := range pids {
+		if pid == b.blockPid {
+			return agreed, b.blockPid, nil
+		}
+	}
+	log.WithFields(b.logFields()).WithField("outvoted", b.blockPid).Warn("Backfill peer was outvoted by the quorum")
+	return agreed, pids[0], nil
+}
@@ -0,0 +1,62 @@
+package backfill
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/interfaces"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/prysmaticlabs/prysm/v5/testing/util"
+)
+
+func quorumTestResponse(t *testing.T, graffiti byte) []interfaces.ReadOnlySignedBeaconBlock {
+	pb := util.NewBeaconBlock()
+	pb.Block.Slot = 100
+	pb.Block.Body.Graffiti[0] = graffiti
+	blk, err := blocks.NewSignedBeaconBlock(pb)
+	require.NoError(t, err)
+	return []interfaces.ReadOnlySignedBeaconBlock{blk}
+}
+
+func TestBlockQuorum(t *testing.T) {
+	canonical := quorumTestResponse(t, 1)
+	fork := quorumTestResponse(t, 2)
+	t.Run("agreement", func(t *testing.T) {
+		q := newBlockQuorum(2)
+		require.NoError(t, q.add(peer.ID("a"), canonical))
+		require.Equal(t, false, q.done())
+		require.NoError(t, q.add(peer.ID("b"), canonical))
+		require.Equal(t, true, q.done())
+		require.Equal(t, false, q.disagreed())
+		agreed, pids, ok := q.winner()
+		require.Equal(t, true, ok)
+		require.DeepEqual(t, []peer.ID{"a", "b"}, pids)
+		require.Equal(t, canonical[0], agreed[0])
+	})
+	t.Run("first peer outvoted", func(t *testing.T) {
+		q := newBlockQuorum(2)
+		require.NoError(t, q.add(peer.ID("liar"), fork))
+		require.Equal(t, 1, q.needed())
+		require.NoError(t, q.add(peer.ID("a"), canonical))
+		require.Equal(t, false, q.done())
+		require.Equal(t, 1, q.needed())
+		require.NoError(t, q.add(peer.ID("b"), canonical))
+		require.Equal(t, true, q.done())
+		require.Equal(t, 0, q.needed())
+		require.Equal(t, true, q.disagreed())
+		agreed, pids, ok := q.winner()
+		require.Equal(t, true, ok)
+		require.DeepEqual(t, []peer.ID{"a", "b"}, pids)
+		require.Equal(t, canonical[0], agreed[0])
+	})
+	t.Run("no quorum", func(t *testing.T) {
+		q := newBlockQuorum(2)
+		require.NoError(t, q.add(peer.ID("a"), canonical))
+		require.NoError(t, q.add(peer.ID("b"), fork))
+		require.NoError(t, q.add(peer.ID("c"), nil))
+		require.Equal(t, true, q.done())
+		_, _, ok := q.winner()
+		require.Equal(t, false, ok)
+	})
+}
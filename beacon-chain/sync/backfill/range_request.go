@@ -0,0 +1,181 @@
+package backfill
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+	"github.com/prysmaticlabs/prysm/v5/time/slots"
+)
+
+// ErrInvalidEpochRange is returned by BackfillEpochRange when the requested range can not be backfilled.
+var ErrInvalidEpochRange = errors.New("invalid backfill epoch range")
+
+// ErrInvalidSlotRange is returned by BackfillRange when the requested range can not be backfilled.
+var ErrInvalidSlotRange = errors.New("invalid backfill slot range")
+
+// ErrRangeNotContiguous is returned by BackfillEpochRange and BackfillRange when the requested range ends below the lowest backfilled
+// slot. Backfill history is a single contiguous range below the origin, so filling a range that is separated from it
+// by a gap would mean downloading the gap as well, which is not what was asked for.
+var ErrRangeNotContiguous = errors.New("backfilling a range that is not contiguous with the backfilled history is not supported")
+
+var errBackfillRangeInterrupted = errors.New("backfill stopped before reaching the start of the requested range")
+
+// requestedMinimum holds a backfill minimum slot requested at runtime via BackfillEpochRange or BackfillRange.
+type requestedMinimum struct {
+	sync.Mutex
+	slot primitives.Slot
+	set  bool
+}
+
+func (r *requestedMinimum) lower(sl primitives.Slot) {
+	r.Lock()
+	defer r.Unlock()
+	if !r.set || sl < r.slot {
+		r.slot, r.set = sl, true
+	}
+}
+
+func (r *requestedMinimum) get() (primitives.Slot, bool) {
+	r.Lock()
+	defer r.Unlock()
+	return r.slot, r.set
+}
+
+// minimum returns the lowest slot that backfill should download, taking any range requested via
+// BackfillEpochRange or BackfillRange into account.
+func (s *Service) minimum(current primitives.Slot) primitives.Slot {
+	m := s.ms(current)
+	if req, ok := s.requested.get(); ok && req < m {
+		return req
+	}
+	return m
+}
+
+// BackfillEpochRange asks the service to backfill the blocks for the epochs [start, end], which must not extend past
+// the checkpoint sync origin. Backfill history is a single contiguous range below the origin, so the range must reach
+// the lowest backfilled block, otherwise ErrRangeNotContiguous is returned. The backfill minimum is then lowered to the
+// start of the requested range, so that exactly the part of the range that is missing is downloaded. If needed, the
+// runloop is restarted to pick up the new minimum. The request is not persisted, so it needs to be repeated if the
+// node restarts before backfill reaches it.
+//
+// Filling an arbitrary sub-range, with the history tracked as several covered ranges, is deliberately not supported.
+// The Store, AvailableBlock, the advertised earliest slot and the blob pruner all rely on history being one range from
+// the low slot to the origin, so a range is only accepted if it extends that range downward.
+func (s *Service) BackfillEpochRange(start, end primitives.Epoch) error {
+	status, err := s.requestStatus(ErrInvalidEpochRange)
+	if err != nil {
+		return err
+	}
+	if start > end {
+		return errors.Wrapf(ErrInvalidEpochRange, "start epoch %d > end epoch %d", start, end)
+	}
+	if origin := slots.ToEpoch(primitives.Slot(status.OriginSlot)); end > origin {
+		return errors.Wrapf(ErrInvalidEpochRange, "end epoch %d > checkpoint sync origin epoch %d", end, origin)
+	}
+	startSlot, err := slots.EpochStart(start)
+	if err != nil {
+		return errors.Wrap(ErrInvalidEpochRange, err.Error())
+	}
+	if startSlot >= primitives.Slot(status.LowSlot) {
+		log.WithField("startSlot", startSlot).WithField("backfillLowestSlot", status.LowSlot).
+			Info("Requested backfill range is already backfilled")
+		return nil
+	}
+	endSlot, err := slots.EpochEnd(end)
+	if err != nil {
+		return errors.Wrap(ErrInvalidEpochRange, err.Error())
+	}
+	if endSlot+1 < primitives.Slot(status.LowSlot) {
+		return errors.Wrapf(ErrRangeNotContiguous, "end epoch %d ends at slot %d, lowest backfilled slot is %d", end, endSlot, status.LowSlot)
+	}
+	log.WithField("startEpoch", start).WithField("endEpoch", end).WithField("minimumSlot", startSlot).
+		Info("Restarting backfill to download requested epoch range")
+	return s.restartAt(startSlot)
+}
+
+// BackfillRange backfills the blocks for the slots [start, end), which must not extend past the checkpoint sync
+// origin, and blocks until they have been downloaded, verified and imported, ctx is done, or the runloop exits
+// without reaching start. Like BackfillEpochRange, the range is downloaded by lowering the backfill minimum, so the
+// usual batch checks apply and the backfill status only ever moves down one contiguous chain of verified blocks. The
+// range must therefore reach the lowest backfilled block, otherwise ErrRangeNotContiguous is returned. Slots that are
+// already backfilled are not downloaded again, so BackfillRange returns immediately if start is at or above the
+// lowest backfilled block.
+//
+// The only gap that BackfillRange repairs is the one below the lowest backfilled block. Holes inside the backfilled
+// history can't be represented by the Store, which only records the low slot, so there is nothing above it to repair;
+// a block missing there is found by the coverage sampler and needs a re-sync of the affected range.
+func (s *Service) BackfillRange(ctx context.Context, start, end primitives.Slot) error {
+	status, err := s.requestStatus(ErrInvalidSlotRange)
+	if err != nil {
+		return err
+	}
+	if start >= end {
+		return errors.Wrapf(ErrInvalidSlotRange, "start slot %d >= end slot %d", start, end)
+	}
+	if origin := primitives.Slot(status.OriginSlot); end > origin {
+		return errors.Wrapf(ErrInvalidSlotRange, "end slot %d > checkpoint sync origin slot %d", end, origin)
+	}
+	if start >= primitives.Slot(status.LowSlot) {
+		log.WithField("startSlot", start).WithField("backfillLowestSlot", status.LowSlot).
+			Info("Requested backfill range is already backfilled")
+		return nil
+	}
+	if end < primitives.Slot(status.LowSlot) {
+		return errors.Wrapf(ErrRangeNotContiguous, "end slot %d, lowest backfilled slot is %d", end, status.LowSlot)
+	}
+	log.WithField("startSlot", start).WithField("endSlot", end).
+		Info("Restarting backfill to download requested slot range")
+	gen := s.run.generation()
+	if err := s.restartAt(start); err != nil {
+		return err
+	}
+	for {
+		advanced := s.store.advanceSignal()
+		if primitives.Slot(s.store.status().LowSlot) <= start {
+			return nil
+		}
+		// The runloop finishes when every batch down to the minimum is imported. The lowest imported block can be
+		// above start if the slots at the bottom of the range are empty. If another request restarted the runloop,
+		// exited is false and the new runloop is waited on instead.
+		exited := s.run.wait()
+		if ok, complete := s.run.exited(gen); ok {
+			if complete {
+				return nil
+			}
+			return errors.Wrapf(errBackfillRangeInterrupted, "start slot %d, lowest backfilled slot %d", start, s.store.status().LowSlot)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-advanced:
+		case <-exited:
+		}
+	}
+}
+
+// requestStatus checks that the service can handle a runtime backfill request, and returns the current status.
+// Errors wrap the given sentinel error.
+func (s *Service) requestStatus(sentinel error) (*dbval.BackfillStatus, error) {
+	if !s.enabled {
+		return nil, errors.Wrap(sentinel, "backfill service is not enabled")
+	}
+	if s.store.isGenesisSync() {
+		return nil, errors.Wrap(sentinel, "node was synced from genesis, there is no history to backfill")
+	}
+	return s.store.status(), nil
+}
+
+// restartAt lowers the requested minimum to the given slot and restarts the runloop to pick it up.
+func (s *Service) restartAt(minimum primitives.Slot) error {
+	s.run.lifecycle.Lock()
+	defer s.run.lifecycle.Unlock()
+	s.requested.lower(minimum)
+	if err := s.stop(); err != nil {
+		return errors.Wrap(err, "could not stop backfill to apply requested range")
+	}
+	s.resume()
+	return nil
+}
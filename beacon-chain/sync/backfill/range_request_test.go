@@ -0,0 +1,213 @@
+package backfill
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/startup"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestBackfillEpochRange(t *testing.T) {
+	spe := params.BeaconConfig().SlotsPerEpoch
+	status := &dbval.BackfillStatus{LowSlot: uint64(50 * spe), OriginSlot: uint64(100 * spe)}
+	cases := []struct {
+		name       string
+		svc        *Service
+		start, end primitives.Epoch
+		err        error
+	}{
+		{
+			name:  "not enabled",
+			svc:   &Service{store: &Store{bs: status}},
+			start: 10, end: 20,
+			err: ErrInvalidEpochRange,
+		},
+		{
+			name:  "genesis sync",
+			svc:   &Service{enabled: true, store: &Store{genesisSync: true}},
+			start: 10, end: 20,
+			err: ErrInvalidEpochRange,
+		},
+		{
+			name:  "start after end",
+			svc:   &Service{enabled: true, store: &Store{bs: status}},
+			start: 20, end: 10,
+			err: ErrInvalidEpochRange,
+		},
+		{
+			name:  "end after origin",
+			svc:   &Service{enabled: true, store: &Store{bs: status}},
+			start: 90, end: 101,
+			err: ErrInvalidEpochRange,
+		},
+		{
+			name:  "gap below backfilled history",
+			svc:   &Service{enabled: true, store: &Store{bs: status}},
+			start: 10, end: 48,
+			err: ErrRangeNotContiguous,
+		},
+		{
+			name:  "already backfilled",
+			svc:   &Service{enabled: true, store: &Store{bs: status}},
+			start: 50, end: 100,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.svc.BackfillEpochRange(c.start, c.end)
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+			} else {
+				require.NoError(t, err)
+			}
+			// None of the cases should change the minimum.
+			_, set := c.svc.requested.get()
+			require.Equal(t, false, set)
+		})
+	}
+}
+
+func TestBackfillRange(t *testing.T) {
+	status := &dbval.BackfillStatus{LowSlot: 500, OriginSlot: 1000}
+	cases := []struct {
+		name       string
+		svc        *Service
+		start, end primitives.Slot
+		err        error
+	}{
+		{
+			name:  "not enabled",
+			svc:   &Service{store: &Store{bs: status}},
+			start: 100, end: 200,
+			err: ErrInvalidSlotRange,
+		},
+		{
+			name:  "genesis sync",
+			svc:   &Service{enabled: true, store: &Store{genesisSync: true}},
+			start: 100, end: 200,
+			err: ErrInvalidSlotRange,
+		},
+		{
+			name:  "empty range",
+			svc:   &Service{enabled: true, store: &Store{bs: status}},
+			start: 200, end: 200,
+			err: ErrInvalidSlotRange,
+		},
+		{
+			name:  "end after origin",
+			svc:   &Service{enabled: true, store: &Store{bs: status}},
+			start: 900, end: 1001,
+			err: ErrInvalidSlotRange,
+		},
+		{
+			name:  "gap below backfilled history",
+			svc:   &Service{enabled: true, store: &Store{bs: status}},
+			start: 100, end: 200,
+			err: ErrRangeNotContiguous,
+		},
+		{
+			name:  "already backfilled",
+			svc:   &Service{enabled: true, store: &Store{bs: status}},
+			start: 500, end: 1000,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.svc.BackfillRange(context.Background(), c.start, c.end)
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+			} else {
+				require.NoError(t, err)
+			}
+			_, set := c.svc.requested.get()
+			require.Equal(t, false, set)
+		})
+	}
+}
+
+func TestBackfillRangeInterrupted(t *testing.T) {
+	// The runloop exits immediately because the service context is canceled while waiting for the clock.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := &Service{
+		ctx:     ctx,
+		enabled: true,
+		store:   &Store{bs: &dbval.BackfillStatus{LowSlot: 500, OriginSlot: 1000}},
+		cw:      startup.NewClockSynchronizer(),
+		newPool: func() batchWorkerPool { return nil },
+	}
+	err := s.BackfillRange(context.Background(), 100, 500)
+	require.ErrorIs(t, err, errBackfillRangeInterrupted)
+	req, set := s.requested.get()
+	require.Equal(t, true, set)
+	require.Equal(t, primitives.Slot(100), req)
+}
+
+func TestBackfillRangeAdvanced(t *testing.T) {
+	// The runloop waits for the clock until the service context is canceled, so BackfillRange returns when the status
+	// advances past the start of the range.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &Service{
+		ctx:     ctx,
+		enabled: true,
+		store:   &Store{bs: &dbval.BackfillStatus{LowSlot: 500, OriginSlot: 1000}},
+		cw:      startup.NewClockSynchronizer(),
+		newPool: func() batchWorkerPool { return nil },
+	}
+	go func() {
+		for !s.run.running() {
+			time.Sleep(time.Millisecond)
+		}
+		s.store.Lock()
+		s.store.bs = &dbval.BackfillStatus{LowSlot: 90, OriginSlot: 1000}
+		s.store.Unlock()
+		s.store.recordAdvance(90, time.Now())
+	}()
+	require.NoError(t, s.BackfillRange(ctx, 100, 500))
+	require.Equal(t, true, s.run.running())
+}
+
+func TestRunStateExited(t *testing.T) {
+	r := &runState{}
+	gen := r.generation()
+	exited, _ := r.exited(gen)
+	require.Equal(t, false, exited)
+	_, finish, ok := r.begin(context.Background())
+	require.Equal(t, true, ok)
+	exited, _ = r.exited(gen)
+	require.Equal(t, false, exited)
+	r.markComplete()
+	finish()
+	exited, complete := r.exited(gen)
+	require.Equal(t, true, exited)
+	require.Equal(t, true, complete)
+	// A runloop that exits without finishing is not complete, and earlier runs are not reported for a later gen.
+	gen = r.generation()
+	exited, _ = r.exited(gen)
+	require.Equal(t, false, exited)
+	_, finish, ok = r.begin(context.Background())
+	require.Equal(t, true, ok)
+	finish()
+	exited, complete = r.exited(gen)
+	require.Equal(t, true, exited)
+	require.Equal(t, false, complete)
+}
+
+func TestServiceMinimumRequested(t *testing.T) {
+	s := &Service{ms: func(primitives.Slot) primitives.Slot { return 1000 }}
+	require.Equal(t, primitives.Slot(1000), s.minimum(5000))
+	s.requested.lower(1200)
+	// A requested minimum above the configured minimum has no effect.
+	require.Equal(t, primitives.Slot(1000), s.minimum(5000))
+	s.requested.lower(500)
+	require.Equal(t, primitives.Slot(500), s.minimum(5000))
+	// The requested minimum can only be lowered.
+	s.requested.lower(800)
+	require.Equal(t, primitives.Slot(500), s.minimum(5000))
+}
@@ -0,0 +1,31 @@
+package backfill
+
+import (
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+)
+
+// ReadyAt returns true if backfill has downloaded history back to the given slot, or if it has completed, ie
+// reached TargetSlot. Nodes that synced from genesis have the full history and are always ready.
+func (p Progress) ReadyAt(sl primitives.Slot) bool {
+	if p.GenesisSync {
+		return true
+	}
+	return p.LowSlot <= sl || p.LowSlot <= p.TargetSlot
+}
+
+// Readiness reports whether backfill has progressed far enough for the node to serve requests for historical data.
+type Readiness struct {
+	store *Store
+	slot  primitives.Slot
+}
+
+// NewReadiness returns a Readiness that is ready once backfill has reached the given slot, or has completed.
+// Using slot 0 means the node is only ready once backfill is complete.
+func NewReadiness(s *Store, slot primitives.Slot) *Readiness {
+	return &Readiness{store: s, slot: slot}
+}
+
+// BackfillReady returns true if backfill has reached the configured slot, or has completed.
+func (r *Readiness) BackfillReady() bool {
+	return r.store.Progress().ReadyAt(r.slot)
+}
@@ -0,0 +1,30 @@
+package backfill
+
+import (
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestProgressReadyAt(t *testing.T) {
+	cases := []struct {
+		name  string
+		p     Progress
+		slot  primitives.Slot
+		ready bool
+	}{
+		{name: "genesis sync", p: Progress{GenesisSync: true}, slot: 0, ready: true},
+		{name: "not reached", p: Progress{LowSlot: 100, OriginSlot: 200}, slot: 50, ready: false},
+		{name: "reached", p: Progress{LowSlot: 50, OriginSlot: 200}, slot: 50, ready: true},
+		{name: "past slot", p: Progress{LowSlot: 40, OriginSlot: 200}, slot: 50, ready: true},
+		{name: "complete before slot", p: Progress{LowSlot: 60, OriginSlot: 200, TargetSlot: 60}, slot: 50, ready: true},
+		{name: "requires complete", p: Progress{LowSlot: 60, OriginSlot: 200, TargetSlot: 40}, slot: 0, ready: false},
+		{name: "target not computed", p: Progress{LowSlot: 60, OriginSlot: 200}, slot: 0, ready: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.ready, c.p.ReadyAt(c.slot))
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package backfill
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// runState tracks the lifecycle of the backfill runloop, so that the runloop can be stopped and later resumed.
+type runState struct {
+	sync.Mutex
+	// lifecycle serializes Stop and Resume, so that a stopped runloop is never resumed twice.
+	lifecycle sync.Mutex
+	cancel    context.CancelFunc
+	done      chan struct{}
+	scheduled time.Time
+	// gen is incremented every time the runloop begins.
+	gen uint64
+	// complete is set when the runloop exits after importing every batch down to the backfill minimum.
+	complete bool
+}
+
+// begin derives the runloop context from the parent context. The returned func must be called when the runloop exits.
+// begin returns false if the runloop is already running.
+func (r *runState) begin(parent context.Context) (context.Context, func(), bool) {
+	r.Lock()
+	defer r.Unlock()
+	if r.isRunning() {
+		return nil, nil, false
+	}
+	ctx, cancel := context.WithCancel(parent)
+	done := make(chan struct{})
+	r.cancel, r.done, r.scheduled = cancel, done, time.Time{}
+	r.gen, r.complete = r.gen+1, false
+	return ctx, func() {
+		cancel()
+		close(done)
+	}, true
+}
+
+// stop cancels the runloop context and blocks until the runloop exits.
+// It returns false if the runloop was never started.
+func (r *runState) stop() bool {
+	r.Lock()
+	cancel, done := r.cancel, r.done
+	r.Unlock()
+	if done == nil {
+		return false
+	}
+	cancel()
+	<-done
+	return true
+}
+
+func (r *runState) running() bool {
+	r.Lock()
+	defer r.Unlock()
+	return r.isRunning()
+}
+
+func (r *runState) isRunning() bool {
+	if r.done == nil {
+		return false
+	}
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// markScheduled records the time the runloop began scheduling batches, after any startup waits have finished.
+func (r *runState) markScheduled(t time.Time) {
+	r.Lock()
+	defer r.Unlock()
+	r.scheduled = t
+}
+
+// scheduledAt returns the time the current runloop began scheduling batches, or the zero value if it has not.
+func (r *runState) scheduledAt() time.Time {
+	r.Lock()
+	defer r.Unlock()
+	return r.scheduled
+}
+
+// markComplete records that the current runloop has imported every batch down to the backfill minimum.
+func (r *runState) markComplete() {
+	r.Lock()
+	defer r.Unlock()
+	r.complete = true
+}
+
+// wait returns a channel that is closed when the current runloop exits, or nil if the runloop was never started.
+func (r *runState) wait() <-chan struct{} {
+	r.Lock()
+	defer r.Unlock()
+	return r.done
+}
+
+// generation returns the number of times the runloop has begun.
+func (r *runState) generation() uint64 {
+	r.Lock()
+	defer r.Unlock()
+	return r.gen
+}
+
+// exited reports whether a runloop of a generation after gen has begun and exited, and if so, whether it completed.
+func (r *runState) exited(gen uint64) (exited bool, complete bool) {
+	r.Lock()
+	defer r.Unlock()
+	if r.gen <= gen || r.isRunning() {
+		return false, false
+	}
+	return true, r.complete
+}
@@ -2,6 +2,8 @@ package backfill
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/pkg/errors"
@@ -11,11 +13,16 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/startup"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
+	"github.com/prysmaticlabs/prysm/v5/cmd/beacon-chain/flags"
+	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/crypto/rand"
 	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
 	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
 	"github.com/prysmaticlabs/prysm/v5/runtime"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
 	"github.com/prysmaticlabs/prysm/v5/time/slots"
 )
 
@@ -39,6 +46,26 @@ type Service struct {
 	batchImporter   batchImporter
 	blobStore       *filesystem.BlobStorage
 	initSyncWaiter  func() error
+	newPool         func() batchWorkerPool
+	run             runState
+	coverageSample  uint64
+	sinceSample     uint64
+	maxBuffered     uint64
+	skipBlobs       bool
+	blobPruner      *blobPruner
+	requested       requestedMinimum
+	inFlight        *inFlightRanges
+	breaker         *peerBreaker
+	maxPeers        int
+	perPeer         int
+	quorum          int
+	wall            prysmTime.Clock
+	advertisedAt    time.Time
+	failed          *failedRanges
+	importSource    ImportSource
+	importClosed    bool
+	anchor          *trustedAnchor
+	rand            *rand.Rand
 }
 
 var _ runtime.Service = (*Service)(nil)
@@ -64,6 +91,17 @@ func defaultBatchImporter(ctx context.Context, current primitives.Slot, b batch,
 	return su.fillBack(ctx, current, b.results, b.availabilityStore())
 }
 
+// blocksOnlyBatchImporter imports the blocks of a batch without checking that their blobs are available, for use when
+// blobs are not backfilled. The blob low slot of the status is left in place, so the Store keeps reporting that the
+// blobs for the imported range are missing.
+func blocksOnlyBatchImporter(ctx context.Context, current primitives.Slot, b batch, su *Store) (*dbval.BackfillStatus, error) {
+	status := su.status()
+	if err := b.ensureParent(bytesutil.ToBytes32(status.LowParentRoot)); err != nil {
+		return status, err
+	}
+	return su.fillBack(ctx, current, b.results, nil)
+}
+
 // ServiceOption represents a functional option for the backfill service constructor.
 type ServiceOption func(*Service) error
 
@@ -93,6 +131,115 @@ func WithBatchSize(n uint64) ServiceOption {
 	}
 }
 
+// WithCoverageSampling enables a check, run after every n imported batches, that picks a random slot from the
+// backfilled range of history and verifies that blocks for it are present in the db. Discrepancies are logged and
+// counted in the backfill_coverage_discrepancies metric. A value of 0 disables the check.
+func WithCoverageSampling(n uint64) ServiceOption {
+	return func(s *Service) error {
+		s.coverageSample = n
+		return nil
+	}
+}
+
+// WithMaxBufferedBytes limits the memory used to hold batches that have been downloaded, but can't be imported yet.
+// When the approximate size of these batches exceeds n bytes, new batches are not requested until enough batches
+// have been imported to bring the total back under the limit. A value of 0 disables the limit.
+func WithMaxBufferedBytes(n uint64) ServiceOption {
+	return func(s *Service) error {
+		s.maxBuffered = n
+		return nil
+	}
+}
+
+// WithSkipBlobs backfills blocks without requesting or storing their blob sidecars. The blob low slot of the
+// backfill status does not move while blobs are skipped, so the Store reports the backfilled blocks as missing
+// their blobs.
+func WithSkipBlobs(skip bool) ServiceOption {
+	return func(s *Service) error {
+		s.skipBlobs = skip
+		return nil
+	}
+}
+
+// WithImportSource backfills from the given source instead of downloading batches from peers. Batches read from the
+// source are verified and imported the same way as batches downloaded from peers, so the source only needs to be
+// trusted to hold the history, not to be correct. A source that is also an io.Closer is closed by Stop.
+func WithImportSource(src ImportSource) ServiceOption {
+	return func(s *Service) error {
+		s.importSource = src
+		return nil
+	}
+}
+
+// WithBlobPruning enables periodic pruning of blob sidecars that are older than the blob retention floor by more
+// than margin epochs. The blob low slot of the backfill status is raised before the blobs are deleted, so that
+// the Store reports them as unavailable. Pruning keeps running after backfill completes or is stopped.
+func WithBlobPruning(margin primitives.Epoch) ServiceOption {
+	return func(s *Service) error {
+		s.blobPruner = &blobPruner{store: s.store, blobs: s.blobStore, margin: margin}
+		if s.blobStore != nil {
+			s.blobPruner.retention = s.blobStore.RetentionEpochs()
+		}
+		return nil
+	}
+}
+
+// WithBytesPerSlotEstimate sets the average number of bytes of blocks and blobs per slot used by
+// Store.RemainingBytesEstimate. A value of 0 uses the default estimate.
+func WithBytesPerSlotEstimate(n uint64) ServiceOption {
+	return func(s *Service) error {
+		s.store.setBytesPerSlot(n)
+		return nil
+	}
+}
+
+// WithMaxPeers sets the largest number of peers that backfill batches are requested from at the same time. A value
+// of 0 means the number of workers is the only limit.
+func WithMaxPeers(n int) ServiceOption {
+	return func(s *Service) error {
+		s.maxPeers = n
+		return nil
+	}
+}
+
+// WithMaxRequestsPerPeer sets the largest number of backfill batches that can be requested from a single peer at the
+// same time. A value of 0 uses the default of 1.
+func WithMaxRequestsPerPeer(n int) ServiceOption {
+	return func(s *Service) error {
+		s.perPeer = n
+		return nil
+	}
+}
+
+// WithQuorum requires n peers to return the same blocks for a batch before it is imported, so that a single peer
+// can't feed backfill a fork that verifies but is not canonical. Values below 2 disable the check.
+func WithQuorum(n int) ServiceOption {
+	return func(s *Service) error {
+		s.quorum = n
+		return nil
+	}
+}
+
+// WithWallClock sets the source of time used for retry delays, peer assignment retries and stall detection. It
+// defaults to the system clock, tests can use a simulated clock to control the timers.
+func WithWallClock(c prysmTime.Clock) ServiceOption {
+	return func(s *Service) error {
+		s.wall = c
+		return nil
+	}
+}
+
+// WithTrustedAnchor sets a block root, such as a weak subjectivity checkpoint, that backfilled blocks must chain to,
+// independently of the checkpoint sync origin stored in the db. Batches that cover the slot of the anchor are
+// rejected unless they contain the anchor block. The anchor is compared to the backfill status when the service
+// starts, with a warning logged if it doesn't match the origin, or history that was already backfilled.
+func WithTrustedAnchor(root [32]byte, slot primitives.Slot) ServiceOption {
+	return func(s *Service) error {
+		s.anchor = &trustedAnchor{root: root, slot: slot}
+		return nil
+	}
+}
+
 // WithInitSyncWaiter sets a function on the service which will block until init-sync
 // completes for the first time, or returns an error if context is canceled.
 func WithInitSyncWaiter(w func() error) ServiceOption {
@@ -148,13 +295,34 @@ func NewService(ctx context.Context, su *Store, bStore *filesystem.BlobStorage,
 		p2p:           p,
 		pa:            pa,
 		batchImporter: defaultBatchImporter,
+		inFlight:      newInFlightRanges(),
+		breaker:       newPeerBreaker(),
+		perPeer:       defaultRequestsPerPeer,
+		wall:          prysmTime.RealClock{},
 	}
 	for _, o := range opts {
 		if err := o(s); err != nil {
 			return nil, err
 		}
 	}
-	s.pool = newP2PBatchWorkerPool(p, s.nWorkers)
+	// The clock can be replaced by an option, so the components that read it are set up after the options.
+	s.failed = newFailedRanges(s.wall)
+	su.wall = s.wall
+	if bc := backfillBatchCount(); s.batchSize > bc {
+		log.WithField("batchSize", s.batchSize).WithField("maxBatchSize", bc).
+			Warn("Backfill batch size exceeds the maximum range that can be requested from peers, using the maximum")
+		s.batchSize = bc
+	}
+	if s.skipBlobs {
+		s.batchImporter = blocksOnlyBatchImporter
+	}
+	s.newPool = func() batchWorkerPool {
+		if s.importSource != nil {
+			return newImportBatchWorkerPool(s.importSource, s.nWorkers, s.inFlight, s.wall, s.skipBlobs)
+		}
+		return newP2PBatchWorkerPool(p, s.nWorkers, s.inFlight, s.breaker, s.maxPeers, s.perPeer, s.skipBlobs, s.quorum, s.wall)
+	}
+	s.pool = s.newPool()
 
 	return s, nil
 }
@@ -182,15 +350,19 @@ func (s *Service) updateComplete() bool {
 	if err != nil {
 		if errors.Is(err, errEndSequence) {
 			log.WithField("backfillSlot", b.begin).Info("Backfill is complete")
+			s.run.markComplete()
 			return true
 		}
 		log.WithError(err).Error("Backfill service received unhandled error from worker pool")
 		return true
 	}
+	s.failed.update(b)
 	s.batchSeq.update(b)
 	return false
 }
 
+// importBatches imports the importable batches in order, one at a time, so backfill never holds more than one db
+// write transaction. Batches are still downloaded and verified concurrently by the worker pool.
 func (s *Service) importBatches(ctx context.Context) {
 	importable := s.batchSeq.importable()
 	imported := 0
@@ -201,33 +373,87 @@ func (s *Service) importBatches(ctx context.Context) {
 		backfillBatchesImported.Add(float64(imported))
 	}()
 	current := s.clock.CurrentSlot()
+	// Importing a batch takes several db writes. Stopping the service should not interrupt an import that is
+	// already underway, so that the db is never left with a partially imported batch.
+	ictx := context.WithoutCancel(ctx)
 	for i := range importable {
+		if ctx.Err() != nil {
+			break
+		}
 		ib := importable[i]
 		if len(ib.results) == 0 {
-			log.WithFields(ib.logFields()).Error("Batch with no results, skipping importer")
+			// Every slot in the batch was skipped, so there is nothing to import. The status is unchanged, so the next
+			// batch is still checked against the parent root of the lowest imported block.
+			log.WithFields(ib.logFields()).Debug("Batch with no results, skipping importer")
+			ib = ib.withState(batchImportComplete)
+			s.failed.update(ib)
+			s.batchSeq.update(ib)
+			imported += 1
+			continue
 		}
-		_, err := s.batchImporter(ctx, current, ib, s.store)
+		_, err := s.batchImporter(ictx, current, ib, s.store)
 		if err != nil {
 			log.WithError(err).WithFields(ib.logFields()).Debug("Backfill batch failed to import")
 			s.downscore(ib)
-			s.batchSeq.update(ib.withState(batchErrRetryable))
+			ib = ib.withRetryableError(err)
+			s.failed.update(ib)
+			s.batchSeq.update(ib)
 			// If a batch fails, the subsequent batches are no longer considered importable.
 			break
 		}
-		s.batchSeq.update(ib.withState(batchImportComplete))
+		ib = ib.withState(batchImportComplete)
+		s.failed.update(ib)
+		s.batchSeq.update(ib)
 		imported += 1
 		// Calling update with state=batchImportComplete will advance the batch list.
 	}
 
+	s.maybeSampleCoverage(ictx, imported)
+
 	nt := s.batchSeq.numTodo()
 	log.WithField("imported", imported).WithField("importable", len(importable)).
 		WithField("batchesRemaining", nt).
 		Info("Backfill batches processed")
 
 	backfillRemainingBatches.Set(float64(nt))
+	backfillRemainingBytesEstimate.Set(float64(s.store.RemainingBytesEstimate()))
+}
+
+// FailedRanges returns the batch ranges that have failed at least once and are waiting to be retried, in descending
+// slot order. Ranges are removed once they are imported.
+func (s *Service) FailedRanges() []FailedRange {
+	return s.failed.list()
+}
+
+func (s *Service) maybeSampleCoverage(ctx context.Context, imported int) {
+	if s.coverageSample == 0 || imported == 0 {
+		return
+	}
+	s.sinceSample += uint64(imported)
+	if s.sinceSample < s.coverageSample {
+		return
+	}
+	s.sinceSample = 0
+	if s.rand == nil {
+		s.rand = rand.NewGenerator()
+	}
+	s.store.sampleCoverage(ctx, s.rand)
 }
 
 func (s *Service) scheduleTodos() {
+	buffered := s.batchSeq.bufferedBytes()
+	backfillBufferedBytes.Set(float64(buffered))
+	if s.maxBuffered > 0 && buffered > s.maxBuffered {
+		// Batches in front of the buffered batches still need to be retried, otherwise the buffer could never drain.
+		retries := s.batchSeq.sequenceRetries()
+		log.WithField("bufferedBytes", buffered).WithField("maxBufferedBytes", s.maxBuffered).
+			WithField("retries", len(retries)).Debug("Backfill buffer is full, waiting for imports before requesting new batches")
+		s.updateActiveLow()
+		for _, b := range retries {
+			s.pool.todo(b)
+		}
+		return
+	}
 	batches, err := s.batchSeq.sequence()
 	if err != nil {
 		// This typically means we have several importable batches, but they are stuck behind a batch that needs
@@ -241,6 +467,7 @@ func (s *Service) scheduleTodos() {
 			return
 		}
 	}
+	s.updateActiveLow()
 	for _, b := range batches {
 		s.pool.todo(b)
 	}
@@ -250,12 +477,33 @@ func (s *Service) scheduleTodos() {
 func (s *Service) Start() {
 	if !s.enabled {
 		log.Info("Backfill service not enabled")
+		// Peers still need to know that the node can't serve blocks from before the checkpoint sync origin.
+		if clock, err := s.cw.WaitForClock(s.ctx); err == nil {
+			s.clock = clock
+			// The target still decides whether the history the node already has is complete.
+			s.store.setTarget(s.minimum(s.clock.CurrentSlot()))
+			s.advertiseEarliestSlot()
+			s.startBlobPruner()
+		}
 		return
 	}
-	ctx, cancel := context.WithCancel(s.ctx)
+	ctx, finish, ok := s.run.begin(s.ctx)
+	if !ok {
+		log.Warn("Backfill service is already running")
+		return
+	}
+	s.runLoop(ctx, finish)
+}
+
+// runLoop is the body of a backfill runloop that was claimed with run.begin. The fields that are (re)initialized here,
+// eg clock, verifier and batchSeq, are only accessed by the runloop, and a runloop only begins after the previous one
+// has called finish.
+func (s *Service) runLoop(ctx context.Context, finish func()) {
+	// Statuses that fail to save are retried for the lifetime of the service, even while the runloop is stopped.
+	s.store.startRetry(s.ctx)
 	defer func() {
 		log.Info("Backfill service is shutting down")
-		cancel()
+		finish()
 	}()
 	clock, err := s.cw.WaitForClock(ctx)
 	if err != nil {
@@ -263,6 +511,9 @@ func (s *Service) Start() {
 		return
 	}
 	s.clock = clock
+	s.store.setTarget(s.minimum(s.clock.CurrentSlot()))
+	s.advertiseEarliestSlot()
+	s.startBlobPruner()
 	v, err := s.verifierWaiter.WaitForInitializer(ctx)
 	s.newBlobVerifier = newBlobVerifierFromInitializer(v)
 
@@ -277,8 +528,8 @@ func (s *Service) Start() {
 	}
 	status := s.store.status()
 	// Exit early if there aren't going to be any batches to backfill.
-	if primitives.Slot(status.LowSlot) <= s.ms(s.clock.CurrentSlot()) {
-		log.WithField("minimumRequiredSlot", s.ms(s.clock.CurrentSlot())).
+	if primitives.Slot(status.LowSlot) <= s.minimum(s.clock.CurrentSlot()) {
+		log.WithField("minimumRequiredSlot", s.minimum(s.clock.CurrentSlot())).
 			WithField("backfillLowestSlot", status.LowSlot).
 			Info("Exiting backfill service; minimum block retention slot > lowest backfilled block")
 		return
@@ -288,6 +539,10 @@ func (s *Service) Start() {
 		log.WithError(err).Error("Unable to initialize backfill verifier")
 		return
 	}
+	if s.anchor != nil && s.anchor.validate(ctx, s.store.store, status) {
+		log.WithFields(s.anchor.logFields()).Info("Backfill will verify that history contains the trusted anchor block")
+		s.verifier.anchor = s.anchor
+	}
 
 	if s.initSyncWaiter != nil {
 		log.Info("Backfill service waiting for initial-sync to reach head before starting")
@@ -296,8 +551,10 @@ func (s *Service) Start() {
 			return
 		}
 	}
+	s.run.markScheduled(s.wall.Now())
 	s.pool.spawn(ctx, s.nWorkers, clock, s.pa, s.verifier, s.ctxMap, s.newBlobVerifier, s.blobStore)
-	s.batchSeq = newBatchSequencer(s.nWorkers, s.ms(s.clock.CurrentSlot()), primitives.Slot(status.LowSlot), primitives.Slot(s.batchSize))
+	s.batchSeq = newBatchSequencer(s.nWorkers, s.minimum(s.clock.CurrentSlot()), primitives.Slot(status.LowSlot), primitives.Slot(s.batchSize), s.wall)
+	defer s.store.clearActiveLow()
 	if err = s.initBatches(); err != nil {
 		log.WithError(err).Error("Non-recoverable error in backfill service")
 		return
@@ -308,40 +565,149 @@ func (s *Service) Start() {
 			return
 		}
 		if s.updateComplete() {
+			s.advertiseEarliestSlot()
 			return
 		}
 		s.importBatches(ctx)
+		s.throttledAdvertiseEarliestSlot()
 		batchesWaiting.Set(float64(s.batchSeq.countWithState(batchImportable)))
-		if err := s.batchSeq.moveMinimum(s.ms(s.clock.CurrentSlot())); err != nil {
+		minimum := s.minimum(s.clock.CurrentSlot())
+		s.store.setTarget(minimum)
+		if err := s.batchSeq.moveMinimum(minimum); err != nil {
 			log.WithError(err).Error("Non-recoverable error while adjusting backfill minimum slot")
 		}
 		s.scheduleTodos()
 	}
 }
 
+// advertiseEarliestSlot updates the earliest slot the node advertises to peers for range requests. This is the lowest
+// backfilled block, or the block retention floor if that is higher.
+func (s *Service) advertiseEarliestSlot() {
+	earliest := minimumBackfillSlot(s.clock.CurrentSlot())
+	if !s.store.isGenesisSync() {
+		if low := primitives.Slot(s.store.status().LowSlot); low > earliest {
+			earliest = low
+		}
+	}
+	s.p2p.SetEarliestAvailableSlot(earliest)
+	s.advertisedAt = s.wall.Now()
+}
+
+// throttledAdvertiseEarliestSlot calls advertiseEarliestSlot at most once per epoch. Each change bumps the sequence
+// number of the ENR, which peers then fetch again through discovery, so it isn't worth doing after every batch.
+// Until the next update peers see a slot that is too high, which only makes them ask other peers for older blocks.
+func (s *Service) throttledAdvertiseEarliestSlot() {
+	epoch := time.Duration(params.BeaconConfig().SlotsPerEpoch.Mul(params.BeaconConfig().SecondsPerSlot)) * time.Second
+	if s.wall.Now().Sub(s.advertisedAt) < epoch {
+		return
+	}
+	s.advertiseEarliestSlot()
+}
+
+// startBlobPruner starts the blob pruner, if it is enabled. Restarting the service does not start a second pruner.
+func (s *Service) startBlobPruner() {
+	if s.blobPruner == nil {
+		return
+	}
+	s.blobPruner.start(s.ctx, s.clock, s.wall)
+}
+
 func (s *Service) initBatches() error {
 	batches, err := s.batchSeq.sequence()
 	if err != nil {
 		return err
 	}
+	s.updateActiveLow()
 	for _, b := range batches {
 		s.pool.todo(b)
 	}
 	return nil
 }
 
+// updateActiveLow tells the Store the lowest slot of the batches in the sequence, so that the blob pruner does not
+// delete their blobs. It is called before batches are handed to the worker pool.
+func (s *Service) updateActiveLow() {
+	if low, ok := s.batchSeq.activeLow(); ok {
+		s.store.setActiveLow(low)
+		return
+	}
+	s.store.clearActiveLow()
+}
+
 func (s *Service) downscore(b batch) {
+	if b.blockPid == "" {
+		// Batches read from an ImportSource were not downloaded from a peer.
+		return
+	}
 	s.p2p.Peers().Scorers().BadResponsesScorer().Increment(b.blockPid)
 }
 
-func (*Service) Stop() error {
-	return nil
+// Stop cancels the backfill runloop and blocks until it has exited. Batches that are still being downloaded are
+// abandoned, but a batch import that is already underway is allowed to complete, after which the backfill status
+// is persisted. Backfill can be restarted from the persisted status by calling Resume, unless it imports from a
+// source that Stop closed.
+func (s *Service) Stop() error {
+	s.run.lifecycle.Lock()
+	defer s.run.lifecycle.Unlock()
+	err := s.stop()
+	if c, ok := s.importSource.(io.Closer); ok && !s.importClosed {
+		// The runloop has exited, so no worker is reading from the source anymore.
+		s.importClosed = true
+		if cerr := c.Close(); cerr != nil {
+			log.WithError(cerr).Error("Could not close backfill import source")
+		}
+	}
+	return err
+}
+
+func (s *Service) stop() error {
+	if !s.run.stop() {
+		return nil
+	}
+	return s.store.flush(context.WithoutCancel(s.ctx))
+}
+
+// Resume restarts a backfill runloop that was previously halted by Stop. It is a no-op if the runloop is running.
+// The runloop is claimed before Resume returns, so a Stop that follows Resume always waits for the new runloop.
+func (s *Service) Resume() {
+	s.run.lifecycle.Lock()
+	defer s.run.lifecycle.Unlock()
+	s.resume()
+}
+
+func (s *Service) resume() {
+	if !s.enabled || s.importClosed {
+		return
+	}
+	ctx, finish, ok := s.run.begin(s.ctx)
+	if !ok {
+		return
+	}
+	// The worker pool can't be reused after its context is canceled. The previous runloop has exited, and the new
+	// one has not started, so nothing else is using the pool.
+	s.pool = s.newPool()
+	go s.runLoop(ctx, finish)
 }
 
 func (*Service) Status() error {
 	return nil
 }
 
+// backfillBatchCount is the largest number of slots that a backfill batch can request in a single
+// blocks or blobs by range request. It is the smaller of the spec request limits and the node's block batch limit,
+// so that both the block and blob requests for a batch stay within what peers will serve.
+func backfillBatchCount() uint64 {
+	cfg := params.BeaconConfig()
+	count := cfg.MaxRequestBlocksDeneb
+	if bc := cfg.MaxRequestBlobSidecars / fieldparams.MaxBlobsPerBlock; bc < count {
+		count = bc
+	}
+	if limit := uint64(flags.Get().BlockBatchLimit); limit > 0 && limit < count {
+		count = limit
+	}
+	return count
+}
+
 // minimumBackfillSlot determines the lowest slot that backfill needs to download based on looking back
 // MIN_EPOCHS_FOR_BLOCK_REQUESTS from the current slot.
 func minimumBackfillSlot(current primitives.Slot) primitives.Slot {
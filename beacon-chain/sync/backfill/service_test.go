@@ -5,17 +5,21 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/core/helpers"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/filesystem"
 	p2ptest "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/testing"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/startup"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/state"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
+	"github.com/prysmaticlabs/prysm/v5/cmd/beacon-chain/flags"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
 	"github.com/prysmaticlabs/prysm/v5/testing/require"
 	"github.com/prysmaticlabs/prysm/v5/testing/util"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+	prysmTesting "github.com/prysmaticlabs/prysm/v5/time/testing"
 )
 
 type mockMinimumSlotter struct {
@@ -49,6 +53,7 @@ func TestServiceInit(t *testing.T) {
 	db.states = map[[32]byte]state.BeaconState{originRoot: origin}
 	su.bs = &dbval.BackfillStatus{
 		LowSlot:    high,
+		OriginSlot: high,
 		OriginRoot: originRoot[:],
 	}
 	remaining := nBatches
@@ -83,6 +88,82 @@ func TestServiceInit(t *testing.T) {
 	}
 }
 
+func TestServiceStopResume(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	db := &mockBackfillDB{}
+	su, err := NewUpdater(ctx, db)
+	require.NoError(t, err)
+	nWorkers := 2
+	var batchSize uint64 = 100
+	var high uint64 = 11235
+	originRoot := [32]byte{}
+	origin, err := util.NewBeaconState()
+	require.NoError(t, err)
+	db.states = map[[32]byte]state.BeaconState{originRoot: origin}
+	su.bs = &dbval.BackfillStatus{
+		LowSlot:    high,
+		OriginSlot: high,
+		OriginRoot: originRoot[:],
+	}
+	cw := startup.NewClockSynchronizer()
+	require.NoError(t, cw.SetClock(startup.NewClock(time.Now(), [32]byte{})))
+	newPool := func() *mockPool {
+		return &mockPool{todoChan: make(chan batch, nWorkers), finishedChan: make(chan batch, nWorkers)}
+	}
+	pool := newPool()
+	srv, err := NewService(ctx, su, filesystem.NewEphemeralBlobStorage(t), cw, p2ptest.NewTestP2P(t), &mockAssigner{},
+		WithBatchSize(batchSize), WithWorkerCount(nWorkers), WithEnableBackfill(true), WithVerifierWaiter(&mockInitalizerWaiter{}))
+	require.NoError(t, err)
+	srv.ms = mockMinimumSlotter{min: primitives.Slot(high - batchSize*10)}.minimumSlot
+	srv.pool = pool
+	pools := 0
+	srv.newPool = func() batchWorkerPool {
+		pools++
+		pool = newPool()
+		return pool
+	}
+
+	// Stopping a service that hasn't started is a no-op.
+	require.NoError(t, srv.Stop())
+
+	go srv.Start()
+	todo := testReadN(ctx, t, pool.todoChan, nWorkers, make([]batch, 0))
+	require.Equal(t, nWorkers, len(todo))
+	require.Equal(t, true, srv.run.running())
+	require.NoError(t, srv.Stop())
+	require.Equal(t, false, srv.run.running())
+	// The current status should have been persisted on stop.
+	require.Equal(t, high, db.status.LowSlot)
+
+	// Resuming should start a new runloop with a new pool that is sequenced from the persisted status.
+	// The runloop is claimed before Resume returns, so resuming again is a no-op.
+	srv.Resume()
+	require.Equal(t, true, srv.run.running())
+	srv.Resume()
+	require.Equal(t, 1, pools)
+	todo = testReadN(ctx, t, pool.todoChan, nWorkers, make([]batch, 0))
+	require.Equal(t, primitives.Slot(high), todo[0].end)
+	require.NoError(t, srv.Stop())
+}
+
+func TestBackfillBatchCount(t *testing.T) {
+	resetFlags := flags.Get()
+	defer flags.Init(resetFlags)
+
+	specMax := params.BeaconConfig().MaxRequestBlocksDeneb
+	flags.Init(&flags.GlobalFlags{})
+	require.Equal(t, specMax, backfillBatchCount())
+	flags.Init(&flags.GlobalFlags{BlockBatchLimit: 10})
+	require.Equal(t, uint64(10), backfillBatchCount())
+	flags.Init(&flags.GlobalFlags{BlockBatchLimit: int(specMax) + 1})
+	require.Equal(t, specMax, backfillBatchCount())
+
+	s, err := NewService(context.Background(), &Store{}, nil, nil, nil, nil, WithBatchSize(specMax*2))
+	require.NoError(t, err)
+	require.Equal(t, specMax, s.batchSize)
+}
+
 func TestMinimumBackfillSlot(t *testing.T) {
 	oe := helpers.MinEpochsForBlockRequests()
 
@@ -95,6 +176,52 @@ func TestMinimumBackfillSlot(t *testing.T) {
 	require.Equal(t, primitives.Slot(1), minSlot)
 }
 
+func TestAdvertiseEarliestSlot(t *testing.T) {
+	// The clock is early in the chain, so the block retention floor is slot 1.
+	clock := startup.NewClock(time.Now(), [32]byte{})
+	p := p2ptest.NewTestP2P(t)
+	wall := prysmTesting.NewClock(time.Now())
+	s := &Service{clock: clock, p2p: p, wall: wall, store: &Store{bs: &dbval.BackfillStatus{LowSlot: 100}}}
+	s.advertiseEarliestSlot()
+	require.Equal(t, primitives.Slot(100), p.EarliestSlot)
+
+	// Progress is only advertised once an epoch has passed since the last update.
+	s.store = &Store{bs: &dbval.BackfillStatus{LowSlot: 50}}
+	s.throttledAdvertiseEarliestSlot()
+	require.Equal(t, primitives.Slot(100), p.EarliestSlot)
+	wall.Advance(time.Duration(params.BeaconConfig().SlotsPerEpoch.Mul(params.BeaconConfig().SecondsPerSlot)) * time.Second)
+	s.throttledAdvertiseEarliestSlot()
+	require.Equal(t, primitives.Slot(50), p.EarliestSlot)
+
+	s.store = &Store{bs: &dbval.BackfillStatus{LowSlot: 0}}
+	s.advertiseEarliestSlot()
+	require.Equal(t, primitives.Slot(1), p.EarliestSlot)
+
+	s.store = &Store{genesisSync: true}
+	p.EarliestSlot = 100
+	s.advertiseEarliestSlot()
+	require.Equal(t, primitives.Slot(1), p.EarliestSlot)
+}
+
+func TestStartDisabledSetsTarget(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	su, err := NewUpdater(ctx, &mockBackfillDB{})
+	require.NoError(t, err)
+	su.bs = &dbval.BackfillStatus{LowSlot: 50, OriginSlot: 200}
+	cw := startup.NewClockSynchronizer()
+	require.NoError(t, cw.SetClock(startup.NewClock(time.Now(), [32]byte{})))
+	srv, err := NewService(ctx, su, filesystem.NewEphemeralBlobStorage(t), cw, p2ptest.NewTestP2P(t), &mockAssigner{})
+	require.NoError(t, err)
+	srv.ms = mockMinimumSlotter{min: 100}.minimumSlot
+	srv.Start()
+
+	// History already reaches below the retention floor, so it is complete even though backfill won't run.
+	require.Equal(t, primitives.Slot(100), su.Progress().TargetSlot)
+	_, _, _, complete := su.EpochSummary()
+	require.Equal(t, true, complete)
+}
+
 func testReadN(ctx context.Context, t *testing.T, c chan batch, n int, into []batch) []batch {
 	for i := 0; i < n; i++ {
 		select {
@@ -132,3 +259,58 @@ func TestBackfillMinSlotDefault(t *testing.T) {
 		require.Equal(t, specMin, s.ms(current))
 	})
 }
+
+func TestScheduleTodosBufferLimit(t *testing.T) {
+	pool := &mockPool{todoChan: make(chan batch, 4)}
+	s := &Service{pool: pool, maxBuffered: 100, store: &Store{}}
+	s.batchSeq = newBatchSequencer(4, 0, 1000, 10, prysmTime.RealClock{})
+	s.scheduleTodos()
+	require.Equal(t, 4, len(pool.todoChan))
+	// The blobs of the scheduled batches are held back from pruning.
+	require.Equal(t, true, s.store.hasActive)
+	require.Equal(t, primitives.Slot(960), s.store.activeLow)
+	for len(pool.todoChan) > 0 {
+		<-pool.todoChan
+	}
+
+	// Complete the first batch so that a new batch is added to the sequence, and fill the buffer with the next batch.
+	s.batchSeq.update(s.batchSeq.seq[0].withState(batchImportComplete))
+	s.batchSeq.seq[1] = s.batchSeq.seq[1].withState(batchImportable)
+	s.batchSeq.seq[1].bytes = 101
+	s.batchSeq.seq[0] = s.batchSeq.seq[0].withRetryableError(errors.New("test"))
+	// Only the retry is scheduled while the buffer is over the limit.
+	s.scheduleTodos()
+	require.Equal(t, 1, len(pool.todoChan))
+	retry := <-pool.todoChan
+	require.Equal(t, s.batchSeq.seq[0].begin, retry.begin)
+	require.Equal(t, batchInit, s.batchSeq.seq[3].state)
+
+	// Once the buffer drains, the new batch is scheduled.
+	s.batchSeq.seq[1].bytes = 100
+	s.scheduleTodos()
+	require.Equal(t, 1, len(pool.todoChan))
+	next := <-pool.todoChan
+	require.Equal(t, s.batchSeq.seq[3].begin, next.begin)
+}
+
+func TestImportBatchesEmptyBatch(t *testing.T) {
+	s := &Service{
+		clock:  startup.NewClock(time.Now(), [32]byte{}),
+		failed: newFailedRanges(prysmTime.RealClock{}),
+		store:  &Store{bs: &dbval.BackfillStatus{LowSlot: 1000, OriginSlot: 1000}},
+		batchImporter: func(_ context.Context, _ primitives.Slot, b batch, _ *Store) (*dbval.BackfillStatus, error) {
+			require.NotEqual(t, 0, len(b.results))
+			return nil, nil
+		},
+	}
+	s.batchSeq = newBatchSequencer(2, 0, 1000, 10, prysmTime.RealClock{})
+	_, err := s.batchSeq.sequence()
+	require.NoError(t, err)
+	// A range where every slot was skipped has no blocks, and is complete without importing anything.
+	empty := s.batchSeq.seq[0].withResults(nil, &blobSync{})
+	require.Equal(t, batchImportable, empty.state)
+	s.batchSeq.update(empty)
+	s.importBatches(context.Background())
+	require.Equal(t, empty.begin-10, s.batchSeq.seq[0].begin)
+	require.Equal(t, 0, len(s.failed.list()))
+}
@@ -1,26 +1,56 @@
 package backfill
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/async/event"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/das"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/state"
+	prysmsync "github.com/prysmaticlabs/prysm/v5/beacon-chain/sync"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/interfaces"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
 	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+	"github.com/prysmaticlabs/prysm/v5/time/slots"
+	"github.com/sirupsen/logrus"
 )
 
 var errBatchDisconnected = errors.New("highest block root in backfill batch doesn't match next parent_root")
+var errBlobsBelowBlocks = errors.New("blob backfill can not extend below the lowest backfilled block")
+var errOriginRootMismatch = errors.New("origin checkpoint root does not match the root of the stored origin block")
+var errNoBackfillStatus = errors.New("node has no backfill status, it was synced from genesis")
+var errImportedStatusInvalid = errors.New("imported backfill status is not consistent with the db")
+
+// ErrOriginOffsetUnderflow indicates an offset relative to the checkpoint sync origin reaches back past genesis.
+var ErrOriginOffsetUnderflow = errors.New("offset from checkpoint sync origin is before genesis")
+
+// ErrBackfillBoundsCrossed indicates an update to the backfill status was rejected because the bounds of the
+// backfilled range would cross, ie the low slot would be above the origin slot, or blobs would be backfilled
+// below the lowest backfilled block.
+var ErrBackfillBoundsCrossed = errors.New("backfill status bounds would cross")
+
+// ErrSlotNotBackfilled indicates that the history for a slot is not available yet, because the node was initialized
+// via checkpoint sync and backfill has not reached the slot. It is returned by AssertCovered.
+var ErrSlotNotBackfilled = errors.New("history for the slot has not been backfilled yet")
+
+// ErrOriginChanged indicates an update to the backfill status was rejected because it would change the checkpoint
+// sync origin. The origin is only set when the status is first created, or loaded from the db.
+var ErrOriginChanged = errors.New("backfill status update would change the checkpoint sync origin")
 
 // NewUpdater correctly initializes a StatusUpdater value with the required database value.
 func NewUpdater(ctx context.Context, store BeaconDB) (*Store, error) {
 	s := &Store{
-		store: store,
+		store:     store,
+		retryWake: make(chan struct{}, 1),
+		wall:      prysmTime.RealClock{},
 	}
 	status, err := s.store.BackfillStatus(ctx)
 	if err != nil {
@@ -30,9 +60,23 @@ func NewUpdater(ctx context.Context, store BeaconDB) (*Store, error) {
 		return nil, errors.Wrap(err, "db error while reading status of previous backfill")
 	}
 	s.swapStatus(status)
+	statusLogFields(status).Info("Loaded existing backfill status from db")
 	return s, nil
 }
 
+func statusLogFields(bs *dbval.BackfillStatus) *logrus.Entry {
+	if bs == nil {
+		return log.WithFields(logrus.Fields{})
+	}
+	return log.WithFields(logrus.Fields{
+		"lowSlot":     bs.LowSlot,
+		"lowRoot":     fmt.Sprintf("%#x", bs.LowRoot),
+		"originSlot":  bs.OriginSlot,
+		"originRoot":  fmt.Sprintf("%#x", bs.OriginRoot),
+		"blobLowSlot": bs.BlobLowSlot,
+	})
+}
+
 // Store provides a way to update and query the status of a backfill process that may be necessary to track when
 // a node was initialized via checkpoint sync. With checkpoint sync, there will be a gap in node history from genesis
 // until the checkpoint sync origin block. Store provides the means to update the value keeping track of the lower
@@ -40,9 +84,72 @@ func NewUpdater(ctx context.Context, store BeaconDB) (*Store, error) {
 // via the AvailableBlock() method, and to see the current StartGap() and EndGap().
 type Store struct {
 	sync.RWMutex
+	// updating serializes the methods that read, modify and save the status, so that concurrent updates can't
+	// save a status computed from a stale copy.
+	updating    sync.Mutex
 	store       BeaconDB
 	genesisSync bool
+	genesisRoot [32]byte
 	bs          *dbval.BackfillStatus
+	advances    advanceRing
+	target      primitives.Slot
+	// bytesPerSlot is the average size of the blocks and blobs for a slot, used by RemainingBytesEstimate.
+	bytesPerSlot uint64
+	// pending is a status that could not be saved, and is being saved again by retryPending. Updates build on the
+	// pending status, while bs only changes once a status has been saved. It is guarded by the updating lock.
+	pending   *dbval.BackfillStatus
+	retryWake chan struct{}
+	retryOnce sync.Once
+	// nearFrontier is true while backfill is within an epoch of its target, see SubscribeNearlyComplete.
+	nearFrontier bool
+	frontierFeed event.Feed
+	// advanced is closed each time the status advances, see advanceSignal.
+	advanced chan struct{}
+	// pruneGuard guards the range of slots that backfill is working on, and the range that blobs are being pruned from.
+	pruneGuard sync.Mutex
+	// activeLow is the lowest slot of the batches that backfill is downloading or importing, when hasActive is set.
+	// Both are guarded by pruneGuard.
+	activeLow primitives.Slot
+	hasActive bool
+	// pruning is closed once the blobs before pruningBefore have been pruned, and is nil when no prune is underway.
+	// Both are guarded by pruneGuard.
+	pruning       chan struct{}
+	pruningBefore primitives.Slot
+	// wall is the source of the time of status advances and of the delay between save retries. The backfill service
+	// replaces it with its own clock.
+	wall prysmTime.Clock
+}
+
+// Advance records the lowest backfilled slot after a batch was imported, and the time of the import.
+type Advance struct {
+	Slot primitives.Slot
+	Time time.Time
+}
+
+// recentAdvanceLimit is the number of Advance values kept in memory by the Store,
+// enough to cover well over an hour of backfill progress at typical batch sizes.
+const recentAdvanceLimit = 256
+
+// advanceRing is a fixed size ring buffer of the most recent Advance values.
+type advanceRing struct {
+	buf  []Advance
+	next int
+}
+
+func (r *advanceRing) add(a Advance) {
+	if len(r.buf) < recentAdvanceLimit {
+		r.buf = append(r.buf, a)
+		return
+	}
+	r.buf[r.next] = a
+	r.next = (r.next + 1) % recentAdvanceLimit
+}
+
+// ordered returns a copy of the buffer contents, oldest first.
+func (r *advanceRing) ordered() []Advance {
+	o := make([]Advance, 0, len(r.buf))
+	o = append(o, r.buf[r.next:]...)
+	return append(o, r.buf[:r.next]...)
 }
 
 // AvailableBlock determines if the given slot is covered by the current chain history.
@@ -58,24 +165,409 @@ func (s *Store) AvailableBlock(sl primitives.Slot) bool {
 	return false
 }
 
+// AssertCovered returns an error wrapping ErrSlotNotBackfilled if the block at the given slot is not covered by the
+// current chain history, see AvailableBlock. The error describes the gap in history that backfill has yet to fill,
+// so that API handlers can pass it on to clients instead of constructing their own.
+func (s *Store) AssertCovered(sl primitives.Slot) error {
+	s.RLock()
+	defer s.RUnlock()
+	if s.genesisSync || sl == 0 || s.bs.LowSlot <= uint64(sl) {
+		return nil
+	}
+	return errors.Wrapf(ErrSlotNotBackfilled, "slot=%d, missing slots=[1, %d], origin slot=%d", sl, s.bs.LowSlot-1, s.bs.OriginSlot)
+}
+
+// BlobSlotCovered determines if the blobs for the given slot are covered by the current chain history. Blobs have a
+// shorter retention period than blocks, so backfill may have imported a block without its blobs. This only reports
+// on the backfilled range; callers still need to apply the blob retention window, since blobs are pruned.
+func (s *Store) BlobSlotCovered(sl primitives.Slot) bool {
+	s.RLock()
+	defer s.RUnlock()
+	if s.genesisSync || blobLowSlot(s.bs) <= uint64(sl) {
+		return true
+	}
+	return false
+}
+
+// SlotsBeforeOrigin reports whether the given slot is before the checkpoint sync origin, ie whether its history
+// is only available once backfill has reached it. It is always false for a node that was synced from genesis.
+func (s *Store) SlotsBeforeOrigin(sl primitives.Slot) bool {
+	s.RLock()
+	defer s.RUnlock()
+	if s.genesisSync || s.bs == nil {
+		return false
+	}
+	return uint64(sl) < s.bs.OriginSlot
+}
+
+// OriginOffsetSlot translates an offset counted back from the checkpoint sync origin into an absolute slot, so that
+// an offset of zero is the origin slot itself. For a node synced from genesis the origin is the genesis slot, so
+// only an offset of zero is valid. The result can be passed to AvailableBlock to learn if it has been backfilled.
+func (s *Store) OriginOffsetSlot(offset primitives.Slot) (primitives.Slot, error) {
+	s.RLock()
+	defer s.RUnlock()
+	var origin primitives.Slot
+	if !s.genesisSync && s.bs != nil {
+		origin = primitives.Slot(s.bs.OriginSlot)
+	}
+	if offset > origin {
+		return 0, errors.Wrapf(ErrOriginOffsetUnderflow, "offset=%d, origin slot=%d", offset, origin)
+	}
+	return origin - offset, nil
+}
+
+// blobLowSlot returns the lowest slot that blobs have been backfilled to. Statuses written before the blob low slot
+// was tracked separately leave it unset; they were only advanced once blobs were available, so low_slot applies.
+func blobLowSlot(bs *dbval.BackfillStatus) uint64 {
+	if bs.BlobLowSlot == 0 {
+		return bs.LowSlot
+	}
+	return bs.BlobLowSlot
+}
+
+// fillBlobBack lowers the blob low slot to the given slot, if it is lower than the current value, and persists the
+// updated status. It is meant for backfilling blobs separately from their blocks, so the blob low slot can
+// not be moved below the block low slot.
+func (s *Store) fillBlobBack(ctx context.Context, sl primitives.Slot) error {
+	s.updating.Lock()
+	defer s.updating.Unlock()
+	status := s.latestStatus()
+	if uint64(sl) < status.LowSlot {
+		return errors.Wrapf(errBlobsBelowBlocks, "blob slot=%d, block low slot=%d", sl, status.LowSlot)
+	}
+	if uint64(sl) >= blobLowSlot(status) {
+		return nil
+	}
+	status.BlobLowSlot = uint64(sl)
+	return s.saveStatus(ctx, status)
+}
+
+// raiseBlobLowSlot moves the blob low slot up to the given slot and persists the updated status, so that
+// BlobSlotCovered reports the blobs before the slot as unavailable. It is used ahead of pruning those blobs.
+// The blob low slot is never lowered, and the status of a node synced from genesis is not changed.
+func (s *Store) raiseBlobLowSlot(ctx context.Context, sl primitives.Slot) error {
+	s.updating.Lock()
+	defer s.updating.Unlock()
+	s.RLock()
+	skip := s.genesisSync || s.bs == nil
+	s.RUnlock()
+	if skip {
+		return nil
+	}
+	status := s.latestStatus()
+	if uint64(sl) <= blobLowSlot(status) {
+		return nil
+	}
+	status.BlobLowSlot = uint64(sl)
+	return s.saveStatus(ctx, status)
+}
+
+// setActiveLow records the lowest slot of the batches that backfill is downloading or importing, so that the blob
+// pruner does not delete blobs at or after that slot. If a prune that is underway is deleting blobs after the slot, it
+// waits for the prune to finish, so that backfill doesn't write blobs that are about to be deleted.
+func (s *Store) setActiveLow(sl primitives.Slot) {
+	for {
+		s.pruneGuard.Lock()
+		if s.pruning == nil || sl >= s.pruningBefore {
+			s.activeLow, s.hasActive = sl, true
+			s.pruneGuard.Unlock()
+			return
+		}
+		done := s.pruning
+		s.pruneGuard.Unlock()
+		<-done
+	}
+}
+
+// clearActiveLow records that backfill is no longer writing blobs, eg because it has stopped or is complete.
+func (s *Store) clearActiveLow() {
+	s.pruneGuard.Lock()
+	defer s.pruneGuard.Unlock()
+	s.activeLow, s.hasActive = 0, false
+}
+
+// holdPrune lowers the given prune slot to the lowest slot that backfill is working on, so that blobs that backfill
+// is still writing are never deleted, and records that blobs before the returned slot are being pruned. Until the
+// returned func is called, once pruning is complete, setActiveLow waits before moving the range of backfill below the
+// prune slot. Moves that stay above it, like the usual scheduling of batches, go ahead without waiting for the prune.
+func (s *Store) holdPrune(before primitives.Slot) (primitives.Slot, func()) {
+	s.pruneGuard.Lock()
+	defer s.pruneGuard.Unlock()
+	if s.hasActive && s.activeLow < before {
+		before = s.activeLow
+	}
+	done := make(chan struct{})
+	s.pruning, s.pruningBefore = done, before
+	return before, func() {
+		s.pruneGuard.Lock()
+		defer s.pruneGuard.Unlock()
+		s.pruning, s.pruningBefore = nil, 0
+		close(done)
+	}
+}
+
+// RecentAdvances returns the most recent backfill progress updates, oldest first. These are only held in memory
+// and can be used to reconstruct a timeline of backfill progress, eg to compute backfill velocity.
+func (s *Store) RecentAdvances() []Advance {
+	s.RLock()
+	defer s.RUnlock()
+	return s.advances.ordered()
+}
+
+func (s *Store) recordAdvance(sl primitives.Slot, t time.Time) {
+	s.Lock()
+	defer s.Unlock()
+	s.advances.add(Advance{Slot: sl, Time: t})
+	if s.advanced != nil {
+		close(s.advanced)
+		s.advanced = nil
+	}
+}
+
+// advanceSignal returns a channel that is closed the next time the status advances. Callers should check the status
+// after taking the channel, so that an advance between checking and waiting is not missed.
+func (s *Store) advanceSignal() <-chan struct{} {
+	s.Lock()
+	defer s.Unlock()
+	if s.advanced == nil {
+		s.advanced = make(chan struct{})
+	}
+	return s.advanced
+}
+
+// BoundaryRoots returns the roots of the blocks at either end of the backfilled range of history:
+// the lowest block that has been backfilled, and the checkpoint sync origin block.
+// If the node was synced from genesis, lowRoot is the genesis block root (if it was known when the Store was
+// initialized) and highRoot is the zero value, because the Store does not track the head of the chain.
+func (s *Store) BoundaryRoots() (lowRoot, highRoot [32]byte) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.genesisSync {
+		return s.genesisRoot, [32]byte{}
+	}
+	return bytesutil.ToBytes32(s.bs.LowRoot), bytesutil.ToBytes32(s.bs.OriginRoot)
+}
+
+// Progress summarizes the state of backfill, for reporting it to other parts of the node and via apis.
+type Progress struct {
+	GenesisSync bool
+	LowSlot     primitives.Slot
+	LowRoot     [32]byte
+	OriginSlot  primitives.Slot
+	OriginRoot  [32]byte
+	// TargetSlot is the oldest slot that backfill will download. It is zero until the backfill service
+	// has computed it, in which case progress is measured against genesis.
+	TargetSlot primitives.Slot
+}
+
+// PercentComplete returns the percentage of the range from TargetSlot to OriginSlot that has been backfilled.
+func (p Progress) PercentComplete() float64 {
+	if p.GenesisSync || p.LowSlot <= p.TargetSlot || p.OriginSlot <= p.TargetSlot {
+		return 100
+	}
+	return 100 * float64(p.OriginSlot-p.LowSlot) / float64(p.OriginSlot-p.TargetSlot)
+}
+
+// EpochSummary describes the current backfill status in epochs, which are easier to reason about than slots. Each
+// epoch is the one containing the corresponding slot, so a bound in the middle of an epoch reports that epoch:
+//   - lowEpoch contains the lowest backfilled block.
+//   - highEpoch contains the highest slot covered by backfill, the slot before the checkpoint sync origin.
+//   - originEpoch contains the checkpoint sync origin.
+//
+// complete reports whether backfill has reached its target slot. A node synced from genesis reports zero epochs and
+// complete, since it does not need backfill.
+func (s *Store) EpochSummary() (lowEpoch, highEpoch, originEpoch primitives.Epoch, complete bool) {
+	s.RLock()
+	defer s.RUnlock()
+	if s.genesisSync {
+		return 0, 0, 0, true
+	}
+	if s.bs == nil {
+		return 0, 0, 0, false
+	}
+	lowEpoch = slots.ToEpoch(primitives.Slot(s.bs.LowSlot))
+	originEpoch = slots.ToEpoch(primitives.Slot(s.bs.OriginSlot))
+	if s.bs.OriginSlot > 0 {
+		highEpoch = slots.ToEpoch(primitives.Slot(s.bs.OriginSlot - 1))
+	}
+	return lowEpoch, highEpoch, originEpoch, s.mode() == backfillModeCheckpointComplete
+}
+
+// EarliestCoveredEpoch returns the first epoch for which the blocks of every slot are covered by the current chain
+// history, and every later epoch is covered as well. This is the epoch of the backfill low slot if the low slot is
+// the first slot of that epoch, otherwise it is the following epoch. A node synced from genesis covers every epoch.
+func (s *Store) EarliestCoveredEpoch() primitives.Epoch {
+	s.RLock()
+	defer s.RUnlock()
+	if s.genesisSync || s.bs == nil {
+		return 0
+	}
+	low := primitives.Slot(s.bs.LowSlot)
+	floor := slots.ToEpoch(low)
+	start, err := slots.EpochStart(floor)
+	if err == nil && low == start {
+		return floor
+	}
+	return floor + 1
+}
+
+// Progress returns a summary of the current backfill status.
+func (s *Store) Progress() Progress {
+	s.RLock()
+	defer s.RUnlock()
+	if s.genesisSync || s.bs == nil {
+		return Progress{GenesisSync: s.genesisSync}
+	}
+	return Progress{
+		LowSlot:    primitives.Slot(s.bs.LowSlot),
+		LowRoot:    bytesutil.ToBytes32(s.bs.LowRoot),
+		OriginSlot: primitives.Slot(s.bs.OriginSlot),
+		OriginRoot: bytesutil.ToBytes32(s.bs.OriginRoot),
+		TargetSlot: s.target,
+	}
+}
+
+// defaultBytesPerSlotEstimate is a rough average of the size of a block and its blobs for a slot on mainnet,
+// used by RemainingBytesEstimate when the average has not been configured.
+const defaultBytesPerSlotEstimate = 512 * 1024
+
+// RemainingBytesEstimate estimates how many bytes of blocks and blobs backfill still needs to download. It is only
+// an estimate: the number of slots left to backfill is multiplied by an average size per slot, which can be set with
+// WithBytesPerSlotEstimate, while the real size of each slot depends on whether it is empty and on the blob count.
+// It is 0 if backfill is complete, or the node was synced from genesis.
+func (s *Store) RemainingBytesEstimate() uint64 {
+	p := s.Progress()
+	if p.GenesisSync || p.LowSlot <= p.TargetSlot {
+		return 0
+	}
+	s.RLock()
+	bps := s.bytesPerSlot
+	s.RUnlock()
+	if bps == 0 {
+		bps = defaultBytesPerSlotEstimate
+	}
+	return uint64(p.LowSlot-p.TargetSlot) * bps
+}
+
+func (s *Store) setBytesPerSlot(n uint64) {
+	s.Lock()
+	defer s.Unlock()
+	s.bytesPerSlot = n
+}
+
+func (s *Store) setTarget(sl primitives.Slot) {
+	s.Lock()
+	s.target = sl
+	s.updateModeMetric()
+	s.Unlock()
+	s.checkFrontier()
+}
+
 // Status is a threadsafe method to access a copy of the BackfillStatus value.
 func (s *Store) status() *dbval.BackfillStatus {
 	s.RLock()
 	defer s.RUnlock()
+	return copyStatus(s.bs)
+}
+
+// latestStatus returns a copy of the newest status, which is the pending status if one is waiting to be saved.
+// It must be called with the updating lock held.
+func (s *Store) latestStatus() *dbval.BackfillStatus {
+	if s.pending != nil {
+		return copyStatus(s.pending)
+	}
+	return s.status()
+}
+
+// ExportState returns a copy of the backfill status, for backing up the progress of backfill or cloning it onto another
+// node, see ImportState. The copy does not share any memory with the status of the Store. A node synced from genesis
+// has no backfill status, and nil is returned.
+func (s *Store) ExportState() *dbval.BackfillStatus {
+	s.RLock()
+	defer s.RUnlock()
+	if s.bs == nil {
+		return nil
+	}
+	return cloneStatus(s.bs)
+}
+
+// ImportState replaces the backfill status with one exported by ExportState, typically from a node whose block db was
+// copied to this node. The status is only saved if it describes this db: it must keep the checkpoint sync origin, its
+// bounds must not cross, and the lowest backfilled block it names must be in the db with the given slot and parent.
+func (s *Store) ImportState(ctx context.Context, bs *dbval.BackfillStatus) error {
+	defer s.checkFrontier()
+	s.updating.Lock()
+	defer s.updating.Unlock()
+	s.RLock()
+	skip := s.genesisSync || s.bs == nil
+	s.RUnlock()
+	if skip {
+		return errNoBackfillStatus
+	}
+	if bs == nil {
+		return errors.Wrap(errImportedStatusInvalid, "status is nil")
+	}
+	if len(bs.LowRoot) != 32 || len(bs.LowParentRoot) != 32 || len(bs.OriginRoot) != 32 {
+		return errors.Wrap(errImportedStatusInvalid, "status roots must be 32 bytes")
+	}
+	if err := checkStatusBounds(bs); err != nil {
+		return err
+	}
+	lowRoot := bytesutil.ToBytes32(bs.LowRoot)
+	low, err := s.store.Block(ctx, lowRoot)
+	if err != nil {
+		return errors.Wrapf(err, "could not read lowest backfilled block, root=%#x", lowRoot)
+	}
+	if err := blocks.BeaconBlockIsNil(low); err != nil {
+		return errors.Wrapf(err, "nil block found for lowest backfilled block, root=%#x", lowRoot)
+	}
+	if uint64(low.Block().Slot()) != bs.LowSlot {
+		return errors.Wrapf(errImportedStatusInvalid, "low slot=%d, slot of block %#x=%d", bs.LowSlot, lowRoot, low.Block().Slot())
+	}
+	lpr := low.Block().ParentRoot()
+	if !bytes.Equal(lpr[:], bs.LowParentRoot) {
+		return errors.Wrapf(errImportedStatusInvalid, "low parent root=%#x, parent root of block %#x=%#x", bs.LowParentRoot, lowRoot, lpr)
+	}
+	// The caller keeps its copy, so the saved status must not share the roots with it.
+	imported := cloneStatus(bs)
+	if err := s.saveStatus(ctx, imported); err != nil {
+		return err
+	}
+	statusLogFields(imported).Info("Imported backfill status")
+	return nil
+}
+
+// cloneStatus is like copyStatus, but also copies the roots, for statuses that are handed to or received from callers
+// outside the package.
+func cloneStatus(bs *dbval.BackfillStatus) *dbval.BackfillStatus {
+	c := copyStatus(bs)
+	c.LowRoot = bytesutil.SafeCopyBytes(bs.LowRoot)
+	c.LowParentRoot = bytesutil.SafeCopyBytes(bs.LowParentRoot)
+	c.OriginRoot = bytesutil.SafeCopyBytes(bs.OriginRoot)
+	return c
+}
+
+func copyStatus(bs *dbval.BackfillStatus) *dbval.BackfillStatus {
 	return &dbval.BackfillStatus{
-		LowSlot:       s.bs.LowSlot,
-		LowRoot:       s.bs.LowRoot,
-		LowParentRoot: s.bs.LowParentRoot,
-		OriginSlot:    s.bs.OriginSlot,
-		OriginRoot:    s.bs.OriginRoot,
+		LowSlot:       bs.LowSlot,
+		LowRoot:       bs.LowRoot,
+		LowParentRoot: bs.LowParentRoot,
+		OriginSlot:    bs.OriginSlot,
+		OriginRoot:    bs.OriginRoot,
+		BlobLowSlot:   bs.BlobLowSlot,
 	}
 }
 
 // fillBack saves the slice of blocks and updates the BackfillStatus LowSlot/Root/ParentRoot tracker to the values
 // from the first block in the slice. This method assumes that the block slice has been fully validated and
-// sorted in slot order by the calling function.
+// sorted in slot order by the calling function. If store is nil, the blobs for the blocks were not backfilled, so
+// their availability is not checked and the blob low slot is not moved.
 func (s *Store) fillBack(ctx context.Context, current primitives.Slot, blocks []blocks.ROBlock, store das.AvailabilityStore) (*dbval.BackfillStatus, error) {
-	status := s.status()
+	// Deferred first, so that it runs after the updating lock is released.
+	defer s.checkFrontier()
+	s.updating.Lock()
+	defer s.updating.Unlock()
+	status := s.latestStatus()
 	if len(blocks) == 0 {
 		return status, nil
 	}
@@ -88,9 +580,17 @@ func (s *Store) fillBack(ctx context.Context, current primitives.Slot, blocks []
 			status.LowParentRoot, highest.Root(), status.LowSlot, highest.Block().Slot())
 	}
 
-	for i := range blocks {
-		if err := store.IsDataAvailable(ctx, current, blocks[i]); err != nil {
-			return nil, err
+	// Blocks and blobs are backfilled in lockstep: the status is only advanced once the blobs for every block in the
+	// batch are verified and stored, so that AvailableBlock never reports a slot as covered when its blobs are missing.
+	// Blobs live in blob storage on the filesystem, so they can't be saved in the same db transaction as the blocks.
+	// Saving the blobs first means a crash part way through the import can only leave blobs without their block, which
+	// are saved again when the batch is retried, and are otherwise removed by the blob pruner.
+	if store != nil {
+		for i := range blocks {
+			if err := store.IsDataAvailable(ctx, current, blocks[i]); err != nil {
+				backfillBlobCoverageMismatch.Inc()
+				return nil, err
+			}
 		}
 	}
 
@@ -107,10 +607,28 @@ func (s *Store) fillBack(ctx context.Context, current primitives.Slot, blocks []
 	// Update backfill status based on the block with the lowest slot in the batch.
 	lowest := blocks[0]
 	pr := lowest.Block().ParentRoot()
+	// The availability store only requires blobs within the retention window, so the blob low slot stops advancing
+	// once the batch is older than the start of the window.
+	bls := blobLowSlot(status)
 	status.LowSlot = uint64(lowest.Block().Slot())
 	status.LowRoot = lowest.RootSlice()
 	status.LowParentRoot = pr[:]
-	return status, s.saveStatus(ctx, status)
+	blobStart, err := prysmsync.BlobRPCMinValidSlot(current)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not compute minimum blob retention slot")
+	}
+	if blobStart < lowest.Block().Slot() {
+		blobStart = lowest.Block().Slot()
+	}
+	if store != nil && uint64(blobStart) < bls {
+		bls = uint64(blobStart)
+	}
+	status.BlobLowSlot = bls
+	if err := s.commitStatus(ctx, status); err != nil {
+		return nil, err
+	}
+	s.recordAdvance(lowest.Block().Slot(), s.wall.Now())
+	return status, nil
 }
 
 // recoverLegacy will check to see if the db is from a legacy checkpoint sync, and either build a new BackfillStatus
@@ -119,6 +637,16 @@ func (s *Store) recoverLegacy(ctx context.Context) error {
 	cpr, err := s.store.OriginCheckpointBlockRoot(ctx)
 	if errors.Is(err, db.ErrNotFoundOriginBlockRoot) {
 		s.genesisSync = true
+		s.updateModeMetric()
+		gr, err := s.store.GenesisBlockRoot(ctx)
+		if err != nil {
+			// The genesis root is only used for informational purposes, so the node can proceed without it.
+			log.WithError(err).Debug("Could not look up genesis block root for node synced from genesis")
+		} else {
+			s.genesisRoot = gr
+		}
+		log.WithField("genesisRoot", fmt.Sprintf("%#x", s.genesisRoot)).
+			Info("No origin checkpoint found in db, node was synced from genesis and does not need backfill")
 		return nil
 	}
 
@@ -129,6 +657,14 @@ func (s *Store) recoverLegacy(ctx context.Context) error {
 	if err := blocks.BeaconBlockIsNil(cpb); err != nil {
 		return errors.Wrapf(err, "nil block found for origin checkpoint root=%#x", cpr)
 	}
+	// A wrong origin block would give backfill a wrong upper bound, so make sure the db is consistent before using it.
+	htr, err := cpb.Block().HashTreeRoot()
+	if err != nil {
+		return errors.Wrapf(err, "error computing root of block for origin checkpoint root=%#x", cpr)
+	}
+	if htr != cpr {
+		return errors.Wrapf(errOriginRootMismatch, "origin checkpoint root=%#x, block root=%#x", cpr, htr)
+	}
 	os := uint64(cpb.Block().Slot())
 	lpr := cpb.Block().ParentRoot()
 	bs := &dbval.BackfillStatus{
@@ -138,22 +674,162 @@ func (s *Store) recoverLegacy(ctx context.Context) error {
 		OriginSlot:    os,
 		OriginRoot:    cpr[:],
 	}
-	return s.saveStatus(ctx, bs)
+	if err := s.saveStatus(ctx, bs); err != nil {
+		return err
+	}
+	statusLogFields(bs).Info("Legacy checkpoint sync db detected with no backfill status, saved a new status starting from the origin checkpoint")
+	return nil
+}
+
+// flush persists the current backfill status.
+func (s *Store) flush(ctx context.Context) error {
+	s.updating.Lock()
+	defer s.updating.Unlock()
+	s.RLock()
+	skip := s.genesisSync || s.bs == nil
+	s.RUnlock()
+	if skip {
+		return nil
+	}
+	return s.saveStatus(ctx, s.latestStatus())
 }
 
 func (s *Store) saveStatus(ctx context.Context, bs *dbval.BackfillStatus) error {
+	// Final guard in front of the db, regardless of which update produced the status.
+	if err := checkStatusBounds(bs); err != nil {
+		return err
+	}
+	s.RLock()
+	current := s.bs
+	s.RUnlock()
+	if err := checkOriginUnchanged(current, bs); err != nil {
+		return err
+	}
 	if err := s.store.SaveBackfillStatus(ctx, bs); err != nil {
 		return err
 	}
 
+	s.pending = nil
 	s.swapStatus(bs)
 	return nil
 }
 
+// statusRetryInterval is the time between attempts to save a pending status.
+var statusRetryInterval = 5 * time.Second
+
+// commitStatus saves the status like saveStatus. If the save fails, the status is queued to be saved by retryPending
+// and nil is returned, so that batches can still be imported on top of it while the db recovers. Readers of the
+// status only see it once it has been saved. It must be called with the updating lock held.
+func (s *Store) commitStatus(ctx context.Context, bs *dbval.BackfillStatus) error {
+	err := s.saveStatus(ctx, bs)
+	if err == nil || errors.Is(err, ErrBackfillBoundsCrossed) || errors.Is(err, ErrOriginChanged) {
+		return err
+	}
+	backfillStatusSaveFailures.Inc()
+	statusLogFields(bs).WithError(err).Warn("Could not save backfill status, retrying in the background")
+	s.pending = bs
+	select {
+	case s.retryWake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// startRetry starts the loop that saves pending statuses, if it isn't running yet.
+func (s *Store) startRetry(ctx context.Context) {
+	s.retryOnce.Do(func() {
+		go s.retryPending(ctx)
+	})
+}
+
+// retryPending saves the pending status each time one is queued by commitStatus, retrying every statusRetryInterval
+// until the save succeeds or the context is canceled.
+func (s *Store) retryPending(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.retryWake:
+		}
+		for !s.savePending(ctx) {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.wall.After(statusRetryInterval):
+			}
+		}
+	}
+}
+
+// savePending attempts to save the pending status. It returns false if the save failed and should be retried.
+func (s *Store) savePending(ctx context.Context) bool {
+	defer s.checkFrontier()
+	s.updating.Lock()
+	defer s.updating.Unlock()
+	if s.pending == nil {
+		return true
+	}
+	bs := s.pending
+	if err := s.saveStatus(ctx, bs); err != nil {
+		backfillStatusSaveFailures.Inc()
+		log.WithError(err).Debug("Retry of backfill status save failed")
+		return false
+	}
+	statusLogFields(bs).Info("Saved backfill status after retrying")
+	return true
+}
+
+func checkStatusBounds(bs *dbval.BackfillStatus) error {
+	if bs.LowSlot > bs.OriginSlot {
+		return errors.Wrapf(ErrBackfillBoundsCrossed, "low slot=%d, origin slot=%d", bs.LowSlot, bs.OriginSlot)
+	}
+	if bs.BlobLowSlot != 0 && bs.BlobLowSlot < bs.LowSlot {
+		return errors.Wrapf(ErrBackfillBoundsCrossed, "blob low slot=%d, low slot=%d", bs.BlobLowSlot, bs.LowSlot)
+	}
+	return nil
+}
+
+// checkOriginUnchanged ensures that an update to the backfill status keeps the origin of the current status. There is
+// no current status when a new status is created from the origin checkpoint, see recoverLegacy.
+func checkOriginUnchanged(current, next *dbval.BackfillStatus) error {
+	if current == nil {
+		return nil
+	}
+	if current.OriginSlot != next.OriginSlot || !bytes.Equal(current.OriginRoot, next.OriginRoot) {
+		return errors.Wrapf(ErrOriginChanged, "origin slot=%d, root=%#x, update slot=%d, root=%#x",
+			current.OriginSlot, current.OriginRoot, next.OriginSlot, next.OriginRoot)
+	}
+	return nil
+}
+
 func (s *Store) swapStatus(bs *dbval.BackfillStatus) {
 	s.Lock()
 	defer s.Unlock()
 	s.bs = bs
+	s.updateModeMetric()
+}
+
+// Values of the backfill_mode gauge, which tells operators how the node was synced.
+const (
+	backfillModeGenesis               = 0
+	backfillModeCheckpointComplete    = 1
+	backfillModeCheckpointBackfilling = 2
+)
+
+// mode returns the value of the backfill_mode gauge. Backfill is complete once the lowest backfilled block is at or
+// below the target slot. It must be called while holding the lock.
+func (s *Store) mode() int {
+	if s.genesisSync {
+		return backfillModeGenesis
+	}
+	if s.bs != nil && primitives.Slot(s.bs.LowSlot) <= s.target {
+		return backfillModeCheckpointComplete
+	}
+	return backfillModeCheckpointBackfilling
+}
+
+func (s *Store) updateModeMetric() {
+	backfillMode.Set(float64(s.mode()))
 }
 
 func (s *Store) isGenesisSync() bool {
@@ -175,7 +851,9 @@ type BeaconDB interface {
 	BackfillStatus(context.Context) (*dbval.BackfillStatus, error)
 	BackfillFinalizedIndex(ctx context.Context, blocks []blocks.ROBlock, finalizedChildRoot [32]byte) error
 	OriginCheckpointBlockRoot(context.Context) ([32]byte, error)
+	GenesisBlockRoot(context.Context) ([32]byte, error)
 	Block(context.Context, [32]byte) (interfaces.ReadOnlySignedBeaconBlock, error)
+	BlockRootsBySlot(ctx context.Context, slot primitives.Slot) (bool, [][32]byte, error)
 	SaveROBlocks(ctx context.Context, blks []blocks.ROBlock, cache bool) error
 	StateOrError(ctx context.Context, blockRoot [32]byte) (state.BeaconState, error)
 }
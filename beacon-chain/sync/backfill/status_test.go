@@ -3,12 +3,16 @@ package backfill
 import (
 	"bytes"
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/das"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/state"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
 	blocktest "github.com/prysmaticlabs/prysm/v5/consensus-types/blocks/testing"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/interfaces"
@@ -16,6 +20,8 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
 	"github.com/prysmaticlabs/prysm/v5/testing/require"
 	"github.com/prysmaticlabs/prysm/v5/testing/util"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+	logTest "github.com/sirupsen/logrus/hooks/test"
 )
 
 var errEmptyMockDBMethod = errors.New("uninitialized mock db method called")
@@ -23,9 +29,12 @@ var errEmptyMockDBMethod = errors.New("uninitialized mock db method called")
 type mockBackfillDB struct {
 	saveBackfillBlockRoot     func(ctx context.Context, blockRoot [32]byte) error
 	originCheckpointBlockRoot func(ctx context.Context) ([32]byte, error)
+	genesisBlockRoot          func(ctx context.Context) ([32]byte, error)
 	block                     func(ctx context.Context, blockRoot [32]byte) (interfaces.ReadOnlySignedBeaconBlock, error)
+	blockRootsBySlot          func(ctx context.Context, slot primitives.Slot) (bool, [][32]byte, error)
 	saveBackfillStatus        func(ctx context.Context, status *dbval.BackfillStatus) error
 	backfillStatus            func(context.Context) (*dbval.BackfillStatus, error)
+	saveROBlocks              func(ctx context.Context, blks []blocks.ROBlock) error
 	status                    *dbval.BackfillStatus
 	err                       error
 	states                    map[[32]byte]state.BeaconState
@@ -64,6 +73,13 @@ func (d *mockBackfillDB) OriginCheckpointBlockRoot(ctx context.Context) ([32]byt
 	return [32]byte{}, errEmptyMockDBMethod
 }
 
+func (d *mockBackfillDB) GenesisBlockRoot(ctx context.Context) ([32]byte, error) {
+	if d.genesisBlockRoot != nil {
+		return d.genesisBlockRoot(ctx)
+	}
+	return [32]byte{}, errEmptyMockDBMethod
+}
+
 func (d *mockBackfillDB) Block(ctx context.Context, blockRoot [32]byte) (interfaces.ReadOnlySignedBeaconBlock, error) {
 	if d.block != nil {
 		return d.block(ctx, blockRoot)
@@ -75,7 +91,25 @@ func (d *mockBackfillDB) Block(ctx context.Context, blockRoot [32]byte) (interfa
 	return b, nil
 }
 
+func (d *mockBackfillDB) BlockRootsBySlot(ctx context.Context, slot primitives.Slot) (bool, [][32]byte, error) {
+	if d.blockRootsBySlot != nil {
+		return d.blockRootsBySlot(ctx, slot)
+	}
+	roots := make([][32]byte, 0)
+	for r, b := range d.blocks {
+		if b.Block().Slot() == slot {
+			roots = append(roots, r)
+		}
+	}
+	return len(roots) > 0, roots, nil
+}
+
 func (d *mockBackfillDB) SaveROBlocks(ctx context.Context, blks []blocks.ROBlock, cache bool) error {
+	if d.saveROBlocks != nil {
+		if err := d.saveROBlocks(ctx, blks); err != nil {
+			return err
+		}
+	}
 	if d.blocks == nil {
 		d.blocks = make(map[[32]byte]blocks.ROBlock)
 	}
@@ -129,6 +163,19 @@ func TestSlotCovered(t *testing.T) {
 	}
 }
 
+func TestAssertCovered(t *testing.T) {
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 50, OriginSlot: 100}}
+	require.NoError(t, s.AssertCovered(0))
+	require.NoError(t, s.AssertCovered(50))
+	require.NoError(t, s.AssertCovered(150))
+	err := s.AssertCovered(49)
+	require.ErrorIs(t, err, ErrSlotNotBackfilled)
+	require.ErrorContains(t, "slot=49, missing slots=[1, 49], origin slot=100", err)
+
+	s = &Store{genesisSync: true}
+	require.NoError(t, s.AssertCovered(1))
+}
+
 func TestStatusUpdater_FillBack(t *testing.T) {
 	ctx := context.Background()
 	mdb := &mockBackfillDB{}
@@ -136,11 +183,632 @@ func TestStatusUpdater_FillBack(t *testing.T) {
 	require.NoError(t, err)
 	rob, err := blocks.NewROBlock(b)
 	require.NoError(t, err)
-	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 100, LowParentRoot: rob.RootSlice()}, store: mdb}
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 100, OriginSlot: 100, LowParentRoot: rob.RootSlice()}, store: mdb, wall: prysmTime.RealClock{}}
 	require.Equal(t, false, s.AvailableBlock(95))
 	_, err = s.fillBack(ctx, 0, []blocks.ROBlock{rob}, &das.MockAvailabilityStore{})
 	require.NoError(t, err)
 	require.Equal(t, true, s.AvailableBlock(95))
+	adv := s.RecentAdvances()
+	require.Equal(t, 1, len(adv))
+	require.Equal(t, primitives.Slot(90), adv[0].Slot)
+}
+
+func TestStatusUpdater_FillBackMissingBlobs(t *testing.T) {
+	ctx := context.Background()
+	mdb := &mockBackfillDB{}
+	b, err := setupTestBlock(90)
+	require.NoError(t, err)
+	rob, err := blocks.NewROBlock(b)
+	require.NoError(t, err)
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 100, OriginSlot: 100, LowParentRoot: rob.RootSlice()}, store: mdb, wall: prysmTime.RealClock{}}
+	errMissing := errors.New("missing blobs")
+	as := &das.MockAvailabilityStore{
+		VerifyAvailabilityCallback: func(context.Context, primitives.Slot, blocks.ROBlock) error {
+			return errMissing
+		},
+	}
+	mismatches := testutil.ToFloat64(backfillBlobCoverageMismatch)
+	_, err = s.fillBack(ctx, 0, []blocks.ROBlock{rob}, as)
+	require.ErrorIs(t, err, errMissing)
+	require.Equal(t, mismatches+1, testutil.ToFloat64(backfillBlobCoverageMismatch))
+	// Neither the blocks nor the status should be saved.
+	require.Equal(t, 0, len(mdb.blocks))
+	require.Equal(t, false, s.AvailableBlock(95))
+	require.Equal(t, 0, len(s.RecentAdvances()))
+}
+
+// TestStatusUpdater_FillBackCrashBetweenSaves simulates the node crashing after the blobs for a batch are saved, but
+// before the blocks are saved. The status must not advance over blocks whose blobs are missing, and importing the
+// batch again must succeed.
+func TestStatusUpdater_FillBackCrashBetweenSaves(t *testing.T) {
+	ctx := context.Background()
+	errCrash := errors.New("crash")
+	mdb := &mockBackfillDB{saveROBlocks: func(context.Context, []blocks.ROBlock) error {
+		return errCrash
+	}}
+	b, err := setupTestBlock(90)
+	require.NoError(t, err)
+	rob, err := blocks.NewROBlock(b)
+	require.NoError(t, err)
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 100, OriginSlot: 100, LowParentRoot: rob.RootSlice()}, store: mdb, wall: prysmTime.RealClock{}}
+	var blobsSaved [][32]byte
+	as := &das.MockAvailabilityStore{
+		VerifyAvailabilityCallback: func(_ context.Context, _ primitives.Slot, b blocks.ROBlock) error {
+			// The blobs of every block in the batch are saved before any of the blocks.
+			require.Equal(t, 0, len(mdb.blocks))
+			blobsSaved = append(blobsSaved, b.Root())
+			return nil
+		},
+	}
+	_, err = s.fillBack(ctx, 0, []blocks.ROBlock{rob}, as)
+	require.ErrorIs(t, err, errCrash)
+	require.Equal(t, 1, len(blobsSaved))
+	// The blobs are stored without their block, but the status does not cover the block.
+	require.Equal(t, 0, len(mdb.blocks))
+	require.Equal(t, false, s.AvailableBlock(95))
+	require.Equal(t, uint64(100), s.status().LowSlot)
+
+	// After the restart, the batch is imported again.
+	mdb.saveROBlocks = nil
+	_, err = s.fillBack(ctx, 0, []blocks.ROBlock{rob}, as)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(blobsSaved))
+	require.Equal(t, 1, len(mdb.blocks))
+	require.Equal(t, true, s.AvailableBlock(95))
+}
+
+func TestSaveStatusBoundsCrossed(t *testing.T) {
+	cases := []struct {
+		name string
+		bs   *dbval.BackfillStatus
+		err  error
+	}{
+		{name: "low below origin", bs: &dbval.BackfillStatus{LowSlot: 10, OriginSlot: 20}},
+		{name: "low equal origin", bs: &dbval.BackfillStatus{LowSlot: 20, OriginSlot: 20}},
+		{name: "low above origin", bs: &dbval.BackfillStatus{LowSlot: 21, OriginSlot: 20}, err: ErrBackfillBoundsCrossed},
+		{name: "blob low above low", bs: &dbval.BackfillStatus{LowSlot: 10, BlobLowSlot: 15, OriginSlot: 20}},
+		{name: "blob low below low", bs: &dbval.BackfillStatus{LowSlot: 10, BlobLowSlot: 5, OriginSlot: 20}, err: ErrBackfillBoundsCrossed},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mdb := &mockBackfillDB{}
+			s := &Store{store: mdb}
+			err := s.saveStatus(context.Background(), c.bs)
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+				require.IsNil(t, mdb.status)
+				require.IsNil(t, s.bs)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.bs, mdb.status)
+		})
+	}
+}
+
+func TestSaveStatusOriginChanged(t *testing.T) {
+	origin := [32]byte{0x01}
+	current := &dbval.BackfillStatus{LowSlot: 100, OriginSlot: 200, OriginRoot: origin[:]}
+	other := [32]byte{0x02}
+	cases := []struct {
+		name string
+		bs   *dbval.BackfillStatus
+		err  error
+	}{
+		{name: "origin kept", bs: &dbval.BackfillStatus{LowSlot: 90, OriginSlot: 200, OriginRoot: origin[:]}},
+		{name: "origin slot changed", bs: &dbval.BackfillStatus{LowSlot: 90, OriginSlot: 300, OriginRoot: origin[:]}, err: ErrOriginChanged},
+		{name: "origin root changed", bs: &dbval.BackfillStatus{LowSlot: 90, OriginSlot: 200, OriginRoot: other[:]}, err: ErrOriginChanged},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mdb := &mockBackfillDB{}
+			s := &Store{store: mdb, bs: current}
+			err := s.commitStatus(context.Background(), c.bs)
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+				require.IsNil(t, mdb.status)
+				require.IsNil(t, s.pending)
+				require.Equal(t, current, s.bs)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.bs, mdb.status)
+		})
+	}
+}
+
+func TestExportImportState(t *testing.T) {
+	ctx := context.Background()
+	b, err := setupTestBlock(90)
+	require.NoError(t, err)
+	low, err := blocks.NewROBlock(b)
+	require.NoError(t, err)
+	lpr := low.Block().ParentRoot()
+	origin := [32]byte{0x01}
+	mdb := &mockBackfillDB{blocks: map[[32]byte]blocks.ROBlock{low.Root(): low}}
+	s := &Store{store: mdb, bs: &dbval.BackfillStatus{LowSlot: 100, LowRoot: make([]byte, 32), LowParentRoot: make([]byte, 32), OriginSlot: 200, OriginRoot: origin[:]}}
+
+	exported := s.ExportState()
+	require.DeepEqual(t, s.bs, exported)
+	// The export is a defensive copy.
+	exported.OriginRoot[0] = 0xff
+	require.Equal(t, byte(0x01), s.bs.OriginRoot[0])
+
+	valid := func() *dbval.BackfillStatus {
+		return &dbval.BackfillStatus{LowSlot: 90, LowRoot: low.RootSlice(), LowParentRoot: lpr[:], OriginSlot: 200, OriginRoot: origin[:]}
+	}
+	other := [32]byte{0x02}
+	cases := []struct {
+		name   string
+		modify func(*dbval.BackfillStatus)
+		err    error
+	}{
+		{name: "short root", modify: func(bs *dbval.BackfillStatus) { bs.LowRoot = bs.LowRoot[:8] }, err: errImportedStatusInvalid},
+		{name: "bounds crossed", modify: func(bs *dbval.BackfillStatus) { bs.BlobLowSlot = 80 }, err: ErrBackfillBoundsCrossed},
+		{name: "unknown low block", modify: func(bs *dbval.BackfillStatus) { bs.LowRoot = other[:] }, err: db.ErrNotFound},
+		{name: "wrong low slot", modify: func(bs *dbval.BackfillStatus) { bs.LowSlot = 91 }, err: errImportedStatusInvalid},
+		{name: "wrong low parent", modify: func(bs *dbval.BackfillStatus) { bs.LowParentRoot = other[:] }, err: errImportedStatusInvalid},
+		{name: "origin changed", modify: func(bs *dbval.BackfillStatus) { bs.OriginRoot = other[:] }, err: ErrOriginChanged},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			bs := valid()
+			c.modify(bs)
+			require.ErrorIs(t, s.ImportState(ctx, bs), c.err)
+			require.IsNil(t, mdb.status)
+		})
+	}
+	require.ErrorIs(t, s.ImportState(ctx, nil), errImportedStatusInvalid)
+
+	bs := valid()
+	require.NoError(t, s.ImportState(ctx, bs))
+	require.DeepEqual(t, bs, mdb.status)
+	require.Equal(t, true, s.AvailableBlock(90))
+	require.Equal(t, false, s.AvailableBlock(89))
+
+	gs := &Store{store: mdb, genesisSync: true}
+	require.IsNil(t, gs.ExportState())
+	require.ErrorIs(t, gs.ImportState(ctx, valid()), errNoBackfillStatus)
+}
+
+func TestStatusUpdater_ConcurrentFill(t *testing.T) {
+	ctx := context.Background()
+	n := 200
+	// Build a chain of blocks at slots 1..n, where each block is the parent of the next.
+	chain := make([]blocks.ROBlock, n+1)
+	var parent [32]byte
+	for i := 1; i <= n; i++ {
+		bRaw := util.NewBeaconBlock()
+		bRaw.Block.Slot = primitives.Slot(i)
+		bRaw.Block.ParentRoot = parent[:]
+		b, err := blocks.NewSignedBeaconBlock(bRaw)
+		require.NoError(t, err)
+		rob, err := blocks.NewROBlock(b)
+		require.NoError(t, err)
+		chain[i] = rob
+		parent = rob.Root()
+	}
+	origin := uint64(n + 1)
+	lastLow := origin
+	mdb := &mockBackfillDB{}
+	mdb.saveBackfillStatus = func(_ context.Context, bs *dbval.BackfillStatus) error {
+		// Saves are serialized, and the low slot must never move back up.
+		if bs.LowSlot > lastLow {
+			return errors.Errorf("low slot moved from %d to %d", lastLow, bs.LowSlot)
+		}
+		lastLow = bs.LowSlot
+		mdb.status = bs
+		return nil
+	}
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: origin, OriginSlot: origin, LowParentRoot: parent[:]}, store: mdb, wall: prysmTime.RealClock{}}
+
+	done := make(chan struct{})
+	blobErrs := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-done:
+				close(blobErrs)
+				return
+			default:
+			}
+			// The low slot only decreases, so a slot read before the update is never below the blocks.
+			if err := s.fillBlobBack(ctx, primitives.Slot(s.status().LowSlot)); err != nil {
+				blobErrs <- err
+				return
+			}
+		}
+	}()
+	for i := n; i >= 1; i-- {
+		_, err := s.fillBack(ctx, 0, []blocks.ROBlock{chain[i]}, &das.MockAvailabilityStore{})
+		require.NoError(t, err)
+	}
+	close(done)
+	require.NoError(t, <-blobErrs)
+	require.Equal(t, uint64(1), s.status().LowSlot)
+	require.NoError(t, checkStatusBounds(s.status()))
+}
+
+func TestStatusUpdater_RetryFailedSave(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer func(d time.Duration) { statusRetryInterval = d }(statusRetryInterval)
+	statusRetryInterval = time.Millisecond
+
+	// Build a chain of blocks at slots 1 and 2.
+	chain := make([]blocks.ROBlock, 3)
+	var parent [32]byte
+	for i := 1; i <= 2; i++ {
+		bRaw := util.NewBeaconBlock()
+		bRaw.Block.Slot = primitives.Slot(i)
+		bRaw.Block.ParentRoot = parent[:]
+		b, err := blocks.NewSignedBeaconBlock(bRaw)
+		require.NoError(t, err)
+		rob, err := blocks.NewROBlock(b)
+		require.NoError(t, err)
+		chain[i] = rob
+		parent = rob.Root()
+	}
+	var mu sync.Mutex
+	failing := true
+	errSave := errors.New("transient db error")
+	mdb := &mockBackfillDB{}
+	mdb.saveBackfillStatus = func(_ context.Context, bs *dbval.BackfillStatus) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if failing {
+			return errSave
+		}
+		mdb.status = bs
+		return nil
+	}
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 3, OriginSlot: 3, LowParentRoot: parent[:]}, store: mdb, retryWake: make(chan struct{}, 1), wall: prysmTime.RealClock{}}
+
+	failures := testutil.ToFloat64(backfillStatusSaveFailures)
+	_, err := s.fillBack(ctx, 0, []blocks.ROBlock{chain[2]}, &das.MockAvailabilityStore{})
+	require.NoError(t, err)
+	// The status is only visible once it is saved.
+	require.Equal(t, uint64(3), s.status().LowSlot)
+	require.Equal(t, false, s.AvailableBlock(2))
+	// The next batch builds on the pending status.
+	_, err = s.fillBack(ctx, 0, []blocks.ROBlock{chain[1]}, &das.MockAvailabilityStore{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), s.status().LowSlot)
+	require.Equal(t, failures+2, testutil.ToFloat64(backfillStatusSaveFailures))
+
+	s.startRetry(ctx)
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.status().LowSlot != 1 {
+		require.Equal(t, true, time.Now().Before(deadline), "pending status was not saved")
+		time.Sleep(time.Millisecond)
+	}
+	require.Equal(t, true, s.AvailableBlock(1))
+	mu.Lock()
+	require.Equal(t, uint64(1), mdb.status.LowSlot)
+	mu.Unlock()
+}
+
+func TestBlobSlotCovered(t *testing.T) {
+	cases := []struct {
+		name   string
+		slot   primitives.Slot
+		status *Store
+		result bool
+	}{
+		{
+			name:   "legacy status uses low slot",
+			status: &Store{bs: &dbval.BackfillStatus{LowSlot: 10}},
+			slot:   10,
+			result: true,
+		},
+		{
+			name:   "legacy status below low slot",
+			status: &Store{bs: &dbval.BackfillStatus{LowSlot: 10}},
+			slot:   9,
+			result: false,
+		},
+		{
+			name:   "block covered, blob not covered",
+			status: &Store{bs: &dbval.BackfillStatus{LowSlot: 10, BlobLowSlot: 20}},
+			slot:   15,
+			result: false,
+		},
+		{
+			name:   "equal blob low slot",
+			status: &Store{bs: &dbval.BackfillStatus{LowSlot: 10, BlobLowSlot: 20}},
+			slot:   20,
+			result: true,
+		},
+		{
+			name:   "genesisSync always true",
+			status: &Store{genesisSync: true},
+			slot:   100,
+			result: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.result, c.status.BlobSlotCovered(c.slot))
+		})
+	}
+}
+
+func TestSlotsBeforeOrigin(t *testing.T) {
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 50, OriginSlot: 100}}
+	require.Equal(t, true, s.SlotsBeforeOrigin(0))
+	require.Equal(t, true, s.SlotsBeforeOrigin(99))
+	require.Equal(t, false, s.SlotsBeforeOrigin(100))
+	require.Equal(t, false, s.SlotsBeforeOrigin(101))
+
+	s = &Store{genesisSync: true}
+	require.Equal(t, false, s.SlotsBeforeOrigin(0))
+	require.Equal(t, false, s.SlotsBeforeOrigin(100))
+}
+
+func TestOriginOffsetSlot(t *testing.T) {
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 50, OriginSlot: 100}}
+	cases := []struct {
+		name   string
+		offset primitives.Slot
+		slot   primitives.Slot
+		err    error
+	}{
+		{name: "origin", offset: 0, slot: 100},
+		{name: "below origin", offset: 30, slot: 70},
+		{name: "genesis", offset: 100, slot: 0},
+		{name: "before genesis", offset: 101, err: ErrOriginOffsetUnderflow},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sl, err := s.OriginOffsetSlot(c.offset)
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.slot, sl)
+		})
+	}
+
+	// An offset into the backfilled range translates to an available slot, and past it to one still to backfill.
+	sl, err := s.OriginOffsetSlot(50)
+	require.NoError(t, err)
+	require.Equal(t, true, s.AvailableBlock(sl))
+	sl, err = s.OriginOffsetSlot(51)
+	require.NoError(t, err)
+	require.Equal(t, false, s.AvailableBlock(sl))
+
+	g := &Store{genesisSync: true}
+	sl, err = g.OriginOffsetSlot(0)
+	require.NoError(t, err)
+	require.Equal(t, primitives.Slot(0), sl)
+	_, err = g.OriginOffsetSlot(1)
+	require.ErrorIs(t, err, ErrOriginOffsetUnderflow)
+}
+
+func TestStatusUpdater_FillBackBlobLowSlot(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.DenebForkEpoch = 0
+	cfg.MinEpochsForBlobsSidecarsRequest = 1
+	params.OverrideBeaconConfig(cfg)
+	spe := params.BeaconConfig().SlotsPerEpoch
+
+	ctx := context.Background()
+	mdb := &mockBackfillDB{}
+	b, err := setupTestBlock(90)
+	require.NoError(t, err)
+	rob, err := blocks.NewROBlock(b)
+	require.NoError(t, err)
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 100, OriginSlot: 100, LowParentRoot: rob.RootSlice()}, store: mdb, wall: prysmTime.RealClock{}}
+	// The start of the blob retention window is after the batch, so blobs are only covered from the window start.
+	current := 10 * spe
+	_, err = s.fillBack(ctx, current, []blocks.ROBlock{rob}, &das.MockAvailabilityStore{})
+	require.NoError(t, err)
+	require.Equal(t, true, s.AvailableBlock(90))
+	require.Equal(t, false, s.BlobSlotCovered(95))
+	require.Equal(t, true, s.BlobSlotCovered(100))
+	saved, err := mdb.BackfillStatus(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), saved.BlobLowSlot)
+
+	// Blobs can be filled back separately, but not below the blocks.
+	require.ErrorIs(t, s.fillBlobBack(ctx, 89), errBlobsBelowBlocks)
+	require.NoError(t, s.fillBlobBack(ctx, 95))
+	require.Equal(t, true, s.BlobSlotCovered(95))
+	require.Equal(t, false, s.BlobSlotCovered(94))
+	// Filling blobs forward of the current blob low slot is a no-op.
+	require.NoError(t, s.fillBlobBack(ctx, 99))
+	require.Equal(t, true, s.BlobSlotCovered(95))
+
+	// When the batch is within the retention window, the blob low slot follows the block low slot.
+	b, err = setupTestBlock(80)
+	require.NoError(t, err)
+	rob, err = blocks.NewROBlock(b)
+	require.NoError(t, err)
+	s.bs.LowParentRoot = rob.RootSlice()
+	_, err = s.fillBack(ctx, 3*spe, []blocks.ROBlock{rob}, &das.MockAvailabilityStore{})
+	require.NoError(t, err)
+	require.Equal(t, true, s.BlobSlotCovered(80))
+	require.Equal(t, false, s.BlobSlotCovered(79))
+}
+
+func TestStatusUpdater_FillBackSkipBlobs(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.DenebForkEpoch = 0
+	cfg.MinEpochsForBlobsSidecarsRequest = 1
+	params.OverrideBeaconConfig(cfg)
+	spe := params.BeaconConfig().SlotsPerEpoch
+
+	ctx := context.Background()
+	mdb := &mockBackfillDB{}
+	b, err := setupTestBlock(90)
+	require.NoError(t, err)
+	rob, err := blocks.NewROBlock(b)
+	require.NoError(t, err)
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 100, OriginSlot: 100, LowParentRoot: rob.RootSlice()}, store: mdb, wall: prysmTime.RealClock{}}
+	// The batch is within the blob retention window, but without an availability store the blobs were not
+	// backfilled, so the blob low slot stays at the previous block low slot.
+	_, err = s.fillBack(ctx, 3*spe, []blocks.ROBlock{rob}, nil)
+	require.NoError(t, err)
+	require.Equal(t, true, s.AvailableBlock(90))
+	require.Equal(t, false, s.BlobSlotCovered(90))
+	require.Equal(t, false, s.BlobSlotCovered(99))
+	require.Equal(t, true, s.BlobSlotCovered(100))
+	saved, err := mdb.BackfillStatus(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(90), saved.LowSlot)
+	require.Equal(t, uint64(100), saved.BlobLowSlot)
+}
+
+func TestAdvanceRing(t *testing.T) {
+	r := &advanceRing{}
+	require.Equal(t, 0, len(r.ordered()))
+	total := recentAdvanceLimit + 10
+	for i := 0; i < total; i++ {
+		r.add(Advance{Slot: primitives.Slot(i)})
+	}
+	o := r.ordered()
+	require.Equal(t, recentAdvanceLimit, len(o))
+	// The oldest values should have been overwritten, and the rest returned in insertion order.
+	for i := range o {
+		require.Equal(t, primitives.Slot(total-recentAdvanceLimit+i), o[i].Slot)
+	}
+}
+
+func TestBoundaryRoots(t *testing.T) {
+	low, origin := [32]byte{0x01}, [32]byte{0x02}
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 10, LowRoot: low[:], OriginSlot: 20, OriginRoot: origin[:]}}
+	lr, hr := s.BoundaryRoots()
+	require.Equal(t, low, lr)
+	require.Equal(t, origin, hr)
+
+	genesis := [32]byte{0x03}
+	mdb := &mockBackfillDB{
+		backfillStatus: func(context.Context) (*dbval.BackfillStatus, error) {
+			return nil, db.ErrNotFound
+		},
+		originCheckpointBlockRoot: func(ctx context.Context) ([32]byte, error) {
+			return [32]byte{}, db.ErrNotFoundOriginBlockRoot
+		},
+		genesisBlockRoot: goodBlockRoot(genesis),
+	}
+	s, err := NewUpdater(context.Background(), mdb)
+	require.NoError(t, err)
+	lr, hr = s.BoundaryRoots()
+	require.Equal(t, genesis, lr)
+	require.Equal(t, [32]byte{}, hr)
+}
+
+func TestBackfillModeMetric(t *testing.T) {
+	mdb := &mockBackfillDB{
+		backfillStatus: func(context.Context) (*dbval.BackfillStatus, error) {
+			return nil, db.ErrNotFound
+		},
+		originCheckpointBlockRoot: func(ctx context.Context) ([32]byte, error) {
+			return [32]byte{}, db.ErrNotFoundOriginBlockRoot
+		},
+		genesisBlockRoot: goodBlockRoot([32]byte{0x01}),
+	}
+	_, err := NewUpdater(context.Background(), mdb)
+	require.NoError(t, err)
+	require.Equal(t, float64(backfillModeGenesis), testutil.ToFloat64(backfillMode))
+
+	s := &Store{}
+	s.swapStatus(&dbval.BackfillStatus{LowSlot: 100, OriginSlot: 200})
+	require.Equal(t, float64(backfillModeCheckpointBackfilling), testutil.ToFloat64(backfillMode))
+	s.setTarget(90)
+	require.Equal(t, float64(backfillModeCheckpointBackfilling), testutil.ToFloat64(backfillMode))
+	// Backfill completes when it reaches the target, or when the target moves up to the lowest backfilled block.
+	s.swapStatus(&dbval.BackfillStatus{LowSlot: 90, OriginSlot: 200})
+	require.Equal(t, float64(backfillModeCheckpointComplete), testutil.ToFloat64(backfillMode))
+	s.swapStatus(&dbval.BackfillStatus{LowSlot: 100, OriginSlot: 200})
+	s.setTarget(100)
+	require.Equal(t, float64(backfillModeCheckpointComplete), testutil.ToFloat64(backfillMode))
+}
+
+func TestEpochSummary(t *testing.T) {
+	spe := params.BeaconConfig().SlotsPerEpoch
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: uint64(10*spe + 5), OriginSlot: uint64(20 * spe)}, target: 2 * spe}
+	low, high, origin, complete := s.EpochSummary()
+	require.Equal(t, primitives.Epoch(10), low)
+	// The origin is the first slot of its epoch, so the backfilled range ends in the epoch before it.
+	require.Equal(t, primitives.Epoch(19), high)
+	require.Equal(t, primitives.Epoch(20), origin)
+	require.Equal(t, false, complete)
+
+	s.bs = &dbval.BackfillStatus{LowSlot: uint64(2*spe - 1), OriginSlot: uint64(20*spe + 3)}
+	low, high, origin, complete = s.EpochSummary()
+	require.Equal(t, primitives.Epoch(1), low)
+	require.Equal(t, primitives.Epoch(20), high)
+	require.Equal(t, primitives.Epoch(20), origin)
+	require.Equal(t, true, complete)
+
+	g := &Store{genesisSync: true}
+	low, high, origin, complete = g.EpochSummary()
+	require.Equal(t, primitives.Epoch(0), low)
+	require.Equal(t, primitives.Epoch(0), high)
+	require.Equal(t, primitives.Epoch(0), origin)
+	require.Equal(t, true, complete)
+}
+
+func TestEarliestCoveredEpoch(t *testing.T) {
+	spe := params.BeaconConfig().SlotsPerEpoch
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: uint64(10*spe + 5), OriginSlot: uint64(20 * spe)}}
+	// The low slot is in the middle of epoch 10, so the first slots of that epoch are missing.
+	require.Equal(t, primitives.Epoch(11), s.EarliestCoveredEpoch())
+
+	s.bs = &dbval.BackfillStatus{LowSlot: uint64(10 * spe), OriginSlot: uint64(20 * spe)}
+	require.Equal(t, primitives.Epoch(10), s.EarliestCoveredEpoch())
+
+	// The genesis block is always available, but the rest of epoch 0 is missing.
+	s.bs = &dbval.BackfillStatus{LowSlot: 5, OriginSlot: uint64(20 * spe)}
+	require.Equal(t, primitives.Epoch(1), s.EarliestCoveredEpoch())
+
+	s.bs = &dbval.BackfillStatus{LowSlot: 0, OriginSlot: uint64(20 * spe)}
+	require.Equal(t, primitives.Epoch(0), s.EarliestCoveredEpoch())
+
+	g := &Store{genesisSync: true}
+	require.Equal(t, primitives.Epoch(0), g.EarliestCoveredEpoch())
+}
+
+func TestProgress(t *testing.T) {
+	low, origin := [32]byte{0x01}, [32]byte{0x02}
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 150, LowRoot: low[:], OriginSlot: 200, OriginRoot: origin[:]}}
+	p := s.Progress()
+	require.Equal(t, false, p.GenesisSync)
+	require.Equal(t, primitives.Slot(150), p.LowSlot)
+	require.Equal(t, low, p.LowRoot)
+	require.Equal(t, primitives.Slot(200), p.OriginSlot)
+	require.Equal(t, origin, p.OriginRoot)
+	// Without a target, progress is measured against genesis.
+	require.Equal(t, float64(25), p.PercentComplete())
+
+	s.setTarget(100)
+	require.Equal(t, float64(50), s.Progress().PercentComplete())
+	s.setTarget(150)
+	require.Equal(t, float64(100), s.Progress().PercentComplete())
+
+	s = &Store{genesisSync: true}
+	require.Equal(t, true, s.Progress().GenesisSync)
+	require.Equal(t, float64(100), s.Progress().PercentComplete())
+}
+
+func TestRemainingBytesEstimate(t *testing.T) {
+	s := &Store{bs: &dbval.BackfillStatus{LowSlot: 150, OriginSlot: 200}}
+	s.setTarget(100)
+	require.Equal(t, uint64(50*defaultBytesPerSlotEstimate), s.RemainingBytesEstimate())
+	s.setBytesPerSlot(1000)
+	require.Equal(t, uint64(50_000), s.RemainingBytesEstimate())
+	s.setTarget(150)
+	require.Equal(t, uint64(0), s.RemainingBytesEstimate())
+
+	s = &Store{genesisSync: true}
+	require.Equal(t, uint64(0), s.RemainingBytesEstimate())
 }
 
 func goodBlockRoot(root [32]byte) func(ctx context.Context) ([32]byte, error) {
@@ -162,10 +830,10 @@ func TestNewUpdater(t *testing.T) {
 	ctx := context.Background()
 
 	originSlot := primitives.Slot(100)
-	var originRoot [32]byte
-	copy(originRoot[:], []byte{0x01})
 	originBlock, err := setupTestBlock(originSlot)
 	require.NoError(t, err)
+	originRoot, err := originBlock.Block().HashTreeRoot()
+	require.NoError(t, err)
 
 	backfillSlot := primitives.Slot(50)
 	var backfillRoot [32]byte
@@ -187,6 +855,7 @@ func TestNewUpdater(t *testing.T) {
 		db       BeaconDB
 		err      error
 		expected *Store
+		logMsg   string
 	}{
 		{
 			name: "origin not found, implying genesis sync ",
@@ -198,6 +867,7 @@ func TestNewUpdater(t *testing.T) {
 					return [32]byte{}, db.ErrNotFoundOriginBlockRoot
 				}},
 			expected: &Store{genesisSync: true},
+			logMsg:   "node was synced from genesis",
 		},
 		{
 			name: "legacy recovery",
@@ -218,6 +888,19 @@ func TestNewUpdater(t *testing.T) {
 				LowSlot: uint64(originSlot), OriginSlot: uint64(originSlot),
 				LowRoot: originRoot[:], OriginRoot: originRoot[:], LowParentRoot: rootSlice(originBlock.Block().ParentRoot()),
 			}},
+			logMsg: "Legacy checkpoint sync db detected",
+		},
+		{
+			name: "legacy recovery, origin block root mismatch",
+			db: &mockBackfillDB{
+				originCheckpointBlockRoot: goodBlockRoot(originRoot),
+				block: func(ctx context.Context, root [32]byte) (interfaces.ReadOnlySignedBeaconBlock, error) {
+					// The db returns the wrong block for the origin root.
+					return backfillBlock, nil
+				},
+				backfillStatus: func(context.Context) (*dbval.BackfillStatus, error) { return nil, db.ErrNotFound },
+			},
+			err: errOriginRootMismatch,
 		},
 		{
 			name: "backfill found",
@@ -225,17 +908,20 @@ func TestNewUpdater(t *testing.T) {
 				return typicalBackfillStatus, nil
 			}},
 			expected: &Store{bs: typicalBackfillStatus},
+			logMsg:   "Loaded existing backfill status from db",
 		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
+			hook := logTest.NewGlobal()
 			s, err := NewUpdater(ctx, c.db)
 			if c.err != nil {
 				require.ErrorIs(t, err, c.err)
 				return
 			}
 			require.NoError(t, err)
+			require.LogsContain(t, hook, c.logMsg)
 			if c.expected == nil {
 				return
 			}
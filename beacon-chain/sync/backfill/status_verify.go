@@ -0,0 +1,200 @@
+package backfill
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
+	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+)
+
+// StatusReport describes the backfill status found in the db, and any ways it is inconsistent with the blocks
+// that are stored in the db.
+type StatusReport struct {
+	GenesisSync bool
+	// Legacy is true when the node was checkpoint synced but no backfill status has been written yet.
+	// In this case the status will be created from the origin checkpoint block the next time the node starts.
+	Legacy        bool
+	LowSlot       primitives.Slot
+	LowRoot       [32]byte
+	LowParentRoot [32]byte
+	BlobLowSlot   primitives.Slot
+	OriginSlot    primitives.Slot
+	OriginRoot    [32]byte
+	// LowBlockFound and OriginBlockFound report whether the blocks at either end of the backfilled range exist.
+	LowBlockFound    bool
+	OriginBlockFound bool
+	Violations       []string
+}
+
+// Corrupt returns true if any invariant violations were found.
+func (r *StatusReport) Corrupt() bool {
+	return len(r.Violations) > 0
+}
+
+func (r *StatusReport) violation(format string, args ...interface{}) {
+	r.Violations = append(r.Violations, fmt.Sprintf(format, args...))
+}
+
+// String formats the report for human readers.
+func (r *StatusReport) String() string {
+	b := &strings.Builder{}
+	switch {
+	case r.GenesisSync:
+		fmt.Fprintln(b, "Node was synced from genesis, backfill is not needed.")
+	case r.Legacy:
+		fmt.Fprintln(b, "No backfill status in db, it will be initialized from the origin checkpoint block.")
+		fmt.Fprintf(b, "Origin:        slot=%d root=%#x found=%t\n", r.OriginSlot, r.OriginRoot, r.OriginBlockFound)
+	default:
+		fmt.Fprintf(b, "Low block:     slot=%d root=%#x found=%t\n", r.LowSlot, r.LowRoot, r.LowBlockFound)
+		fmt.Fprintf(b, "Low parent:    root=%#x\n", r.LowParentRoot)
+		fmt.Fprintf(b, "Low blob slot: %d\n", r.BlobLowSlot)
+		fmt.Fprintf(b, "Origin:        slot=%d root=%#x found=%t\n", r.OriginSlot, r.OriginRoot, r.OriginBlockFound)
+	}
+	if !r.Corrupt() {
+		fmt.Fprintln(b, "No problems found.")
+		return b.String()
+	}
+	fmt.Fprintf(b, "Found %d problem(s):\n", len(r.Violations))
+	for _, v := range r.Violations {
+		fmt.Fprintf(b, "- %s\n", v)
+	}
+	return b.String()
+}
+
+// VerifyStatus reads the backfill status from the db and checks it against the blocks in the db, without
+// modifying the db. Unlike NewUpdater, a missing status is reported rather than recovered.
+// Errors are only returned when the db could not be read; problems with the data are reported as violations.
+func VerifyStatus(ctx context.Context, store BeaconDB) (*StatusReport, error) {
+	r := &StatusReport{}
+	bs, err := store.BackfillStatus(ctx)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, errors.Wrap(err, "db error while reading backfill status")
+	}
+	cpr, hasOrigin, err := originCheckpointRoot(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	if bs == nil {
+		if !hasOrigin {
+			r.GenesisSync = true
+			return r, nil
+		}
+		r.Legacy = true
+		r.OriginRoot = cpr
+		r.OriginSlot, r.OriginBlockFound, err = verifyBoundaryBlock(ctx, store, r, "origin", cpr, nil)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+	return verifyStatus(ctx, store, bs, cpr, hasOrigin)
+}
+
+// CheckConsistency checks the backfill status held by the Store against the blocks in the db, in the same way as
+// VerifyStatus. The status held by the Store can be ahead of the status saved in the db, eg while a save is being
+// retried, so this shows whether the status that backfill is working from has diverged from the db. It is read-only.
+func (s *Store) CheckConsistency(ctx context.Context) (*StatusReport, error) {
+	if s.isGenesisSync() {
+		return &StatusReport{GenesisSync: true}, nil
+	}
+	s.updating.Lock()
+	bs := s.latestStatus()
+	s.updating.Unlock()
+	s.RLock()
+	store := s.store
+	s.RUnlock()
+	cpr, hasOrigin, err := originCheckpointRoot(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	return verifyStatus(ctx, store, bs, cpr, hasOrigin)
+}
+
+func originCheckpointRoot(ctx context.Context, store BeaconDB) ([32]byte, bool, error) {
+	cpr, err := store.OriginCheckpointBlockRoot(ctx)
+	if errors.Is(err, db.ErrNotFoundOriginBlockRoot) {
+		return [32]byte{}, false, nil
+	}
+	if err != nil {
+		return [32]byte{}, false, errors.Wrap(err, "db error while reading origin checkpoint root")
+	}
+	return cpr, true, nil
+}
+
+// verifyStatus checks a backfill status against the origin checkpoint root and the blocks in the db.
+func verifyStatus(ctx context.Context, store BeaconDB, bs *dbval.BackfillStatus, cpr [32]byte, hasOrigin bool) (*StatusReport, error) {
+	r := &StatusReport{}
+	r.LowSlot = primitives.Slot(bs.LowSlot)
+	r.LowRoot = bytesutil.ToBytes32(bs.LowRoot)
+	r.LowParentRoot = bytesutil.ToBytes32(bs.LowParentRoot)
+	r.BlobLowSlot = primitives.Slot(blobLowSlot(bs))
+	r.OriginSlot = primitives.Slot(bs.OriginSlot)
+	r.OriginRoot = bytesutil.ToBytes32(bs.OriginRoot)
+	verifyStatusBounds(r, bs)
+	if !hasOrigin {
+		r.violation("backfill status exists, but origin checkpoint root is missing")
+	} else if cpr != r.OriginRoot {
+		r.violation("backfill status origin root %#x != origin checkpoint root %#x", r.OriginRoot, cpr)
+	}
+
+	lowParent := r.LowParentRoot
+	lowSlot, found, err := verifyBoundaryBlock(ctx, store, r, "low", r.LowRoot, &lowParent)
+	if err != nil {
+		return nil, err
+	}
+	r.LowBlockFound = found
+	if found && lowSlot != r.LowSlot {
+		r.violation("low block slot %d != backfill status low slot %d", lowSlot, r.LowSlot)
+	}
+	originSlot, found, err := verifyBoundaryBlock(ctx, store, r, "origin", r.OriginRoot, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.OriginBlockFound = found
+	if found && originSlot != r.OriginSlot {
+		r.violation("origin block slot %d != backfill status origin slot %d", originSlot, r.OriginSlot)
+	}
+	return r, nil
+}
+
+// verifyStatusBounds records violations of the invariants between the slots in the backfill status.
+func verifyStatusBounds(r *StatusReport, bs *dbval.BackfillStatus) {
+	if len(bs.LowRoot) != 32 || len(bs.LowParentRoot) != 32 || len(bs.OriginRoot) != 32 {
+		r.violation("malformed root in backfill status, lengths low=%d, low parent=%d, origin=%d",
+			len(bs.LowRoot), len(bs.LowParentRoot), len(bs.OriginRoot))
+	}
+	if bs.LowSlot > bs.OriginSlot {
+		r.violation("low slot %d > origin slot %d", bs.LowSlot, bs.OriginSlot)
+	}
+	if blobLowSlot(bs) < bs.LowSlot {
+		r.violation("blob low slot %d < low slot %d", bs.BlobLowSlot, bs.LowSlot)
+	}
+}
+
+// verifyBoundaryBlock looks up the block with the given root, recording a violation if it is missing, if it does not
+// hash to the root it is stored under, or if its parent root does not match the optional expected parent.
+func verifyBoundaryBlock(ctx context.Context, store BeaconDB, r *StatusReport, name string, root [32]byte, parent *[32]byte) (primitives.Slot, bool, error) {
+	b, err := store.Block(ctx, root)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return 0, false, errors.Wrapf(err, "db error while reading %s block root=%#x", name, root)
+	}
+	if err != nil || blocks.BeaconBlockIsNil(b) != nil {
+		r.violation("%s block root=%#x not found in db", name, root)
+		return 0, false, nil
+	}
+	if htr, err := b.Block().HashTreeRoot(); err != nil {
+		r.violation("could not compute root of %s block root=%#x: %s", name, root, err)
+	} else if htr != root {
+		r.violation("%s block stored under root=%#x has root %#x", name, root, htr)
+	}
+	if parent != nil && b.Block().ParentRoot() != *parent {
+		r.violation("%s block parent root %#x != backfill status parent root %#x", name, b.Block().ParentRoot(), *parent)
+	}
+	return b.Block().Slot(), true, nil
+}
@@ -0,0 +1,186 @@
+package backfill
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/interfaces"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/prysmaticlabs/prysm/v5/testing/util"
+)
+
+func TestVerifyStatusReport(t *testing.T) {
+	ctx := context.Background()
+	low, _ := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{0x03}, 50, 0)
+	origin, _ := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{0x04}, 100, 0)
+	lowParent := low.Block().ParentRoot()
+	lowRoot, originRoot := low.Root(), origin.Root()
+	goodStatus := func() *dbval.BackfillStatus {
+		return &dbval.BackfillStatus{
+			LowSlot:       50,
+			LowRoot:       lowRoot[:],
+			LowParentRoot: lowParent[:],
+			OriginSlot:    100,
+			OriginRoot:    originRoot[:],
+		}
+	}
+	blks := map[[32]byte]blocks.ROBlock{lowRoot: low, originRoot: origin}
+
+	cases := []struct {
+		name       string
+		db         *mockBackfillDB
+		err        error
+		violations []string
+		check      func(t *testing.T, r *StatusReport)
+	}{
+		{
+			name: "genesis sync",
+			db: &mockBackfillDB{
+				backfillStatus: func(context.Context) (*dbval.BackfillStatus, error) { return nil, db.ErrNotFound },
+				originCheckpointBlockRoot: func(context.Context) ([32]byte, error) {
+					return [32]byte{}, db.ErrNotFoundOriginBlockRoot
+				},
+			},
+			check: func(t *testing.T, r *StatusReport) {
+				require.Equal(t, true, r.GenesisSync)
+			},
+		},
+		{
+			name: "legacy",
+			db: &mockBackfillDB{
+				backfillStatus:            func(context.Context) (*dbval.BackfillStatus, error) { return nil, db.ErrNotFound },
+				originCheckpointBlockRoot: goodBlockRoot(originRoot),
+				blocks:                    blks,
+			},
+			check: func(t *testing.T, r *StatusReport) {
+				require.Equal(t, true, r.Legacy)
+				require.Equal(t, true, r.OriginBlockFound)
+				require.Equal(t, primitives.Slot(100), r.OriginSlot)
+			},
+		},
+		{
+			name: "consistent",
+			db:   &mockBackfillDB{status: goodStatus(), originCheckpointBlockRoot: goodBlockRoot(originRoot), blocks: blks},
+			check: func(t *testing.T, r *StatusReport) {
+				require.Equal(t, true, r.LowBlockFound)
+				require.Equal(t, true, r.OriginBlockFound)
+				require.Equal(t, r.LowSlot, r.BlobLowSlot)
+			},
+		},
+		{
+			name: "missing low block",
+			db: &mockBackfillDB{status: goodStatus(), originCheckpointBlockRoot: goodBlockRoot(originRoot),
+				blocks: map[[32]byte]blocks.ROBlock{originRoot: origin}},
+			violations: []string{"low block root"},
+		},
+		{
+			name: "slots out of order",
+			db: &mockBackfillDB{status: func() *dbval.BackfillStatus {
+				bs := goodStatus()
+				bs.LowSlot, bs.BlobLowSlot = 150, 120
+				return bs
+			}(), originCheckpointBlockRoot: goodBlockRoot(originRoot), blocks: blks},
+			violations: []string{"low slot 150 > origin slot 100", "blob low slot 120 < low slot 150", "low block slot 50"},
+		},
+		{
+			name: "wrong parent and origin",
+			db: &mockBackfillDB{status: func() *dbval.BackfillStatus {
+				bs := goodStatus()
+				bs.LowParentRoot = make([]byte, 32)
+				return bs
+			}(), originCheckpointBlockRoot: goodBlockRoot([32]byte{0x05}), blocks: blks},
+			violations: []string{"!= origin checkpoint root", "low block parent root"},
+		},
+		{
+			name: "block stored under wrong root",
+			db: &mockBackfillDB{status: goodStatus(), originCheckpointBlockRoot: goodBlockRoot(originRoot),
+				blocks: map[[32]byte]blocks.ROBlock{lowRoot: origin, originRoot: origin}},
+			violations: []string{"low block stored under root", "low block parent root", "low block slot 100"},
+		},
+		{
+			name: "db error",
+			db: &mockBackfillDB{status: goodStatus(), originCheckpointBlockRoot: goodBlockRoot(originRoot),
+				block: func(context.Context, [32]byte) (interfaces.ReadOnlySignedBeaconBlock, error) {
+					return nil, errEmptyMockDBMethod
+				}},
+			err: errEmptyMockDBMethod,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			saved := false
+			c.db.saveBackfillStatus = func(context.Context, *dbval.BackfillStatus) error {
+				saved = true
+				return errors.New("VerifyStatus should not write to the db")
+			}
+			r, err := VerifyStatus(ctx, c.db)
+			require.Equal(t, false, saved)
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			if c.check != nil {
+				c.check(t, r)
+			}
+			require.Equal(t, len(c.violations), len(r.Violations), strings.Join(r.Violations, "\n"))
+			for i := range c.violations {
+				require.StringContains(t, c.violations[i], r.Violations[i])
+			}
+			require.Equal(t, len(c.violations) > 0, r.Corrupt())
+			if r.Corrupt() {
+				require.StringContains(t, "problem(s)", r.String())
+			} else {
+				require.StringContains(t, "No problems found", r.String())
+			}
+		})
+	}
+}
+
+func TestStoreCheckConsistency(t *testing.T) {
+	ctx := context.Background()
+	low, _ := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{0x03}, 50, 0)
+	origin, _ := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{0x04}, 100, 0)
+	lowParent := low.Block().ParentRoot()
+	lowRoot, originRoot := low.Root(), origin.Root()
+	mdb := &mockBackfillDB{
+		originCheckpointBlockRoot: goodBlockRoot(originRoot),
+		blocks:                    map[[32]byte]blocks.ROBlock{lowRoot: low, originRoot: origin},
+	}
+	s := &Store{store: mdb, bs: &dbval.BackfillStatus{
+		LowSlot:       50,
+		LowRoot:       lowRoot[:],
+		LowParentRoot: lowParent[:],
+		OriginSlot:    100,
+		OriginRoot:    originRoot[:],
+	}}
+	r, err := s.CheckConsistency(ctx)
+	require.NoError(t, err)
+	require.Equal(t, false, r.Corrupt(), strings.Join(r.Violations, "\n"))
+	require.Equal(t, primitives.Slot(50), r.LowSlot)
+
+	// A pending status is checked in place of the current status, because it is the one backfill works from.
+	missing := [32]byte{0x06}
+	s.pending = &dbval.BackfillStatus{
+		LowSlot:       40,
+		LowRoot:       missing[:],
+		LowParentRoot: lowParent[:],
+		OriginSlot:    100,
+		OriginRoot:    originRoot[:],
+	}
+	r, err = s.CheckConsistency(ctx)
+	require.NoError(t, err)
+	require.Equal(t, primitives.Slot(40), r.LowSlot)
+	require.Equal(t, 1, len(r.Violations), strings.Join(r.Violations, "\n"))
+	require.StringContains(t, "low block root", r.Violations[0])
+
+	r, err = (&Store{genesisSync: true}).CheckConsistency(ctx)
+	require.NoError(t, err)
+	require.Equal(t, true, r.GenesisSync)
+}
@@ -0,0 +1,88 @@
+package backfill
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db"
+	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
+	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+)
+
+// ErrInconsistentStore is returned by SwapStore when the backfill status in the new db can't be reconciled with
+// the status held by the Store.
+var ErrInconsistentStore = errors.New("backfill status in new db is inconsistent with the current status")
+
+// SwapStore replaces the db used by the Store, eg to migrate to a different storage backend while the node is
+// running. The backfill status is read from the new db and reconciled with the current status before the swap:
+//   - Both statuses must describe the same checkpoint sync origin.
+//   - The status with the lowest backfilled block is kept, because backfill only moves down. If that is the current
+//     status, it is saved to the new db, so a new db that lags behind the old one catches up.
+//   - The lowest backfilled block of the kept status must be in the new db.
+//
+// If the statuses can't be reconciled, an error wrapping ErrInconsistentStore is returned and the Store keeps using
+// the old db. A status waiting to be saved by retryPending is reconciled in place of the current status.
+func (s *Store) SwapStore(ctx context.Context, store BeaconDB) error {
+	defer s.checkFrontier()
+	s.updating.Lock()
+	defer s.updating.Unlock()
+	next, err := store.BackfillStatus(ctx)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return errors.Wrap(err, "db error while reading backfill status from new db")
+	}
+	if s.isGenesisSync() {
+		if next != nil {
+			return errors.Wrap(ErrInconsistentStore, "node was synced from genesis, but new db has a backfill status")
+		}
+		s.setStore(store)
+		return nil
+	}
+	current := s.latestStatus()
+	if next == nil {
+		return errors.Wrap(ErrInconsistentStore, "new db does not have a backfill status")
+	}
+	keep, err := reconcileStatus(current, next)
+	if err != nil {
+		return err
+	}
+	if _, err := store.Block(ctx, bytesutil.ToBytes32(keep.LowRoot)); err != nil {
+		return errors.Wrapf(ErrInconsistentStore, "lowest backfilled block root=%#x not found in new db: %s", keep.LowRoot, err)
+	}
+	if keep == current {
+		if err := store.SaveBackfillStatus(ctx, keep); err != nil {
+			return errors.Wrap(err, "could not save backfill status to new db")
+		}
+	}
+	s.setStore(store)
+	s.pending = nil
+	s.swapStatus(keep)
+	statusLogFields(keep).Info("Swapped backfill db")
+	return nil
+}
+
+// reconcileStatus returns the status that should be used after swapping to a db with the next status, which is
+// whichever of the two statuses has backfilled further, as long as they agree on the origin and the bounds are valid.
+func reconcileStatus(current, next *dbval.BackfillStatus) (*dbval.BackfillStatus, error) {
+	if err := checkStatusBounds(next); err != nil {
+		return nil, errors.Wrap(ErrInconsistentStore, err.Error())
+	}
+	if next.OriginSlot != current.OriginSlot || !bytes.Equal(next.OriginRoot, current.OriginRoot) {
+		return nil, errors.Wrapf(ErrInconsistentStore, "origin slot=%d root=%#x, new db origin slot=%d root=%#x",
+			current.OriginSlot, current.OriginRoot, next.OriginSlot, next.OriginRoot)
+	}
+	if next.LowSlot == current.LowSlot && !bytes.Equal(next.LowRoot, current.LowRoot) {
+		return nil, errors.Wrapf(ErrInconsistentStore, "low slot=%d has root=%#x, new db root=%#x",
+			current.LowSlot, current.LowRoot, next.LowRoot)
+	}
+	if next.LowSlot < current.LowSlot {
+		return next, nil
+	}
+	return current, nil
+}
+
+func (s *Store) setStore(store BeaconDB) {
+	s.Lock()
+	defer s.Unlock()
+	s.store = store
+}
@@ -0,0 +1,98 @@
+package backfill
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/proto/dbval"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestSwapStore(t *testing.T) {
+	ctx := context.Background()
+	low, err := setupTestBlock(50)
+	require.NoError(t, err)
+	lowBlock, err := blocks.NewROBlock(low)
+	require.NoError(t, err)
+	lower, err := setupTestBlock(40)
+	require.NoError(t, err)
+	lowerBlock, err := blocks.NewROBlock(lower)
+	require.NoError(t, err)
+	origin := []byte{0xaa}
+	status := func(b blocks.ROBlock) *dbval.BackfillStatus {
+		return &dbval.BackfillStatus{
+			LowSlot:    uint64(b.Block().Slot()),
+			LowRoot:    b.RootSlice(),
+			OriginSlot: 100,
+			OriginRoot: origin,
+		}
+	}
+	newDB := func(bs *dbval.BackfillStatus, blks ...blocks.ROBlock) *mockBackfillDB {
+		mdb := &mockBackfillDB{status: bs}
+		require.NoError(t, mdb.SaveROBlocks(ctx, blks, false))
+		return mdb
+	}
+
+	t.Run("new db lags behind", func(t *testing.T) {
+		old := newDB(status(lowBlock), lowBlock)
+		s := &Store{store: old, bs: status(lowBlock)}
+		next := newDB(&dbval.BackfillStatus{LowSlot: 100, LowRoot: origin, OriginSlot: 100, OriginRoot: origin}, lowBlock)
+		require.NoError(t, s.SwapStore(ctx, next))
+		// The current status is kept and saved to the new db.
+		require.Equal(t, uint64(50), s.status().LowSlot)
+		require.Equal(t, uint64(50), next.status.LowSlot)
+		require.Equal(t, BeaconDB(next), s.store)
+	})
+	t.Run("new db is ahead", func(t *testing.T) {
+		s := &Store{store: newDB(status(lowBlock), lowBlock), bs: status(lowBlock)}
+		next := newDB(status(lowerBlock), lowBlock, lowerBlock)
+		require.NoError(t, s.SwapStore(ctx, next))
+		require.Equal(t, uint64(40), s.status().LowSlot)
+	})
+	t.Run("pending status is reconciled", func(t *testing.T) {
+		s := &Store{store: newDB(status(lowBlock), lowBlock), bs: status(lowBlock), pending: status(lowerBlock)}
+		next := newDB(status(lowBlock), lowBlock, lowerBlock)
+		require.NoError(t, s.SwapStore(ctx, next))
+		require.Equal(t, uint64(40), s.status().LowSlot)
+		require.Equal(t, uint64(40), next.status.LowSlot)
+		require.IsNil(t, s.pending)
+	})
+	t.Run("inconsistent", func(t *testing.T) {
+		otherOrigin := status(lowBlock)
+		otherOrigin.OriginRoot = []byte{0xbb}
+		otherLowRoot := status(lowBlock)
+		otherLowRoot.LowRoot = lowerBlock.RootSlice()
+		crossed := status(lowBlock)
+		crossed.BlobLowSlot = 10
+		cases := []struct {
+			name string
+			next *mockBackfillDB
+		}{
+			{name: "no status", next: newDB(nil, lowBlock)},
+			{name: "different origin", next: newDB(otherOrigin, lowBlock)},
+			{name: "different root for low slot", next: newDB(otherLowRoot, lowBlock, lowerBlock)},
+			{name: "bounds crossed", next: newDB(crossed, lowBlock)},
+			{name: "low block missing", next: newDB(status(lowerBlock), lowBlock)},
+		}
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				old := newDB(status(lowBlock), lowBlock)
+				s := &Store{store: old, bs: status(lowBlock)}
+				require.ErrorIs(t, s.SwapStore(ctx, c.next), ErrInconsistentStore)
+				require.Equal(t, BeaconDB(old), s.store)
+				require.Equal(t, uint64(50), s.status().LowSlot)
+			})
+		}
+	})
+	t.Run("genesis sync", func(t *testing.T) {
+		s := &Store{genesisSync: true, store: &mockBackfillDB{}}
+		next := &mockBackfillDB{backfillStatus: func(context.Context) (*dbval.BackfillStatus, error) {
+			return nil, db.ErrNotFound
+		}}
+		require.NoError(t, s.SwapStore(ctx, next))
+		require.Equal(t, BeaconDB(next), s.store)
+		require.ErrorIs(t, s.SwapStore(ctx, newDB(status(lowBlock), lowBlock)), ErrInconsistentStore)
+	})
+}
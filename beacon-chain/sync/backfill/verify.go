@@ -18,6 +18,8 @@ import (
 var errInvalidBatchChain = errors.New("parent_root of block does not match the previous block's root")
 var errProposerIndexTooHigh = errors.New("proposer index not present in origin state")
 var errUnknownDomain = errors.New("runtime error looking up signing domain for fork")
+var errUnknownForkVersion = errors.New("fork version is not mapped to a known block version")
+var errBlockVersionMismatch = errors.New("block version does not match the fork scheduled for its slot")
 
 // verifiedROBlocks represents a slice of blocks that have passed signature verification.
 type verifiedROBlocks []blocks.ROBlock
@@ -52,13 +54,20 @@ func (v verifiedROBlocks) blobIdents(retentionStart primitives.Slot) ([]blobSumm
 }
 
 type verifier struct {
-	keys   [][fieldparams.BLSPubkeyLength]byte
-	maxVal primitives.ValidatorIndex
-	domain *domainCache
+	keys     [][fieldparams.BLSPubkeyLength]byte
+	maxVal   primitives.ValidatorIndex
+	domain   *domainCache
+	versions map[[fieldparams.VersionLength]byte]int
+	// anchor is checked against the blocks of every batch, if the operator configured one.
+	anchor *trustedAnchor
 }
 
 // TODO: rewrite this to use ROBlock.
 func (vr verifier) verify(blks []interfaces.ReadOnlySignedBeaconBlock) (verifiedROBlocks, error) {
+	// A batch where every slot was skipped has nothing to verify, and an empty signature set does not verify.
+	if len(blks) == 0 {
+		return nil, nil
+	}
 	var err error
 	result := make([]blocks.ROBlock, len(blks))
 	sigSet := bls.NewSet()
@@ -74,6 +83,9 @@ func (vr verifier) verify(blks []interfaces.ReadOnlySignedBeaconBlock) (verified
 				b.Block().Slot(), b.Block().ParentRoot(),
 				p.Block().Slot(), p.Root())
 		}
+		if err := vr.checkForkVersion(result[i]); err != nil {
+			return nil, err
+		}
 		set, err := vr.blockSignatureBatch(result[i])
 		if err != nil {
 			return nil, err
@@ -90,6 +102,25 @@ func (vr verifier) verify(blks []interfaces.ReadOnlySignedBeaconBlock) (verified
 	return result, nil
 }
 
+// checkForkVersion ensures that the block type matches the fork that is scheduled for the block's slot,
+// eg rejecting a deneb block claimed for a slot that is before the deneb fork epoch.
+func (vr verifier) checkForkVersion(b blocks.ROBlock) error {
+	slot := b.Block().Slot()
+	fv, err := vr.domain.fsched.VersionForEpoch(slots.ToEpoch(slot))
+	if err != nil {
+		return err
+	}
+	expected, ok := vr.versions[fv]
+	if !ok {
+		return errors.Wrapf(errUnknownForkVersion, "fork version=%#x, slot=%d", fv, slot)
+	}
+	if b.Version() != expected {
+		return errors.Wrapf(errBlockVersionMismatch, "slot=%d, root=%#x, expected=%s, got=%s",
+			slot, b.Root(), version.String(expected), version.String(b.Version()))
+	}
+	return nil
+}
+
 func (vr verifier) blockSignatureBatch(b blocks.ROBlock) (*bls.SignatureBatch, error) {
 	pidx := b.Block().ProposerIndex()
 	if pidx > vr.maxVal {
@@ -113,8 +144,9 @@ func newBackfillVerifier(vr []byte, keys [][fieldparams.BLSPubkeyLength]byte) (*
 		return nil, err
 	}
 	v := &verifier{
-		keys:   keys,
-		domain: dc,
+		keys:     keys,
+		domain:   dc,
+		versions: params.ConfigForkVersions(params.BeaconConfig()),
 	}
 	v.maxVal = primitives.ValidatorIndex(len(v.keys) - 1)
 	return v, nil
@@ -16,6 +16,7 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/runtime/interop"
 	"github.com/prysmaticlabs/prysm/v5/testing/require"
 	"github.com/prysmaticlabs/prysm/v5/testing/util"
+	"github.com/prysmaticlabs/prysm/v5/time/slots"
 )
 
 func TestDomainCache(t *testing.T) {
@@ -54,7 +55,21 @@ func testBlocksWithKeys(t *testing.T, nBlocks uint64, nBlobs int, vr []byte) ([]
 	return blks, blbs, sks, pks
 }
 
+// setDenebAtGenesis adjusts the fork schedule so that the deneb test blocks generated for low slots
+// are the expected block type for their slots.
+func setDenebAtGenesis(t testing.TB) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.AltairForkEpoch = 0
+	cfg.BellatrixForkEpoch = 0
+	cfg.CapellaForkEpoch = 0
+	cfg.DenebForkEpoch = 0
+	cfg.InitializeForkSchedule()
+	params.OverrideBeaconConfig(cfg)
+}
+
 func TestVerify(t *testing.T) {
+	setDenebAtGenesis(t)
 	vr := make([]byte, 32)
 	copy(vr, "yooooo")
 	blks, _, _, pks := testBlocksWithKeys(t, 2, 0, vr)
@@ -73,3 +88,71 @@ func TestVerify(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, len(blks), len(vbs))
 }
+
+func TestVerifyForkVersionMismatch(t *testing.T) {
+	// With the mainnet schedule, slots 0 and 1 are in phase0, so the deneb test blocks are the wrong type.
+	vr := make([]byte, 32)
+	copy(vr, "yooooo")
+	blks, _, _, pks := testBlocksWithKeys(t, 2, 0, vr)
+	pubkeys := make([][fieldparams.BLSPubkeyLength]byte, len(pks))
+	for i := range pks {
+		pubkeys[i] = bytesutil.ToBytes48(pks[i].Marshal())
+	}
+	v, err := newBackfillVerifier(vr, pubkeys)
+	require.NoError(t, err)
+	notrob := make([]interfaces.ReadOnlySignedBeaconBlock, len(blks))
+	for i := range blks {
+		notrob[i] = blks[i].ReadOnlySignedBeaconBlock
+	}
+	_, err = v.verify(notrob)
+	require.ErrorIs(t, err, errBlockVersionMismatch)
+}
+
+func BenchmarkVerify(b *testing.B) {
+	setDenebAtGenesis(b)
+	vr := make([]byte, 32)
+	copy(vr, "yooooo")
+	n := uint64(64)
+	sks, pks, err := interop.DeterministicallyGenerateKeys(0, n)
+	require.NoError(b, err)
+	pubkeys := make([][fieldparams.BLSPubkeyLength]byte, len(pks))
+	for i := range pks {
+		pubkeys[i] = bytesutil.ToBytes48(pks[i].Marshal())
+	}
+	schedule := forks.NewOrderedSchedule(params.BeaconConfig())
+	batch := make([]interfaces.ReadOnlySignedBeaconBlock, n)
+	var parent [32]byte
+	for i := uint64(0); i < n; i++ {
+		pb := util.NewBeaconBlockDeneb()
+		pb.Block.Slot = primitives.Slot(i)
+		pb.Block.ProposerIndex = primitives.ValidatorIndex(i)
+		pb.Block.ParentRoot = bytesutil.SafeCopyBytes(parent[:])
+		epoch := slots.ToEpoch(pb.Block.Slot)
+		fv, err := schedule.VersionForEpoch(epoch)
+		require.NoError(b, err)
+		fork, err := schedule.ForkFromVersion(fv)
+		require.NoError(b, err)
+		pb.Signature, err = signing.ComputeDomainAndSignWithoutState(fork, epoch, params.BeaconConfig().DomainBeaconProposer, vr, pb.Block, sks[i])
+		require.NoError(b, err)
+		parent, err = pb.Block.HashTreeRoot()
+		require.NoError(b, err)
+		batch[i], err = blocks.NewSignedBeaconBlock(pb)
+		require.NoError(b, err)
+	}
+	v, err := newBackfillVerifier(vr, pubkeys)
+	require.NoError(b, err)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := v.verify(batch)
+		require.NoError(b, err)
+	}
+	b.ReportMetric(float64(int(n)*b.N)/b.Elapsed().Seconds(), "blocks/s")
+}
+
+func TestEmptyBatchVerifies(t *testing.T) {
+	v, err := newBackfillVerifier(make([]byte, 32), nil)
+	require.NoError(t, err)
+	vbs, err := v.verify(nil)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(vbs))
+}
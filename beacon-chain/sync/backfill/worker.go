@@ -10,10 +10,25 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/startup"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
 )
 
 type workerId int
 
+var errBatchTimeout = errors.New("backfill batch timed out")
+
+// A worker gives up on a batch after batchTimeoutBase, plus batchTimeoutPerSlot for every slot in the batch.
+var (
+	batchTimeoutBase    = 10 * time.Second
+	batchTimeoutPerSlot = 500 * time.Millisecond
+)
+
+// batchTimeout is the time a worker may spend requesting, verifying and storing the blocks or blobs for a batch,
+// before the batch is abandoned and retried. It scales with the number of slots in the batch.
+func batchTimeout(b batch) time.Duration {
+	return batchTimeoutBase + time.Duration(b.slotRange().Len())*batchTimeoutPerSlot
+}
+
 type p2pWorker struct {
 	id   workerId
 	todo chan batch
@@ -24,6 +39,13 @@ type p2pWorker struct {
 	cm   sync.ContextByteVersions
 	nbv  verification.NewBlobVerifier
 	bfs  *filesystem.BlobStorage
+	// inFlight is shared between the workers of every pool, see inFlightRanges.
+	inFlight *inFlightRanges
+	// skipBlobs means batches are sent to the importer without downloading their blobs.
+	skipBlobs bool
+	// quorum is the number of peers that must return the same blocks for a batch, see withQuorum.
+	quorum int
+	wall   prysmTime.Clock
 }
 
 func (w *p2pWorker) run(ctx context.Context) {
@@ -31,10 +53,15 @@ func (w *p2pWorker) run(ctx context.Context) {
 		select {
 		case b := <-w.todo:
 			log.WithFields(b.logFields()).WithField("backfillWorker", w.id).Debug("Backfill worker received batch")
-			if b.state == batchBlobSync {
-				w.done <- w.handleBlobs(ctx, b)
-			} else {
-				w.done <- w.handleBlocks(ctx, b)
+			w.inFlight.add(b)
+			b = w.handle(ctx, b)
+			w.inFlight.remove(b)
+			select {
+			case w.done <- b:
+			case <-ctx.Done():
+				// The pool is shutting down and won't read the result.
+				log.WithField("backfillWorker", w.id).Info("Backfill worker exiting after context canceled")
+				return
 			}
 		case <-ctx.Done():
 			log.WithField("backfillWorker", w.id).Info("Backfill worker exiting after context canceled")
@@ -43,6 +70,60 @@ func (w *p2pWorker) run(ctx context.Context) {
 	}
 }
 
+// handle processes the batch under a deadline computed by batchTimeout. A batch that times out is abandoned, even if
+// the request is still blocked on the peer or the disk, and is sent back for retry with the peer penalized.
+func (w *p2pWorker) handle(ctx context.Context, b batch) batch {
+	timeout := batchTimeout(b)
+	handler := w.handleBlocks
+	if b.state == batchBlobSync {
+		handler = w.handleBlobs
+	}
+	done, ok := withBatchTimeout(ctx, w.wall, timeout, b, handler)
+	if ok {
+		return done
+	}
+	if ctx.Err() != nil {
+		// The pool is shutting down, there is no need to penalize the peer.
+		return b.withRetryableError(ctx.Err())
+	}
+	backfillBatchTimeouts.Inc()
+	err := errors.Wrapf(errBatchTimeout, "timeout=%s", timeout)
+	log.WithError(err).WithFields(b.logFields()).Debug("Backfill batch abandoned after timeout")
+	w.p2p.Peers().Scorers().BadResponsesScorer().Increment(b.busy)
+	if b.state == batchBlobSync {
+		b.blobPid = b.busy
+		b.bs = nil
+		return b.withRetryableError(err)
+	}
+	b.blockPid = b.busy
+	return b.withBlockPeerFailure(err)
+}
+
+// withBatchTimeout runs the handler for the batch in a separate goroutine, so that it can be abandoned if it does
+// not return before the timeout, or before ctx is canceled. The timeout is measured with the given clock. The handler's
+// context is canceled when withBatchTimeout returns, and handlers must check it before any side effect such as
+// penalizing a peer, so that an abandoned handler stops at its next step. The returned bool is false if the handler
+// was abandoned, in which case the result it eventually produces is dropped.
+func withBatchTimeout(ctx context.Context, wall prysmTime.Clock, timeout time.Duration, b batch, handler func(context.Context, batch) batch) (batch, bool) {
+	tctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	t := wall.NewTimer(timeout)
+	defer t.Stop()
+	// Buffered so that an abandoned handler can still exit.
+	res := make(chan batch, 1)
+	go func() {
+		res <- handler(tctx, b)
+	}()
+	select {
+	case done := <-res:
+		return done, true
+	case <-t.C():
+		return batch{}, false
+	case <-ctx.Done():
+		return batch{}, false
+	}
+}
+
 func (w *p2pWorker) handleBlocks(ctx context.Context, b batch) batch {
 	cs := w.c.CurrentSlot()
 	blobRetentionStart, err := sync.BlobRPCMinValidSlot(cs)
@@ -50,19 +131,43 @@ func (w *p2pWorker) handleBlocks(ctx context.Context, b batch) batch {
 		return b.withRetryableError(errors.Wrap(err, "configuration issue, could not compute minimum blob retention slot"))
 	}
 	b.blockPid = b.busy
-	start := time.Now()
+	start := w.wall.Now()
 	results, err := sync.SendBeaconBlocksByRangeRequest(ctx, w.c, w.p2p, b.blockPid, b.blockRequest(), blockValidationMetrics)
-	dlt := time.Now()
+	dlt := w.wall.Now()
 	backfillBatchTimeDownloadingBlocks.Observe(float64(dlt.Sub(start).Milliseconds()))
+	if ctx.Err() != nil {
+		// The batch was abandoned while downloading, so the result will be discarded.
+		return b.withRetryableError(ctx.Err())
+	}
 	if err != nil {
 		log.WithError(err).WithFields(b.logFields()).Debug("Batch requesting failed")
-		return b.withRetryableError(err)
+		return b.withBlockPeerFailure(err)
+	}
+	if w.quorum > 1 {
+		agreed, pid, err := w.withQuorum(ctx, b, results)
+		if err != nil {
+			log.WithError(err).WithFields(b.logFields()).Debug("Backfill batch did not reach quorum")
+			return b.withRetryableError(err)
+		}
+		if ctx.Err() != nil {
+			return b.withRetryableError(ctx.Err())
+		}
+		if pid != b.blockPid {
+			w.p2p.Peers().Scorers().BadResponsesScorer().Increment(b.blockPid)
+			b.blockPid = pid
+		}
+		results = agreed
 	}
 	vb, err := w.v.verify(results)
-	backfillBatchTimeVerifying.Observe(float64(time.Since(dlt).Milliseconds()))
+	backfillBatchTimeVerifying.Observe(float64(w.wall.Now().Sub(dlt).Milliseconds()))
 	if err != nil {
 		log.WithError(err).WithFields(b.logFields()).Debug("Batch validation failed")
-		return b.withRetryableError(err)
+		return b.withBlockPeerFailure(err)
+	}
+	if err := w.v.anchor.check(b, vb); err != nil {
+		backfillTrustedAnchorMismatches.Inc()
+		log.WithError(err).WithFields(b.logFields()).Warn("Backfill batch does not contain the trusted anchor block")
+		return b.withBlockPeerFailure(err)
 	}
 	// This is a hack to get the rough size of the batch. This helps us approximate the amount of memory needed
 	// to hold batches and relative sizes between batches, but will be inaccurate when it comes to measuring actual
@@ -72,7 +177,12 @@ func (w *p2pWorker) handleBlocks(ctx context.Context, b batch) batch {
 		bdl += vb[i].SizeSSZ()
 	}
 	backfillBlocksApproximateBytes.Add(float64(bdl))
+	b.bytes = uint64(bdl)
 	log.WithFields(b.logFields()).WithField("dlbytes", bdl).Debug("Backfill batch block bytes downloaded")
+	if w.skipBlobs {
+		// An empty blobSync does not need any blobs, so the batch goes straight to the importer.
+		return b.withResults(vb, &blobSync{})
+	}
 	bs, err := newBlobSync(cs, vb, &blobSyncConfig{retentionStart: blobRetentionStart, nbv: w.nbv, store: w.bfs})
 	if err != nil {
 		return b.withRetryableError(err)
@@ -82,35 +192,44 @@ func (w *p2pWorker) handleBlocks(ctx context.Context, b batch) batch {
 
 func (w *p2pWorker) handleBlobs(ctx context.Context, b batch) batch {
 	b.blobPid = b.busy
-	start := time.Now()
+	start := w.wall.Now()
 	// we don't need to use the response for anything other than metrics, because blobResponseValidation
 	// adds each of them to a batch AvailabilityStore once it is checked.
 	blobs, err := sync.SendBlobsByRangeRequest(ctx, w.c, w.p2p, b.blobPid, w.cm, b.blobRequest(), b.blobResponseValidator(), blobValidationMetrics)
+	if ctx.Err() != nil {
+		b.bs = nil
+		return b.withRetryableError(ctx.Err())
+	}
 	if err != nil {
 		b.bs = nil
 		return b.withRetryableError(err)
 	}
-	dlt := time.Now()
+	dlt := w.wall.Now()
 	backfillBatchTimeDownloadingBlobs.Observe(float64(dlt.Sub(start).Milliseconds()))
 	if len(blobs) > 0 {
 		// All blobs are the same size, so we can compute 1 and use it for all in the batch.
 		sz := blobs[0].SizeSSZ() * len(blobs)
 		backfillBlobsApproximateBytes.Add(float64(sz))
+		b.bytes += uint64(sz)
 		log.WithFields(b.logFields()).WithField("dlbytes", sz).Debug("Backfill batch blob bytes downloaded")
 	}
 	return b.postBlobSync()
 }
 
-func newP2pWorker(id workerId, p p2p.P2P, todo, done chan batch, c *startup.Clock, v *verifier, cm sync.ContextByteVersions, nbv verification.NewBlobVerifier, bfs *filesystem.BlobStorage) *p2pWorker {
+func newP2pWorker(id workerId, p p2p.P2P, todo, done chan batch, c *startup.Clock, v *verifier, cm sync.ContextByteVersions, nbv verification.NewBlobVerifier, bfs *filesystem.BlobStorage, inFlight *inFlightRanges, skipBlobs bool, quorum int, wall prysmTime.Clock) *p2pWorker {
 	return &p2pWorker{
-		id:   id,
-		todo: todo,
-		done: done,
-		p2p:  p,
-		v:    v,
-		c:    c,
-		cm:   cm,
-		nbv:  nbv,
-		bfs:  bfs,
+		id:        id,
+		todo:      todo,
+		done:      done,
+		p2p:       p,
+		v:         v,
+		c:         c,
+		cm:        cm,
+		nbv:       nbv,
+		bfs:       bfs,
+		inFlight:  inFlight,
+		skipBlobs: skipBlobs,
+		quorum:    quorum,
+		wall:      wall,
 	}
 }
@@ -0,0 +1,84 @@
+package backfill
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	p2ptest "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/testing"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/startup"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+	prysmTesting "github.com/prysmaticlabs/prysm/v5/time/testing"
+)
+
+func TestBatchTimeoutScalesWithSize(t *testing.T) {
+	small := batchTimeout(batch{begin: 100, end: 110})
+	large := batchTimeout(batch{begin: 100, end: 164})
+	require.Equal(t, batchTimeoutBase+10*batchTimeoutPerSlot, small)
+	require.Equal(t, batchTimeoutBase+64*batchTimeoutPerSlot, large)
+}
+
+func TestWithBatchTimeout(t *testing.T) {
+	b := batch{begin: 100, end: 164, state: batchSequenced}
+	t.Run("completes", func(t *testing.T) {
+		done, ok := withBatchTimeout(context.Background(), prysmTime.RealClock{}, time.Second, b, func(_ context.Context, b batch) batch {
+			return b.withState(batchImportable)
+		})
+		require.Equal(t, true, ok)
+		require.Equal(t, batchImportable, done.state)
+	})
+	t.Run("abandoned", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		canceled := make(chan struct{})
+		// The timeout is measured with the given clock, so the handler is only abandoned once the clock is advanced.
+		clock := prysmTesting.NewClock(time.Now())
+		res := make(chan bool)
+		go func() {
+			_, ok := withBatchTimeout(context.Background(), clock, time.Minute, b, func(ctx context.Context, b batch) batch {
+				<-ctx.Done()
+				close(canceled)
+				// Ignore the canceled context, like a request blocked on a slow peer or disk.
+				<-release
+				return b
+			})
+			res <- ok
+		}()
+		require.NoError(t, waitForWaiters(clock, 1))
+		clock.Advance(time.Minute)
+		require.Equal(t, false, <-res)
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Fatal("handler context was not canceled after timeout")
+		}
+	})
+	t.Run("parent canceled", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, ok := withBatchTimeout(ctx, prysmTime.RealClock{}, time.Minute, b, func(_ context.Context, b batch) batch {
+			<-release
+			return b
+		})
+		require.Equal(t, false, ok)
+	})
+}
+
+func TestHandleBlocksCanceled(t *testing.T) {
+	w := &p2pWorker{
+		p2p:  p2ptest.NewTestP2P(t),
+		c:    startup.NewClock(time.Now(), [32]byte{}),
+		wall: prysmTime.RealClock{},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b := batch{begin: 100, end: 164, state: batchSequenced, busy: "peer"}
+	done := w.handleBlocks(ctx, b)
+	require.Equal(t, batchErrRetryable, done.state)
+	require.ErrorIs(t, done.err, context.Canceled)
+	// An abandoned handler must not blame the peer for the failed request.
+	require.Equal(t, 0, len(done.failedPeers))
+}
@@ -0,0 +1,46 @@
+package sync
+
+import (
+	"time"
+
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+)
+
+// batchThrottleInterval is the minimum time between the batches read by a blockRangeBatcher, which staggers out the
+// work of serving large range requests.
+const batchThrottleInterval = time.Second
+
+// batchThrottle paces the batches read by a blockRangeBatcher. It behaves like a time.Ticker, waking at every
+// multiple of the interval since it was created and dropping the ticks missed while the caller was busy, but its
+// timer comes from a prysmTime.Clock so that tests can control it.
+type batchThrottle struct {
+	clock    prysmTime.Clock
+	timer    prysmTime.Timer
+	interval time.Duration
+	next     time.Time
+}
+
+func newBatchThrottle(c prysmTime.Clock, interval time.Duration) *batchThrottle {
+	return &batchThrottle{
+		clock:    c,
+		timer:    c.NewTimer(interval),
+		interval: interval,
+		next:     c.Now().Add(interval),
+	}
+}
+
+// wait blocks until the next tick.
+func (t *batchThrottle) wait() {
+	<-t.timer.C()
+	now := t.clock.Now()
+	t.next = t.next.Add(t.interval)
+	for !t.next.After(now) {
+		t.next = t.next.Add(t.interval)
+	}
+	t.timer.Reset(t.next.Sub(now))
+}
+
+// stop releases the timer of the throttle.
+func (t *batchThrottle) stop() {
+	t.timer.Stop()
+}
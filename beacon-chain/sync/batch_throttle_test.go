@@ -0,0 +1,48 @@
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	prysmTesting "github.com/prysmaticlabs/prysm/v5/time/testing"
+)
+
+func TestBatchThrottle(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := prysmTesting.NewClock(start)
+	throttle := newBatchThrottle(clock, time.Second)
+	defer throttle.stop()
+
+	waited := make(chan time.Time)
+	wait := func() {
+		go func() {
+			throttle.wait()
+			waited <- clock.Now()
+		}()
+	}
+	wait()
+	clock.Advance(999 * time.Millisecond)
+	select {
+	case <-waited:
+		t.Fatal("throttle released before the interval")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Advance(time.Millisecond)
+	require.Equal(t, start.Add(time.Second), <-waited)
+
+	// Like a time.Ticker, time spent by the caller between waits counts towards the interval, and missed ticks
+	// are dropped rather than released back to back.
+	clock.Advance(2500 * time.Millisecond)
+	wait()
+	require.Equal(t, start.Add(3500*time.Millisecond), <-waited)
+	wait()
+	clock.Advance(400 * time.Millisecond)
+	select {
+	case <-waited:
+		t.Fatal("throttle released before the next tick")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Advance(100 * time.Millisecond)
+	require.Equal(t, start.Add(4*time.Second), <-waited)
+}
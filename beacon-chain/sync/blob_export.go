@@ -0,0 +1,229 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
+	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
+	"github.com/prysmaticlabs/prysm/v5/monitoring/tracing/trace"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+)
+
+var (
+	errInvalidExportRange   = errors.New("blob sidecar export end slot is lower than start slot")
+	errInvalidImportLength  = errors.New("blob sidecar length prefix does not match the size of a blob sidecar")
+	errInvalidImportRoot    = errors.New("imported blob sidecar has a zero block root")
+	errTruncatedBlobsImport = errors.New("blob sidecar import ended in the middle of a sidecar")
+
+	errImportUnknownBlock       = errors.New("imported blob sidecar does not belong to a block in the db")
+	errImportCommitmentMismatch = errors.New("imported blob sidecar does not match the kzg commitment of its block")
+	errImportSignatureMismatch  = errors.New("imported blob sidecar header signature does not match the signature of its block")
+	errImportVerifierNotReady   = errors.New("blob sidecars can't be imported until the blob verifier is initialized")
+)
+
+// ExportBlobSidecars writes the blob sidecars for canonical blocks in the slot range [start, end] to w, in slot and
+// index order. Each sidecar is written as its ssz encoding, prefixed by the length of the encoding as a little-endian
+// uint64. Blocks are read from the db using the same batched range scan as the BlobSidecarsByRange handler, so the
+// export is the offline counterpart to serving the range to a peer. The part of the range before the start of the
+// blob retention window is skipped, because those sidecars may already have been pruned.
+func (s *Service) ExportBlobSidecars(ctx context.Context, start, end primitives.Slot, w io.Writer) error {
+	ctx, span := trace.StartSpan(ctx, "sync.ExportBlobSidecars")
+	defer span.End()
+	if end < start {
+		return errors.Wrapf(errInvalidExportRange, "start=%d, end=%d", start, end)
+	}
+	log := log.WithField("start", start).WithField("end", end)
+	minStart, err := BlobRPCMinValidSlot(s.cfg.chain.CurrentSlot())
+	if err != nil {
+		return errors.Wrap(err, "could not compute the start of the blob retention window")
+	}
+	if start < minStart {
+		log.WithField("retentionStart", minStart).Info("Skipping export of blob sidecars before the start of the retention window")
+		start = minStart
+	}
+	if start > end {
+		return nil
+	}
+
+	cf := &canonicalFilter{canonical: s.cfg.chain.IsCanonical}
+	size := blobBatchLimit()
+	if size == 0 {
+		size = 1
+	}
+	var exported, skipped int
+	for nb, more := newBlockBatch(start, end, size); more; nb, more = nb.next(end, size) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		nb, err = readBlockBatch(ctx, s.cfg.beaconDB, cf, nb)
+		if err != nil {
+			return err
+		}
+		if err := nb.error(); err != nil {
+			return err
+		}
+		for _, b := range nb.canonical() {
+			root := b.Root()
+			if root == [32]byte{} {
+				skipped += 1
+				continue
+			}
+			n, err := s.exportBlockSidecars(root, w)
+			if err != nil {
+				return err
+			}
+			exported += n
+		}
+		if nb.nonLinear() {
+			log.WithField("slot", nb.nonlin[0].Block().Slot()).Warn("Ending blob sidecar export at a break in the canonical chain")
+			break
+		}
+	}
+	log.WithField("exported", exported).WithField("skippedZeroRoots", skipped).Info("Exported blob sidecars")
+	return nil
+}
+
+func (s *Service) exportBlockSidecars(root [32]byte, w io.Writer) (int, error) {
+	idxs, err := s.cfg.blobStorage.Indices(root)
+	if err != nil {
+		return 0, errors.Wrapf(err, "could not retrieve sidecars for block root %#x", root)
+	}
+	n := 0
+	for i := range idxs {
+		if !idxs[i] {
+			continue
+		}
+		sc, err := s.cfg.blobStorage.Get(root, uint64(i))
+		if err != nil {
+			return n, errors.Wrapf(err, "could not retrieve sidecar: index %d, block root %#x", i, root)
+		}
+		enc, err := sc.MarshalSSZ()
+		if err != nil {
+			return n, errors.Wrapf(err, "could not encode sidecar: index %d, block root %#x", i, root)
+		}
+		if _, err := w.Write(bytesutil.Uint64ToBytesLittleEndian(uint64(len(enc)))); err != nil {
+			return n, errors.Wrap(err, "could not write sidecar length prefix")
+		}
+		if _, err := w.Write(enc); err != nil {
+			return n, errors.Wrap(err, "could not write sidecar")
+		}
+		n += 1
+	}
+	return n, nil
+}
+
+// ImportBlobSidecars reads blob sidecars in the format written by ExportBlobSidecars from r, and saves them to blob
+// storage, so that the blob store of a node can be seeded from an export of another node. The export is not trusted:
+// each sidecar must have a length prefix matching the size of a blob sidecar, belong to a block in the db whose kzg
+// commitment at the sidecar index matches the sidecar, and pass the blob verifier's inclusion proof and kzg proof
+// checks, like sidecars downloaded by backfill. Sidecars that fail these checks are logged and skipped. Reading stops
+// at the end of r, and an error is returned if r ends in the middle of a sidecar, a length prefix is too large to skip,
+// or a sidecar can't be saved. The number of sidecars saved is returned in either case.
+func (s *Service) ImportBlobSidecars(ctx context.Context, r io.Reader) (imported int, err error) {
+	ctx, span := trace.StartSpan(ctx, "sync.ImportBlobSidecars")
+	defer span.End()
+	if s.newBlobVerifier == nil {
+		return 0, errImportVerifierNotReady
+	}
+	var skipped int
+	defer func() {
+		log.WithField("imported", imported).WithField("skipped", skipped).Info("Imported blob sidecars")
+	}()
+	prefix := make([]byte, 8)
+	enc := make([]byte, fieldparams.BlobSidecarSize)
+	for entry := 0; ; entry++ {
+		if err := ctx.Err(); err != nil {
+			return imported, err
+		}
+		if _, err := io.ReadFull(r, prefix); err != nil {
+			if errors.Is(err, io.EOF) {
+				return imported, nil
+			}
+			return imported, errors.Wrap(errTruncatedBlobsImport, err.Error())
+		}
+		log := log.WithField("entry", entry)
+		size := binary.LittleEndian.Uint64(prefix)
+		if size > math.MaxInt64 {
+			// The entry can't be skipped, so the rest of the stream can't be framed.
+			return imported, errors.Wrapf(errInvalidImportLength, "length=%d", size)
+		}
+		if size != fieldparams.BlobSidecarSize {
+			// The prefix still frames the entry, so the rest of the stream can be read.
+			log.WithError(errors.Wrapf(errInvalidImportLength, "length=%d", size)).Warn("Skipping invalid blob sidecar in import")
+			skipped += 1
+			if _, err := io.CopyN(io.Discard, r, int64(size)); err != nil {
+				return imported, errors.Wrap(errTruncatedBlobsImport, err.Error())
+			}
+			continue
+		}
+		if _, err := io.ReadFull(r, enc); err != nil {
+			return imported, errors.Wrap(errTruncatedBlobsImport, err.Error())
+		}
+		sc, err := s.importedBlobSidecar(ctx, enc)
+		if err != nil {
+			log.WithError(err).Warn("Skipping invalid blob sidecar in import")
+			skipped += 1
+			continue
+		}
+		if err := s.cfg.blobStorage.Save(sc); err != nil {
+			return imported, errors.Wrapf(err, "could not save imported sidecar: index %d, block root %#x", sc.Index, sc.BlockRoot())
+		}
+		imported += 1
+	}
+}
+
+// importedBlobSidecar decodes an imported sidecar, and verifies it against its block in the db. The header of the
+// sidecar hashes to the root of a block that was verified when it was saved, and its signature must be the signature
+// of that block, which stands in for checking the proposer signature, so only the sidecar itself needs to be verified.
+func (s *Service) importedBlobSidecar(ctx context.Context, enc []byte) (blocks.VerifiedROBlob, error) {
+	pb := &ethpb.BlobSidecar{}
+	if err := pb.UnmarshalSSZ(enc); err != nil {
+		return blocks.VerifiedROBlob{}, errors.Wrap(err, "could not decode sidecar")
+	}
+	sc, err := blocks.NewROBlob(pb)
+	if err != nil {
+		return blocks.VerifiedROBlob{}, err
+	}
+	if sc.BlockRoot() == [32]byte{} {
+		return blocks.VerifiedROBlob{}, errInvalidImportRoot
+	}
+	blk, err := s.cfg.beaconDB.Block(ctx, sc.BlockRoot())
+	if err != nil {
+		return blocks.VerifiedROBlob{}, errors.Wrapf(err, "could not read block for sidecar: block root %#x", sc.BlockRoot())
+	}
+	if blk == nil || blk.IsNil() {
+		return blocks.VerifiedROBlob{}, errors.Wrapf(errImportUnknownBlock, "block root %#x", sc.BlockRoot())
+	}
+	// The header root doesn't cover the signature, so it has to be compared separately.
+	sig := blk.Signature()
+	if !bytes.Equal(sc.SignedBlockHeader.Signature, sig[:]) {
+		return blocks.VerifiedROBlob{}, errors.Wrapf(errImportSignatureMismatch, "block root %#x", sc.BlockRoot())
+	}
+	commitments, err := blk.Block().Body().BlobKzgCommitments()
+	if err != nil {
+		return blocks.VerifiedROBlob{}, errors.Wrapf(err, "could not read commitments of block %#x", sc.BlockRoot())
+	}
+	if sc.Index >= uint64(len(commitments)) || !bytes.Equal(commitments[sc.Index], sc.KzgCommitment) {
+		return blocks.VerifiedROBlob{}, errors.Wrapf(errImportCommitmentMismatch, "index %d, block root %#x", sc.Index, sc.BlockRoot())
+	}
+	v := s.newBlobVerifier(sc, verification.BackfillSidecarRequirements)
+	if err := v.BlobIndexInBounds(); err != nil {
+		return blocks.VerifiedROBlob{}, err
+	}
+	v.SatisfyRequirement(verification.RequireValidProposerSignature)
+	if err := v.SidecarInclusionProven(); err != nil {
+		return blocks.VerifiedROBlob{}, err
+	}
+	if err := v.SidecarKzgProofVerified(); err != nil {
+		return blocks.VerifiedROBlob{}, err
+	}
+	return v.VerifiedROBlob()
+}
@@ -0,0 +1,217 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	types "github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func readExportedSidecars(t *testing.T, r io.Reader) []blocks.ROBlob {
+	scs := make([]blocks.ROBlob, 0)
+	for {
+		prefix := make([]byte, 8)
+		if _, err := io.ReadFull(r, prefix); err == io.EOF {
+			return scs
+		} else {
+			require.NoError(t, err)
+		}
+		enc := make([]byte, binary.LittleEndian.Uint64(prefix))
+		_, err := io.ReadFull(r, enc)
+		require.NoError(t, err)
+		sc := &ethpb.BlobSidecar{}
+		require.NoError(t, sc.UnmarshalSSZ(enc))
+		rob, err := blocks.NewROBlob(sc)
+		require.NoError(t, err)
+		scs = append(scs, rob)
+	}
+}
+
+func TestExportBlobSidecars(t *testing.T) {
+	ctx := context.Background()
+	c := &blobsTestCase{nblocks: 10, expired: map[int]bool{0: true}}
+	c.oldestSlot = c.defaultOldestSlotByRange
+	s, sidecars, cleanup := c.setup(t)
+	defer cleanup()
+	v, err := verification.BlobSidecarSliceNoop(sidecars)
+	require.NoError(t, err)
+	for i := range v {
+		require.NoError(t, s.cfg.blobStorage.Save(v[i]))
+	}
+	lowest, highest := sidecars[0].Slot(), sidecars[0].Slot()
+	for _, sc := range sidecars {
+		if sc.Slot() < lowest {
+			lowest = sc.Slot()
+		}
+		if sc.Slot() > highest {
+			highest = sc.Slot()
+		}
+	}
+	minStart, err := BlobRPCMinValidSlot(s.cfg.chain.CurrentSlot())
+	require.NoError(t, err)
+	require.Equal(t, true, lowest < minStart)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, s.ExportBlobSidecars(ctx, lowest, highest, buf))
+	got := readExportedSidecars(t, buf)
+	// The sidecars for the expired block are skipped.
+	expected := make([]blocks.ROBlob, 0)
+	for _, sc := range sidecars {
+		if sc.Slot() >= minStart {
+			expected = append(expected, sc)
+		}
+	}
+	require.Equal(t, len(sidecars)-len(sidecars)/c.nblocks, len(expected))
+	require.Equal(t, len(expected), len(got))
+	for i := range expected {
+		require.Equal(t, expected[i].BlockRoot(), got[i].BlockRoot())
+		require.Equal(t, expected[i].Index, got[i].Index)
+	}
+
+	// A range entirely before the retention window exports nothing.
+	buf.Reset()
+	require.NoError(t, s.ExportBlobSidecars(ctx, lowest, minStart-1, buf))
+	require.Equal(t, 0, buf.Len())
+
+	require.ErrorIs(t, s.ExportBlobSidecars(ctx, highest, lowest, buf), errInvalidExportRange)
+}
+
+func TestExportBlobSidecarsLimitedBatch(t *testing.T) {
+	// Use a batch size smaller than the range, so the export spans several db reads.
+	cfg := params.BeaconConfig()
+	require.Equal(t, true, blobBatchLimit() > 0)
+	ctx := context.Background()
+	c := &blobsTestCase{nblocks: int(blobBatchLimit()) + 3}
+	c.oldestSlot = c.defaultOldestSlotByRange
+	s, sidecars, cleanup := c.setup(t)
+	defer cleanup()
+	v, err := verification.BlobSidecarSliceNoop(sidecars)
+	require.NoError(t, err)
+	for i := range v {
+		require.NoError(t, s.cfg.blobStorage.Save(v[i]))
+	}
+	buf := &bytes.Buffer{}
+	end := sidecars[len(sidecars)-1].Slot() + types.Slot(cfg.SlotsPerEpoch)
+	require.NoError(t, s.ExportBlobSidecars(ctx, sidecars[0].Slot(), end, buf))
+	got := readExportedSidecars(t, buf)
+	require.Equal(t, len(sidecars), len(got))
+	for i := range sidecars {
+		require.Equal(t, sidecars[i].BlockRoot(), got[i].BlockRoot())
+		require.Equal(t, sidecars[i].Index, got[i].Index)
+	}
+}
+
+func writeImportEntry(t *testing.T, w io.Writer, enc []byte) {
+	prefix := make([]byte, 8)
+	binary.LittleEndian.PutUint64(prefix, uint64(len(enc)))
+	_, err := w.Write(prefix)
+	require.NoError(t, err)
+	_, err = w.Write(enc)
+	require.NoError(t, err)
+}
+
+// importVerifier returns a NewBlobVerifier for ImportBlobSidecars, that fails the kzg proof check with errKzg.
+func importVerifier(errKzg error) verification.NewBlobVerifier {
+	return func(b blocks.ROBlob, _ []verification.Requirement) verification.BlobVerifier {
+		return &verification.MockBlobVerifier{
+			ErrSidecarKzgProofVerified: errKzg,
+			CbVerifiedROBlob: func() (blocks.VerifiedROBlob, error) {
+				return blocks.NewVerifiedROBlob(b), nil
+			},
+		}
+	}
+}
+
+func TestImportBlobSidecars(t *testing.T) {
+	ctx := context.Background()
+	c := &blobsTestCase{nblocks: 3}
+	c.oldestSlot = c.defaultOldestSlotByRange
+	s, sidecars, cleanup := c.setup(t)
+	defer cleanup()
+	require.Equal(t, true, len(sidecars) > 2)
+
+	// Sidecars can't be verified before the service has a blob verifier.
+	_, err := s.ImportBlobSidecars(ctx, &bytes.Buffer{})
+	require.ErrorIs(t, err, errImportVerifierNotReady)
+	s.newBlobVerifier = importVerifier(nil)
+
+	buf := &bytes.Buffer{}
+	// An entry with the wrong length is skipped using its prefix.
+	writeImportEntry(t, buf, []byte{1, 2, 3})
+	for i := range sidecars {
+		enc, err := sidecars[i].MarshalSSZ()
+		require.NoError(t, err)
+		writeImportEntry(t, buf, enc)
+	}
+	imported, err := s.ImportBlobSidecars(ctx, buf)
+	require.NoError(t, err)
+	require.Equal(t, len(sidecars), imported)
+	for _, sc := range sidecars {
+		got, err := s.cfg.blobStorage.Get(sc.BlockRoot(), sc.Index)
+		require.NoError(t, err)
+		require.Equal(t, sc.Slot(), got.Slot())
+	}
+
+	// Sidecars that fail verification are skipped.
+	s.newBlobVerifier = importVerifier(errors.New("bad kzg proof"))
+	buf.Reset()
+	enc, err := sidecars[0].MarshalSSZ()
+	require.NoError(t, err)
+	writeImportEntry(t, buf, enc)
+	imported, err = s.ImportBlobSidecars(ctx, buf)
+	require.NoError(t, err)
+	require.Equal(t, 0, imported)
+	s.newBlobVerifier = importVerifier(nil)
+
+	// So are sidecars that don't match the commitments or signature of their block, or whose block is not in the db.
+	mismatch := proto.Clone(sidecars[0].BlobSidecar).(*ethpb.BlobSidecar)
+	mismatch.KzgCommitment = bytes.Repeat([]byte{0xff}, 48)
+	badSig := proto.Clone(sidecars[0].BlobSidecar).(*ethpb.BlobSidecar)
+	badSig.SignedBlockHeader.Signature = bytes.Repeat([]byte{0xee}, 96)
+	badSigEnc, err := badSig.MarshalSSZ()
+	require.NoError(t, err)
+	_, err = s.importedBlobSidecar(ctx, badSigEnc)
+	require.ErrorIs(t, err, errImportSignatureMismatch)
+	unknown := proto.Clone(sidecars[0].BlobSidecar).(*ethpb.BlobSidecar)
+	unknown.SignedBlockHeader.Header.Slot += 1000
+	buf.Reset()
+	for _, sc := range []*ethpb.BlobSidecar{mismatch, badSig, unknown} {
+		enc, err := sc.MarshalSSZ()
+		require.NoError(t, err)
+		writeImportEntry(t, buf, enc)
+	}
+	imported, err = s.ImportBlobSidecars(ctx, buf)
+	require.NoError(t, err)
+	require.Equal(t, 0, imported)
+
+	// A length prefix that can't be skipped ends the import.
+	buf.Reset()
+	prefix := make([]byte, 8)
+	binary.LittleEndian.PutUint64(prefix, math.MaxUint64)
+	_, err = buf.Write(prefix)
+	require.NoError(t, err)
+	writeImportEntry(t, buf, enc)
+	imported, err = s.ImportBlobSidecars(ctx, buf)
+	require.ErrorIs(t, err, errInvalidImportLength)
+	require.Equal(t, 0, imported)
+
+	// A stream that ends in the middle of a sidecar is reported, with the count of what was saved before it.
+	buf.Reset()
+	writeImportEntry(t, buf, enc)
+	writeImportEntry(t, buf, enc)
+	buf.Truncate(buf.Len() - 10)
+	imported, err = s.ImportBlobSidecars(ctx, buf)
+	require.ErrorIs(t, err, errTruncatedBlobsImport)
+	require.Equal(t, 1, imported)
+}
@@ -0,0 +1,69 @@
+package sync
+
+import (
+	"bufio"
+	"time"
+
+	libp2pcore "github.com/libp2p/go-libp2p/core"
+)
+
+// blobFlushBufferSize is the size of the buffer used to write blob sidecar responses. It holds at least one
+// encoded sidecar, so that buffered writes are flushed by the flush policy rather than by a full buffer.
+const blobFlushBufferSize = 256 << 10
+
+// flushingStream buffers the writes for a blob sidecars response, so that the response code, context bytes and
+// payload of each chunk are sent to the peer together rather than as tiny separate writes. Buffered chunks are
+// flushed once the flush interval has elapsed since the last flush, after every chunk if the interval is zero,
+// and before the stream is closed.
+type flushingStream struct {
+	libp2pcore.Stream
+	w         *bufio.Writer
+	interval  time.Duration
+	lastFlush time.Time
+}
+
+func newFlushingStream(stream libp2pcore.Stream, interval time.Duration) *flushingStream {
+	return &flushingStream{
+		Stream:    stream,
+		w:         bufio.NewWriterSize(stream, blobFlushBufferSize),
+		interval:  interval,
+		lastFlush: time.Now(),
+	}
+}
+
+// Write buffers the bytes to be written to the stream.
+func (f *flushingStream) Write(p []byte) (int, error) {
+	return f.w.Write(p)
+}
+
+// chunkWritten is called after a complete chunk has been written, and flushes the buffered chunks
+// if the flush interval has elapsed.
+func (f *flushingStream) chunkWritten(now time.Time) error {
+	if now.Sub(f.lastFlush) < f.interval {
+		return nil
+	}
+	return f.flush(now)
+}
+
+func (f *flushingStream) flush(now time.Time) error {
+	f.lastFlush = now
+	return f.w.Flush()
+}
+
+// Close flushes any buffered chunks before closing the stream.
+func (f *flushingStream) Close() error {
+	err := f.flush(time.Now())
+	if cerr := f.Stream.Close(); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// CloseWrite flushes any buffered chunks before closing the stream for writing.
+func (f *flushingStream) CloseWrite() error {
+	err := f.flush(time.Now())
+	if cerr := f.Stream.CloseWrite(); cerr != nil {
+		return cerr
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+// countingStream records the writes that reach the underlying stream.
+type countingStream struct {
+	network.Stream
+	buf    bytes.Buffer
+	writes int
+	closed bool
+}
+
+func (c *countingStream) Write(p []byte) (int, error) {
+	c.writes++
+	return c.buf.Write(p)
+}
+
+func (c *countingStream) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *countingStream) CloseWrite() error {
+	c.closed = true
+	return nil
+}
+
+// writeTestChunk writes a chunk the same way WriteBlobSidecarChunk does, with separate writes for the
+// response code, context bytes and payload.
+func writeTestChunk(t testing.TB, f *flushingStream, payload []byte) {
+	_, err := f.Write([]byte{responseCodeSuccess})
+	require.NoError(t, err)
+	_, err = f.Write([]byte{1, 2, 3, 4})
+	require.NoError(t, err)
+	_, err = f.Write(payload)
+	require.NoError(t, err)
+}
+
+func TestFlushingStream(t *testing.T) {
+	payload := make([]byte, 1024)
+	t.Run("flush every chunk", func(t *testing.T) {
+		cs := &countingStream{}
+		f := newFlushingStream(cs, 0)
+		for i := 0; i < 3; i++ {
+			writeTestChunk(t, f, payload)
+			require.NoError(t, f.chunkWritten(time.Now()))
+			// The three writes for the chunk are sent together.
+			require.Equal(t, i+1, cs.writes)
+		}
+		require.Equal(t, 3*(5+len(payload)), cs.buf.Len())
+	})
+	t.Run("flush interval", func(t *testing.T) {
+		cs := &countingStream{}
+		f := newFlushingStream(cs, time.Hour)
+		for i := 0; i < 3; i++ {
+			writeTestChunk(t, f, payload)
+			require.NoError(t, f.chunkWritten(time.Now()))
+		}
+		require.Equal(t, 0, cs.writes)
+		require.NoError(t, f.chunkWritten(time.Now().Add(time.Hour)))
+		require.Equal(t, 1, cs.writes)
+		require.Equal(t, 3*(5+len(payload)), cs.buf.Len())
+	})
+	t.Run("close flushes", func(t *testing.T) {
+		cs := &countingStream{}
+		f := newFlushingStream(cs, time.Hour)
+		writeTestChunk(t, f, payload)
+		require.NoError(t, f.chunkWritten(time.Now()))
+		require.Equal(t, 0, cs.buf.Len())
+		require.NoError(t, f.Close())
+		require.Equal(t, true, cs.closed)
+		require.Equal(t, 5+len(payload), cs.buf.Len())
+	})
+}
+
+// BenchmarkFlushingStream compares the number of writes reaching the stream for small and large responses,
+// with the different flush policies. Sidecars are ~128KiB encoded, before snappy compression.
+func BenchmarkFlushingStream(b *testing.B) {
+	payload := make([]byte, 128<<10)
+	for _, chunks := range []int{1, 6, 768} {
+		for _, interval := range []time.Duration{0, 10 * time.Millisecond, 100 * time.Millisecond} {
+			b.Run(fmt.Sprintf("chunks=%d/interval=%s", chunks, interval), func(b *testing.B) {
+				writes := 0
+				for i := 0; i < b.N; i++ {
+					cs := &countingStream{}
+					f := newFlushingStream(cs, interval)
+					for c := 0; c < chunks; c++ {
+						writeTestChunk(b, f, payload)
+						require.NoError(b, f.chunkWritten(time.Now()))
+					}
+					require.NoError(b, f.Close())
+					writes += cs.writes
+				}
+				b.ReportMetric(float64(writes)/float64(b.N), "writes/op")
+			})
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	ic "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	pb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/prysmaticlabs/prysm/v5/time/slots"
+	"github.com/sirupsen/logrus"
+)
+
+// blobServeSelfTestInterval is the time between blob serving self-tests. A self-test reads an epoch of sidecars from
+// blob storage, so they are run sparingly.
+const blobServeSelfTestInterval = 15 * time.Minute
+
+// Results of a blob serving self-test, used as the label of the rpc_blob_serve_self_test_total metric.
+const (
+	blobSelfTestOK      = "ok"
+	blobSelfTestRefused = "refused"
+	blobSelfTestFailed  = "failed"
+)
+
+// errBlobSelfTestRefused is returned by selfTestBlobRange when the node responds to its own request with
+// responseCodeResourceUnavailable, for instance while it is syncing.
+var errBlobSelfTestRefused = errors.New("blob serving self-test request was refused")
+
+// loopbackAddr is reported as both ends of a loopback connection.
+var loopbackAddr = ma.StringCast("/ip4/127.0.0.1/tcp/0")
+
+// loopbackStream is one end of an in-memory stream, that lets the node send a request to its own handlers without
+// going through the network. Methods that don't apply to an in-memory stream, like deadlines, are no-ops.
+type loopbackStream struct {
+	r      io.Reader
+	w      io.Writer
+	closer io.Closer
+	proto  protocol.ID
+	conn   loopbackConn
+	// code is the first byte read from the stream, which for the client end is the response code of the first chunk.
+	code     byte
+	readCode bool
+}
+
+var _ network.Stream = (*loopbackStream)(nil)
+
+// newLoopbackStreams returns the server and client ends of a loopback stream for the protocol. Whatever the server
+// writes can be read by the client, until the server closes its end.
+func newLoopbackStreams(pid peer.ID, proto protocol.ID) (server, client *loopbackStream) {
+	pr, pw := io.Pipe()
+	conn := loopbackConn{pid: pid}
+	server = &loopbackStream{r: strings.NewReader(""), w: pw, closer: pw, proto: proto, conn: conn}
+	// Closing the client end makes the writes of the server fail, instead of blocking once the client stops reading.
+	client = &loopbackStream{r: pr, w: io.Discard, closer: pr, proto: proto, conn: conn}
+	return server, client
+}
+
+func (s *loopbackStream) Read(b []byte) (int, error) {
+	n, err := s.r.Read(b)
+	if n > 0 && !s.readCode {
+		s.code, s.readCode = b[0], true
+	}
+	return n, err
+}
+
+// responseCode returns the response code of the first chunk read from the stream, if any has been read.
+func (s *loopbackStream) responseCode() (byte, bool) {
+	return s.code, s.readCode
+}
+
+func (s *loopbackStream) Write(b []byte) (int, error) {
+	return s.w.Write(b)
+}
+
+func (s *loopbackStream) Close() error {
+	return s.closer.Close()
+}
+
+func (s *loopbackStream) CloseWrite() error {
+	return s.closer.Close()
+}
+
+func (*loopbackStream) CloseRead() error {
+	return nil
+}
+
+func (s *loopbackStream) Reset() error {
+	return s.closer.Close()
+}
+
+func (*loopbackStream) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (*loopbackStream) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (*loopbackStream) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+func (*loopbackStream) ID() string {
+	return "loopback"
+}
+
+func (s *loopbackStream) Protocol() protocol.ID {
+	return s.proto
+}
+
+func (s *loopbackStream) SetProtocol(id protocol.ID) error {
+	s.proto = id
+	return nil
+}
+
+func (*loopbackStream) Stat() network.Stats {
+	return network.Stats{Direction: network.DirInbound}
+}
+
+func (s *loopbackStream) Conn() network.Conn {
+	return s.conn
+}
+
+func (*loopbackStream) Scope() network.StreamScope {
+	return &network.NullScope{}
+}
+
+// loopbackConn reports the node itself as the remote peer, so that the handlers rate limit the self-test like any other
+// peer. The node does not score itself or count the self-test in the per-peer serving metrics, see fromSelf.
+type loopbackConn struct {
+	pid peer.ID
+}
+
+var _ network.Conn = loopbackConn{}
+
+func (loopbackConn) Close() error {
+	return nil
+}
+
+func (c loopbackConn) LocalPeer() peer.ID {
+	return c.pid
+}
+
+func (c loopbackConn) RemotePeer() peer.ID {
+	return c.pid
+}
+
+func (loopbackConn) RemotePublicKey() ic.PubKey {
+	return nil
+}
+
+func (loopbackConn) ConnState() network.ConnectionState {
+	return network.ConnectionState{}
+}
+
+func (loopbackConn) LocalMultiaddr() ma.Multiaddr {
+	return loopbackAddr
+}
+
+func (loopbackConn) RemoteMultiaddr() ma.Multiaddr {
+	return loopbackAddr
+}
+
+func (loopbackConn) Stat() network.ConnStats {
+	return network.ConnStats{Stats: network.Stats{Direction: network.DirInbound}, NumStreams: 1}
+}
+
+func (loopbackConn) Scope() network.ConnScope {
+	return &network.NullScope{}
+}
+
+func (loopbackConn) ID() string {
+	return "loopback"
+}
+
+func (loopbackConn) NewStream(context.Context) (network.Stream, error) {
+	return nil, errors.New("loopback connections do not support new streams")
+}
+
+func (loopbackConn) GetStreams() []network.Stream {
+	return nil
+}
+
+func (loopbackConn) IsClosed() bool {
+	return false
+}
+
+// fromSelf reports whether the request on the stream was sent by the node itself, by the blob serving self-test.
+// Such requests are not held against the peer score, and are not counted as sidecars served to peers.
+func fromSelf(p p2p.P2P, stream network.Stream) bool {
+	return stream.Conn().RemotePeer() == p.PeerID()
+}
+
+// runBlobServeSelfTest periodically requests the sidecars of the latest epoch from the node itself, see
+// blobServeSelfTest.
+func (s *Service) runBlobServeSelfTest() {
+	ticker := time.NewTicker(blobServeSelfTestInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.blobServeSelfTest(s.ctx)
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// blobServeSelfTest requests the blob sidecars of the epoch up to the head from the node itself, over a loopback
+// stream, and checks that the response decodes and is valid for the request. This exercises the same encoding, fork
+// digest and rate limiting as a request from a peer, so that a node that can't serve blobs is noticed before peers
+// complain. Requests that the node refuses to serve, for instance while it is syncing, are not counted as failures.
+func (s *Service) blobServeSelfTest(ctx context.Context) {
+	head := s.cfg.chain.HeadSlot()
+	if slots.ToEpoch(head) < params.BeaconConfig().DenebForkEpoch {
+		return
+	}
+	minSlot, err := BlobRPCMinValidSlot(s.cfg.clock.CurrentSlot())
+	if err != nil {
+		log.WithError(err).Debug("Skipping blob serving self-test, could not compute the minimum blob request slot")
+		return
+	}
+	start := primitives.Slot(0)
+	if head >= params.BeaconConfig().SlotsPerEpoch {
+		start = head - params.BeaconConfig().SlotsPerEpoch + 1
+	}
+	if start < minSlot {
+		start = minSlot
+	}
+	req := &pb.BlobSidecarsByRangeRequest{StartSlot: start, Count: uint64(head - start + 1)}
+	n, err := s.selfTestBlobRange(ctx, req)
+	result := blobSelfTestOK
+	if err != nil {
+		result = blobSelfTestFailed
+		if errors.Is(err, errBlobSelfTestRefused) {
+			result = blobSelfTestRefused
+		}
+	}
+	blobServeSelfTests.WithLabelValues(result).Inc()
+	fields := logrus.Fields{"startSlot": req.StartSlot, "count": req.Count, "sidecars": n}
+	switch result {
+	case blobSelfTestFailed:
+		blobServeSelfTestFailing.Set(1)
+		log.WithError(err).WithFields(fields).Error("Blob serving self-test failed, peers may not be able to download blob sidecars from this node")
+	case blobSelfTestRefused:
+		log.WithError(err).WithFields(fields).Debug("Blob serving self-test request was refused")
+	default:
+		blobServeSelfTestFailing.Set(0)
+		log.WithFields(fields).Debug("Blob serving self-test succeeded")
+	}
+}
+
+// selfTestBlobRange serves the request to the node itself, and returns the number of sidecars in the response.
+func (s *Service) selfTestBlobRange(ctx context.Context, req *pb.BlobSidecarsByRangeRequest) (int, error) {
+	ctxMap, err := ContextByteVersionsForValRoot(s.cfg.clock.GenesisValidatorsRoot())
+	if err != nil {
+		return 0, errors.Wrap(err, "could not compute context bytes for the self-test response")
+	}
+	proto := protocol.ID(p2p.RPCBlobSidecarsByRangeTopicV1 + s.cfg.p2p.Encoding().ProtocolSuffix())
+	server, client := newLoopbackStreams(s.cfg.p2p.PeerID(), proto)
+	served := make(chan error, 1)
+	go func() {
+		err := s.blobSidecarsByRangeRPCHandler(ctx, req, server)
+		// The handler closes the stream when the response is complete, but not on every error.
+		if cerr := server.Close(); cerr != nil {
+			log.WithError(cerr).Debug("Could not close blob serving self-test stream")
+		}
+		served <- err
+	}()
+	scs, err := readChunkEncodedBlobs(client, s.cfg.p2p.Encoding(), ctxMap, blobValidatorFromRangeReq(req), params.BeaconConfig().MaxRequestBlobSidecars)
+	// If the response could not be read, the handler may still be writing to the stream.
+	_ = client.Close()
+	if serr := <-served; serr != nil && err == nil {
+		err = serr
+	}
+	if code, ok := client.responseCode(); err != nil && ok && code == responseCodeResourceUnavailable {
+		err = errors.Wrap(errBlobSelfTestRefused, err.Error())
+	}
+	return len(scs), err
+}
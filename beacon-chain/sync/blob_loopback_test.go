@@ -0,0 +1,75 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/pkg/errors"
+	p2ptypes "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/types"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
+	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	pb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestSelfTestBlobRange(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	c := &blobsTestCase{nblocks: 3}
+	c.oldestSlot = c.defaultOldestSlotByRange
+	s, sidecars, cleanup := c.setup(t)
+	defer cleanup()
+	scs, err := verification.BlobSidecarSliceNoop(sidecars)
+	require.NoError(t, err)
+	for i := range scs {
+		require.NoError(t, s.cfg.blobStorage.Save(scs[i]))
+	}
+
+	req := &pb.BlobSidecarsByRangeRequest{StartSlot: sidecars[0].Slot(), Count: uint64(c.nblocks)}
+	n, err := s.selfTestBlobRange(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, c.nblocks*fieldparams.MaxBlobsPerBlock, n)
+
+	// The self-test is rate limited like a request from any other peer, and the first request used up the capacity
+	// of the test collector, so the handler rejects the second one.
+	_, err = s.selfTestBlobRange(context.Background(), req)
+	require.ErrorContains(t, p2ptypes.ErrRateLimited.Error(), err)
+	require.Equal(t, false, errors.Is(err, errBlobSelfTestRefused))
+	// The node does not score itself for exceeding the limit, and the self-test is not counted as served to a peer.
+	self := s.cfg.p2p.PeerID()
+	_, err = s.cfg.p2p.Peers().Scorers().BadResponsesScorer().Count(self)
+	require.NotNil(t, err, "the node has a bad responses score for itself")
+	_, tracked := blobServeStats.get(self)
+	require.Equal(t, false, tracked)
+
+	// Once the service is stopping, requests are refused with responseCodeResourceUnavailable.
+	require.Equal(t, true, s.blobServes.drain(0))
+	_, err = s.selfTestBlobRange(context.Background(), req)
+	require.ErrorIs(t, err, errBlobSelfTestRefused)
+}
+
+func TestLoopbackStream(t *testing.T) {
+	server, client := newLoopbackStreams("self", "/test")
+	require.Equal(t, peer.ID("self"), client.Conn().RemotePeer())
+	require.NotNil(t, client.Conn().RemoteMultiaddr())
+	require.NotNil(t, client.Scope())
+	_, ok := client.responseCode()
+	require.Equal(t, false, ok)
+	go func() {
+		_, err := server.Write([]byte{responseCodeResourceUnavailable, 1})
+		require.NoError(t, err)
+		require.NoError(t, server.Close())
+	}()
+	b, err := io.ReadAll(client)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(b))
+	code, ok := client.responseCode()
+	require.Equal(t, true, ok)
+	require.Equal(t, responseCodeResourceUnavailable, code)
+}
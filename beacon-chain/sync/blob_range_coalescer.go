@@ -0,0 +1,132 @@
+package sync
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"golang.org/x/sync/singleflight"
+)
+
+// blobRangeKey identifies a read of the blob sidecars with the given indices for a range of count slots beginning
+// at start. Reads with the same key that are in flight at the same time are coalesced, see blobRangeCoalescer.
+type blobRangeKey struct {
+	start   primitives.Slot
+	count   uint64
+	indices [fieldparams.MaxBlobsPerBlock]bool
+}
+
+// allBlobIndices is the set of indices read for a range request, which asks for every sidecar of each block.
+var allBlobIndices = func() [fieldparams.MaxBlobsPerBlock]bool {
+	var idxs [fieldparams.MaxBlobsPerBlock]bool
+	for i := range idxs {
+		idxs[i] = true
+	}
+	return idxs
+}()
+
+func (k blobRangeKey) String() string {
+	var mask uint64
+	for i := range k.indices {
+		if k.indices[i] {
+			mask |= 1 << i
+		}
+	}
+	return fmt.Sprintf("%d/%d/%x", k.start, k.count, mask)
+}
+
+// blobRangeSidecars holds the sidecars read for a range, by block root.
+type blobRangeSidecars map[[32]byte][]blocks.VerifiedROBlob
+
+// blobRangeCoalescer lets concurrent requests for the same range of blob sidecars share a single pass over blob
+// storage. This avoids reading and decoding the same sidecars once for every peer when many peers ask for the same
+// range at the same time. The zero value is ready to use, and only shares reads that are in flight at the same time.
+type blobRangeCoalescer struct {
+	group singleflight.Group
+	// window is how long the result of a read is also shared with requests that arrive after it completed. At most
+	// maxRecent results are kept, since each one holds a batch of sidecars in memory.
+	window    time.Duration
+	maxRecent int
+	mu        sync.Mutex
+	recent    map[string]recentBlobRange
+}
+
+type recentBlobRange struct {
+	sidecars blobRangeSidecars
+	expires  time.Time
+}
+
+// read calls fn to read the sidecars for the key, unless a read for the same key is already in flight, in which case
+// it waits for that read and returns its result, or a read for the key completed within the coalescing window.
+// The result is shared between the callers and must not be modified.
+func (c *blobRangeCoalescer) read(key blobRangeKey, now time.Time, fn func() (blobRangeSidecars, error)) (blobRangeSidecars, error) {
+	k := key.String()
+	if sc, ok := c.cached(k, now); ok {
+		blobRangeReadsCached.Inc()
+		return sc, nil
+	}
+	v, err, shared := c.group.Do(k, func() (interface{}, error) {
+		blobRangeReads.Inc()
+		sc, err := fn()
+		if err == nil {
+			c.remember(k, sc, now)
+		}
+		return sc, err
+	})
+	if shared {
+		blobRangeReadsCoalesced.Inc()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v.(blobRangeSidecars), nil
+}
+
+func (c *blobRangeCoalescer) cached(k string, now time.Time) (blobRangeSidecars, bool) {
+	if c.window <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.recent[k]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(r.expires) {
+		delete(c.recent, k)
+		return nil, false
+	}
+	return r.sidecars, true
+}
+
+// remember keeps the result of a read for the coalescing window. Expired results are dropped first, then if there
+// is still no room, the result that would expire soonest is evicted.
+func (c *blobRangeCoalescer) remember(k string, sc blobRangeSidecars, now time.Time) {
+	if c.window <= 0 || c.maxRecent <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.recent == nil {
+		c.recent = make(map[string]recentBlobRange, c.maxRecent)
+	}
+	for rk, r := range c.recent {
+		if !now.Before(r.expires) {
+			delete(c.recent, rk)
+		}
+	}
+	for len(c.recent) >= c.maxRecent {
+		var oldest string
+		var expires time.Time
+		for rk, r := range c.recent {
+			if oldest == "" || r.expires.Before(expires) {
+				oldest, expires = rk, r.expires
+			}
+		}
+		delete(c.recent, oldest)
+	}
+	c.recent[k] = recentBlobRange{sidecars: sc, expires: now.Add(c.window)}
+}
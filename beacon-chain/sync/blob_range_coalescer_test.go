@@ -0,0 +1,128 @@
+package sync
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestBlobRangeKey(t *testing.T) {
+	a := blobRangeKey{start: 10, count: 5, indices: allBlobIndices}
+	require.Equal(t, a.String(), blobRangeKey{start: 10, count: 5, indices: allBlobIndices}.String())
+	require.NotEqual(t, a.String(), blobRangeKey{start: 10, count: 6, indices: allBlobIndices}.String())
+	require.NotEqual(t, a.String(), blobRangeKey{start: 11, count: 5, indices: allBlobIndices}.String())
+	var first [fieldparams.MaxBlobsPerBlock]bool
+	first[0] = true
+	require.NotEqual(t, a.String(), blobRangeKey{start: 10, count: 5, indices: first}.String())
+}
+
+func TestBlobRangeCoalescer(t *testing.T) {
+	c := &blobRangeCoalescer{}
+	key := blobRangeKey{start: 10, count: 5, indices: allBlobIndices}
+	release := make(chan struct{})
+	var reads atomic.Int32
+	want := blobRangeSidecars{{1}: []blocks.VerifiedROBlob{{}}}
+	read := func() (blobRangeSidecars, error) {
+		reads.Add(1)
+		<-release
+		return want, nil
+	}
+
+	n := 4
+	var started, done sync.WaitGroup
+	results := make([]blobRangeSidecars, n)
+	started.Add(n)
+	done.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			got, err := c.read(key, time.Now(), read)
+			require.NoError(t, err)
+			results[i] = got
+		}(i)
+	}
+	started.Wait()
+	// Wait for the first read to be in flight, and give the other callers time to join it, before letting it finish.
+	for reads.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	done.Wait()
+	require.Equal(t, int32(1), reads.Load())
+	for i := range results {
+		require.Equal(t, 1, len(results[i][[32]byte{1}]))
+	}
+
+	// Once a read completes, the next read for the key goes to storage again.
+	release = make(chan struct{})
+	close(release)
+	_, err := c.read(key, time.Now(), read)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), reads.Load())
+
+	// Errors are returned to every caller.
+	readErr := errors.New("read failed")
+	_, err = c.read(key, time.Now(), func() (blobRangeSidecars, error) { return nil, readErr })
+	require.ErrorIs(t, err, readErr)
+}
+
+func TestBlobRangeCoalescerWindow(t *testing.T) {
+	c := &blobRangeCoalescer{window: 2 * time.Second, maxRecent: 2}
+	var reads int
+	read := func() (blobRangeSidecars, error) {
+		reads++
+		return blobRangeSidecars{}, nil
+	}
+	keyAt := func(start primitives.Slot) blobRangeKey {
+		return blobRangeKey{start: start, count: 5, indices: allBlobIndices}
+	}
+	storageReads := testutil.ToFloat64(blobRangeReads)
+	cachedReads := testutil.ToFloat64(blobRangeReadsCached)
+
+	now := time.Now()
+	_, err := c.read(keyAt(10), now, read)
+	require.NoError(t, err)
+	// A request for the same range within the window uses the completed read.
+	_, err = c.read(keyAt(10), now.Add(time.Second), read)
+	require.NoError(t, err)
+	require.Equal(t, 1, reads)
+	require.Equal(t, storageReads+1, testutil.ToFloat64(blobRangeReads))
+	require.Equal(t, cachedReads+1, testutil.ToFloat64(blobRangeReadsCached))
+
+	// After the window, the range is read again.
+	_, err = c.read(keyAt(10), now.Add(2*time.Second), read)
+	require.NoError(t, err)
+	require.Equal(t, 2, reads)
+
+	// Only maxRecent reads are kept, the one expiring soonest is evicted to make room.
+	now = now.Add(2 * time.Second)
+	_, err = c.read(keyAt(20), now.Add(time.Millisecond), read)
+	require.NoError(t, err)
+	_, err = c.read(keyAt(30), now.Add(2*time.Millisecond), read)
+	require.NoError(t, err)
+	require.Equal(t, 4, reads)
+	require.Equal(t, 2, len(c.recent))
+	_, err = c.read(keyAt(10), now.Add(3*time.Millisecond), read)
+	require.NoError(t, err)
+	require.Equal(t, 5, reads)
+	_, err = c.read(keyAt(30), now.Add(4*time.Millisecond), read)
+	require.NoError(t, err)
+	require.Equal(t, 5, reads)
+
+	// Failed reads are not kept.
+	readErr := errors.New("read failed")
+	_, err = c.read(keyAt(40), now, func() (blobRangeSidecars, error) { return nil, readErr })
+	require.ErrorIs(t, err, readErr)
+	_, ok := c.cached(keyAt(40).String(), now)
+	require.Equal(t, false, ok)
+}
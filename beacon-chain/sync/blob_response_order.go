@@ -0,0 +1,58 @@
+package sync
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+)
+
+var errBlobResponseOrder = errors.New("blob sidecar is out of order in BlobSidecarsByRange response")
+
+// blobResponseOrder enforces the ordering that the spec requires of a BlobSidecarsByRange response: every sidecar for
+// slot N, in ascending index order, is sent before any sidecar for slot N+1. The batcher reads blocks in slot order
+// and readBlockSidecars sorts the sidecars of each block by index, so a sidecar that fails the check indicates a bug
+// rather than a bad peer, and the response is ended instead of sending sidecars the peer will reject.
+// When reverse is set, as for BlobSidecarsByRangeReverse, slots must instead be in descending order, while the
+// sidecars of each slot are still in ascending index order.
+type blobResponseOrder struct {
+	reverse bool
+	slot    primitives.Slot
+	index   uint64
+	started bool
+}
+
+// next checks that the sidecar can be written after the last sidecar accepted by next, and records it if so.
+func (o *blobResponseOrder) next(sc blocks.ROBlob) error {
+	slot, index := sc.Slot(), sc.Index
+	backwards := slot < o.slot
+	if o.reverse {
+		backwards = slot > o.slot
+	}
+	if o.started && (backwards || (slot == o.slot && index <= o.index)) {
+		return errors.Wrapf(errBlobResponseOrder, "slot=%d index=%d written after slot=%d index=%d", slot, index, o.slot, o.index)
+	}
+	o.slot, o.index, o.started = slot, index, true
+	return nil
+}
+
+// ordered returns the canonical blocks of the batch in the order that their sidecars are written.
+func (o *blobResponseOrder) ordered(batch blockBatch) []blocks.ROBlock {
+	canonical := batch.canonical()
+	if !o.reverse {
+		return canonical
+	}
+	rev := make([]blocks.ROBlock, len(canonical))
+	for i := range canonical {
+		rev[len(canonical)-1-i] = canonical[i]
+	}
+	return rev
+}
+
+// sortBlobsByIndex sorts the sidecars of a single block by index.
+func sortBlobsByIndex(scs []blocks.VerifiedROBlob) {
+	sort.SliceStable(scs, func(i, j int) bool {
+		return scs[i].Index < scs[j].Index
+	})
+}
@@ -0,0 +1,67 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/prysmaticlabs/prysm/v5/testing/util"
+)
+
+func TestBlobResponseOrder(t *testing.T) {
+	_, first := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, 10, 3)
+	_, second := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, 11, 2)
+
+	o := &blobResponseOrder{}
+	for _, sc := range append(first, second...) {
+		require.NoError(t, o.next(sc))
+	}
+	// Repeating the last sidecar, going back an index, or going back a slot are all rejected.
+	require.ErrorIs(t, o.next(second[1]), errBlobResponseOrder)
+	require.ErrorIs(t, o.next(second[0]), errBlobResponseOrder)
+	require.ErrorIs(t, o.next(first[2]), errBlobResponseOrder)
+
+	// A gap in the indices of a slot is allowed, eg when a sidecar is skipped.
+	o = &blobResponseOrder{}
+	require.NoError(t, o.next(first[0]))
+	require.NoError(t, o.next(first[2]))
+}
+
+func TestBlobResponseOrderReverse(t *testing.T) {
+	_, first := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, 10, 3)
+	_, second := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, 11, 2)
+
+	o := &blobResponseOrder{reverse: true}
+	for _, sc := range append(second, first...) {
+		require.NoError(t, o.next(sc))
+	}
+	// Going forward a slot is rejected, and so is going back an index within a slot.
+	require.ErrorIs(t, o.next(second[0]), errBlobResponseOrder)
+	require.ErrorIs(t, o.next(first[1]), errBlobResponseOrder)
+}
+
+func TestBlobResponseOrderBlocks(t *testing.T) {
+	low, _ := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, 10, 0)
+	high, _ := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, 11, 0)
+	batch := blockBatch{lin: []blocks.ROBlock{low, high}}
+	fwd := (&blobResponseOrder{}).ordered(batch)
+	require.Equal(t, low.Root(), fwd[0].Root())
+	require.Equal(t, high.Root(), fwd[1].Root())
+	rev := (&blobResponseOrder{reverse: true}).ordered(batch)
+	require.Equal(t, high.Root(), rev[0].Root())
+	require.Equal(t, low.Root(), rev[1].Root())
+	// The batch is not modified.
+	require.Equal(t, low.Root(), batch.lin[0].Root())
+}
+
+func TestSortBlobsByIndex(t *testing.T) {
+	_, scs := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, 10, 4)
+	vscs := make([]blocks.VerifiedROBlob, 0, len(scs))
+	for i := len(scs) - 1; i >= 0; i-- {
+		vscs = append(vscs, blocks.NewVerifiedROBlob(scs[i]))
+	}
+	sortBlobsByIndex(vscs)
+	for i := range vscs {
+		require.Equal(t, uint64(i), vscs[i].Index)
+	}
+}
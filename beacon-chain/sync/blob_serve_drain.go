@@ -0,0 +1,73 @@
+package sync
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// errBlobServeDraining is returned when a blob sidecar range response is ended early because the service is stopping.
+var errBlobServeDraining = errors.New("blob sidecar serving is draining for shutdown")
+
+// blobServeDrain tracks the BlobSidecarsByRange responses that are being written, so that Stop can let them finish
+// the chunk they are writing and close their streams, rather than leaving peers with a reset stream when the host
+// shuts down. The zero value is ready to use.
+type blobServeDrain struct {
+	sync.Mutex
+	active   int
+	draining bool
+	idle     chan struct{}
+}
+
+// begin registers a response that is about to be written. It returns false if the service is stopping, in which
+// case the response must not be started. Every call that returns true must be followed by a call to done.
+func (d *blobServeDrain) begin() bool {
+	d.Lock()
+	defer d.Unlock()
+	if d.draining {
+		return false
+	}
+	d.active += 1
+	return true
+}
+
+// done marks a response registered with begin as finished.
+func (d *blobServeDrain) done() {
+	d.Lock()
+	defer d.Unlock()
+	d.active -= 1
+	if d.draining && d.active == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
+}
+
+// stopping returns true once drain has been called. Responses check it between chunks, and end early when it is set.
+func (d *blobServeDrain) stopping() bool {
+	d.Lock()
+	defer d.Unlock()
+	return d.draining
+}
+
+// drain stops new responses from starting, and waits up to timeout for the responses in flight to end. It returns
+// false if responses were still being written when the timeout elapsed.
+func (d *blobServeDrain) drain(timeout time.Duration) bool {
+	d.Lock()
+	d.draining = true
+	if d.active == 0 {
+		d.Unlock()
+		return true
+	}
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+	}
+	idle := d.idle
+	d.Unlock()
+	select {
+	case <-idle:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
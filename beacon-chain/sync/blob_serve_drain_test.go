@@ -0,0 +1,31 @@
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestBlobServeDrain(t *testing.T) {
+	var d blobServeDrain
+	require.Equal(t, true, d.begin())
+	require.Equal(t, false, d.stopping())
+
+	// The response in flight holds up the drain until the timeout.
+	require.Equal(t, false, d.drain(10*time.Millisecond))
+	require.Equal(t, true, d.stopping())
+	// No new responses start once the service is draining.
+	require.Equal(t, false, d.begin())
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		d.done()
+	}()
+	require.Equal(t, true, d.drain(time.Second))
+}
+
+func TestBlobServeDrain_Idle(t *testing.T) {
+	var d blobServeDrain
+	require.Equal(t, true, d.drain(0))
+}
@@ -0,0 +1,102 @@
+package sync
+
+import (
+	"sync"
+	"time"
+)
+
+// blobStoreBreakerWindow is the longest time between two blob storage errors that are counted as consecutive.
+var blobStoreBreakerWindow = time.Minute
+
+// States of blobStoreBreaker, as reported by the rpc_blob_store_breaker_state metric.
+const (
+	blobStoreBreakerClosed = iota
+	blobStoreBreakerOpen
+	blobStoreBreakerHalfOpen
+)
+
+// blobStoreBreaker is a circuit breaker for reads of blob storage by the BlobSidecarsByRange handler. When blob
+// storage is failing, for instance because the disk is full or corrupted, every request would otherwise read from it,
+// log an error and answer with a server error. After threshold consecutive errors, the breaker opens and requests are
+// answered as resource unavailable without reading blob storage, until the cooldown has passed. The next request is
+// then served as a trial: an error opens the breaker again, a successful read closes it. Other requests are refused
+// until the trial has finished. The zero value never opens, threshold and cooldown are set from flags, see initCaches.
+type blobStoreBreaker struct {
+	sync.Mutex
+	threshold   int
+	cooldown    time.Duration
+	state       int
+	failures    int
+	lastFailure time.Time
+	opened      time.Time
+	// trial is set while the trial request of a half-open breaker is being served.
+	trial bool
+}
+
+func noopBlobStoreRelease() {}
+
+// allow reports whether blob storage should be read for a request. It moves an open breaker to half-open once the
+// cooldown has passed, and lets exactly one trial request through while it is half-open. The returned func must be
+// called when the request is finished, so that a trial that ended without reading blob storage, for instance because
+// the range has no blobs, lets the next request be the trial.
+func (b *blobStoreBreaker) allow(now time.Time) (func(), bool) {
+	b.Lock()
+	defer b.Unlock()
+	switch b.state {
+	case blobStoreBreakerClosed:
+		return noopBlobStoreRelease, true
+	case blobStoreBreakerOpen:
+		if now.Sub(b.opened) < b.cooldown {
+			return nil, false
+		}
+		b.setState(blobStoreBreakerHalfOpen)
+	}
+	if b.trial {
+		return nil, false
+	}
+	b.trial = true
+	return b.endTrial, true
+}
+
+func (b *blobStoreBreaker) endTrial() {
+	b.Lock()
+	defer b.Unlock()
+	b.trial = false
+}
+
+// failure records an error reading blob storage.
+func (b *blobStoreBreaker) failure(now time.Time) {
+	b.Lock()
+	defer b.Unlock()
+	if b.threshold <= 0 {
+		return
+	}
+	if now.Sub(b.lastFailure) > blobStoreBreakerWindow {
+		b.failures = 0
+	}
+	b.failures++
+	b.lastFailure = now
+	if b.state == blobStoreBreakerHalfOpen || (b.state == blobStoreBreakerClosed && b.failures >= b.threshold) {
+		b.opened = now
+		b.setState(blobStoreBreakerOpen)
+		log.WithField("failures", b.failures).WithField("cooldown", b.cooldown).
+			Error("Not serving blob sidecar range requests after repeated blob storage errors")
+	}
+}
+
+// success records a successful read of blob storage, which closes the breaker.
+func (b *blobStoreBreaker) success() {
+	b.Lock()
+	defer b.Unlock()
+	b.failures = 0
+	if b.state == blobStoreBreakerClosed {
+		return
+	}
+	b.setState(blobStoreBreakerClosed)
+	log.Info("Resuming blob sidecar range serving after blob storage recovered")
+}
+
+func (b *blobStoreBreaker) setState(state int) {
+	b.state = state
+	blobStoreBreakerState.Set(float64(state))
+}
@@ -0,0 +1,73 @@
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func breakerAllows(b *blobStoreBreaker, now time.Time) bool {
+	_, ok := b.allow(now)
+	return ok
+}
+
+func TestBlobStoreBreaker(t *testing.T) {
+	now := time.Now()
+	t.Run("disabled", func(t *testing.T) {
+		b := &blobStoreBreaker{}
+		for i := 0; i < 10; i++ {
+			b.failure(now)
+		}
+		require.Equal(t, true, breakerAllows(b, now))
+	})
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		b := &blobStoreBreaker{threshold: 3, cooldown: 30 * time.Second}
+		b.failure(now)
+		b.failure(now.Add(time.Second))
+		require.Equal(t, true, breakerAllows(b, now.Add(time.Second)))
+		b.failure(now.Add(2 * time.Second))
+		require.Equal(t, false, breakerAllows(b, now.Add(3*time.Second)))
+		require.Equal(t, float64(blobStoreBreakerOpen), testutil.ToFloat64(blobStoreBreakerState))
+	})
+	t.Run("success resets the count", func(t *testing.T) {
+		b := &blobStoreBreaker{threshold: 2, cooldown: 30 * time.Second}
+		b.failure(now)
+		b.success()
+		b.failure(now.Add(time.Second))
+		require.Equal(t, true, breakerAllows(b, now.Add(time.Second)))
+	})
+	t.Run("failures outside the window are not consecutive", func(t *testing.T) {
+		b := &blobStoreBreaker{threshold: 2, cooldown: 30 * time.Second}
+		b.failure(now)
+		b.failure(now.Add(blobStoreBreakerWindow + time.Second))
+		require.Equal(t, true, breakerAllows(b, now.Add(blobStoreBreakerWindow+time.Second)))
+	})
+	t.Run("half-open after cooldown", func(t *testing.T) {
+		b := &blobStoreBreaker{threshold: 1, cooldown: 30 * time.Second}
+		b.failure(now)
+		require.Equal(t, false, breakerAllows(b, now.Add(29*time.Second)))
+		// The trial request fails and the breaker opens again.
+		release, ok := b.allow(now.Add(30 * time.Second))
+		require.Equal(t, true, ok)
+		require.Equal(t, float64(blobStoreBreakerHalfOpen), testutil.ToFloat64(blobStoreBreakerState))
+		// Only one trial is let through at a time.
+		require.Equal(t, false, breakerAllows(b, now.Add(30*time.Second)))
+		b.failure(now.Add(31 * time.Second))
+		release()
+		require.Equal(t, false, breakerAllows(b, now.Add(32*time.Second)))
+		// A trial that ends without reading blob storage lets the next request be the trial.
+		release, ok = b.allow(now.Add(61 * time.Second))
+		require.Equal(t, true, ok)
+		require.Equal(t, false, breakerAllows(b, now.Add(61*time.Second)))
+		release()
+		// The next trial succeeds and the breaker closes.
+		release, ok = b.allow(now.Add(61 * time.Second))
+		require.Equal(t, true, ok)
+		b.success()
+		release()
+		require.Equal(t, float64(blobStoreBreakerClosed), testutil.ToFloat64(blobStoreBreakerState))
+		require.Equal(t, true, breakerAllows(b, now.Add(62*time.Second)))
+	})
+}
@@ -4,32 +4,42 @@ import (
 	"context"
 	"fmt"
 	"sort"
-	"time"
 
 	libp2pcore "github.com/libp2p/go-libp2p/core"
 	"github.com/pkg/errors"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/filters"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/backfill/coverage"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 )
 
+// slotSeeker returns the lowest slot >= from that a blockRangeBatcher needs to read, and false if there is nothing
+// left to read at or after from.
+type slotSeeker func(from primitives.Slot) (primitives.Slot, bool)
+
 // blockRangeBatcher encapsulates the logic for splitting up a block range request into fixed-size batches of
 // blocks that are retrieved from the database, ensured to be canonical, sequential and unique.
-// If a non-nil value for ticker is set, it will be used to pause between batches lookups, as a rate-limiter.
+// If a non-nil value for throttle is set, it will be used to pause between batches lookups, as a rate-limiter.
 type blockRangeBatcher struct {
-	start   primitives.Slot
-	end     primitives.Slot
-	size    uint64
-	db      db.NoHeadAccessDatabase
-	limiter *limiter
-	ticker  *time.Ticker
+	start    primitives.Slot
+	end      primitives.Slot
+	size     uint64
+	db       db.NoHeadAccessDatabase
+	limiter  *limiter
+	throttle *batchThrottle
+	// seek is optional. When set, the batcher skips ahead over slots that the seeker reports have nothing to read.
+	// It is not used when reverse is set.
+	seek slotSeeker
+	// reverse makes the batcher read batches from the end of the range down to the start. Blocks within each batch
+	// are still in ascending slot order.
+	reverse bool
 
 	cf      *canonicalFilter
 	current *blockBatch
 }
 
-func newBlockRangeBatcher(rp rangeParams, bdb db.NoHeadAccessDatabase, limiter *limiter, canonical canonicalChecker, ticker *time.Ticker) (*blockRangeBatcher, error) {
+func newBlockRangeBatcher(rp rangeParams, bdb db.NoHeadAccessDatabase, limiter *limiter, canonical canonicalChecker, throttle *batchThrottle) (*blockRangeBatcher, error) {
 	if bdb == nil {
 		return nil, errors.New("nil db param, unable to initialize blockRangeBatcher")
 	}
@@ -39,8 +49,8 @@ func newBlockRangeBatcher(rp rangeParams, bdb db.NoHeadAccessDatabase, limiter *
 	if canonical == nil {
 		return nil, errors.New("nil canonicalChecker param, unable to initialize blockRangeBatcher")
 	}
-	if ticker == nil {
-		return nil, errors.New("nil ticker param, unable to initialize blockRangeBatcher")
+	if throttle == nil {
+		return nil, errors.New("nil throttle param, unable to initialize blockRangeBatcher")
 	}
 	if rp.size == 0 {
 		return nil, fmt.Errorf("invalid batch size of %d", rp.size)
@@ -50,13 +60,13 @@ func newBlockRangeBatcher(rp rangeParams, bdb db.NoHeadAccessDatabase, limiter *
 	}
 	cf := &canonicalFilter{canonical: canonical}
 	return &blockRangeBatcher{
-		start:   rp.start,
-		end:     rp.end,
-		size:    rp.size,
-		db:      bdb,
-		limiter: limiter,
-		ticker:  ticker,
-		cf:      cf,
+		start:    rp.start,
+		end:      rp.end,
+		size:     rp.size,
+		db:       bdb,
+		limiter:  limiter,
+		throttle: throttle,
+		cf:       cf,
 	}, nil
 }
 
@@ -66,10 +76,13 @@ func (bb *blockRangeBatcher) next(ctx context.Context, stream libp2pcore.Stream)
 	// The result of each call to next() is saved in the `current` field.
 	// If current is not nil, current.next figures out the next batch based on the previous one.
 	// If current is nil, newBlockBatch is used to generate the first batch.
-	if bb.current != nil {
+	switch {
+	case bb.reverse:
+		nb, more = bb.nextReverse()
+	case bb.current != nil:
 		current := *bb.current
 		nb, more = current.next(bb.end, bb.size)
-	} else {
+	default:
 		nb, more = newBlockBatch(bb.start, bb.end, bb.size)
 	}
 	// newBlockBatch and next() both return a boolean to indicate whether calling .next() will yield another batch
@@ -78,47 +91,101 @@ func (bb *blockRangeBatcher) next(ctx context.Context, stream libp2pcore.Stream)
 	if !more {
 		return blockBatch{}, false
 	}
+	if bb.seek != nil && !bb.reverse {
+		if nb, more = bb.skipAhead(nb); !more {
+			return blockBatch{}, false
+		}
+	}
 	if err := bb.limiter.validateRequest(stream, bb.size); err != nil {
 		return blockBatch{err: errors.Wrap(err, "throttled by rate limiter")}, false
 	}
 
-	// Wait for the ticker before doing anything expensive, unless this is the first batch.
-	if bb.ticker != nil && bb.current != nil {
-		<-bb.ticker.C
+	// Wait for the throttle before doing anything expensive, unless this is the first batch.
+	if bb.throttle != nil && bb.current != nil {
+		bb.throttle.wait()
+	}
+	nb, err := readBlockBatch(ctx, bb.db, bb.cf, nb)
+	if err != nil {
+		return blockBatch{err: err}, false
+	}
+
+	// Decrease allowed blocks capacity by the number of streamed blocks.
+	bb.limiter.add(stream, int64(1+nb.end.SubSlot(nb.start)))
+	bb.current = &nb
+	return *bb.current, true
+}
+
+// skipAhead moves the batch forward to the next slot reported by the seeker. The canonical filter is reset when slots
+// are skipped, because the blocks in the skipped slots are not read, so the first block after the gap can't be
+// checked against its parent. Each block is still checked to be canonical.
+func (bb *blockRangeBatcher) skipAhead(nb blockBatch) (blockBatch, bool) {
+	next, ok := bb.seek(nb.start)
+	if !ok || next > bb.end {
+		return blockBatch{}, false
+	}
+	if next <= nb.start {
+		return nb, true
+	}
+	bb.cf.prevRoot = [32]byte{}
+	return newBlockBatch(next, bb.end, bb.size)
+}
+
+// nextReverse returns the batch below the current batch, or the batch at the end of the range if this is the first
+// batch. The canonical filter is reset for each batch, because it checks each block against the block read before it,
+// which is in the batch above when reading down. Each block is still checked to be canonical.
+func (bb *blockRangeBatcher) nextReverse() (blockBatch, bool) {
+	end := bb.end
+	if bb.current != nil {
+		current := *bb.current
+		if current.error() != nil || current.nonLinear() || current.start <= bb.start {
+			return blockBatch{}, false
+		}
+		end = current.start - 1
 	}
+	if end < bb.start {
+		return blockBatch{}, false
+	}
+	start := bb.start
+	if uint64(end-start) >= bb.size {
+		start = end - primitives.Slot(bb.size-1)
+	}
+	bb.cf.prevRoot = [32]byte{}
+	return blockBatch{start: start, end: end}, true
+}
+
+// readBlockBatch reads the blocks in the slot range of the given batch from the db, and uses the canonicalFilter to
+// split them into the linear canonical chain and any non-linear tail. Errors from the canonicalFilter are set on the
+// returned batch, while the returned error indicates that the blocks could not be read at all.
+func readBlockBatch(ctx context.Context, bdb db.NoHeadAccessDatabase, cf *canonicalFilter, nb blockBatch) (blockBatch, error) {
 	filter := filters.NewFilter().SetStartSlot(nb.start).SetEndSlot(nb.end)
-	blks, roots, err := bb.db.Blocks(ctx, filter)
+	blks, roots, err := bdb.Blocks(ctx, filter)
 	if err != nil {
-		return blockBatch{err: errors.Wrap(err, "Could not retrieve blocks")}, false
+		return nb, errors.Wrap(err, "Could not retrieve blocks")
 	}
 
 	rob := make([]blocks.ROBlock, 0)
 	if nb.start == 0 {
-		gb, err := bb.genesisBlock(ctx)
+		gb, err := genesisROBlock(ctx, bdb)
 		if err != nil {
-			return blockBatch{err: errors.Wrap(err, "could not retrieve genesis block")}, false
+			return nb, errors.Wrap(err, "could not retrieve genesis block")
 		}
 		rob = append(rob, gb)
 	}
 	for i := 0; i < len(blks); i++ {
 		rb, err := blocks.NewROBlockWithRoot(blks[i], roots[i])
 		if err != nil {
-			return blockBatch{err: errors.Wrap(err, "Could not initialize ROBlock")}, false
+			return nb, errors.Wrap(err, "Could not initialize ROBlock")
 		}
 		rob = append(rob, rb)
 	}
 
 	// Filter and sort our retrieved blocks, so that we only return valid sets of blocks.
-	nb.lin, nb.nonlin, nb.err = bb.cf.filter(ctx, rob)
-
-	// Decrease allowed blocks capacity by the number of streamed blocks.
-	bb.limiter.add(stream, int64(1+nb.end.SubSlot(nb.start)))
-	bb.current = &nb
-	return *bb.current, true
+	nb.lin, nb.nonlin, nb.err = cf.filter(ctx, rob)
+	return nb, nil
 }
 
-func (bb *blockRangeBatcher) genesisBlock(ctx context.Context) (blocks.ROBlock, error) {
-	b, err := bb.db.GenesisBlock(ctx)
+func genesisROBlock(ctx context.Context, bdb db.NoHeadAccessDatabase) (blocks.ROBlock, error) {
+	b, err := bdb.GenesisBlock(ctx)
 	if err != nil {
 		return blocks.ROBlock{}, err
 	}
@@ -137,6 +204,11 @@ type blockBatch struct {
 	err    error
 }
 
+// slotRange returns the slots from start to end as a half-open coverage.SlotRange; end is inclusive in blockBatch.
+func (bb blockBatch) slotRange() coverage.SlotRange {
+	return coverage.SlotRange{Start: bb.start, End: bb.end + 1}
+}
+
 func newBlockBatch(start, reqEnd primitives.Slot, size uint64) (blockBatch, bool) {
 	if start > reqEnd {
 		return blockBatch{}, false
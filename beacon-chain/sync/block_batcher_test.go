@@ -137,3 +137,84 @@ func TestZeroSizeNoOp(t *testing.T) {
 	_, more := newBlockBatch(12345, 12345, 0)
 	require.Equal(t, false, more)
 }
+
+func TestBlockRangeBatcherSkipAhead(t *testing.T) {
+	populated := []primitives.Slot{10, 40}
+	seek := func(from primitives.Slot) (primitives.Slot, bool) {
+		for _, sl := range populated {
+			if sl >= from {
+				return sl, true
+			}
+		}
+		return 0, false
+	}
+	bb := &blockRangeBatcher{start: 0, end: 50, size: 8, seek: seek, cf: &canonicalFilter{prevRoot: [32]byte{1}}}
+
+	nb, ok := newBlockBatch(0, bb.end, bb.size)
+	require.Equal(t, true, ok)
+	nb, ok = bb.skipAhead(nb)
+	require.Equal(t, true, ok)
+	require.Equal(t, primitives.Slot(10), nb.start)
+	require.Equal(t, primitives.Slot(17), nb.end)
+	// Blocks in the skipped slots are not read, so the parent of the next block can't be checked.
+	require.Equal(t, [32]byte{}, bb.cf.prevRoot)
+
+	// A batch that starts at a populated slot is unchanged.
+	bb.cf.prevRoot = [32]byte{1}
+	nb, ok = bb.skipAhead(nb)
+	require.Equal(t, true, ok)
+	require.Equal(t, primitives.Slot(10), nb.start)
+	require.Equal(t, [32]byte{1}, bb.cf.prevRoot)
+
+	nb, ok = newBlockBatch(18, bb.end, bb.size)
+	require.Equal(t, true, ok)
+	nb, ok = bb.skipAhead(nb)
+	require.Equal(t, true, ok)
+	require.Equal(t, primitives.Slot(40), nb.start)
+	require.Equal(t, primitives.Slot(47), nb.end)
+
+	// Nothing is left to read after the last populated slot.
+	nb, ok = newBlockBatch(48, bb.end, bb.size)
+	require.Equal(t, true, ok)
+	_, ok = bb.skipAhead(nb)
+	require.Equal(t, false, ok)
+
+	// A populated slot past the end of the range ends the iteration too.
+	bb.end = 30
+	nb, ok = newBlockBatch(18, bb.end, bb.size)
+	require.Equal(t, true, ok)
+	_, ok = bb.skipAhead(nb)
+	require.Equal(t, false, ok)
+}
+
+func TestBlockRangeBatcherNextReverse(t *testing.T) {
+	bb := &blockRangeBatcher{start: 3, end: 20, size: 8, reverse: true, cf: &canonicalFilter{prevRoot: [32]byte{1}}}
+	expected := []blockBatch{{start: 13, end: 20}, {start: 5, end: 12}, {start: 3, end: 4}}
+	for _, exp := range expected {
+		nb, ok := bb.nextReverse()
+		require.Equal(t, true, ok)
+		require.Equal(t, exp.start, nb.start)
+		require.Equal(t, exp.end, nb.end)
+		// Each batch is checked to be linear on its own.
+		require.Equal(t, [32]byte{}, bb.cf.prevRoot)
+		bb.cf.prevRoot = [32]byte{1}
+		bb.current = &nb
+	}
+	_, ok := bb.nextReverse()
+	require.Equal(t, false, ok)
+
+	// A break in the chain of blocks ends the iteration, like it does reading forward.
+	bb.current = &blockBatch{start: 13, end: 20, nonlin: []blocks.ROBlock{{}}}
+	_, ok = bb.nextReverse()
+	require.Equal(t, false, ok)
+
+	// A range that starts at genesis is read down to slot 0.
+	bb = &blockRangeBatcher{start: 0, end: 7, size: 8, reverse: true, cf: &canonicalFilter{}}
+	nb, ok := bb.nextReverse()
+	require.Equal(t, true, ok)
+	require.Equal(t, primitives.Slot(0), nb.start)
+	require.Equal(t, primitives.Slot(7), nb.end)
+	bb.current = &nb
+	_, ok = bb.nextReverse()
+	require.Equal(t, false, ok)
+}
@@ -0,0 +1,71 @@
+package sync
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/cmd/beacon-chain/flags"
+	"github.com/sirupsen/logrus"
+)
+
+// chunkFailureLogInterval is the shortest time between two log lines about failures to send a chunked response.
+var chunkFailureLogInterval = time.Minute
+
+// chunkFailures summarizes failures to write a chunk of a response, which usually mean that the peer disconnected
+// part way through the response. See chunkFailureLog.
+var chunkFailures = &chunkFailureLog{}
+
+// chunkFailureLog aggregates failures to send a chunked response, so that churny networks don't log a line for every
+// dropped response. The first failure is logged straight away, after that failures are counted and logged as a
+// summary at most once per chunkFailureLogInterval, either when the next failure occurs or when flush is called by
+// the sync service's ticker. The level of the log line is set by the --chunk-send-failure-log-level flag.
+type chunkFailureLog struct {
+	sync.Mutex
+	count  uint64
+	last   error
+	logged time.Time
+}
+
+// observe records a failure, and logs the failures counted since the last log line if the interval has passed.
+func (c *chunkFailureLog) observe(err error, now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	c.count++
+	c.last = err
+	if !c.logged.IsZero() && now.Sub(c.logged) < chunkFailureLogInterval {
+		return
+	}
+	c.logSummary(now)
+}
+
+// flush logs the failures counted since the last log line if the interval has passed, so that failures are reported
+// even if no more failures occur after them.
+func (c *chunkFailureLog) flush(now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	if c.count == 0 || now.Sub(c.logged) < chunkFailureLogInterval {
+		return
+	}
+	c.logSummary(now)
+}
+
+// logSummary logs the failures counted since the last log line and resets the count. It must be called while holding
+// the lock.
+func (c *chunkFailureLog) logSummary(now time.Time) {
+	fields := logrus.Fields{"failures": c.count}
+	if !c.logged.IsZero() {
+		fields["since"] = now.Sub(c.logged).Round(time.Second)
+	}
+	log.WithError(c.last).WithFields(fields).Log(chunkFailureLogLevel(), "Could not send chunked responses")
+	c.count, c.logged = 0, now
+}
+
+// chunkFailureLogLevel parses the --chunk-send-failure-log-level flag, defaulting to debug. Invalid values are rejected
+// when the flags are configured, so the default only applies when the flags were never configured.
+func chunkFailureLogLevel() logrus.Level {
+	lvl, err := logrus.ParseLevel(flags.Get().ChunkSendFailureLogLevel)
+	if err != nil {
+		return logrus.DebugLevel
+	}
+	return lvl
+}
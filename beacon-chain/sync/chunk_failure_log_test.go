@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/cmd/beacon-chain/flags"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/sirupsen/logrus"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestChunkFailureLog(t *testing.T) {
+	resetFlags := flags.Get()
+	gFlags := *resetFlags
+	gFlags.ChunkSendFailureLogLevel = "info"
+	flags.Init(&gFlags)
+	defer flags.Init(resetFlags)
+	hook := logTest.NewGlobal()
+
+	c := &chunkFailureLog{}
+	now := time.Now()
+	errReset := errors.New("stream reset")
+	// The first failure is logged straight away.
+	c.observe(errReset, now)
+	require.Equal(t, 1, len(hook.AllEntries()))
+	require.Equal(t, logrus.InfoLevel, hook.LastEntry().Level)
+	require.Equal(t, uint64(1), hook.LastEntry().Data["failures"])
+
+	// Failures within the interval are counted, not logged.
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Second)
+		c.observe(errReset, now)
+	}
+	require.Equal(t, 1, len(hook.AllEntries()))
+
+	now = now.Add(chunkFailureLogInterval)
+	c.observe(errReset, now)
+	require.Equal(t, 2, len(hook.AllEntries()))
+	require.Equal(t, uint64(11), hook.LastEntry().Data["failures"])
+
+	// Failures counted after the last log line are logged by flush once the interval has passed, even if no more
+	// failures occur.
+	now = now.Add(time.Second)
+	c.observe(errReset, now)
+	c.flush(now)
+	require.Equal(t, 2, len(hook.AllEntries()))
+	now = now.Add(chunkFailureLogInterval)
+	c.flush(now)
+	require.Equal(t, 3, len(hook.AllEntries()))
+	require.Equal(t, uint64(1), hook.LastEntry().Data["failures"])
+	// There is nothing left to log.
+	c.flush(now.Add(chunkFailureLogInterval))
+	require.Equal(t, 3, len(hook.AllEntries()))
+}
+
+func TestChunkFailureLogLevel(t *testing.T) {
+	resetFlags := flags.Get()
+	defer flags.Init(resetFlags)
+	gFlags := *resetFlags
+	for value, level := range map[string]logrus.Level{
+		"":        logrus.DebugLevel,
+		"bogus":   logrus.DebugLevel,
+		"warn":    logrus.WarnLevel,
+		"trace":   logrus.TraceLevel,
+		"info":    logrus.InfoLevel,
+		"debug":   logrus.DebugLevel,
+		"error":   logrus.ErrorLevel,
+		"warning": logrus.WarnLevel,
+	} {
+		gFlags.ChunkSendFailureLogLevel = value
+		flags.Init(&gFlags)
+		require.Equal(t, level, chunkFailureLogLevel(), value)
+	}
+}
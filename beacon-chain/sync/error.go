@@ -18,6 +18,10 @@ import (
 var ErrNoValidDigest = errors.New("no valid digest matched")
 var ErrUnrecognizedVersion = errors.New("cannot determine context bytes for unrecognized object")
 
+// ErrBlobForkSchedule indicates a blob sidecar could not be written because the fork schedule of the node has no
+// fork with blobs for the slot of the sidecar, which means the fork epochs in the chain config are wrong.
+var ErrBlobForkSchedule = errors.New("fork schedule has no blob fork for the slot of the blob sidecar, check the fork epochs of the chain config")
+
 var responseCodeSuccess = byte(0x00)
 var responseCodeInvalidRequest = byte(0x01)
 var responseCodeServerError = byte(0x02)
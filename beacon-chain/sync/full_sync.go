@@ -0,0 +1,43 @@
+package sync
+
+// FullSyncChecker distinguishes a node that has synced to the head of the chain from one that has also
+// downloaded the full history of the chain. Nodes that start from a checkpoint sync origin reach head well before
+// backfill has finished, so components that need historical data should gate on FullySynced.
+type FullSyncChecker interface {
+	HeadSynced() bool
+	FullySynced() bool
+}
+
+// HistoryChecker reports whether the node has downloaded the history of the chain before its checkpoint sync origin.
+// It is satisfied by backfill.Readiness.
+type HistoryChecker interface {
+	BackfillReady() bool
+}
+
+// FullSync combines a Checker for the head of the chain with a HistoryChecker for backfill.
+type FullSync struct {
+	head    Checker
+	history HistoryChecker
+}
+
+var _ FullSyncChecker = &FullSync{}
+
+// NewFullSync returns a FullSync that is head-synced when the given Checker reports that the node is synced,
+// and fully-synced when the HistoryChecker also reports that backfill is done. A nil HistoryChecker means
+// that the node is not tracking backfill, in which case reaching head is enough to be fully-synced.
+func NewFullSync(head Checker, history HistoryChecker) *FullSync {
+	return &FullSync{head: head, history: history}
+}
+
+// HeadSynced returns true when the node has synced to the head of the chain.
+func (f *FullSync) HeadSynced() bool {
+	return f.head.Synced()
+}
+
+// FullySynced returns true when the node has synced to the head of the chain and also holds its full history.
+func (f *FullSync) FullySynced() bool {
+	if !f.HeadSynced() {
+		return false
+	}
+	return f.history == nil || f.history.BackfillReady()
+}
@@ -0,0 +1,36 @@
+package sync
+
+import (
+	"testing"
+
+	mockSync "github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/initial-sync/testing"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+type mockHistory bool
+
+func (m mockHistory) BackfillReady() bool {
+	return bool(m)
+}
+
+func TestFullSync(t *testing.T) {
+	cases := []struct {
+		name    string
+		synced  bool
+		history HistoryChecker
+		head    bool
+		full    bool
+	}{
+		{name: "syncing, backfill done", synced: false, history: mockHistory(true), head: false, full: false},
+		{name: "synced, backfilling", synced: true, history: mockHistory(false), head: true, full: false},
+		{name: "synced, backfill done", synced: true, history: mockHistory(true), head: true, full: true},
+		{name: "synced, no backfill", synced: true, head: true, full: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f := NewFullSync(&mockSync.Sync{IsSynced: c.synced}, c.history)
+			require.Equal(t, c.head, f.HeadSynced())
+			require.Equal(t, c.full, f.FullySynced())
+		})
+	}
+}
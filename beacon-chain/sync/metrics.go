@@ -89,6 +89,113 @@ var (
 			Buckets: []float64{5, 10, 50, 100, 150, 250, 500, 1000, 2000},
 		},
 	)
+	blobsServedBelowFloor = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "blobs_served_below_floor_total",
+			Help: "Number of blob sidecars served by range for a slot older than the blob retention floor when the sidecar was written",
+		},
+	)
+	blobLookupsSkipped = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rpc_blob_lookups_skipped_total",
+			Help: "Number of blocks in blob sidecar range responses that were not looked up in blob storage because they have no blob kzg commitments",
+		},
+	)
+	blobsSkippedNonCanonical = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rpc_blobs_skipped_non_canonical_total",
+			Help: "Number of blob sidecars not served by range because their block was not canonical when the sidecar was written",
+		},
+	)
+	blobServeSelfTests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rpc_blob_serve_self_test_total",
+			Help: "Number of blob serving self-tests, by result: ok, refused or failed",
+		},
+		[]string{"result"},
+	)
+	blobServeSelfTestFailing = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "rpc_blob_serve_self_test_failing",
+			Help: "1 if the last blob serving self-test failed, meaning that peers may not be able to download blob sidecars from this node",
+		},
+	)
+	blobsSkippedForkSchedule = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rpc_blobs_skipped_fork_schedule_total",
+			Help: "Number of blob sidecars not served because the fork schedule has no blob fork for their slot",
+		},
+	)
+	blobRangeReads = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rpc_blob_range_reads_total",
+			Help: "Number of reads of blob storage for blob sidecar range requests when reads are coalesced",
+		},
+	)
+	blobStoreBreakerState = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "rpc_blob_store_breaker_state",
+			Help: "State of the circuit breaker for blob storage reads when serving blob sidecar range requests: 0 closed, 1 open, 2 half-open",
+		},
+	)
+	blobRangesRefusedStoreBreaker = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rpc_blob_ranges_refused_store_breaker_total",
+			Help: "Number of blob sidecar range requests answered as resource unavailable because blob storage reads are failing",
+		},
+	)
+	blobRangeReadsCached = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rpc_blob_range_reads_cached_total",
+			Help: "Number of blob sidecar range reads served from a read of the same range that completed within the coalescing window",
+		},
+	)
+	blobRangeReadsCoalesced = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rpc_blob_range_reads_coalesced_total",
+			Help: "Number of blob sidecar range reads that were shared between concurrent requests for the same range",
+		},
+	)
+	blobRangesPausedForProposal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rpc_blob_ranges_paused_for_proposal_total",
+			Help: "Number of large blob sidecar range requests answered as unavailable because a proposal by a local validator was imminent",
+		},
+	)
+	blobRangesRefusedWhileSyncing = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rpc_blob_ranges_refused_while_syncing_total",
+			Help: "Number of large blob sidecar range requests answered as unavailable because the node was behind the head of the chain",
+		},
+	)
+	rpcBlobsByRangeServedRatio = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rpc_blobs_by_range_served_ratio",
+			Help:    "Ratio of blob sidecars served to the most that could be served for the requested count of slots, by how the response ended",
+			Buckets: []float64{0, 0.1, 0.25, 0.5, 0.75, 0.9, 1},
+		},
+		[]string{"termination"},
+	)
+	rpcBlobsByRangeSlotsSkipped = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rpc_blobs_by_range_slots_skipped_total",
+			Help: "Number of slots in blob sidecar range requests that were not read from the db because blob storage has no sidecars for them",
+		},
+	)
+	blobSidecarsServedByFork = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rpc_blob_sidecars_served_by_fork_total",
+			Help: "Number of blob sidecars served in response to range requests, by the fork of the slot of the sidecar",
+		},
+		[]string{"fork"},
+	)
+	blobBytesServedByFork = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rpc_blob_sidecar_bytes_served_by_fork_total",
+			Help: "Approximate bytes of blob sidecars served in response to range requests, by the fork of the slot of the sidecar",
+		},
+		[]string{"fork"},
+	)
 	arrivalBlockPropagationHistogram = promauto.NewHistogram(
 		prometheus.HistogramOpts{
 			Name:    "block_arrival_latency_milliseconds",
@@ -18,6 +18,7 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/state/stategen"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/backfill/coverage"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
 )
 
 type Option func(s *Service) error
@@ -180,3 +181,41 @@ func WithAvailableBlocker(avb coverage.AvailableBlocker) Option {
 		return nil
 	}
 }
+
+// WithAvailableBlobber allows the sync package to check which slots backfill has downloaded blobs for. It is only
+// needed when backfill skips or prunes blobs, so that blob sidecar requests for blocks backfilled without their
+// blobs, or whose blobs were pruned, are answered as unavailable.
+func WithAvailableBlobber(avb coverage.AvailableBlobber) Option {
+	return func(s *Service) error {
+		s.availableBlobber = avb
+		return nil
+	}
+}
+
+// WithBlobServingAccessList restricts the peers that blob sidecars will be served to by BlobSidecarsByRange.
+// The list can be updated while the node is running. A nil list serves blobs to every peer.
+func WithBlobServingAccessList(l *PeerAccessList) Option {
+	return func(s *Service) error {
+		s.cfg.blobServingAccessList = l
+		return nil
+	}
+}
+
+// WithProposalDutyChecker lets BlobSidecarsByRange turn away large requests while a local validator is about to
+// propose, so that serving blobs doesn't compete with building and publishing the block. The pause only applies when
+// the blob-serve-proposal-pause flag is set.
+func WithProposalDutyChecker(c ProposalDutyChecker) Option {
+	return func(s *Service) error {
+		s.proposalDuties = c
+		return nil
+	}
+}
+
+// WithWallClock sets the source of time for the timers that pace range request handlers. It defaults to the system
+// clock, tests can use a simulated clock to control the timers.
+func WithWallClock(c prysmTime.Clock) Option {
+	return func(s *Service) error {
+		s.wall = c
+		return nil
+	}
+}
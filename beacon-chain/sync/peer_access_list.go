@@ -0,0 +1,55 @@
+package sync
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// PeerAccessList is an allowlist/denylist of peers that can be updated while the node is running.
+// A peer in the denylist is never allowed. If the allowlist is non-empty, only peers in the allowlist are allowed.
+// A nil *PeerAccessList allows every peer.
+type PeerAccessList struct {
+	sync.RWMutex
+	allow map[peer.ID]bool
+	deny  map[peer.ID]bool
+}
+
+// NewPeerAccessList initializes a PeerAccessList with the given allowlist and denylist.
+func NewPeerAccessList(allow, deny []peer.ID) *PeerAccessList {
+	l := &PeerAccessList{}
+	l.Update(allow, deny)
+	return l
+}
+
+// Update atomically replaces the allowlist and denylist.
+func (l *PeerAccessList) Update(allow, deny []peer.ID) {
+	am := make(map[peer.ID]bool, len(allow))
+	for _, pid := range allow {
+		am[pid] = true
+	}
+	dm := make(map[peer.ID]bool, len(deny))
+	for _, pid := range deny {
+		dm[pid] = true
+	}
+	l.Lock()
+	defer l.Unlock()
+	l.allow = am
+	l.deny = dm
+}
+
+// Allowed determines whether the given peer may be served.
+func (l *PeerAccessList) Allowed(pid peer.ID) bool {
+	if l == nil {
+		return true
+	}
+	l.RLock()
+	defer l.RUnlock()
+	if l.deny[pid] {
+		return false
+	}
+	if len(l.allow) == 0 {
+		return true
+	}
+	return l.allow[pid]
+}
@@ -0,0 +1,26 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestPeerAccessList(t *testing.T) {
+	a, b, c := peer.ID("a"), peer.ID("b"), peer.ID("c")
+	var nilList *PeerAccessList
+	require.Equal(t, true, nilList.Allowed(a))
+
+	l := NewPeerAccessList(nil, []peer.ID{b})
+	require.Equal(t, true, l.Allowed(a))
+	require.Equal(t, false, l.Allowed(b))
+
+	l.Update([]peer.ID{a, b}, []peer.ID{b})
+	require.Equal(t, true, l.Allowed(a))
+	require.Equal(t, false, l.Allowed(b), "denylist takes precedence over allowlist")
+	require.Equal(t, false, l.Allowed(c), "peers missing from a non-empty allowlist are not allowed")
+
+	l.Update(nil, nil)
+	require.Equal(t, true, l.Allowed(c))
+}
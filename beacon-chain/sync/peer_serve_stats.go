@@ -0,0 +1,103 @@
+package sync
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// maxTrackedServePeers bounds the number of peers that have their own label in the per-peer serving metrics.
+const maxTrackedServePeers = 32
+
+var (
+	blobSidecarsServedByPeer = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rpc_blob_sidecars_served_by_peer",
+			Help: "Number of blob sidecars served to the peers requesting the most blob data.",
+		}, []string{"peer"},
+	)
+	blobBytesServedByPeer = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rpc_blob_sidecar_bytes_served_by_peer",
+			Help: "Approximate bytes of blob sidecars served to the peers requesting the most blob data.",
+		}, []string{"peer"},
+	)
+)
+
+var blobServeStats = newPeerServeStats(maxTrackedServePeers, blobSidecarsServedByPeer, blobBytesServedByPeer)
+
+type peerServeCount struct {
+	sidecars uint64
+	bytes    uint64
+}
+
+// peerServeStats keeps serving totals for the peers that have been served the most bytes. To avoid unbounded
+// label cardinality, only the top n peers by volume are tracked, using the "space-saving" heavy hitters algorithm:
+// when a new peer is seen and the tracker is full, the peer with the lowest volume is evicted (and its labels are
+// removed from the metrics), and the new peer inherits the evicted totals. This means totals can overestimate
+// the volume for recently added peers, but a peer that is served a large volume cannot avoid being tracked.
+type peerServeStats struct {
+	sync.Mutex
+	n       int
+	counts  map[peer.ID]*peerServeCount
+	served  *prometheus.GaugeVec
+	byteVec *prometheus.GaugeVec
+}
+
+func newPeerServeStats(n int, served, byteVec *prometheus.GaugeVec) *peerServeStats {
+	return &peerServeStats{
+		n:       n,
+		counts:  make(map[peer.ID]*peerServeCount),
+		served:  served,
+		byteVec: byteVec,
+	}
+}
+
+func (ps *peerServeStats) add(pid peer.ID, sidecars, bytes uint64) {
+	ps.Lock()
+	defer ps.Unlock()
+	c, ok := ps.counts[pid]
+	if !ok {
+		c = &peerServeCount{}
+		if len(ps.counts) >= ps.n {
+			*c = ps.evictLowest()
+		}
+		ps.counts[pid] = c
+	}
+	c.sidecars += sidecars
+	c.bytes += bytes
+	label := pid.String()
+	ps.served.WithLabelValues(label).Set(float64(c.sidecars))
+	ps.byteVec.WithLabelValues(label).Set(float64(c.bytes))
+}
+
+// evictLowest removes the tracked peer with the lowest byte count and returns its totals.
+func (ps *peerServeStats) evictLowest() peerServeCount {
+	var lowest peer.ID
+	var lowestCount *peerServeCount
+	for pid, c := range ps.counts {
+		if lowestCount == nil || c.bytes < lowestCount.bytes {
+			lowest, lowestCount = pid, c
+		}
+	}
+	if lowestCount == nil {
+		return peerServeCount{}
+	}
+	delete(ps.counts, lowest)
+	ps.served.DeleteLabelValues(lowest.String())
+	ps.byteVec.DeleteLabelValues(lowest.String())
+	return *lowestCount
+}
+
+// get returns the totals for the given peer, and false if the peer is not tracked.
+func (ps *peerServeStats) get(pid peer.ID) (peerServeCount, bool) {
+	ps.Lock()
+	defer ps.Unlock()
+	c, ok := ps.counts[pid]
+	if !ok {
+		return peerServeCount{}, false
+	}
+	return *c, true
+}
@@ -0,0 +1,40 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func testServeGauges() (*prometheus.GaugeVec, *prometheus.GaugeVec) {
+	served := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_served"}, []string{"peer"})
+	bytes := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_bytes"}, []string{"peer"})
+	return served, bytes
+}
+
+func TestPeerServeStats(t *testing.T) {
+	served, bytes := testServeGauges()
+	ps := newPeerServeStats(2, served, bytes)
+	a, b, c := peer.ID("a"), peer.ID("b"), peer.ID("c")
+	ps.add(a, 1, 100)
+	ps.add(a, 1, 100)
+	ps.add(b, 1, 50)
+	got, ok := ps.get(a)
+	require.Equal(t, true, ok)
+	require.Equal(t, peerServeCount{sidecars: 2, bytes: 200}, got)
+
+	// Adding a third peer evicts the lowest volume peer, and the new peer inherits its totals.
+	ps.add(c, 1, 10)
+	_, ok = ps.get(b)
+	require.Equal(t, false, ok)
+	got, ok = ps.get(c)
+	require.Equal(t, true, ok)
+	require.Equal(t, peerServeCount{sidecars: 2, bytes: 60}, got)
+	require.Equal(t, 2, len(ps.counts))
+
+	// Evicted peers are also removed from the metrics.
+	require.Equal(t, false, served.DeleteLabelValues(b.String()))
+	require.Equal(t, true, served.DeleteLabelValues(c.String()))
+}
@@ -25,6 +25,9 @@ const blockBucketPeriod = 30 * time.Second
 // Dummy topic to validate all incoming rpc requests.
 const rpcLimiterTopic = "rpc-limiter-topic"
 
+// Dummy topic for the secondary budget used to serve blob sidecars older than the hot window.
+const blobHistoricalLimiterTopic = "blob-historical-limiter-topic"
+
 type limiter struct {
 	limiterMap map[string]*leakybucket.Collector
 	p2p        p2p.P2P
@@ -78,6 +81,18 @@ func newRateLimiter(p2pProvider p2p.P2P) *limiter {
 	topicMap[addEncoding(p2p.RPCBlobSidecarsByRootTopicV1)] = blobCollector
 	// BlobSidecarsByRangeV1
 	topicMap[addEncoding(p2p.RPCBlobSidecarsByRangeTopicV1)] = blobCollector
+	// BlobSidecarCountsByRangeV1 shares the blob budget, with one unit for each count in the response.
+	topicMap[addEncoding(p2p.RPCBlobSidecarCountsByRangeTopicV1)] = blobCollector
+	// BlobSidecarsByRangeReverseV1 serves the same sidecars as BlobSidecarsByRangeV1.
+	topicMap[addEncoding(p2p.RPCBlobSidecarsByRangeReverseTopicV1)] = blobCollector
+
+	// Blob sidecars older than the hot window also draw from a smaller budget, so that bulk historical requests
+	// can't starve peers requesting recent blobs.
+	if flags.Get().BlobServingHotEpochs > 0 {
+		allowedHistoricalBlobsPerSecond := float64(flags.Get().BlobBatchLimitHistorical)
+		allowedHistoricalBlobsBurst := int64(flags.Get().BlobBatchLimitBurstFactor * flags.Get().BlobBatchLimitHistorical)
+		topicMap[blobHistoricalLimiterTopic] = leakybucket.NewCollector(allowedHistoricalBlobsPerSecond, allowedHistoricalBlobsBurst, blockBucketPeriod, false /* deleteEmptyBuckets */)
+	}
 
 	// General topic for all rpc requests.
 	topicMap[rpcLimiterTopic] = leakybucket.NewCollector(5, defaultBurstLimit*2, leakyBucketPeriod, false /* deleteEmptyBuckets */)
@@ -110,7 +125,7 @@ func (l *limiter) validateRequest(stream network.Stream, amt uint64) error {
 		amt = 1
 	}
 	if amt > uint64(remaining) {
-		l.p2p.Peers().Scorers().BadResponsesScorer().Increment(stream.Conn().RemotePeer())
+		l.penalize(stream)
 		writeErrorResponseToStream(responseCodeInvalidRequest, p2ptypes.ErrRateLimited.Error(), stream, l.p2p)
 		return p2ptypes.ErrRateLimited
 	}
@@ -133,13 +148,73 @@ func (l *limiter) validateRawRpcRequest(stream network.Stream) error {
 	// Treat each request as a minimum of 1.
 	amt := int64(1)
 	if amt > remaining {
-		l.p2p.Peers().Scorers().BadResponsesScorer().Increment(stream.Conn().RemotePeer())
+		l.penalize(stream)
 		writeErrorResponseToStream(responseCodeInvalidRequest, p2ptypes.ErrRateLimited.Error(), stream, l.p2p)
 		return p2ptypes.ErrRateLimited
 	}
 	return nil
 }
 
+// validates a request for blob sidecars older than the hot window against the historical blob budget.
+// Requests are not limited by the historical budget if it is not configured.
+func (l *limiter) validateHistoricalBlobRequest(stream network.Stream, amt uint64) error {
+	l.RLock()
+	defer l.RUnlock()
+
+	collector, ok := l.limiterMap[blobHistoricalLimiterTopic]
+	if !ok {
+		return nil
+	}
+	key := stream.Conn().RemotePeer().String()
+	remaining := collector.Remaining(key)
+	// Treat each request as a minimum of 1.
+	if amt == 0 {
+		amt = 1
+	}
+	if amt > uint64(remaining) {
+		l.penalize(stream)
+		writeErrorResponseToStream(responseCodeInvalidRequest, p2ptypes.ErrRateLimited.Error(), stream, l.p2p)
+		return p2ptypes.ErrRateLimited
+	}
+	return nil
+}
+
+// penalize increments the bad responses score of the peer that exceeded its rate limit. The node does not score
+// itself, when the blob serving self-test exceeds the limits.
+func (l *limiter) penalize(stream network.Stream) {
+	if fromSelf(l.p2p, stream) {
+		return
+	}
+	l.p2p.Peers().Scorers().BadResponsesScorer().Increment(stream.Conn().RemotePeer())
+}
+
+// reports whether the historical blob budget of the peer covers another blob sidecar older than the hot window. Unlike
+// validateHistoricalBlobRequest, it doesn't respond to the peer or penalize it, so that a response that runs out of
+// budget can end after the sidecars that were covered.
+func (l *limiter) historicalBlobAllowed(stream network.Stream) bool {
+	l.RLock()
+	defer l.RUnlock()
+
+	collector, ok := l.limiterMap[blobHistoricalLimiterTopic]
+	if !ok {
+		return true
+	}
+	return collector.Remaining(stream.Conn().RemotePeer().String()) >= 1
+}
+
+// adds the cost of serving blob sidecars older than the hot window to the historical blob budget, if it is configured.
+func (l *limiter) addHistoricalBlobs(stream network.Stream, amt int64) {
+	l.Lock()
+	defer l.Unlock()
+
+	collector, ok := l.limiterMap[blobHistoricalLimiterTopic]
+	if !ok {
+		return
+	}
+	key := stream.Conn().RemotePeer().String()
+	collector.Add(key, amt)
+}
+
 // adds the cost to our leaky bucket for the topic.
 func (l *limiter) add(stream network.Stream, amt int64) {
 	l.Lock()
@@ -11,6 +11,7 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
 	mockp2p "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/testing"
 	p2ptypes "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/types"
+	"github.com/prysmaticlabs/prysm/v5/cmd/beacon-chain/flags"
 	"github.com/prysmaticlabs/prysm/v5/testing/assert"
 	"github.com/prysmaticlabs/prysm/v5/testing/require"
 	"github.com/prysmaticlabs/prysm/v5/testing/util"
@@ -18,7 +19,7 @@ import (
 
 func TestNewRateLimiter(t *testing.T) {
 	rlimiter := newRateLimiter(mockp2p.NewTestP2P(t))
-	assert.Equal(t, len(rlimiter.limiterMap), 12, "correct number of topics not registered")
+	assert.Equal(t, len(rlimiter.limiterMap), 14, "correct number of topics not registered")
 }
 
 func TestNewRateLimiter_FreeCorrectly(t *testing.T) {
@@ -110,3 +111,51 @@ func Test_limiter_retrieveCollector_requiresLock(t *testing.T) {
 	_, err := l.retrieveCollector("")
 	require.ErrorContains(t, "caller must hold read/write lock", err)
 }
+
+func TestRateLimiter_HistoricalBlobs(t *testing.T) {
+	p1 := mockp2p.NewTestP2P(t)
+	p2 := mockp2p.NewTestP2P(t)
+	p1.Connect(p2)
+
+	topic := p2p.RPCBlobSidecarsByRangeTopicV1 + p1.Encoding().ProtocolSuffix()
+	wg := sync.WaitGroup{}
+	p2.BHost.SetStreamHandler(protocol.ID(topic), func(stream network.Stream) {
+		defer wg.Done()
+		code, errMsg, err := readStatusCodeNoDeadline(stream, p2.Encoding())
+		require.NoError(t, err, "could not read incoming stream")
+		assert.Equal(t, responseCodeInvalidRequest, code, "not equal response codes")
+		assert.Equal(t, p2ptypes.ErrRateLimited.Error(), errMsg, "not equal errors")
+	})
+	wg.Add(1)
+	stream, err := p1.BHost.NewStream(context.Background(), p2.PeerID(), protocol.ID(topic))
+	require.NoError(t, err, "could not create stream")
+
+	// Without a hot window, the historical budget is not used.
+	rlimiter := newRateLimiter(p1)
+	rlimiter.addHistoricalBlobs(stream, 1000)
+	require.NoError(t, rlimiter.validateHistoricalBlobRequest(stream, 1000))
+	require.Equal(t, true, rlimiter.historicalBlobAllowed(stream))
+
+	resetFlags := flags.Get()
+	gFlags := *resetFlags
+	gFlags.BlobServingHotEpochs = 2
+	gFlags.BlobBatchLimitHistorical = 4
+	gFlags.BlobBatchLimitBurstFactor = 2
+	flags.Init(&gFlags)
+	defer flags.Init(resetFlags)
+
+	rlimiter = newRateLimiter(p1)
+	require.NoError(t, rlimiter.validateHistoricalBlobRequest(stream, 8))
+	rlimiter.addHistoricalBlobs(stream, 7)
+	require.Equal(t, true, rlimiter.historicalBlobAllowed(stream))
+	rlimiter.addHistoricalBlobs(stream, 1)
+	require.Equal(t, false, rlimiter.historicalBlobAllowed(stream))
+	// The normal blob budget is unaffected.
+	require.NoError(t, rlimiter.validateRequest(stream, 8))
+	require.ErrorIs(t, rlimiter.validateHistoricalBlobRequest(stream, 1), p2ptypes.ErrRateLimited)
+
+	require.NoError(t, stream.Close(), "could not close stream")
+	if util.WaitTimeout(&wg, 1*time.Second) {
+		t.Fatal("Did not receive stream within 1 sec")
+	}
+}
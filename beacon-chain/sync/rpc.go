@@ -28,11 +28,49 @@ var ttfbTimeout = params.BeaconConfig().TtfbTimeoutDuration()
 // respTimeout is the maximum time for complete response transfer.
 var respTimeout = params.BeaconConfig().RespTimeoutDuration()
 
+var errUnsupportedRPCVersion = errors.New("unsupported rpc protocol version")
+
 // rpcHandler is responsible for handling and responding to any incoming message.
 // This method may return an error to internal monitoring, but the error will
 // not be relayed to the peer.
 type rpcHandler func(context.Context, interface{}, libp2pcore.Stream) error
 
+// rpcVersionedHandlers maps each supported version of an rpc topic family, identified by its base topic,
+// to the handler for that version.
+type rpcVersionedHandlers map[string]rpcHandler
+
+// blobSidecarsByRangeHandlers is the mapping of the supported blob_sidecars_by_range versions to their handlers.
+// When the request or response format of the topic changes, the handler for the new version is added here.
+func (s *Service) blobSidecarsByRangeHandlers() rpcVersionedHandlers {
+	return rpcVersionedHandlers{
+		p2p.RPCBlobSidecarsByRangeTopicV1: s.blobSidecarsByRangeRPCHandler,
+	}
+}
+
+// registerVersionedRPC registers every version in the mapping, with a handler that dispatches on the
+// protocol ID negotiated for the stream.
+func (s *Service) registerVersionedRPC(handlers rpcVersionedHandlers) {
+	dispatch := s.versionedRPCHandler(handlers)
+	for baseTopic := range handlers {
+		s.registerRPC(baseTopic, dispatch)
+	}
+}
+
+// versionedRPCHandler returns an rpcHandler that calls the handler for the version of the topic negotiated
+// for the stream. Streams for an unsupported version are sent an invalid request error.
+func (s *Service) versionedRPCHandler(handlers rpcVersionedHandlers) rpcHandler {
+	return func(ctx context.Context, msg interface{}, stream libp2pcore.Stream) error {
+		baseTopic := strings.TrimSuffix(string(stream.Protocol()), s.cfg.p2p.Encoding().ProtocolSuffix())
+		handle, ok := handlers[baseTopic]
+		if !ok {
+			s.writeErrorResponseToStream(responseCodeInvalidRequest, errUnsupportedRPCVersion.Error(), stream)
+			closeStream(stream, log.WithField("topic", string(stream.Protocol())))
+			return errors.Wrapf(errUnsupportedRPCVersion, "protocol %s", stream.Protocol())
+		}
+		return handle(ctx, msg, stream)
+	}
+}
+
 // registerRPCHandlers for p2p RPC.
 func (s *Service) registerRPCHandlers() {
 	currEpoch := slots.ToEpoch(s.cfg.clock.CurrentSlot())
@@ -99,14 +137,19 @@ func (s *Service) registerRPCHandlersAltair() {
 }
 
 func (s *Service) registerRPCHandlersDeneb() {
-	s.registerRPC(
-		p2p.RPCBlobSidecarsByRangeTopicV1,
-		s.blobSidecarsByRangeRPCHandler,
-	)
+	s.registerVersionedRPC(s.blobSidecarsByRangeHandlers())
 	s.registerRPC(
 		p2p.RPCBlobSidecarsByRootTopicV1,
 		s.blobSidecarByRootRPCHandler,
 	)
+	s.registerRPC(
+		p2p.RPCBlobSidecarCountsByRangeTopicV1,
+		s.blobSidecarCountsByRangeRPCHandler,
+	)
+	s.registerRPC(
+		p2p.RPCBlobSidecarsByRangeReverseTopicV1,
+		s.blobSidecarsByRangeReverseRPCHandler,
+	)
 }
 
 // Remove all v1 Stream handlers that are no longer supported
@@ -7,6 +7,7 @@ import (
 	libp2pcore "github.com/libp2p/go-libp2p/core"
 	"github.com/pkg/errors"
 	p2ptypes "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/types"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/backfill/coverage"
 	"github.com/prysmaticlabs/prysm/v5/cmd/beacon-chain/flags"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
@@ -59,13 +60,13 @@ func (s *Service) beaconBlocksByRangeRPCHandler(ctx context.Context, msg interfa
 		trace.Int64Attribute("remaining_capacity", remainingBucketCapacity),
 	)
 
-	// Ticker to stagger out large requests.
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-	batcher, err := newBlockRangeBatcher(rp, s.cfg.beaconDB, s.rateLimiter, s.cfg.chain.IsCanonical, ticker)
+	// Throttle to stagger out large requests.
+	throttle := newBatchThrottle(s.wallClock(), batchThrottleInterval)
+	defer throttle.stop()
+	batcher, err := newBlockRangeBatcher(rp, s.cfg.beaconDB, s.rateLimiter, s.cfg.chain.IsCanonical, throttle)
 	if err != nil {
 		log.WithError(err).Info("error in BlocksByRange batch")
-		s.writeErrorResponseToStream(responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+		s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
 		tracing.AnnotateError(span, err)
 		return err
 	}
@@ -74,17 +75,27 @@ func (s *Service) beaconBlocksByRangeRPCHandler(ctx context.Context, msg interfa
 	// by comparing the previous root of the block in the list with the current block's parent.
 	var batch blockBatch
 	var more bool
+	sent := 0
 	for batch, more = batcher.next(ctx, stream); more; batch, more = batcher.next(ctx, stream) {
 		batchStart := time.Now()
 		if err := s.writeBlockBatchToStream(ctx, batch, stream); err != nil {
-			s.writeErrorResponseToStream(responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+			s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
 			return err
 		}
+		sent += len(batch.canonical())
 		rpcBlocksByRangeResponseLatency.Observe(float64(time.Since(batchStart).Milliseconds()))
 	}
 	if err := batch.error(); err != nil {
+		// The blocks that were already sent are a valid partial response. Unless the peer is being rate limited,
+		// in which case the limiter has already responded, end the response instead of sending an error after them.
+		if sent > 0 && !errors.Is(err, p2ptypes.ErrRateLimited) {
+			log.WithError(err).WithField("sent", sent).Debug("Ending BlocksByRange response early due to error in batch")
+			tracing.AnnotateError(span, err)
+			closeStream(stream, log)
+			return nil
+		}
 		log.WithError(err).Debug("error in BlocksByRange batch")
-		s.writeErrorResponseToStream(responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+		s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
 		tracing.AnnotateError(span, err)
 		return err
 	}
@@ -98,28 +109,50 @@ type rangeParams struct {
 	size  uint64
 }
 
-func validateRangeRequest(r *pb.BeaconBlocksByRangeRequest, current primitives.Slot) (rangeParams, error) {
-	rp := rangeParams{
-		start: r.StartSlot,
-		size:  r.Count,
+// slotRange returns the slots from start to end as a half-open coverage.SlotRange; end is inclusive in rangeParams.
+func (rp rangeParams) slotRange() coverage.SlotRange {
+	return coverage.SlotRange{Start: rp.start, End: rp.end + 1}
+}
+
+// validateByRangeRequest applies the checks shared by all by-range request handlers to the requested start slot and
+// count. The count must be non-zero and the requested range must not overflow the slot type. The returned count is
+// clamped to max. The returned error always wraps p2ptypes.ErrInvalidRequest.
+func validateByRangeRequest(start primitives.Slot, count, max uint64) (primitives.Slot, uint64, error) {
+	if count == 0 {
+		return 0, 0, errors.Wrap(p2ptypes.ErrInvalidRequest, "invalid request Count parameter")
+	}
+	if _, err := start.SafeAdd(count - 1); err != nil {
+		return 0, 0, errors.Wrap(p2ptypes.ErrInvalidRequest, "overflow start + count -1")
 	}
+	if count > max {
+		count = max
+	}
+	return start, count, nil
+}
+
+func validateRangeRequest(r *pb.BeaconBlocksByRangeRequest, current primitives.Slot) (rangeParams, error) {
 	maxRequest := params.MaxRequestBlock(slots.ToEpoch(current))
 	// Ensure all request params are within appropriate bounds
-	if rp.size == 0 || rp.size > maxRequest {
+	if r.Count > maxRequest {
 		return rangeParams{}, p2ptypes.ErrInvalidRequest
 	}
+	start, count, err := validateByRangeRequest(r.StartSlot, r.Count, maxRequest)
+	if err != nil {
+		return rangeParams{}, err
+	}
 	// Allow some wiggle room, up to double the MaxRequestBlocks past the current slot,
 	// to give nodes syncing close to the head of the chain some margin for error.
 	maxStart, err := current.SafeAdd(maxRequest * 2)
 	if err != nil {
 		return rangeParams{}, p2ptypes.ErrInvalidRequest
 	}
-	if rp.start > maxStart {
+	if start > maxStart {
 		return rangeParams{}, p2ptypes.ErrInvalidRequest
 	}
-	rp.end, err = rp.start.SafeAdd(rp.size - 1)
-	if err != nil {
-		return rangeParams{}, p2ptypes.ErrInvalidRequest
+	rp := rangeParams{
+		start: start,
+		end:   start + primitives.Slot(count-1),
+		size:  count,
 	}
 
 	limit := uint64(flags.Get().BlockBatchLimit)
@@ -152,7 +185,7 @@ func (s *Service) writeBlockBatchToStream(ctx context.Context, batch blockBatch,
 			continue
 		}
 		if chunkErr := s.chunkBlockWriter(stream, b); chunkErr != nil {
-			log.WithError(chunkErr).Debug("Could not send a chunked response")
+			chunkFailures.observe(chunkErr, time.Now())
 			return chunkErr
 		}
 	}
@@ -173,7 +206,7 @@ func (s *Service) writeBlockBatchToStream(ctx context.Context, batch blockBatch,
 			continue
 		}
 		if chunkErr := s.chunkBlockWriter(stream, b); chunkErr != nil {
-			log.WithError(chunkErr).Debug("Could not send a chunked response")
+			chunkFailures.observe(chunkErr, time.Now())
 			return chunkErr
 		}
 	}
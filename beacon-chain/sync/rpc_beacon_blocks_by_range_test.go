@@ -3,6 +3,7 @@ package sync
 import (
 	"context"
 	"io"
+	"math"
 	"math/big"
 	"sync"
 	"testing"
@@ -15,6 +16,7 @@ import (
 	"github.com/pkg/errors"
 	chainMock "github.com/prysmaticlabs/prysm/v5/beacon-chain/blockchain/testing"
 	db2 "github.com/prysmaticlabs/prysm/v5/beacon-chain/db"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/filters"
 	db "github.com/prysmaticlabs/prysm/v5/beacon-chain/db/testing"
 	mockExecution "github.com/prysmaticlabs/prysm/v5/beacon-chain/execution/testing"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
@@ -26,6 +28,7 @@ import (
 	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/interfaces"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	leakybucket "github.com/prysmaticlabs/prysm/v5/container/leaky-bucket"
 	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
@@ -557,6 +560,124 @@ func TestRPCBeaconBlocksByRange_RPCHandlerRateLimitOverflow(t *testing.T) {
 	})
 }
 
+func TestRPCBeaconBlocksByRange_CollectorErrorAfterFirstBatch(t *testing.T) {
+	p1 := p2ptest.NewTestP2P(t)
+	p2 := p2ptest.NewTestP2P(t)
+	p1.Connect(p2)
+	assert.Equal(t, 1, len(p1.BHost.Network().Peers()), "Expected peers to be connected")
+	d := db.SetupDB(t)
+
+	resetFlags := flags.Get()
+	gFlags := *resetFlags
+	gFlags.BlockBatchLimit = 20
+	flags.Init(&gFlags)
+	defer flags.Init(resetFlags)
+	batchSize := uint64(gFlags.BlockBatchLimit)
+	req := &ethpb.BeaconBlocksByRangeRequest{
+		StartSlot: 100,
+		Step:      1,
+		Count:     batchSize*2 + 10,
+	}
+	var parentRoot [32]byte
+	for i := req.StartSlot; i < req.StartSlot.Add(req.Count); i += primitives.Slot(1) {
+		blk := util.NewBeaconBlock()
+		blk.Block.Slot = i
+		blk.Block.ParentRoot = parentRoot[:]
+		util.SaveBlock(t, context.Background(), d, blk)
+		rt, err := blk.Block.HashTreeRoot()
+		require.NoError(t, err)
+		parentRoot = rt
+	}
+
+	clock := startup.NewClock(time.Unix(0, 0), [32]byte{})
+	r := &Service{cfg: &config{p2p: p1, beaconDB: d, clock: clock, chain: &chainMock.ChainService{}}, availableBlocker: mockBlocker{avail: true}, rateLimiter: newRateLimiter(p1)}
+	pcl := protocol.ID(p2p.RPCBlocksByRangeTopicV1)
+	topic := string(pcl)
+	r.rateLimiter.limiterMap[topic] = leakybucket.NewCollector(0.000001, int64(req.Count*10), time.Second, false)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	p2.BHost.SetStreamHandler(pcl, func(stream network.Stream) {
+		defer wg.Done()
+		for i := uint64(0); i < batchSize; i++ {
+			expectSuccess(t, stream)
+			res := util.NewBeaconBlock()
+			assert.NoError(t, r.cfg.p2p.Encoding().DecodeWithMaxLength(stream, res))
+		}
+		// Remove the collector while the handler waits to serve the next batch, so that the rate limiter
+		// errors after the first batch of blocks has been sent. The following batch may already have been
+		// checked against the rate limiter, in which case the error happens on the batch after it.
+		r.rateLimiter.Lock()
+		delete(r.rateLimiter.limiterMap, topic)
+		r.rateLimiter.Unlock()
+		received := batchSize
+		// The stream should be closed without an error response following the blocks.
+		for {
+			code, msg, err := ReadStatusCode(stream, r.cfg.p2p.Encoding())
+			if err != nil {
+				require.ErrorIs(t, err, io.EOF)
+				break
+			}
+			require.Equal(t, responseCodeSuccess, code, msg)
+			res := util.NewBeaconBlock()
+			assert.NoError(t, r.cfg.p2p.Encoding().DecodeWithMaxLength(stream, res))
+			received++
+		}
+		require.Equal(t, true, received < req.Count)
+	})
+
+	stream, err := p1.BHost.NewStream(context.Background(), p2.BHost.ID(), pcl)
+	require.NoError(t, err)
+	require.NoError(t, r.beaconBlocksByRangeRPCHandler(context.Background(), req, stream))
+	if util.WaitTimeout(&wg, 5*time.Second) {
+		t.Fatal("Did not receive stream within 5 sec")
+	}
+}
+
+// expiringBlocksDB fails every Blocks call after canceling the request context, like a db read that outlasts the
+// response deadline.
+type expiringBlocksDB struct {
+	db2.NoHeadAccessDatabase
+	cancel context.CancelFunc
+}
+
+func (d *expiringBlocksDB) Blocks(_ context.Context, _ *filters.QueryFilter) ([]interfaces.ReadOnlySignedBeaconBlock, [][32]byte, error) {
+	d.cancel()
+	return nil, nil, errors.New("db read failed")
+}
+
+func TestRPCBeaconBlocksByRange_DBErrorAfterContextDone(t *testing.T) {
+	p1 := p2ptest.NewTestP2P(t)
+	p2 := p2ptest.NewTestP2P(t)
+	p1.Connect(p2)
+	assert.Equal(t, 1, len(p1.BHost.Network().Peers()), "Expected peers to be connected")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d := &expiringBlocksDB{NoHeadAccessDatabase: db.SetupDB(t), cancel: cancel}
+	req := &ethpb.BeaconBlocksByRangeRequest{StartSlot: 100, Step: 1, Count: 10}
+	clock := startup.NewClock(time.Unix(0, 0), [32]byte{})
+	r := &Service{cfg: &config{p2p: p1, beaconDB: d, clock: clock, chain: &chainMock.ChainService{}}, availableBlocker: mockBlocker{avail: true}, rateLimiter: newRateLimiter(p1)}
+	pcl := protocol.ID(p2p.RPCBlocksByRangeTopicV1)
+	r.rateLimiter.limiterMap[string(pcl)] = leakybucket.NewCollector(0.000001, int64(req.Count*10), time.Second, false)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	p2.BHost.SetStreamHandler(pcl, func(stream network.Stream) {
+		defer wg.Done()
+		// The stream is closed without writing an error response once the request context is done.
+		_, _, err := ReadStatusCode(stream, r.cfg.p2p.Encoding())
+		require.ErrorIs(t, err, io.EOF)
+	})
+
+	stream, err := p1.BHost.NewStream(context.Background(), p2.BHost.ID(), pcl)
+	require.NoError(t, err)
+	require.ErrorContains(t, "db read failed", r.beaconBlocksByRangeRPCHandler(ctx, req, stream))
+	if util.WaitTimeout(&wg, 5*time.Second) {
+		t.Fatal("Did not receive stream within 5 sec")
+	}
+}
+
 func TestRPCBeaconBlocksByRange_validateRangeRequest(t *testing.T) {
 	slotsSinceGenesis := primitives.Slot(1000)
 	offset := int64(slotsSinceGenesis.Mul(params.BeaconConfig().SecondsPerSlot))
@@ -668,6 +789,72 @@ func TestRPCBeaconBlocksByRange_validateRangeRequest(t *testing.T) {
 	}
 }
 
+func TestValidateByRangeRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		start primitives.Slot
+		count uint64
+		max   uint64
+		want  uint64
+		err   error
+	}{
+		{
+			name:  "zero count",
+			start: 10,
+			count: 0,
+			max:   64,
+			err:   p2ptypes.ErrInvalidRequest,
+		},
+		{
+			name:  "overflow",
+			start: math.MaxUint64 - 1,
+			count: 3,
+			max:   64,
+			err:   p2ptypes.ErrInvalidRequest,
+		},
+		{
+			name:  "overflow checked before clamping",
+			start: math.MaxUint64 - 10,
+			count: 100,
+			max:   5,
+			err:   p2ptypes.ErrInvalidRequest,
+		},
+		{
+			name:  "last slot",
+			start: math.MaxUint64,
+			count: 1,
+			max:   64,
+			want:  1,
+		},
+		{
+			name:  "clamped to max",
+			start: 10,
+			count: 100,
+			max:   64,
+			want:  64,
+		},
+		{
+			name:  "within max",
+			start: 10,
+			count: 32,
+			max:   64,
+			want:  32,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, count, err := validateByRangeRequest(tt.start, tt.count, tt.max)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.start, start)
+			require.Equal(t, tt.want, count)
+		})
+	}
+}
+
 func TestRPCBeaconBlocksByRange_EnforceResponseInvariants(t *testing.T) {
 	d := db.SetupDB(t)
 	hook := logTest.NewGlobal()
@@ -104,7 +104,7 @@ func (s *Service) beaconBlocksRootRPCHandler(ctx context.Context, msg interface{
 		blk, err := s.cfg.beaconDB.Block(ctx, root)
 		if err != nil {
 			log.WithError(err).Debug("Could not fetch block")
-			s.writeErrorResponseToStream(responseCodeServerError, types.ErrGeneric.Error(), stream)
+			s.writeErrorResponseWithContext(ctx, responseCodeServerError, types.ErrGeneric.Error(), stream)
 			return err
 		}
 		if err := blocks.BeaconBlockIsNil(blk); err != nil {
@@ -119,7 +119,7 @@ func (s *Service) beaconBlocksRootRPCHandler(ctx context.Context, msg interface{
 				} else {
 					log.WithError(err).Error("Could not get reconstruct full block from blinded body")
 				}
-				s.writeErrorResponseToStream(responseCodeServerError, types.ErrGeneric.Error(), stream)
+				s.writeErrorResponseWithContext(ctx, responseCodeServerError, types.ErrGeneric.Error(), stream)
 				return err
 			}
 		}
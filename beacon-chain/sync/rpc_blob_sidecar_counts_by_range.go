@@ -0,0 +1,136 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	libp2pcore "github.com/libp2p/go-libp2p/core"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/encoder"
+	p2ptypes "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/types"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/monitoring/tracing"
+	"github.com/prysmaticlabs/prysm/v5/monitoring/tracing/trace"
+)
+
+// blobSidecarCountsByRangeRPCHandler responds to a BlobSidecarCountsByRange request with the number of blob sidecars
+// stored for each canonical block in the range, without reading the sidecars. The request is validated and clamped
+// like a BlobSidecarsByRange request, and the counts stop once they add up to MAX_REQUEST_BLOB_SIDECARS, so that the
+// response describes what a BlobSidecarsByRange request for the same range would be served.
+func (s *Service) blobSidecarCountsByRangeRPCHandler(ctx context.Context, msg interface{}, stream libp2pcore.Stream) error {
+	ctx, span := trace.StartSpan(ctx, "sync.BlobSidecarCountsByRangeHandler")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, respTimeout)
+	defer cancel()
+	SetRPCStreamDeadlines(stream)
+	log := log.WithField("handler", p2p.BlobSidecarCountsByRangeName[1:]) // slice the leading slash off the name var
+
+	r, err := blobsByRangeRequest(msg)
+	if err != nil {
+		return err
+	}
+	if err := s.rateLimiter.validateRequest(stream, 1); err != nil {
+		return err
+	}
+	if !s.cfg.blobServingAccessList.Allowed(stream.Conn().RemotePeer()) {
+		log.WithField("peer", stream.Conn().RemotePeer().String()).Trace("Peer is not allowed to be served blob sidecar counts")
+		s.writeErrorResponseToStream(responseCodeResourceUnavailable, p2ptypes.ErrResourceUnavailable.Error(), stream)
+		return nil
+	}
+	rp, err := validateBlobsByRange(r, s.cfg.chain.CurrentSlot())
+	if err != nil {
+		s.writeErrorResponseToStream(responseCodeInvalidRequest, err.Error(), stream)
+		s.cfg.p2p.Peers().Scorers().BadResponsesScorer().Increment(stream.Conn().RemotePeer())
+		tracing.AnnotateError(span, err)
+		return err
+	}
+	if s.blobsSkippedByBackfill(rp.start) {
+		s.writeErrorResponseToStream(responseCodeResourceUnavailable, p2ptypes.ErrResourceUnavailable.Error(), stream)
+		return nil
+	}
+
+	// Throttle to stagger out large requests.
+	throttle := newBatchThrottle(s.wallClock(), batchThrottleInterval)
+	defer throttle.stop()
+	batcher, err := newBlockRangeBatcher(rp, s.cfg.beaconDB, s.rateLimiter, s.cfg.chain.IsCanonical, throttle)
+	if err != nil {
+		log.WithError(err).Info("error in BlobSidecarCountsByRange batch")
+		s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+		tracing.AnnotateError(span, err)
+		return err
+	}
+
+	remaining := params.BeaconConfig().MaxRequestBlobSidecars
+	var batch blockBatch
+	var ok bool
+	for batch, ok = batcher.next(ctx, stream); ok; batch, ok = batcher.next(ctx, stream) {
+		remaining, err = s.streamBlobCountBatch(ctx, batch, remaining, stream)
+		if err != nil {
+			tracing.AnnotateError(span, err)
+			return err
+		}
+		// The counts for the range add up to MAX_REQUEST_BLOB_SIDECARS, a full response would end here.
+		if remaining == 0 {
+			break
+		}
+	}
+	if err := batch.error(); err != nil {
+		log.WithError(err).Debug("error in BlobSidecarCountsByRange batch")
+		s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+		tracing.AnnotateError(span, err)
+		return err
+	}
+	closeStream(stream, log)
+	return nil
+}
+
+// streamBlobCountBatch writes a count for every canonical block in the batch, including blocks with no sidecars, so
+// the requester can tell which blocks the responder has. The count for the last block is reduced so that the counts
+// never add up to more than the remaining quota.
+func (s *Service) streamBlobCountBatch(ctx context.Context, batch blockBatch, remaining uint64, stream libp2pcore.Stream) (uint64, error) {
+	for _, b := range batch.canonical() {
+		if remaining == 0 {
+			return 0, nil
+		}
+		root := b.Root()
+		var count uint64
+		if expectsBlobs(b) {
+			idxs, err := s.cfg.blobStorage.Indices(root)
+			if err != nil {
+				s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+				return remaining, errors.Wrapf(err, "could not retrieve sidecar indices for block root %#x", root)
+			}
+			for i := range idxs {
+				if idxs[i] {
+					count++
+				}
+			}
+		} else {
+			blobLookupsSkipped.Inc()
+		}
+		if count > remaining {
+			count = remaining
+		}
+		SetStreamWriteDeadline(stream, defaultWriteDuration)
+		c := &p2ptypes.BlobSidecarCount{Slot: b.Block().Slot(), BlockRoot: root, Count: count}
+		if err := WriteBlobSidecarCountChunk(stream, s.cfg.p2p.Encoding(), c); err != nil {
+			chunkFailures.observe(err, time.Now())
+			s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+			return remaining, err
+		}
+		s.rateLimiter.add(stream, 1)
+		remaining -= count
+	}
+	return remaining, nil
+}
+
+// WriteBlobSidecarCountChunk writes a BlobSidecarCount response chunk to the stream. Counts are the same for every
+// fork, so unlike sidecar chunks the chunk has no context bytes.
+func WriteBlobSidecarCountChunk(stream libp2pcore.Stream, encoding encoder.NetworkEncoding, c *p2ptypes.BlobSidecarCount) error {
+	if _, err := stream.Write([]byte{responseCodeSuccess}); err != nil {
+		return err
+	}
+	_, err := encoding.EncodeWithMaxLength(stream, c)
+	return err
+}
@@ -0,0 +1,153 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	types "github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func (c *blobsTestCase) runTestBlobSidecarCountsByRange(t *testing.T) {
+	var req *ethpb.BlobSidecarsByRangeRequest
+	if c.serverHandle == nil {
+		c.serverHandle = func(s *Service) rpcHandler { return s.blobSidecarCountsByRangeRPCHandler }
+	}
+	c.topic = p2p.RPCBlobSidecarCountsByRangeTopicV1
+	if c.requestFromSidecars == nil {
+		c.requestFromSidecars = blobRangeRequestFromSidecars
+	}
+	fromSidecars := c.requestFromSidecars
+	c.requestFromSidecars = func(scs []blocks.ROBlob) interface{} {
+		r := fromSidecars(scs)
+		req = r.(*ethpb.BlobSidecarsByRangeRequest)
+		return r
+	}
+	if c.streamReader == nil {
+		c.streamReader = func(t *testing.T, s *Service, expect []*expectedBlobChunk) func(network.Stream) {
+			return func(stream network.Stream) {
+				got, err := readChunkEncodedBlobCounts(stream, s.cfg.p2p.Encoding(), req)
+				require.NoError(t, err)
+				// Every sidecar served by a BlobSidecarsByRange request for the range is counted.
+				want := make(map[types.Slot]uint64)
+				for _, e := range expect {
+					want[e.sidecar.Slot()]++
+				}
+				var total uint64
+				for _, g := range got {
+					require.Equal(t, want[g.Slot], g.Count)
+					total += g.Count
+				}
+				require.Equal(t, uint64(len(expect)), total)
+			}
+		}
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
+
+func TestBlobSidecarCountsByRange(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	cases := []*blobsTestCase{
+		{
+			name:    "beginning of window + 10",
+			nblocks: 10,
+		},
+		{
+			name:    "10 slots before window, 10 slots after, count = 20",
+			nblocks: 10,
+			requestFromSidecars: func(scs []blocks.ROBlob) interface{} {
+				return &ethpb.BlobSidecarsByRangeRequest{
+					StartSlot: scs[0].Slot() - 10,
+					Count:     20,
+				}
+			},
+		},
+		{
+			name:    "counts stop at MAX_REQUEST_BLOB_SIDECARS",
+			nblocks: int(params.BeaconConfig().MaxRequestBlocksDeneb) + 10,
+			requestFromSidecars: func(scs []blocks.ROBlob) interface{} {
+				return &ethpb.BlobSidecarsByRangeRequest{
+					StartSlot: scs[0].Slot(),
+					Count:     params.BeaconConfig().MaxRequestBlocksDeneb + 1,
+				}
+			},
+			total: func() *int { x := int(params.BeaconConfig().MaxRequestBlobSidecars); return &x }(),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.runTestBlobSidecarCountsByRange(t)
+		})
+	}
+}
+
+func TestBlobSidecarCountsByRangeAccessList(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	c := &blobsTestCase{
+		name:    "peer not in allowlist",
+		nblocks: 1,
+		serverHandle: func(s *Service) rpcHandler {
+			s.cfg.blobServingAccessList = NewPeerAccessList([]peer.ID{"trusted"}, nil)
+			return s.blobSidecarCountsByRangeRPCHandler
+		},
+		streamReader: func(t *testing.T, s *Service, _ []*expectedBlobChunk) func(network.Stream) {
+			return func(stream network.Stream) {
+				code, _, err := ReadStatusCode(stream, s.cfg.p2p.Encoding())
+				require.NoError(t, err)
+				require.Equal(t, responseCodeResourceUnavailable, code)
+			}
+		},
+	}
+	c.runTestBlobSidecarCountsByRange(t)
+}
+
+func TestBlobSidecarCountsByRangeSkippedByBackfill(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	var start types.Slot
+	c := &blobsTestCase{
+		name:    "range backfilled without blobs",
+		nblocks: 10,
+		requestFromSidecars: func(scs []blocks.ROBlob) interface{} {
+			start = scs[0].Slot()
+			return blobRangeRequestFromSidecars(scs)
+		},
+		serverHandle: func(s *Service) rpcHandler {
+			s.availableBlobber = mockBlobber{lowSlot: start + 1}
+			return s.blobSidecarCountsByRangeRPCHandler
+		},
+		streamReader: func(t *testing.T, s *Service, _ []*expectedBlobChunk) func(network.Stream) {
+			return func(stream network.Stream) {
+				code, _, err := ReadStatusCode(stream, s.cfg.p2p.Encoding())
+				require.NoError(t, err)
+				require.Equal(t, responseCodeResourceUnavailable, code)
+			}
+		},
+	}
+	c.runTestBlobSidecarCountsByRange(t)
+}
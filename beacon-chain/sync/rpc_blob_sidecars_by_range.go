@@ -2,56 +2,206 @@ package sync
 
 import (
 	"context"
+	"fmt"
 	"math"
+	"runtime/debug"
+	"sync/atomic"
 	"time"
 
 	libp2pcore "github.com/libp2p/go-libp2p/core"
 	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/filesystem"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
 	p2ptypes "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/types"
 	"github.com/prysmaticlabs/prysm/v5/cmd/beacon-chain/flags"
+	"github.com/prysmaticlabs/prysm/v5/config/features"
 	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	"github.com/prysmaticlabs/prysm/v5/monitoring/tracing"
 	"github.com/prysmaticlabs/prysm/v5/monitoring/tracing/trace"
 	pb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/prysmaticlabs/prysm/v5/runtime/version"
 	"github.com/prysmaticlabs/prysm/v5/time/slots"
+	"github.com/sirupsen/logrus"
 )
 
-func (s *Service) streamBlobBatch(ctx context.Context, batch blockBatch, wQuota uint64, stream libp2pcore.Stream) (uint64, error) {
+var (
+	errBlobsByRangePanic        = errors.New("recovered from panic while serving BlobSidecarsByRange request")
+	errServedBlobRootMismatch   = errors.New("blob sidecar block root does not match the root it is stored under")
+	errBlobWriteBudgetExhausted = errors.New("insufficient time remaining before response deadline to write another blob sidecar")
+	errHistoricalBlobsExhausted = errors.New("historical blob budget of the peer does not cover another blob sidecar")
+)
+
+// minBlobWriteBudget is the least amount of time that must remain before the response deadline
+// in order to attempt writing the sidecars for another block.
+var minBlobWriteBudget = 250 * time.Millisecond
+
+// blobWriteBudget tracks the time remaining to serve a blob sidecars request, so that the handler can stop early
+// with a partial response when a slow peer won't be able to receive another sidecar before the deadline.
+type blobWriteBudget struct {
+	deadline    time.Time
+	hasDeadline bool
+	slowest     time.Duration
+}
+
+func newBlobWriteBudget(ctx context.Context) *blobWriteBudget {
+	deadline, ok := ctx.Deadline()
+	return &blobWriteBudget{deadline: deadline, hasDeadline: ok}
+}
+
+// observe records the time it took to write a sidecar.
+func (b *blobWriteBudget) observe(d time.Duration) {
+	if d > b.slowest {
+		b.slowest = d
+	}
+}
+
+// sufficient reports whether there is enough time left to write another sidecar, based on the slowest
+// write seen so far for the request, and no less than minBlobWriteBudget.
+func (b *blobWriteBudget) sufficient(now time.Time) bool {
+	if !b.hasDeadline {
+		return true
+	}
+	need := minBlobWriteBudget
+	if b.slowest > need {
+		need = b.slowest
+	}
+	return b.deadline.Sub(now) >= need
+}
+
+// blobSidecarServeCost is the number of bytes counted for each blob sidecar that is served. The ssz encoding of a blob
+// sidecar has a fixed size, so the cost is the same for every sidecar and there is nothing to compute or cache.
+const blobSidecarServeCost = uint64(fieldparams.BlobSidecarSize)
+
+// writeBlobSidecarChunk is a package variable so that tests can substitute the chunk writer. The fork digest is
+// resolved by the caller through slotForkCache, so that it is computed once per epoch rather than for every sidecar.
+var writeBlobSidecarChunk = writeBlobSidecarChunkWithDigest
+
+// blobForkScheduleLogged is one more than the epoch of the last sidecar logged by skipBlobForkSchedule, or 0 if
+// nothing was logged yet.
+var blobForkScheduleLogged atomic.Uint64
+
+// skipBlobForkSchedule reports whether a sidecar that could not be written should be skipped, rather than ending the
+// response, because the fork schedule has no blob fork for its slot. Nothing has been written to the stream for the
+// sidecar in that case, and the rest of the response can still be served. Every skipped sidecar is counted, but the
+// error is only logged once per epoch, since every sidecar of the misconfigured epochs fails the same way until the
+// operator fixes the fork epochs of the chain config.
+func skipBlobForkSchedule(err error, sc blocks.VerifiedROBlob) bool {
+	if !errors.Is(err, ErrBlobForkSchedule) {
+		return false
+	}
+	blobsSkippedForkSchedule.Inc()
+	epoch := uint64(slots.ToEpoch(sc.Slot())) + 1
+	if blobForkScheduleLogged.Swap(epoch) == epoch {
+		return true
+	}
+	log.WithError(err).WithFields(logrus.Fields{
+		"slot":  sc.Slot(),
+		"root":  fmt.Sprintf("%#x", sc.BlockRoot()),
+		"index": sc.Index,
+	}).Error("Skipping blob sidecars in response, the fork schedule of the chain config does not cover their epoch")
+	return true
+}
+
+func (s *Service) streamBlobBatch(ctx context.Context, batch blockBatch, wQuota uint64, budget *blobWriteBudget, order *blobResponseOrder, forks *slotForkCache, blobs *filesystem.BlobSnapshot, stream *flushingStream) (uint64, error) {
 	// Defensive check to guard against underflow.
 	if wQuota == 0 {
 		return 0, nil
 	}
 	_, span := trace.StartSpan(ctx, "sync.streamBlobBatch")
 	defer span.End()
-	for _, b := range batch.canonical() {
-		root := b.Root()
-		idxs, err := s.cfg.blobStorage.Indices(b.Root())
+	var shared blobRangeSidecars
+	if flags.Get().BlobServeCoalesceReads {
+		key := blobRangeKey{start: batch.start, count: batch.slotRange().Len(), indices: allBlobIndices}
+		var err error
+		shared, err = s.blobReads.read(key, s.wallClock().Now(), func() (blobRangeSidecars, error) {
+			return readBlobBatch(batch, blobs)
+		})
 		if err != nil {
-			s.writeErrorResponseToStream(responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
-			return wQuota, errors.Wrapf(err, "could not retrieve sidecars for block root %#x", root)
+			s.blobStore.failure(s.wallClock().Now())
+			s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+			return wQuota, err
+		}
+		s.blobStore.success()
+	}
+	hot, hasHot := blobHotWindowStart(s.cfg.chain.CurrentSlot())
+	valRoot := s.cfg.chain.GenesisValidatorsRoot()
+	self := fromSelf(s.cfg.p2p, stream)
+	for _, b := range order.ordered(batch) {
+		if !budget.sufficient(time.Now()) {
+			return wQuota, errBlobWriteBudgetExhausted
+		}
+		if s.blobServes.stopping() {
+			return wQuota, errBlobServeDraining
+		}
+		if !expectsBlobs(b) {
+			blobLookupsSkipped.Inc()
+			continue
+		}
+		scs, ok := shared[b.Root()]
+		if !ok {
+			var err error
+			scs, err = readBlockSidecars(b.Root(), blobs)
+			if err != nil {
+				s.blobStore.failure(s.wallClock().Now())
+				s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+				return wQuota, err
+			}
+			s.blobStore.success()
 		}
-		for i, l := uint64(0), uint64(len(idxs)); i < l; i++ {
-			// index not available, skip
-			if !idxs[i] {
+		for _, sc := range scs {
+			if features.Get().ServeCanonicalBlobsOnly && !s.canonicalBlob(ctx, sc.ROBlob) {
+				blobsSkippedNonCanonical.Inc()
 				continue
 			}
-			// We won't check for file not found since the .Indices method should normally prevent that from happening.
-			sc, err := s.cfg.blobStorage.Get(b.Root(), i)
+			// The budget is checked for every sidecar, since adding to it is capped at its capacity, so a single
+			// check up front would let a large request through no matter how much of it is historical.
+			historical := hasHot && sc.Slot() < hot
+			if historical && !s.rateLimiter.historicalBlobAllowed(stream) {
+				return wQuota, errHistoricalBlobsExhausted
+			}
+			if err := order.next(sc.ROBlob); err != nil {
+				log.WithError(err).Error("Ending BlobSidecarsByRange response")
+				s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+				tracing.AnnotateError(span, err)
+				return wQuota, err
+			}
+			digest, err := forks.blobDigest(sc.Slot(), valRoot[:])
 			if err != nil {
-				s.writeErrorResponseToStream(responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
-				return wQuota, errors.Wrapf(err, "could not retrieve sidecar: index %d, block root %#x", i, root)
+				if skipBlobForkSchedule(err, sc) {
+					continue
+				}
+				s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+				tracing.AnnotateError(span, err)
+				return wQuota, err
 			}
 			SetStreamWriteDeadline(stream, defaultWriteDuration)
-			if chunkErr := WriteBlobSidecarChunk(stream, s.cfg.chain, s.cfg.p2p.Encoding(), sc); chunkErr != nil {
-				log.WithError(chunkErr).Debug("Could not send a chunked response")
-				s.writeErrorResponseToStream(responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+			writeStart := time.Now()
+			if chunkErr := writeBlobSidecarChunk(stream, s.cfg.p2p.Encoding(), digest, sc); chunkErr != nil {
+				chunkFailures.observe(chunkErr, time.Now())
+				s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
 				tracing.AnnotateError(span, chunkErr)
 				return wQuota, chunkErr
 			}
+			if err := stream.chunkWritten(time.Now()); err != nil {
+				log.WithError(err).Debug("Could not flush chunked response")
+				tracing.AnnotateError(span, err)
+				return wQuota, err
+			}
+			budget.observe(time.Since(writeStart))
+			s.observeServedBelowFloor(sc.ROBlob)
 			s.rateLimiter.add(stream, 1)
+			if historical {
+				s.rateLimiter.addHistoricalBlobs(stream, 1)
+			}
+			if !self {
+				blobServeStats.add(stream.Conn().RemotePeer(), 1, blobSidecarServeCost)
+				fork := forks.label(sc.Slot())
+				blobSidecarsServedByFork.WithLabelValues(fork).Inc()
+				blobBytesServedByFork.WithLabelValues(fork).Add(float64(blobSidecarServeCost))
+			}
 			wQuota -= 1
 			// Stop streaming results once the quota of writes for the request is consumed.
 			if wQuota == 0 {
@@ -62,106 +212,449 @@ func (s *Service) streamBlobBatch(ctx context.Context, batch blockBatch, wQuota
 	return wQuota, nil
 }
 
+// blobSlotSeeker lets the batcher skip over slots that have no sidecars in blob storage, so that sparse ranges, such
+// as those spanning the Deneb fork, are not read from the db one batch of empty slots at a time. If blob storage
+// can't answer, for instance while its cache is warming up at startup, the seeker doesn't skip anything and every slot
+// is read as before. The lookup doesn't block, so it can't hold up the response.
+func blobSlotSeeker(blobs *filesystem.BlobSnapshot, end primitives.Slot) slotSeeker {
+	return func(from primitives.Slot) (primitives.Slot, bool) {
+		next, ok, err := blobs.NextBlobSlot(from)
+		if err != nil {
+			log.WithError(err).Debug("Could not find the next slot with blob sidecars, reading every slot in the range")
+			return from, true
+		}
+		if !ok || next > end {
+			rpcBlobsByRangeSlotsSkipped.Add(float64(end.SubSlot(from) + 1))
+			return 0, false
+		}
+		rpcBlobsByRangeSlotsSkipped.Add(float64(next - from))
+		return next, true
+	}
+}
+
+// canonicalBlob checks whether the block root of the sidecar is on the canonical chain. The blocks of a batch are
+// canonical when the batch is read, but the chain may reorg before their sidecars are written, and the sidecar itself
+// may not match the block it is stored under, so the check uses the root from the sidecar at the time it is served.
+func (s *Service) canonicalBlob(ctx context.Context, sc blocks.ROBlob) bool {
+	canonical, err := s.cfg.chain.IsCanonical(ctx, sc.BlockRoot())
+	if err != nil {
+		log.WithError(err).WithFields(blobFields(sc)).Debug("Could not determine if blob sidecar is canonical")
+		return false
+	}
+	return canonical
+}
+
+// readBlobBatch reads the sidecars for every canonical block in the batch that can have blobs.
+func readBlobBatch(batch blockBatch, blobs *filesystem.BlobSnapshot) (blobRangeSidecars, error) {
+	scs := make(blobRangeSidecars)
+	for _, b := range batch.canonical() {
+		if !expectsBlobs(b) {
+			continue
+		}
+		bscs, err := readBlockSidecars(b.Root(), blobs)
+		if err != nil {
+			return nil, err
+		}
+		scs[b.Root()] = bscs
+	}
+	return scs, nil
+}
+
+// readBlockSidecars reads the sidecars stored for the given block root, in index order.
+func readBlockSidecars(root [32]byte, blobs *filesystem.BlobSnapshot) ([]blocks.VerifiedROBlob, error) {
+	idxs, err := blobs.Indices(root)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not retrieve sidecars for block root %#x", root)
+	}
+	var scs []blocks.VerifiedROBlob
+	for i, l := uint64(0), uint64(len(idxs)); i < l; i++ {
+		// index not available, skip
+		if !idxs[i] {
+			continue
+		}
+		// We won't check for file not found since the .Indices method should normally prevent that from happening.
+		sc, err := blobs.Get(root, i)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not retrieve sidecar: index %d, block root %#x", i, root)
+		}
+		if features.Get().VerifyServedBlobInclusionProofs {
+			if err := verifyServedBlobSidecar(root, sc.ROBlob); err != nil {
+				log.WithError(err).WithFields(blobFields(sc.ROBlob)).Warn("Skipping blob sidecar that failed verification")
+				continue
+			}
+		}
+		scs = append(scs, sc)
+	}
+	// Indices already iterates in index order, the sort makes the ordering of the response explicit, see
+	// blobResponseOrder.
+	sortBlobsByIndex(scs)
+	return scs, nil
+}
+
+// observeServedBelowFloor counts sidecars that were served for a slot older than the blob retention floor
+// (see BlobRPCMinValidSlot). Request validation keeps the start of the range above the floor, but the floor can move
+// forward while a response is written, and archival nodes keep sidecars older than the floor.
+func (s *Service) observeServedBelowFloor(sc blocks.ROBlob) {
+	floor, err := BlobRPCMinValidSlot(s.cfg.chain.CurrentSlot())
+	if err != nil || sc.Slot() >= floor {
+		return
+	}
+	blobsServedBelowFloor.Inc()
+	log.WithFields(blobFields(sc)).WithField("floor", floor).Debug("Served blob sidecar older than the retention floor")
+}
+
+// blobsSkippedByBackfill reports whether backfill imported the block at the given slot without its blobs, or pruned
+// them. It is only true when backfill is configured to skip or prune blobs, see WithAvailableBlobber.
+func (s *Service) blobsSkippedByBackfill(sl primitives.Slot) bool {
+	return s.availableBlobber != nil && !s.availableBlobber.BlobSlotCovered(sl)
+}
+
+// pausedForProposal reports whether a blob sidecar range request should be turned away because a validator attached
+// to the node is about to propose, see WithProposalDutyChecker. Requests that span no more than an epoch of slots are
+// still served, only larger ranges are paused.
+func (s *Service) pausedForProposal(rp rangeParams) bool {
+	within := flags.Get().BlobServeProposalPause
+	if within == 0 || s.proposalDuties == nil {
+		return false
+	}
+	if rp.slotRange().Len() <= uint64(params.BeaconConfig().SlotsPerEpoch) {
+		return false
+	}
+	return s.proposalDuties.ProposalImminent(within)
+}
+
+// refusedWhileSyncing reports whether a blob sidecar range request should be turned away because the head of the node
+// is more than serve-while-syncing-margin epochs behind the current slot, so that catching up with the chain is
+// prioritized over serving history. While the node is behind, only requests that span no more than an epoch of slots
+// and start within the margin of the head are served, since those read the recent sidecars that are likely cached.
+func (s *Service) refusedWhileSyncing(rp rangeParams) bool {
+	margin := primitives.Epoch(flags.Get().ServeWhileSyncingMargin)
+	if flags.Get().ServeWhileSyncing || margin == 0 {
+		return false
+	}
+	lag, err := slots.EpochStart(margin)
+	if err != nil {
+		return false
+	}
+	head, current := s.cfg.chain.HeadSlot(), s.cfg.chain.CurrentSlot()
+	if current <= head || current-head <= lag {
+		return false
+	}
+	if rp.slotRange().Len() > uint64(params.BeaconConfig().SlotsPerEpoch) {
+		return true
+	}
+	return head > lag && rp.start < head-lag
+}
+
+// blobHotWindowStart returns the first slot of the window of recent epochs that are served with the normal blob budget,
+// and false if a hot window is not configured. Sidecars before this slot also draw from the historical blob budget.
+func blobHotWindowStart(current primitives.Slot) (primitives.Slot, bool) {
+	hotEpochs := primitives.Epoch(flags.Get().BlobServingHotEpochs)
+	if hotEpochs == 0 {
+		return 0, false
+	}
+	// The window includes the current epoch.
+	currentEpoch := slots.ToEpoch(current)
+	if currentEpoch < hotEpochs {
+		return 0, true
+	}
+	start, err := slots.EpochStart(currentEpoch + 1 - hotEpochs)
+	if err != nil {
+		return 0, true
+	}
+	return start, true
+}
+
+// expectsBlobs reports whether blob storage could have sidecars for the block. Blocks from before deneb and blocks
+// without blob kzg commitments can't have sidecars, so serving a range can skip the storage lookup for them.
+func expectsBlobs(b blocks.ROBlock) bool {
+	if b.Version() < version.Deneb {
+		return false
+	}
+	commits, err := b.Block().Body().BlobKzgCommitments()
+	if err != nil {
+		// Fall back to the storage lookup rather than risk not serving sidecars that exist.
+		return true
+	}
+	return len(commits) > 0
+}
+
+// verifyServedBlobSidecar checks that a sidecar read from blob storage belongs to the block it is stored under,
+// and that its kzg commitment inclusion proof is valid against the block header.
+func verifyServedBlobSidecar(root [32]byte, sc blocks.ROBlob) error {
+	if sc.BlockRoot() != root {
+		return errors.Wrapf(errServedBlobRootMismatch, "sidecar root=%#x, stored root=%#x", sc.BlockRoot(), root)
+	}
+	return blocks.VerifyKZGInclusionProof(sc)
+}
+
 // blobsSidecarsByRangeRPCHandler looks up the request blobs from the database from a given start slot index
+// Sidecars are written ordered by slot, then by index: every sidecar for a slot is sent before any sidecar for a
+// later slot, as the spec requires. See blobResponseOrder.
 func (s *Service) blobSidecarsByRangeRPCHandler(ctx context.Context, msg interface{}, stream libp2pcore.Stream) error {
-	var err error
+	return s.serveBlobSidecarsByRange(ctx, msg, stream, false)
+}
+
+// blobSidecarsByRangeReverseRPCHandler serves the same sidecars as blobSidecarsByRangeRPCHandler, newest first: the
+// range is read from its last slot down to the start slot, for requesters that consume history backwards, like
+// backfill. The sidecars of each slot are still written in index order. The caps and throttling are the same.
+func (s *Service) blobSidecarsByRangeReverseRPCHandler(ctx context.Context, msg interface{}, stream libp2pcore.Stream) error {
+	return s.serveBlobSidecarsByRange(ctx, msg, stream, true)
+}
+
+func (s *Service) serveBlobSidecarsByRange(ctx context.Context, msg interface{}, stream libp2pcore.Stream, reverse bool) (err error) {
 	ctx, span := trace.StartSpan(ctx, "sync.BlobsSidecarsByRangeHandler")
 	defer span.End()
 	ctx, cancel := context.WithTimeout(ctx, respTimeout)
 	defer cancel()
 	SetRPCStreamDeadlines(stream)
 	log := log.WithField("handler", p2p.BlobSidecarsByRangeName[1:]) // slice the leading slash off the name var
+	// Buffer the response, every write below goes through the flushing stream so that chunks and error
+	// responses reach the peer in order when the stream is closed.
+	fstream := newFlushingStream(stream, flags.Get().BlobServeFlushInterval)
+	stream = fstream
 
-	r, ok := msg.(*pb.BlobSidecarsByRangeRequest)
-	if !ok {
-		return errors.New("message is not type *pb.BlobsSidecarsByRangeRequest")
+	r, err := blobsByRangeRequest(msg)
+	if err != nil {
+		return err
+	}
+	maxQuota := params.BeaconConfig().MaxRequestBlobSidecars
+	wQuota := maxQuota
+	// Responses aren't started once the service is stopping, see blobServeDrain.
+	if !s.blobServes.begin() {
+		s.writeErrorResponseToStream(responseCodeResourceUnavailable, p2ptypes.ErrResourceUnavailable.Error(), stream)
+		return nil
 	}
+	defer s.blobServes.done()
+	// Make sure that a panic while reading or writing a sidecar doesn't leave the stream open.
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.WithField("error", rec).
+				WithField("peer", stream.Conn().RemotePeer().String()).
+				WithField("startSlot", r.StartSlot).
+				WithField("count", r.Count).
+				WithField("stack", string(debug.Stack())).
+				Error("Panic occurred while serving BlobSidecarsByRange request")
+			// Only send an error response if the peer hasn't already received sidecars.
+			if wQuota == maxQuota {
+				s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+			}
+			closeStream(stream, log)
+			err = errBlobsByRangePanic
+			tracing.AnnotateError(span, err)
+		}
+	}()
 	if err := s.rateLimiter.validateRequest(stream, 1); err != nil {
 		return err
 	}
+	if !s.cfg.blobServingAccessList.Allowed(stream.Conn().RemotePeer()) {
+		log.WithField("peer", stream.Conn().RemotePeer().String()).Trace("Peer is not allowed to be served blob sidecars")
+		s.writeErrorResponseToStream(responseCodeResourceUnavailable, p2ptypes.ErrResourceUnavailable.Error(), stream)
+		return nil
+	}
 	rp, err := validateBlobsByRange(r, s.cfg.chain.CurrentSlot())
 	if err != nil {
 		s.writeErrorResponseToStream(responseCodeInvalidRequest, err.Error(), stream)
-		s.cfg.p2p.Peers().Scorers().BadResponsesScorer().Increment(stream.Conn().RemotePeer())
+		if !fromSelf(s.cfg.p2p, stream) {
+			s.cfg.p2p.Peers().Scorers().BadResponsesScorer().Increment(stream.Conn().RemotePeer())
+		}
 		tracing.AnnotateError(span, err)
 		return err
 	}
+	if s.blobsSkippedByBackfill(rp.start) {
+		log.WithField("startSlot", rp.start).Debug("Blob sidecars in the requested range were not backfilled")
+		s.writeErrorResponseToStream(responseCodeResourceUnavailable, p2ptypes.ErrResourceUnavailable.Error(), stream)
+		return nil
+	}
+	if s.pausedForProposal(rp) {
+		log.WithField("peer", stream.Conn().RemotePeer().String()).WithField("startSlot", rp.start).
+			WithField("count", r.Count).Debug("Not serving large blob sidecar range while a proposal is imminent")
+		blobRangesPausedForProposal.Inc()
+		s.writeErrorResponseToStream(responseCodeResourceUnavailable, p2ptypes.ErrResourceUnavailable.Error(), stream)
+		return nil
+	}
+	if s.refusedWhileSyncing(rp) {
+		log.WithField("peer", stream.Conn().RemotePeer().String()).WithField("startSlot", rp.start).
+			WithField("count", r.Count).Debug("Not serving large blob sidecar range while the node is behind the head of the chain")
+		blobRangesRefusedWhileSyncing.Inc()
+		s.writeErrorResponseToStream(responseCodeResourceUnavailable, p2ptypes.ErrResourceUnavailable.Error(), stream)
+		return nil
+	}
+	release, allowed := s.blobStore.allow(s.wallClock().Now())
+	if !allowed {
+		log.WithField("peer", stream.Conn().RemotePeer().String()).WithField("startSlot", rp.start).
+			WithField("count", r.Count).Debug("Not serving blob sidecar range while blob storage reads are failing")
+		blobRangesRefusedStoreBreaker.Inc()
+		s.writeErrorResponseToStream(responseCodeResourceUnavailable, p2ptypes.ErrResourceUnavailable.Error(), stream)
+		return nil
+	}
+	defer release()
+	if hot, ok := blobHotWindowStart(s.cfg.chain.CurrentSlot()); ok && rp.start < hot {
+		if err := s.rateLimiter.validateHistoricalBlobRequest(stream, 1); err != nil {
+			return err
+		}
+	}
 
-	// Ticker to stagger out large requests.
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-	batcher, err := newBlockRangeBatcher(rp, s.cfg.beaconDB, s.rateLimiter, s.cfg.chain.IsCanonical, ticker)
+	// Throttle to stagger out large requests.
+	throttle := newBatchThrottle(s.wallClock(), batchThrottleInterval)
+	defer throttle.stop()
+	batcher, err := newBlockRangeBatcher(rp, s.cfg.beaconDB, s.rateLimiter, s.cfg.chain.IsCanonical, throttle)
 	if err != nil {
 		log.WithError(err).Info("error in BlobSidecarsByRange batch")
-		s.writeErrorResponseToStream(responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+		s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
 		tracing.AnnotateError(span, err)
 		return err
 	}
 
+	// Unlike BeaconBlocksByRange, the range is not checked against the block backfill status. Apart from ranges
+	// that backfill skipped blobs for, whether a sidecar can be served is decided by blob storage, so sidecars that
+	// are present are served even if the status lags behind.
+	// Read the range from a snapshot, so that the pruner can't delete sidecars in the range while it is served.
+	blobs := s.cfg.blobStorage.SlotRangeSnapshot(rp.start)
+	defer blobs.Release()
+	if reverse {
+		batcher.reverse = true
+	} else {
+		batcher.seek = blobSlotSeeker(blobs, rp.end)
+	}
+	budget := newBlobWriteBudget(ctx)
+	order := &blobResponseOrder{reverse: reverse}
+	// The fork is resolved once per epoch of the range, rather than for every sidecar that is served.
+	forks := &slotForkCache{}
+	term := blobServeTermEndOfRange
 	var batch blockBatch
-	wQuota := params.BeaconConfig().MaxRequestBlobSidecars
+	var ok bool
 	for batch, ok = batcher.next(ctx, stream); ok; batch, ok = batcher.next(ctx, stream) {
 		batchStart := time.Now()
-		wQuota, err = s.streamBlobBatch(ctx, batch, wQuota, stream)
+		wQuota, err = s.streamBlobBatch(ctx, batch, wQuota, budget, order, forks, blobs, fstream)
 		rpcBlobsByRangeResponseLatency.Observe(float64(time.Since(batchStart).Milliseconds()))
+		if errors.Is(err, errBlobWriteBudgetExhausted) {
+			// Send the peer a partial response rather than letting the write fail at the deadline.
+			log.WithField("peer", stream.Conn().RemotePeer().String()).
+				WithField("sent", maxQuota-wQuota).
+				Debug("Ending BlobSidecarsByRange response early, response deadline is too close to write more sidecars")
+			term = blobServeTermCap
+			break
+		}
+		if errors.Is(err, errHistoricalBlobsExhausted) {
+			log.WithField("peer", stream.Conn().RemotePeer().String()).
+				WithField("sent", maxQuota-wQuota).
+				Debug("Ending BlobSidecarsByRange response early, the historical blob budget of the peer is exhausted")
+			term = blobServeTermRateLimited
+			break
+		}
+		if errors.Is(err, errBlobServeDraining) {
+			// End the response after the last complete chunk, so that the peer doesn't see a reset stream.
+			log.WithField("peer", stream.Conn().RemotePeer().String()).
+				WithField("sent", maxQuota-wQuota).
+				Debug("Ending BlobSidecarsByRange response early, the node is shutting down")
+			term = blobServeTermShutdown
+			break
+		}
 		if err != nil {
 			return err
 		}
 		// once we have written MAX_REQUEST_BLOB_SIDECARS, we're done serving the request
 		if wQuota == 0 {
+			term = blobServeTermCap
 			break
 		}
 	}
 	if err := batch.error(); err != nil {
 		log.WithError(err).Debug("error in BlobSidecarsByRange batch")
-		s.writeErrorResponseToStream(responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
+		s.writeErrorResponseWithContext(ctx, responseCodeServerError, p2ptypes.ErrGeneric.Error(), stream)
 		tracing.AnnotateError(span, err)
 		return err
 	}
 
+	served := maxQuota - wQuota
+	if served == 0 {
+		term = blobServeTermEmpty
+	}
+	rpcBlobsByRangeServedRatio.WithLabelValues(term).Observe(blobsServedRatio(r.Count, served, maxQuota))
 	closeStream(stream, log)
 	return nil
 }
 
+// Values for the termination label of the rpc_blobs_by_range_served_ratio metric. A response that ends because
+// the response deadline is too close to write more sidecars is counted as hitting a cap.
+const (
+	blobServeTermCap         = "cap"
+	blobServeTermEndOfRange  = "end_of_range"
+	blobServeTermEmpty       = "empty"
+	blobServeTermShutdown    = "shutdown"
+	blobServeTermRateLimited = "rate_limited"
+)
+
+// blobsServedRatio compares the number of sidecars served for a request to the most that could be served
+// for the requested count of slots, assuming every block has the maximum number of blobs, up to maxQuota.
+func blobsServedRatio(count, served, maxQuota uint64) float64 {
+	requested := maxQuota
+	if count < maxQuota/fieldparams.MaxBlobsPerBlock {
+		requested = count * fieldparams.MaxBlobsPerBlock
+	}
+	if requested == 0 {
+		return 0
+	}
+	return float64(served) / float64(requested)
+}
+
 // BlobRPCMinValidSlot returns the lowest slot that we should expect peers to respect as the
 // start slot in a BlobSidecarsByRange request. This can be used to validate incoming requests and
 // to avoid pestering peers with requests for blobs that are outside the retention window.
+// The retention window of each fork is taken into account, see params.BlobRetentionFloor.
 func BlobRPCMinValidSlot(current primitives.Slot) (primitives.Slot, error) {
 	// Avoid overflow if we're running on a config where deneb is set to far future epoch.
 	if params.BeaconConfig().DenebForkEpoch == math.MaxUint64 {
 		return primitives.Slot(math.MaxUint64), nil
 	}
-	minReqEpochs := params.BeaconConfig().MinEpochsForBlobsSidecarsRequest
-	currEpoch := slots.ToEpoch(current)
-	minStart := params.BeaconConfig().DenebForkEpoch
-	if currEpoch > minReqEpochs && currEpoch-minReqEpochs > minStart {
-		minStart = currEpoch - minReqEpochs
-	}
-	return slots.EpochStart(minStart)
+	return slots.EpochStart(params.BeaconConfig().BlobRetentionFloor(slots.ToEpoch(current)))
 }
 
 func blobBatchLimit() uint64 {
 	return uint64(flags.Get().BlockBatchLimit / fieldparams.MaxBlobsPerBlock)
 }
 
-func validateBlobsByRange(r *pb.BlobSidecarsByRangeRequest, current primitives.Slot) (rangeParams, error) {
-	if r.Count == 0 {
-		return rangeParams{}, errors.Wrap(p2ptypes.ErrInvalidRequest, "invalid request Count parameter")
+// NewBlobSidecarsByRangeRequest builds a BlobSidecarsByRange request, applying the same rules that peers use to
+// validate the request. Count must be non-zero, and the range must not overflow the slot type. Count is reduced
+// so that the request can't ask for more than MAX_REQUEST_BLOB_SIDECARS sidecars.
+func NewBlobSidecarsByRangeRequest(start primitives.Slot, count uint64) (*pb.BlobSidecarsByRangeRequest, error) {
+	maxCount := params.BeaconConfig().MaxRequestBlobSidecars / fieldparams.MaxBlobsPerBlock
+	if maxCount == 0 {
+		maxCount = count
 	}
-	rp := rangeParams{
-		start: r.StartSlot,
-		size:  r.Count,
+	start, count, err := validateByRangeRequest(start, count, maxCount)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.BlobSidecarsByRangeRequest{StartSlot: start, Count: count}, nil
+}
+
+// blobsByRangeRequest asserts that the decoded message is a BlobSidecarsByRange request. It is separated from the
+// handler, together with validateBlobsByRange, so the request validation path can be exercised in isolation.
+func blobsByRangeRequest(msg interface{}) (*pb.BlobSidecarsByRangeRequest, error) {
+	r, ok := msg.(*pb.BlobSidecarsByRangeRequest)
+	if !ok || r == nil {
+		return nil, errors.New("message is not type *pb.BlobsSidecarsByRangeRequest")
+	}
+	return r, nil
+}
+
+func validateBlobsByRange(r *pb.BlobSidecarsByRangeRequest, current primitives.Slot) (rangeParams, error) {
+	// The range is not clamped; instead the number of sidecars in the response is limited by MAX_REQUEST_BLOB_SIDECARS.
+	start, count, err := validateByRangeRequest(r.StartSlot, r.Count, math.MaxUint64)
+	if err != nil {
+		return rangeParams{}, err
 	}
 	// Peers may overshoot the current slot when in initial sync, so we don't want to penalize them by treating the
 	// request as an error. So instead we return a set of params that acts as a noop.
-	if rp.start > current {
+	if start > current {
 		return rangeParams{start: current, end: current, size: 0}, nil
 	}
-
-	var err error
-	rp.end, err = rp.start.SafeAdd(rp.size - 1)
-	if err != nil {
-		return rangeParams{}, errors.Wrap(p2ptypes.ErrInvalidRequest, "overflow start + count -1")
+	rp := rangeParams{
+		start: start,
+		end:   start + primitives.Slot(count-1),
+		size:  count,
 	}
 
 	maxRequest := params.MaxRequestBlock(slots.ToEpoch(current))
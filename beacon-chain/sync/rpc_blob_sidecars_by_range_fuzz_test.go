@@ -0,0 +1,237 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/pkg/errors"
+	mock "github.com/prysmaticlabs/prysm/v5/beacon-chain/blockchain/testing"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/filesystem"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
+	p2ptest "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/testing"
+	p2ptypes "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/types"
+	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
+	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/prysmaticlabs/prysm/v5/time/slots"
+)
+
+func FuzzValidateBlobsByRange(f *testing.F) {
+	params.SetupTestConfigCleanup(f)
+	denebSlot, err := slots.EpochStart(params.BeaconConfig().DenebForkEpoch)
+	require.NoError(f, err)
+	maxSlot := uint64(math.MaxUint64)
+	f.Add(uint64(denebSlot), uint64(10), uint64(denebSlot+100))
+	f.Add(uint64(denebSlot), uint64(0), uint64(denebSlot+100))
+	f.Add(uint64(0), uint64(math.MaxUint64), uint64(denebSlot))
+	f.Add(maxSlot, uint64(1), maxSlot)
+	f.Add(maxSlot-1, uint64(2), maxSlot)
+	f.Add(uint64(denebSlot+100), uint64(math.MaxUint64), maxSlot)
+	f.Add(uint64(0), uint64(1), uint64(0))
+
+	f.Fuzz(func(t *testing.T, start, count, current uint64) {
+		r, err := blobsByRangeRequest(&ethpb.BlobSidecarsByRangeRequest{StartSlot: primitives.Slot(start), Count: count})
+		require.NoError(t, err)
+		cs := primitives.Slot(current)
+		rp, err := validateBlobsByRange(r, cs)
+		if err != nil {
+			require.Equal(t, true, errors.Is(err, p2ptypes.ErrInvalidRequest), "unexpected error: %v", err)
+			return
+		}
+		require.NotEqual(t, uint64(0), count)
+		if r.StartSlot > cs {
+			// Requests past the current slot are answered with an empty response.
+			require.Equal(t, cs, rp.start)
+			require.Equal(t, cs, rp.end)
+			return
+		}
+		require.Equal(t, true, rp.start >= r.StartSlot)
+		require.Equal(t, true, rp.end >= rp.start)
+		require.Equal(t, true, rp.size <= count)
+		require.Equal(t, true, rp.size <= params.MaxRequestBlock(slots.ToEpoch(cs)))
+
+		req, err := NewBlobSidecarsByRangeRequest(primitives.Slot(start), count)
+		if err != nil {
+			require.Equal(t, true, errors.Is(err, p2ptypes.ErrInvalidRequest), "unexpected error: %v", err)
+			return
+		}
+		require.Equal(t, r.StartSlot, req.StartSlot)
+		require.Equal(t, true, req.Count <= count)
+	})
+}
+
+// blobFuzzFixtureSlots is the number of consecutive slots, starting at the deneb fork, that have a block in the
+// fixture of FuzzBlobsByRangeRequest.
+const blobFuzzFixtureSlots = 64
+
+// blobFuzzFixture saves a block at each of the blobFuzzFixtureSlots slots starting at start, with between 0 and
+// MAX_BLOBS_PER_BLOCK sidecars, and returns the blocks by slot.
+func blobFuzzFixture(f *testing.F, start primitives.Slot, bs *filesystem.BlobStorage) map[primitives.Slot]blocks.ROBlock {
+	fixture := make(map[primitives.Slot]blocks.ROBlock, blobFuzzFixtureSlots)
+	var parent [32]byte
+	for i := 0; i < blobFuzzFixtureSlots; i++ {
+		sl := start + primitives.Slot(i)
+		pb, scs := generateTestBlockWithSidecars(f, parent, sl, i%(fieldparams.MaxBlobsPerBlock+1))
+		sb, err := blocks.NewSignedBeaconBlock(pb)
+		require.NoError(f, err)
+		rb, err := blocks.NewROBlock(sb)
+		require.NoError(f, err)
+		for _, sc := range scs {
+			require.NoError(f, bs.Save(blocks.VerifiedROBlob{ROBlob: sc}))
+		}
+		fixture[sl] = rb
+		parent = rb.Root()
+	}
+	return fixture
+}
+
+// FuzzBlobsByRangeRequest feeds requests through the same steps as blobSidecarsByRangeRPCHandler: the request type
+// check, validation against the current slot, iteration over the range in batches, skipping slots without sidecars,
+// and streamBlobBatch writing the sidecars of each batch to a mock stream. Blob storage holds a fixture of blocks with
+// sidecars at the first slots of deneb, and blobSlots is a bitmask of the fixture slots (mod 64) that are canonical.
+// Iteration must end and stay within the requested range, and the response must be ordered, within the range, and
+// respect the wQuota cap on the number of sidecars.
+func FuzzBlobsByRangeRequest(f *testing.F) {
+	params.SetupTestConfigCleanup(f)
+	denebSlot, err := slots.EpochStart(params.BeaconConfig().DenebForkEpoch)
+	require.NoError(f, err)
+	ds := uint64(denebSlot)
+	maxQuota := params.BeaconConfig().MaxRequestBlobSidecars
+	f.Add(true, uint64(0), uint64(0), uint64(0), uint64(0), uint64(0))
+	f.Add(false, uint64(1), uint64(1), uint64(1), uint64(1), uint64(1))
+	f.Add(true, ds, uint64(10), ds+100, uint64(math.MaxUint64), maxQuota)
+	f.Add(true, ds, uint64(blobFuzzFixtureSlots), ds+100, uint64(math.MaxUint64), uint64(7))
+	f.Add(true, ds, uint64(math.MaxUint64), ds+10000, uint64(0x5555555555555555), maxQuota)
+	f.Add(true, uint64(math.MaxUint64), uint64(1), uint64(math.MaxUint64), uint64(1), maxQuota)
+	f.Add(true, uint64(math.MaxUint64-1), uint64(2), uint64(math.MaxUint64-1), uint64(math.MaxUint64), maxQuota)
+	f.Add(true, ds+50, uint64(math.MaxUint64), ds+40, uint64(math.MaxUint64), maxQuota)
+	f.Add(true, uint64(0), uint64(math.MaxUint64), ds+1000, uint64(1)<<63, maxQuota)
+	f.Add(true, uint64(1), uint64(1), uint64(1), uint64(1), uint64(1))
+
+	p := p2ptest.NewFuzzTestP2P()
+	bs := filesystem.NewEphemeralBlobStorage(f)
+	fixture := blobFuzzFixture(f, denebSlot, bs)
+	chain := &mock.ChainService{}
+	s := &Service{
+		cfg:         &config{p2p: p, chain: chain, blobStorage: bs},
+		rateLimiter: newRateLimiter(p),
+	}
+	proto := protocol.ID(p2p.RPCBlobSidecarsByRangeTopicV1 + p.Encoding().ProtocolSuffix())
+	f.Fuzz(func(t *testing.T, typed bool, start, count, current, blobSlots, quota uint64) {
+		var msg interface{} = &ethpb.BeaconBlocksByRangeRequest{StartSlot: primitives.Slot(start), Count: count}
+		if typed {
+			msg = &ethpb.BlobSidecarsByRangeRequest{StartSlot: primitives.Slot(start), Count: count}
+		}
+		r, err := blobsByRangeRequest(msg)
+		if !typed {
+			require.NotNil(t, err)
+			return
+		}
+		require.NoError(t, err)
+		require.Equal(t, primitives.Slot(start), r.StartSlot)
+		require.Equal(t, count, r.Count)
+
+		cs := primitives.Slot(current)
+		rp, err := validateBlobsByRange(r, cs)
+		if err != nil || rp.size == 0 {
+			// The handler doesn't serve anything for an invalid request, or one that starts after the current slot.
+			return
+		}
+		chain.Slot = &cs
+		canonical := func(sl primitives.Slot) (blocks.ROBlock, bool) {
+			b, ok := fixture[sl]
+			return b, ok && blobSlots&(1<<(uint64(sl)%64)) != 0
+		}
+		seek := func(from primitives.Slot) (primitives.Slot, bool) {
+			// Like BlobStorage.NextBlobSlot, only the slots up to the end of the range are considered.
+			for sl := max(from, denebSlot); sl <= rp.end && sl < denebSlot+blobFuzzFixtureSlots; sl++ {
+				if b, ok := canonical(sl); ok && expectsBlobs(b) {
+					return sl, true
+				}
+			}
+			return 0, false
+		}
+		bb := &blockRangeBatcher{start: rp.start, end: rp.end, size: rp.size, seek: seek, cf: &canonicalFilter{}}
+		snapshot := bs.SlotRangeSnapshot(rp.start)
+		defer snapshot.Release()
+		var out bytes.Buffer
+		server := &loopbackStream{r: strings.NewReader(""), w: &out, proto: proto, conn: loopbackConn{pid: "fuzz"}}
+		stream := newFlushingStream(server, 0)
+		budget := newBlobWriteBudget(context.Background())
+		order, forks := &blobResponseOrder{}, &slotForkCache{}
+		wQuota := quota % (maxQuota + 1)
+		initial := wQuota
+		// Every batch covers at least one slot, so there can't be more batches than slots in the range.
+		maxBatches := rp.slotRange().Len()
+		var batches uint64
+		var prevEnd primitives.Slot
+		nb, more := newBlockBatch(bb.start, bb.end, bb.size)
+		for more && wQuota > 0 {
+			if nb, more = bb.skipAhead(nb); !more {
+				break
+			}
+			batches++
+			require.Equal(t, true, batches <= maxBatches, "batch %d exceeds the %d slots in the range", batches, maxBatches)
+			require.Equal(t, true, nb.start >= rp.start && nb.end <= rp.end && nb.start <= nb.end)
+			require.Equal(t, true, nb.slotRange().Len() <= rp.size)
+			require.Equal(t, true, batches == 1 || nb.start > prevEnd, "batch %s overlaps the previous batch", nb.slotRange())
+			// Requests are not served before the start of the request, or past the current slot. Validation turns a
+			// range that ends before the retention floor into a single slot at the floor, which can be after the
+			// current slot before deneb.
+			require.Equal(t, true, nb.start >= r.StartSlot)
+			require.Equal(t, true, nb.end <= max(cs, rp.start))
+			prevEnd = nb.end
+			nb.lin = nil
+			for sl := nb.start; sl <= nb.end; sl++ {
+				if b, ok := canonical(sl); ok {
+					nb.lin = append(nb.lin, b)
+				}
+				if sl == nb.end {
+					break
+				}
+			}
+			wQuota, err = s.streamBlobBatch(context.Background(), nb, wQuota, budget, order, forks, snapshot, stream)
+			require.NoError(t, err)
+			nb, more = nb.next(bb.end, bb.size)
+		}
+		served := initial - wQuota
+		require.Equal(t, true, served <= maxQuota)
+		ratio := blobsServedRatio(r.Count, served, maxQuota)
+		require.Equal(t, true, ratio >= 0 && !math.IsNaN(ratio))
+
+		// Read the response back, every sidecar that was counted against the quota must have been written in order.
+		client := &loopbackStream{r: &out, w: io.Discard, proto: proto, conn: loopbackConn{pid: "fuzz"}}
+		var decoded uint64
+		var prev *ethpb.BlobSidecar
+		for out.Len() > 0 {
+			code, _, err := ReadStatusCode(client, p.Encoding())
+			require.NoError(t, err)
+			require.Equal(t, responseCodeSuccess, code)
+			_, err = readContextFromStream(client)
+			require.NoError(t, err)
+			sc := &ethpb.BlobSidecar{}
+			require.NoError(t, p.Encoding().DecodeWithMaxLength(client, sc))
+			sl := sc.SignedBlockHeader.Header.Slot
+			b, ok := canonical(sl)
+			require.Equal(t, true, ok, "sidecar at slot %d is not in the fixture", sl)
+			require.Equal(t, true, sl >= rp.start && sl <= rp.end)
+			require.Equal(t, b.Block().ParentRoot(), bytesutil.ToBytes32(sc.SignedBlockHeader.Header.ParentRoot))
+			if prev != nil {
+				ps := prev.SignedBlockHeader.Header.Slot
+				require.Equal(t, true, sl > ps || (sl == ps && sc.Index > prev.Index), "sidecar %d at slot %d is out of order", sc.Index, sl)
+			}
+			prev = sc
+			decoded++
+		}
+		require.Equal(t, served, decoded)
+	})
+}
@@ -1,15 +1,32 @@
 package sync
 
 import (
+	"context"
+	"io"
+	"math"
+	"strings"
 	"testing"
+	"time"
 
+	libp2pcore "github.com/libp2p/go-libp2p/core"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	mock "github.com/prysmaticlabs/prysm/v5/beacon-chain/blockchain/testing"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/encoder"
+	p2ptypes "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/types"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
+	"github.com/prysmaticlabs/prysm/v5/cmd/beacon-chain/flags"
+	"github.com/prysmaticlabs/prysm/v5/config/features"
 	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
 	types "github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
 	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/prysmaticlabs/prysm/v5/testing/util"
 	"github.com/prysmaticlabs/prysm/v5/time/slots"
 )
 
@@ -156,6 +173,453 @@ func TestBlobByRangeOK(t *testing.T) {
 	}
 }
 
+func TestBlobByRangePanicRecovery(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	origWriter := writeBlobSidecarChunk
+	defer func() {
+		writeBlobSidecarChunk = origWriter
+	}()
+	writeBlobSidecarChunk = func(libp2pcore.Stream, encoder.NetworkEncoding, [4]byte, blocks.VerifiedROBlob) error {
+		panic("malformed sidecar")
+	}
+
+	c := &blobsTestCase{
+		name:    "panic in chunk writer",
+		nblocks: 1,
+		err:     errBlobsByRangePanic,
+		streamReader: func(t *testing.T, s *Service, _ []*expectedBlobChunk) func(network.Stream) {
+			return func(stream network.Stream) {
+				code, _, err := ReadStatusCode(stream, s.cfg.p2p.Encoding())
+				require.NoError(t, err)
+				require.Equal(t, responseCodeServerError, code)
+			}
+		},
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
+
+func TestBlobByRangeAccessList(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	c := &blobsTestCase{
+		name:    "peer not in allowlist",
+		nblocks: 1,
+		serverHandle: func(s *Service) rpcHandler {
+			s.cfg.blobServingAccessList = NewPeerAccessList([]peer.ID{"trusted"}, nil)
+			return s.blobSidecarsByRangeRPCHandler
+		},
+		streamReader: func(t *testing.T, s *Service, _ []*expectedBlobChunk) func(network.Stream) {
+			return func(stream network.Stream) {
+				code, _, err := ReadStatusCode(stream, s.cfg.p2p.Encoding())
+				require.NoError(t, err)
+				require.Equal(t, responseCodeResourceUnavailable, code)
+			}
+		},
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
+
+func TestBlobByRangeVerifyInclusionProofs(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	resetCfg := features.InitWithReset(&features.Flags{VerifyServedBlobInclusionProofs: true})
+	defer resetCfg()
+	// The test fixtures use placeholder inclusion proofs, so every sidecar should be skipped.
+	c := &blobsTestCase{
+		name:    "sidecars with invalid inclusion proofs are not served",
+		nblocks: 10,
+		streamReader: func(t *testing.T, s *Service, _ []*expectedBlobChunk) func(network.Stream) {
+			return func(stream network.Stream) {
+				_, _, err := ReadStatusCode(stream, s.cfg.p2p.Encoding())
+				require.ErrorIs(t, err, io.EOF)
+			}
+		},
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
+
+func TestBlobByRangeServeCanonicalOnly(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	resetCfg := features.InitWithReset(&features.Flags{ServeCanonicalBlobsOnly: true})
+	defer resetCfg()
+	c := &blobsTestCase{
+		name:    "canonical sidecars are served",
+		nblocks: 10,
+		total:   func() *int { x := fieldparams.MaxBlobsPerBlock * 10; return &x }(),
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
+
+func TestCanonicalBlob(t *testing.T) {
+	_, sidecars := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, 1, 1)
+	sc := sidecars[0]
+	chain := &mock.ChainService{CanonicalRoots: map[[32]byte]bool{}}
+	s := &Service{cfg: &config{chain: chain}}
+	require.Equal(t, false, s.canonicalBlob(context.Background(), sc))
+	chain.CanonicalRoots[sc.BlockRoot()] = true
+	require.Equal(t, true, s.canonicalBlob(context.Background(), sc))
+}
+
+func TestBlobByRangeWriteBudgetExhausted(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	origBudget := minBlobWriteBudget
+	defer func() {
+		minBlobWriteBudget = origBudget
+	}()
+	// Require more time than the response timeout allows, so that the handler stops before writing anything.
+	minBlobWriteBudget = respTimeout * 2
+	c := &blobsTestCase{
+		name:    "response ends cleanly when the deadline is too close",
+		nblocks: 10,
+		streamReader: func(t *testing.T, s *Service, _ []*expectedBlobChunk) func(network.Stream) {
+			return func(stream network.Stream) {
+				_, _, err := ReadStatusCode(stream, s.cfg.p2p.Encoding())
+				require.ErrorIs(t, err, io.EOF)
+			}
+		},
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
+
+func TestBlobByRangeReorgedSidecars(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	var canonical []blocks.ROBlob
+	c := &blobsTestCase{
+		name:    "only sidecars for the canonical block at a reorged slot are served",
+		nblocks: 10,
+	}
+	c.requestFromSidecars = func(scs []blocks.ROBlob) interface{} {
+		canonical = scs
+		c.chain.CanonicalRoots = make(map[[32]byte]bool)
+		for _, sc := range scs {
+			c.chain.CanonicalRoots[sc.BlockRoot()] = true
+		}
+		return blobRangeRequestFromSidecars(scs)
+	}
+	c.serverHandle = func(s *Service) rpcHandler {
+		// Save an orphaned block and sidecars at the same slot as the first canonical block, as if the slot was reorged.
+		reorged, rscs := generateTestBlockWithSidecars(t, [32]byte{0x01}, canonical[0].Slot(), fieldparams.MaxBlobsPerBlock)
+		util.SaveBlock(t, context.Background(), s.cfg.beaconDB, reorged)
+		vscs, err := verification.BlobSidecarSliceNoop(rscs)
+		require.NoError(t, err)
+		for i := range vscs {
+			require.NoError(t, s.cfg.blobStorage.Save(vscs[i]))
+		}
+		// Saving a sidecar again for the same root and index should not create a duplicate entry.
+		vsc, err := verification.BlobSidecarNoop(canonical[0])
+		require.NoError(t, err)
+		require.NoError(t, s.cfg.blobStorage.Save(vsc))
+		return s.blobSidecarsByRangeRPCHandler
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
+
+// TestBlobByRangeResponseOrder reads an entire multi-slot response, with several sidecars per slot, and checks that
+// it is ordered by slot, then by index, as the spec requires.
+func TestBlobByRangeResponseOrder(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	nblocks := 10
+	c := &blobsTestCase{
+		name:    "response is ordered by slot then index",
+		nblocks: nblocks,
+		streamReader: func(t *testing.T, s *Service, _ []*expectedBlobChunk) func(network.Stream) {
+			return func(stream network.Stream) {
+				encoding := s.cfg.p2p.Encoding()
+				var got []*ethpb.BlobSidecar
+				for {
+					code, _, err := ReadStatusCode(stream, encoding)
+					if err == io.EOF {
+						break
+					}
+					require.NoError(t, err)
+					require.Equal(t, responseCodeSuccess, code)
+					_, err = readContextFromStream(stream)
+					require.NoError(t, err)
+					sc := &ethpb.BlobSidecar{}
+					require.NoError(t, encoding.DecodeWithMaxLength(stream, sc))
+					got = append(got, sc)
+				}
+				require.Equal(t, nblocks*fieldparams.MaxBlobsPerBlock, len(got))
+				for i := 1; i < len(got); i++ {
+					prev, sc := got[i-1], got[i]
+					prevSlot, slot := prev.SignedBlockHeader.Header.Slot, sc.SignedBlockHeader.Header.Slot
+					if slot == prevSlot {
+						require.Equal(t, prev.Index+1, sc.Index, "sidecars for slot %d are not in index order", slot)
+						continue
+					}
+					require.Equal(t, true, slot > prevSlot, "slot %d written after slot %d", slot, prevSlot)
+					require.Equal(t, uint64(0), sc.Index, "first sidecar for slot %d is not index 0", slot)
+				}
+			}
+		},
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
+
+func TestBlobByRangeReverseResponseOrder(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 200
+	params.OverrideBeaconConfig(nc)
+
+	// Enough blocks for the range to be read in more than one batch.
+	nblocks := int(2*blobBatchLimit() + 1)
+	c := &blobsTestCase{
+		name:         "reverse response is ordered by descending slot then index",
+		nblocks:      nblocks,
+		topic:        p2p.RPCBlobSidecarsByRangeReverseTopicV1,
+		serverHandle: func(s *Service) rpcHandler { return s.blobSidecarsByRangeReverseRPCHandler },
+		streamReader: func(t *testing.T, s *Service, expect []*expectedBlobChunk) func(network.Stream) {
+			return func(stream network.Stream) {
+				encoding := s.cfg.p2p.Encoding()
+				var got []*ethpb.BlobSidecar
+				for {
+					code, _, err := ReadStatusCode(stream, encoding)
+					if err == io.EOF {
+						break
+					}
+					require.NoError(t, err)
+					require.Equal(t, responseCodeSuccess, code)
+					_, err = readContextFromStream(stream)
+					require.NoError(t, err)
+					sc := &ethpb.BlobSidecar{}
+					require.NoError(t, encoding.DecodeWithMaxLength(stream, sc))
+					got = append(got, sc)
+				}
+				require.Equal(t, len(expect), len(got))
+				require.Equal(t, expect[len(expect)-1].sidecar.Slot(), got[0].SignedBlockHeader.Header.Slot)
+				for i := 1; i < len(got); i++ {
+					prev, sc := got[i-1], got[i]
+					prevSlot, slot := prev.SignedBlockHeader.Header.Slot, sc.SignedBlockHeader.Header.Slot
+					if slot == prevSlot {
+						require.Equal(t, prev.Index+1, sc.Index, "sidecars for slot %d are not in index order", slot)
+						continue
+					}
+					require.Equal(t, true, slot < prevSlot, "slot %d written after slot %d", slot, prevSlot)
+					require.Equal(t, uint64(0), sc.Index, "first sidecar for slot %d is not index 0", slot)
+				}
+			}
+		},
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
+
+func TestBlobByRangeServesUncoveredSlots(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	// The backfill status reports every slot as a gap, but the sidecars are in blob storage.
+	c := &blobsTestCase{
+		name:    "sidecars in storage are served when backfill reports a gap",
+		nblocks: 10,
+		serverHandle: func(s *Service) rpcHandler {
+			s.availableBlocker = mockBlocker{avail: false}
+			return s.blobSidecarsByRangeRPCHandler
+		},
+		total: func() *int { x := fieldparams.MaxBlobsPerBlock * 10; return &x }(),
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
+
+func TestBlobByRangeCoalescedReads(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	resetFlags := flags.Get()
+	gFlags := *resetFlags
+	gFlags.BlobServeCoalesceReads = true
+	flags.Init(&gFlags)
+	defer flags.Init(resetFlags)
+
+	c := &blobsTestCase{
+		name:    "coalesced reads serve every sidecar",
+		nblocks: 10,
+		requestFromSidecars: func(scs []blocks.ROBlob) interface{} {
+			return &ethpb.BlobSidecarsByRangeRequest{
+				StartSlot: scs[0].Slot(),
+				Count:     10,
+			}
+		},
+		total: func() *int { x := fieldparams.MaxBlobsPerBlock * 10; return &x }(),
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
+
+// mockBlobber reports blobs as backfilled from lowSlot onwards.
+type mockBlobber struct {
+	lowSlot types.Slot
+}
+
+func (m mockBlobber) BlobSlotCovered(sl types.Slot) bool {
+	return sl >= m.lowSlot
+}
+
+func TestBlobByRangeSkippedByBackfill(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	var start types.Slot
+	fromSidecars := func(scs []blocks.ROBlob) interface{} {
+		start = scs[0].Slot()
+		return blobRangeRequestFromSidecars(scs)
+	}
+	unavailable := func(t *testing.T, s *Service, _ []*expectedBlobChunk) func(network.Stream) {
+		return func(stream network.Stream) {
+			code, _, err := ReadStatusCode(stream, s.cfg.p2p.Encoding())
+			require.NoError(t, err)
+			require.Equal(t, responseCodeResourceUnavailable, code)
+		}
+	}
+	cases := []*blobsTestCase{
+		{
+			name:    "range backfilled without blobs",
+			nblocks: 10,
+			serverHandle: func(s *Service) rpcHandler {
+				s.availableBlobber = mockBlobber{lowSlot: start + 1}
+				return s.blobSidecarsByRangeRPCHandler
+			},
+			requestFromSidecars: fromSidecars,
+			streamReader:        unavailable,
+		},
+		{
+			name:    "range backfilled with blobs",
+			nblocks: 10,
+			serverHandle: func(s *Service) rpcHandler {
+				s.availableBlobber = mockBlobber{lowSlot: start}
+				return s.blobSidecarsByRangeRPCHandler
+			},
+			requestFromSidecars: fromSidecars,
+			total:               func() *int { x := fieldparams.MaxBlobsPerBlock * 10; return &x }(),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.runTestBlobSidecarsByRange(t)
+		})
+	}
+}
+
+func TestBlobSidecarServeCost(t *testing.T) {
+	_, sidecars := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, 1, fieldparams.MaxBlobsPerBlock)
+	for _, sc := range sidecars {
+		enc, err := sc.MarshalSSZ()
+		require.NoError(t, err)
+		require.Equal(t, blobSidecarServeCost, uint64(len(enc)))
+	}
+}
+
+func TestBlobWriteBudget(t *testing.T) {
+	b := newBlobWriteBudget(context.Background())
+	require.Equal(t, true, b.sufficient(time.Now()))
+
+	now := time.Now()
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(time.Second))
+	defer cancel()
+	b = newBlobWriteBudget(ctx)
+	require.Equal(t, true, b.sufficient(now))
+	require.Equal(t, false, b.sufficient(now.Add(time.Second-minBlobWriteBudget+time.Millisecond)))
+	// A slow write raises the time needed for the next one.
+	b.observe(800 * time.Millisecond)
+	require.Equal(t, true, b.sufficient(now.Add(200*time.Millisecond)))
+	require.Equal(t, false, b.sufficient(now.Add(201*time.Millisecond)))
+}
+
+func TestVerifyServedBlobSidecar(t *testing.T) {
+	block, sidecars := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, 1, 2)
+	require.NoError(t, verifyServedBlobSidecar(block.Root(), sidecars[0]))
+	require.ErrorIs(t, verifyServedBlobSidecar([32]byte{0x01}, sidecars[0]), errServedBlobRootMismatch)
+
+	// Swapping commitments between sidecars for the same block invalidates the inclusion proof.
+	bad, err := blocks.NewROBlobWithRoot(&ethpb.BlobSidecar{
+		Index:                    sidecars[0].Index,
+		Blob:                     sidecars[0].Blob,
+		KzgCommitment:            sidecars[1].KzgCommitment,
+		KzgProof:                 sidecars[0].KzgProof,
+		SignedBlockHeader:        sidecars[0].SignedBlockHeader,
+		CommitmentInclusionProof: sidecars[0].CommitmentInclusionProof,
+	}, block.Root())
+	require.NoError(t, err)
+	require.NotNil(t, verifyServedBlobSidecar(block.Root(), bad))
+}
+
+func TestExpectsBlobs(t *testing.T) {
+	withBlobs, _ := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, 1, 2)
+	require.Equal(t, true, expectsBlobs(withBlobs))
+	noBlobs, _ := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, 1, 0)
+	require.Equal(t, false, expectsBlobs(noBlobs))
+
+	sb, err := blocks.NewSignedBeaconBlock(util.NewBeaconBlock())
+	require.NoError(t, err)
+	phase0, err := blocks.NewROBlock(sb)
+	require.NoError(t, err)
+	require.Equal(t, false, expectsBlobs(phase0))
+}
+
 func TestBlobsByRangeValidation(t *testing.T) {
 	cfg := params.BeaconConfig()
 	repositionFutureEpochs(cfg)
@@ -286,6 +750,60 @@ func TestBlobsByRangeValidation(t *testing.T) {
 	}
 }
 
+func TestNewBlobSidecarsByRangeRequest(t *testing.T) {
+	maxCount := params.BeaconConfig().MaxRequestBlobSidecars / fieldparams.MaxBlobsPerBlock
+	cases := []struct {
+		name  string
+		start types.Slot
+		count uint64
+		want  uint64
+		err   error
+	}{
+		{
+			name:  "zero count",
+			start: 10,
+			count: 0,
+			err:   p2ptypes.ErrInvalidRequest,
+		},
+		{
+			name:  "overflow",
+			start: math.MaxUint64 - 1,
+			count: 3,
+			err:   p2ptypes.ErrInvalidRequest,
+		},
+		{
+			name:  "count at max",
+			start: 10,
+			count: maxCount,
+			want:  maxCount,
+		},
+		{
+			name:  "count clamped to max",
+			start: 10,
+			count: maxCount + 1,
+			want:  maxCount,
+		},
+		{
+			name:  "ordinary request",
+			start: 10,
+			count: 32,
+			want:  32,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, err := NewBlobSidecarsByRangeRequest(c.start, c.count)
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.start, req.StartSlot)
+			require.Equal(t, c.want, req.Count)
+		})
+	}
+}
+
 func TestBlobRPCMinValidSlot(t *testing.T) {
 	denebSlot, err := slots.EpochStart(params.BeaconConfig().DenebForkEpoch)
 	require.NoError(t, err)
@@ -349,3 +867,277 @@ func TestBlobRPCMinValidSlot(t *testing.T) {
 		})
 	}
 }
+
+func TestBlobByRangeVersionedHandler(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	versioned := func(s *Service) rpcHandler { return s.versionedRPCHandler(s.blobSidecarsByRangeHandlers()) }
+	t.Run("supported version", func(t *testing.T) {
+		c := &blobsTestCase{
+			name:         "v1 dispatched to blobSidecarsByRangeRPCHandler",
+			nblocks:      10,
+			serverHandle: versioned,
+		}
+		c.runTestBlobSidecarsByRange(t)
+	})
+	t.Run("unsupported version", func(t *testing.T) {
+		c := &blobsTestCase{
+			name:         "v2 rejected",
+			nblocks:      1,
+			topic:        protocol.ID(strings.TrimSuffix(p2p.RPCBlobSidecarsByRangeTopicV1, p2p.SchemaVersionV1) + p2p.SchemaVersionV2),
+			serverHandle: versioned,
+			err:          errUnsupportedRPCVersion,
+			streamReader: func(t *testing.T, s *Service, _ []*expectedBlobChunk) func(network.Stream) {
+				return func(stream network.Stream) {
+					code, _, err := ReadStatusCode(stream, s.cfg.p2p.Encoding())
+					require.NoError(t, err)
+					require.Equal(t, responseCodeInvalidRequest, code)
+				}
+			},
+		}
+		c.runTestBlobSidecarsByRange(t)
+	})
+}
+
+func TestBlobsServedRatio(t *testing.T) {
+	maxBlobs := uint64(fieldparams.MaxBlobsPerBlock)
+	cases := []struct {
+		name                    string
+		count, served, maxQuota uint64
+		expected                float64
+	}{
+		{name: "all served", count: 10, served: 10 * maxBlobs, maxQuota: 768, expected: 1},
+		{name: "none served", count: 10, served: 0, maxQuota: 768, expected: 0},
+		{name: "half served", count: 10, served: 5 * maxBlobs, maxQuota: 768, expected: 0.5},
+		{name: "count above quota", count: math.MaxUint64, served: 384, maxQuota: 768, expected: 0.5},
+		{name: "zero quota", count: 10, served: 0, maxQuota: 0, expected: 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.expected, blobsServedRatio(c.count, c.served, c.maxQuota))
+		})
+	}
+}
+
+func TestBlobByRangeServedBelowFloor(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	origWriter := writeBlobSidecarChunk
+	defer func() {
+		writeBlobSidecarChunk = origWriter
+	}()
+	chain, _ := defaultMockChain(t)
+	current := chain.CurrentSlot()
+	chain.Slot = &current
+	window, err := slots.EpochStart(params.BeaconConfig().MinEpochsForBlobsSidecarsRequest)
+	require.NoError(t, err)
+	writeBlobSidecarChunk = func(stream libp2pcore.Stream, enc encoder.NetworkEncoding, digest [4]byte, sc blocks.VerifiedROBlob) error {
+		// Move the clock far enough forward that the floor passes the sidecars after the request was validated.
+		moved := current + window
+		chain.Slot = &moved
+		return origWriter(stream, enc, digest, sc)
+	}
+
+	before := testutil.ToFloat64(blobsServedBelowFloor)
+	c := &blobsTestCase{
+		name:    "floor moves while serving",
+		nblocks: 1,
+		chain:   chain,
+	}
+	c.runTestBlobSidecarsByRange(t)
+	require.Equal(t, float64(fieldparams.MaxBlobsPerBlock), testutil.ToFloat64(blobsServedBelowFloor)-before)
+}
+
+func TestBlobHotWindowStart(t *testing.T) {
+	_, ok := blobHotWindowStart(1000)
+	require.Equal(t, false, ok)
+
+	resetFlags := flags.Get()
+	gFlags := *resetFlags
+	gFlags.BlobServingHotEpochs = 2
+	flags.Init(&gFlags)
+	defer flags.Init(resetFlags)
+
+	spe := params.BeaconConfig().SlotsPerEpoch
+	cases := []struct {
+		name    string
+		current types.Slot
+		start   types.Slot
+	}{
+		{name: "first epoch", current: 1, start: 0},
+		{name: "window reaches genesis", current: spe + 1, start: 0},
+		{name: "current and previous epoch", current: 10*spe + 1, start: 9 * spe},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			start, ok := blobHotWindowStart(c.current)
+			require.Equal(t, true, ok)
+			require.Equal(t, c.start, start)
+		})
+	}
+}
+
+func TestBlobByRangeHistoricalBudget(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	// Every sidecar in the range is older than the hot window, and the budget covers 4 of them.
+	resetFlags := flags.Get()
+	gFlags := *resetFlags
+	gFlags.BlobServingHotEpochs = 1
+	gFlags.BlobBatchLimitHistorical = 4
+	gFlags.BlobBatchLimitBurstFactor = 1
+	flags.Init(&gFlags)
+	defer flags.Init(resetFlags)
+
+	allowed := 4
+	c := &blobsTestCase{
+		name:    "response ends when the historical budget is exhausted",
+		nblocks: 2,
+		streamReader: func(t *testing.T, s *Service, expect []*expectedBlobChunk) func(network.Stream) {
+			return func(stream network.Stream) {
+				require.Equal(t, true, len(expect) > allowed)
+				defaultExpectedRequirer(t, s, expect[:allowed])(stream)
+				_, _, err := ReadStatusCode(stream, s.cfg.p2p.Encoding())
+				require.ErrorIs(t, err, io.EOF)
+			}
+		},
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
+
+type mockProposalDuties bool
+
+func (m mockProposalDuties) ProposalImminent(_ time.Duration) bool {
+	return bool(m)
+}
+
+func TestPausedForProposal(t *testing.T) {
+	spe := params.BeaconConfig().SlotsPerEpoch
+	large := rangeParams{start: 100, end: 100 + spe}
+	small := rangeParams{start: 100, end: 100 + spe - 1}
+	s := &Service{proposalDuties: mockProposalDuties(true)}
+	// The pause is disabled by default.
+	require.Equal(t, false, s.pausedForProposal(large))
+
+	resetFlags := flags.Get()
+	gFlags := *resetFlags
+	gFlags.BlobServeProposalPause = 4 * time.Second
+	flags.Init(&gFlags)
+	defer flags.Init(resetFlags)
+
+	require.Equal(t, true, s.pausedForProposal(large))
+	require.Equal(t, false, s.pausedForProposal(small))
+	s.proposalDuties = mockProposalDuties(false)
+	require.Equal(t, false, s.pausedForProposal(large))
+	s.proposalDuties = nil
+	require.Equal(t, false, s.pausedForProposal(large))
+}
+
+func TestBlobByRangePausedForProposal(t *testing.T) {
+	origNC := params.BeaconConfig()
+	defer func() {
+		params.OverrideBeaconConfig(origNC)
+	}()
+	nc := params.BeaconConfig().Copy()
+	nc.MaxRequestBlobSidecars = 100
+	params.OverrideBeaconConfig(nc)
+
+	resetFlags := flags.Get()
+	gFlags := *resetFlags
+	gFlags.BlobServeProposalPause = 4 * time.Second
+	flags.Init(&gFlags)
+	defer flags.Init(resetFlags)
+
+	c := &blobsTestCase{
+		name:    "large range while proposal is imminent",
+		nblocks: 1,
+		serverHandle: func(s *Service) rpcHandler {
+			s.proposalDuties = mockProposalDuties(true)
+			return s.blobSidecarsByRangeRPCHandler
+		},
+		requestFromSidecars: func(scs []blocks.ROBlob) interface{} {
+			return &ethpb.BlobSidecarsByRangeRequest{
+				StartSlot: scs[0].Slot(),
+				Count:     uint64(params.BeaconConfig().SlotsPerEpoch) + 1,
+			}
+		},
+		streamReader: func(t *testing.T, s *Service, _ []*expectedBlobChunk) func(network.Stream) {
+			return func(stream network.Stream) {
+				code, _, err := ReadStatusCode(stream, s.cfg.p2p.Encoding())
+				require.NoError(t, err)
+				require.Equal(t, responseCodeResourceUnavailable, code)
+			}
+		},
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
+
+func TestRefusedWhileSyncing(t *testing.T) {
+	spe := params.BeaconConfig().SlotsPerEpoch
+	large := rangeParams{start: 100, end: 100 + spe}
+	small := rangeParams{start: 100, end: 100 + spe - 1}
+	nearHead := rangeParams{start: 8 * spe, end: 9*spe - 1}
+	st, err := util.NewBeaconState()
+	require.NoError(t, err)
+	require.NoError(t, st.SetSlot(10*spe))
+	current := 12*spe + 1
+	s := &Service{cfg: &config{chain: &mock.ChainService{State: st, Slot: &current}}}
+	// The refusal is disabled when the margin is 0, which is the case if flags are not configured.
+	require.Equal(t, false, s.refusedWhileSyncing(large))
+
+	resetFlags := flags.Get()
+	gFlags := *resetFlags
+	gFlags.ServeWhileSyncingMargin = 2
+	flags.Init(&gFlags)
+	defer flags.Init(resetFlags)
+
+	require.Equal(t, true, s.refusedWhileSyncing(large))
+	// Small requests are only served if they start within the margin of the head.
+	require.Equal(t, true, s.refusedWhileSyncing(small))
+	require.Equal(t, false, s.refusedWhileSyncing(nearHead))
+	require.Equal(t, true, s.refusedWhileSyncing(rangeParams{start: 8*spe - 1, end: 9*spe - 2}))
+	// The head is within the margin.
+	current = 12 * spe
+	require.Equal(t, false, s.refusedWhileSyncing(large))
+	require.Equal(t, false, s.refusedWhileSyncing(small))
+	current = 20 * spe
+	gFlags.ServeWhileSyncing = true
+	require.Equal(t, false, s.refusedWhileSyncing(large))
+}
+
+func TestBlobByRangeDraining(t *testing.T) {
+	c := &blobsTestCase{
+		name:    "range request while the service is stopping",
+		nblocks: 1,
+		serverHandle: func(s *Service) rpcHandler {
+			require.Equal(t, true, s.blobServes.drain(0))
+			return s.blobSidecarsByRangeRPCHandler
+		},
+		streamReader: func(t *testing.T, s *Service, _ []*expectedBlobChunk) func(network.Stream) {
+			return func(stream network.Stream) {
+				code, _, err := ReadStatusCode(stream, s.cfg.p2p.Encoding())
+				require.NoError(t, err)
+				require.Equal(t, responseCodeResourceUnavailable, code)
+			}
+		},
+	}
+	c.runTestBlobSidecarsByRange(t)
+}
@@ -12,10 +12,13 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/types"
 	"github.com/prysmaticlabs/prysm/v5/cmd/beacon-chain/flags"
+	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
 	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
 	"github.com/prysmaticlabs/prysm/v5/monitoring/tracing"
 	"github.com/prysmaticlabs/prysm/v5/monitoring/tracing/trace"
+	"github.com/prysmaticlabs/prysm/v5/runtime/version"
 	"github.com/sirupsen/logrus"
 )
 
@@ -41,6 +44,16 @@ func (s *Service) blobSidecarByRootRPCHandler(ctx context.Context, msg interface
 	}
 	// Sort the identifiers so that requests for the same blob root will be adjacent, minimizing db lookups.
 	sort.Sort(blobIdents)
+	if err := s.validateBlobByRootIndices(ctx, blobIdents); err != nil {
+		if !errors.Is(err, types.ErrBlobIndexOutOfRange) {
+			log.WithError(err).Error("Unexpected db error validating BlobSidecarsByRoot request")
+			s.writeErrorResponseWithContext(ctx, responseCodeServerError, types.ErrGeneric.Error(), stream)
+			return err
+		}
+		s.cfg.p2p.Peers().Scorers().BadResponsesScorer().Increment(stream.Conn().RemotePeer())
+		s.writeErrorResponseToStream(responseCodeInvalidRequest, types.ErrBlobIndexOutOfRange.Error(), stream)
+		return err
+	}
 
 	batchSize := flags.Get().BlobBatchLimit
 	var ticker *time.Ticker
@@ -77,7 +90,7 @@ func (s *Service) blobSidecarByRootRPCHandler(ctx context.Context, msg interface
 				continue
 			}
 			log.WithError(err).Errorf("unexpected db error retrieving BlobSidecar, root=%x, index=%d", root, idx)
-			s.writeErrorResponseToStream(responseCodeServerError, types.ErrGeneric.Error(), stream)
+			s.writeErrorResponseWithContext(ctx, responseCodeServerError, types.ErrGeneric.Error(), stream)
 			return err
 		}
 
@@ -94,8 +107,11 @@ func (s *Service) blobSidecarByRootRPCHandler(ctx context.Context, msg interface
 
 		SetStreamWriteDeadline(stream, defaultWriteDuration)
 		if chunkErr := WriteBlobSidecarChunk(stream, s.cfg.chain, s.cfg.p2p.Encoding(), sc); chunkErr != nil {
-			log.WithError(chunkErr).Debug("Could not send a chunked response")
-			s.writeErrorResponseToStream(responseCodeServerError, types.ErrGeneric.Error(), stream)
+			if skipBlobForkSchedule(chunkErr, sc) {
+				continue
+			}
+			chunkFailures.observe(chunkErr, time.Now())
+			s.writeErrorResponseWithContext(ctx, responseCodeServerError, types.ErrGeneric.Error(), stream)
 			tracing.AnnotateError(span, chunkErr)
 			return chunkErr
 		}
@@ -110,3 +126,64 @@ func validateBlobByRootRequest(blobIdents types.BlobSidecarsByRootReq) error {
 	}
 	return nil
 }
+
+// validateBlobByRootIndices checks the requested indices against the number of kzg commitments in the block for each
+// root, so that a request for an index the block can't have is rejected instead of being answered as not found.
+// Sidecars in blob storage were verified against their block before they were saved, so the block is only read for
+// identifiers that are not in blob storage. Roots for blocks that are not in the db can't be checked, the sidecars
+// for them are not found when served. The identifiers must be sorted by root, so that each root is only looked up once.
+func (s *Service) validateBlobByRootIndices(ctx context.Context, blobIdents types.BlobSidecarsByRootReq) error {
+	var root [32]byte
+	var stored [fieldparams.MaxBlobsPerBlock]bool
+	// checked is set once the commitments of the block for root have been read.
+	checked, known := false, false
+	var commitments uint64
+	for i := range blobIdents {
+		idx := blobIdents[i].Index
+		if idx >= fieldparams.MaxBlobsPerBlock {
+			return errors.Wrapf(types.ErrBlobIndexOutOfRange, "index=%d, max blobs per block=%d", idx, fieldparams.MaxBlobsPerBlock)
+		}
+		if r := bytesutil.ToBytes32(blobIdents[i].BlockRoot); i == 0 || r != root {
+			root, checked = r, false
+			var err error
+			stored, err = s.cfg.blobStorage.Indices(root)
+			if err != nil {
+				return errors.Wrapf(err, "could not read blob indices for root %#x", root)
+			}
+		}
+		if stored[idx] {
+			continue
+		}
+		if !checked {
+			n, ok, err := s.blockCommitmentCount(ctx, root)
+			if err != nil {
+				return err
+			}
+			checked, known, commitments = true, ok, n
+		}
+		if known && idx >= commitments {
+			return errors.Wrapf(types.ErrBlobIndexOutOfRange, "root=%#x, index=%d, commitments=%d", root, idx, commitments)
+		}
+	}
+	return nil
+}
+
+// blockCommitmentCount returns the number of kzg commitments in the block with the given root. The returned bool is
+// false if the block is not in the db.
+func (s *Service) blockCommitmentCount(ctx context.Context, root [32]byte) (uint64, bool, error) {
+	b, err := s.cfg.beaconDB.Block(ctx, root)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "could not read block for root %#x", root)
+	}
+	if err := blocks.BeaconBlockIsNil(b); err != nil {
+		return 0, false, nil
+	}
+	if b.Version() < version.Deneb {
+		return 0, true, nil
+	}
+	c, err := b.Block().Body().BlobKzgCommitments()
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "could not read kzg commitments for block root %#x", root)
+	}
+	return uint64(len(c)), true, nil
+}
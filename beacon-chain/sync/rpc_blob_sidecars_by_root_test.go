@@ -1,13 +1,17 @@
 package sync
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"testing"
 
 	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/filesystem"
+	db "github.com/prysmaticlabs/prysm/v5/beacon-chain/db/testing"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
 	p2pTypes "github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/types"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
 	fieldparams "github.com/prysmaticlabs/prysm/v5/config/fieldparams"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
@@ -15,6 +19,7 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
 	ethpb "github.com/prysmaticlabs/prysm/v5/proto/prysm/v1alpha1"
 	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/prysmaticlabs/prysm/v5/testing/util"
 	"github.com/prysmaticlabs/prysm/v5/time/slots"
 )
 
@@ -230,6 +235,19 @@ func TestBlobsByRootValidation(t *testing.T) {
 			nblocks: int(params.BeaconConfig().MaxRequestBlobSidecars) + 1,
 			err:     p2pTypes.ErrMaxBlobReqExceeded,
 		},
+		{
+			name:    "index out of range",
+			nblocks: 1,
+			requestFromSidecars: func(scs []blocks.ROBlob) interface{} {
+				req := blobRootRequestFromSidecars(scs).(*p2pTypes.BlobSidecarsByRootReq)
+				*req = append(*req, &ethpb.BlobIdentifier{BlockRoot: scs[0].BlockRootSlice(), Index: fieldparams.MaxBlobsPerBlock})
+				return req
+			},
+			defineExpected: func(*testing.T, []blocks.ROBlob, interface{}) []*expectedBlobChunk {
+				return []*expectedBlobChunk{{code: responseCodeInvalidRequest, message: p2pTypes.ErrBlobIndexOutOfRange.Error()}}
+			},
+			err: p2pTypes.ErrBlobIndexOutOfRange,
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -259,3 +277,67 @@ func TestBlobsByRootOK(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateBlobByRootIndices(t *testing.T) {
+	ctx := context.Background()
+	d := db.SetupDB(t)
+	bs := filesystem.NewEphemeralBlobStorage(t)
+	s := &Service{cfg: &config{beaconDB: d, blobStorage: bs}}
+	block, _ := generateTestBlockWithSidecars(t, [32]byte{}, 1, 2)
+	util.SaveBlock(t, ctx, d, block)
+	root, err := block.Block.HashTreeRoot()
+	require.NoError(t, err)
+	unknown := [32]byte{0xff}
+
+	cases := []struct {
+		name   string
+		idents []*ethpb.BlobIdentifier
+		err    error
+	}{
+		{
+			name:   "indices within commitments",
+			idents: []*ethpb.BlobIdentifier{{BlockRoot: root[:], Index: 0}, {BlockRoot: root[:], Index: 1}},
+		},
+		{
+			name:   "index beyond commitments",
+			idents: []*ethpb.BlobIdentifier{{BlockRoot: root[:], Index: 0}, {BlockRoot: root[:], Index: 2}},
+			err:    p2pTypes.ErrBlobIndexOutOfRange,
+		},
+		{
+			name:   "unknown block is not checked",
+			idents: []*ethpb.BlobIdentifier{{BlockRoot: unknown[:], Index: 3}},
+		},
+		{
+			name:   "index beyond max blobs per block",
+			idents: []*ethpb.BlobIdentifier{{BlockRoot: unknown[:], Index: fieldparams.MaxBlobsPerBlock}},
+			err:    p2pTypes.ErrBlobIndexOutOfRange,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := p2pTypes.BlobSidecarsByRootReq(c.idents)
+			sort.Sort(req)
+			err := s.validateBlobByRootIndices(ctx, req)
+			if c.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, c.err)
+		})
+	}
+
+	t.Run("stored sidecars are not checked against the block", func(t *testing.T) {
+		sblock, sidecars := generateTestBlockWithSidecars(t, [32]byte{}, 2, 2)
+		sroot, err := sblock.Block.HashTreeRoot()
+		require.NoError(t, err)
+		vscs, err := verification.BlobSidecarSliceNoop(sidecars)
+		require.NoError(t, err)
+		for i := range vscs {
+			require.NoError(t, bs.Save(vscs[i]))
+		}
+		// The service has no db, so the block can't be read.
+		stored := &Service{cfg: &config{blobStorage: bs}}
+		req := p2pTypes.BlobSidecarsByRootReq{{BlockRoot: sroot[:], Index: 0}, {BlockRoot: sroot[:], Index: 1}}
+		require.NoError(t, stored.validateBlobByRootIndices(ctx, req))
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/interfaces"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
 	"github.com/prysmaticlabs/prysm/v5/network/forks"
 	"github.com/prysmaticlabs/prysm/v5/runtime/version"
 	"github.com/prysmaticlabs/prysm/v5/time/slots"
@@ -139,19 +140,45 @@ func readResponseChunk(stream libp2pcore.Stream, tor blockchain.TemporalOracle,
 
 // WriteBlobSidecarChunk writes blob chunk object to stream.
 // response_chunk  ::= <result> | <context-bytes> | <encoding-dependent-header> | <encoded-payload>
+// The fork digest is computed before anything is written, so that if the fork schedule has no blob fork for the slot
+// of the sidecar, ErrBlobForkSchedule is returned with the stream untouched, and the sidecar can be skipped.
 func WriteBlobSidecarChunk(stream libp2pcore.Stream, tor blockchain.TemporalOracle, encoding encoder.NetworkEncoding, sidecar blocks.VerifiedROBlob) error {
-	if _, err := stream.Write([]byte{responseCodeSuccess}); err != nil {
-		return err
-	}
 	valRoot := tor.GenesisValidatorsRoot()
-	ctxBytes, err := forks.ForkDigestFromEpoch(slots.ToEpoch(sidecar.Slot()), valRoot[:])
+	ctxBytes, err := blobForkDigest(sidecar.Slot(), valRoot[:])
 	if err != nil {
 		return err
 	}
+	return writeBlobSidecarChunkWithDigest(stream, encoding, ctxBytes, sidecar)
+}
+
+// writeBlobSidecarChunkWithDigest writes a blob chunk like WriteBlobSidecarChunk, using a fork digest that the caller
+// has already resolved for the slot of the sidecar.
+func writeBlobSidecarChunkWithDigest(stream libp2pcore.Stream, encoding encoder.NetworkEncoding, ctxBytes [4]byte, sidecar blocks.VerifiedROBlob) error {
+	if _, err := stream.Write([]byte{responseCodeSuccess}); err != nil {
+		return err
+	}
 
 	if err := writeContextToStream(ctxBytes[:], stream); err != nil {
 		return err
 	}
-	_, err = encoding.EncodeWithMaxLength(stream, sidecar)
+	_, err := encoding.EncodeWithMaxLength(stream, sidecar)
 	return err
 }
+
+// blobForkDigest returns the fork digest for a blob sidecar at the slot. Blob sidecars only exist from Deneb onwards,
+// so a slot that the fork schedule places before Deneb is an error, just like a slot that no fork can be found for.
+func blobForkDigest(slot primitives.Slot, valRoot []byte) ([4]byte, error) {
+	epoch := slots.ToEpoch(slot)
+	v, err := slotForkVersion(slot)
+	if err != nil {
+		return [4]byte{}, errors.Wrapf(ErrBlobForkSchedule, "slot=%d, epoch=%d: %v", slot, epoch, err)
+	}
+	if v < version.Deneb {
+		return [4]byte{}, errors.Wrapf(ErrBlobForkSchedule, "slot=%d, epoch=%d, fork=%s", slot, epoch, version.String(v))
+	}
+	digest, err := forks.ForkDigestFromEpoch(epoch, valRoot)
+	if err != nil {
+		return [4]byte{}, errors.Wrapf(ErrBlobForkSchedule, "slot=%d, epoch=%d: %v", slot, epoch, err)
+	}
+	return digest, nil
+}
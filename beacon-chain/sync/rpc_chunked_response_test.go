@@ -0,0 +1,86 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/pkg/errors"
+	mock "github.com/prysmaticlabs/prysm/v5/beacon-chain/blockchain/testing"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/p2p/encoder"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/prysmaticlabs/prysm/v5/testing/util"
+	logTest "github.com/sirupsen/logrus/hooks/test"
+)
+
+// blobRangeStream is a countingStream for the BlobSidecarsByRange protocol, so that context bytes are written.
+type blobRangeStream struct {
+	countingStream
+}
+
+func (*blobRangeStream) Protocol() protocol.ID {
+	return protocol.ID(p2p.RPCBlobSidecarsByRangeTopicV1 + encoder.SszNetworkEncoder{}.ProtocolSuffix())
+}
+
+func TestWriteBlobSidecarChunkForkSchedule(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.AltairForkEpoch = 1
+	cfg.BellatrixForkEpoch = 2
+	cfg.CapellaForkEpoch = 3
+	cfg.DenebForkEpoch = 4
+	cfg.InitializeForkSchedule()
+	params.OverrideBeaconConfig(cfg)
+
+	spe := params.BeaconConfig().SlotsPerEpoch
+	chain := &mock.ChainService{ValidatorsRoot: [32]byte{0x01}}
+	cases := []struct {
+		name string
+		slot primitives.Slot
+		err  error
+	}{
+		{name: "slot before the configured deneb fork", slot: 4*spe - 1, err: ErrBlobForkSchedule},
+		{name: "first slot of deneb", slot: 4 * spe},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, scs := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, c.slot, 1)
+			sc, err := verification.BlobSidecarNoop(scs[0])
+			require.NoError(t, err)
+			stream := &blobRangeStream{}
+			err = WriteBlobSidecarChunk(stream, chain, encoder.SszNetworkEncoder{}, sc)
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+				// Nothing is written, so the sidecar can be skipped without breaking the response.
+				require.Equal(t, 0, stream.writes)
+				require.Equal(t, true, skipBlobForkSchedule(err, sc))
+				return
+			}
+			require.NoError(t, err)
+			require.NotEqual(t, 0, stream.writes)
+			require.Equal(t, false, skipBlobForkSchedule(err, sc))
+		})
+	}
+}
+
+func TestSkipBlobForkScheduleLogsOncePerEpoch(t *testing.T) {
+	hook := logTest.NewGlobal()
+	blobForkScheduleLogged.Store(0)
+	spe := params.BeaconConfig().SlotsPerEpoch
+	sidecar := func(slot primitives.Slot) blocks.VerifiedROBlob {
+		_, scs := util.GenerateTestDenebBlockWithSidecar(t, [32]byte{}, slot, 1)
+		sc, err := verification.BlobSidecarNoop(scs[0])
+		require.NoError(t, err)
+		return sc
+	}
+	err := errors.Wrap(ErrBlobForkSchedule, "no blob fork")
+	require.Equal(t, true, skipBlobForkSchedule(err, sidecar(spe)))
+	require.Equal(t, true, skipBlobForkSchedule(err, sidecar(spe+1)))
+	require.Equal(t, 1, len(hook.AllEntries()))
+	require.Equal(t, true, skipBlobForkSchedule(err, sidecar(2*spe)))
+	require.Equal(t, 2, len(hook.AllEntries()))
+}
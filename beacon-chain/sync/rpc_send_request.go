@@ -40,6 +40,7 @@ var (
 	errBlobResponseOutOfBounds        = errors.Wrap(ErrInvalidFetchedData, "received BlobSidecar with slot outside BlobSidecarsByRangeRequest bounds")
 	errChunkResponseBlockMismatch     = errors.Wrap(ErrInvalidFetchedData, "blob block details do not match")
 	errChunkResponseParentMismatch    = errors.Wrap(ErrInvalidFetchedData, "parent root for response element doesn't match previous element root")
+	errMaxBlobCountsExceeded          = errors.Wrap(ErrInvalidFetchedData, "peer sent more blob counts than requested slots")
 )
 
 // BeaconBlockProcessor defines a block processing function, which allows to start utilizing
@@ -156,6 +157,11 @@ func SendBeaconBlocksByRootRequest(
 }
 
 func SendBlobsByRangeRequest(ctx context.Context, tor blockchain.TemporalOracle, p2pApi p2p.SenderEncoder, pid peer.ID, ctxMap ContextByteVersions, req *pb.BlobSidecarsByRangeRequest, bvs ...BlobResponseValidation) ([]blocks.ROBlob, error) {
+	// Check the request locally to fail fast, rather than waiting for the peer to reject it.
+	req, err := NewBlobSidecarsByRangeRequest(req.StartSlot, req.Count)
+	if err != nil {
+		return nil, err
+	}
 	topic, err := p2p.TopicFromMessage(p2p.BlobSidecarsByRangeName, slots.ToEpoch(tor.CurrentSlot()))
 	if err != nil {
 		return nil, err
@@ -182,6 +188,72 @@ func SendBlobsByRangeRequest(ctx context.Context, tor blockchain.TemporalOracle,
 	return readChunkEncodedBlobs(stream, p2pApi.Encoding(), ctxMap, composeBlobValidations(vfuncs...), max)
 }
 
+// SendBlobSidecarCountsByRangeRequest sends a BlobSidecarCountsByRange request, and returns the number of blob sidecars
+// the peer has for each block in the range. This lets a caller find out which peers have the sidecars for a range
+// before requesting them with SendBlobsByRangeRequest.
+func SendBlobSidecarCountsByRangeRequest(ctx context.Context, tor blockchain.TemporalOracle, p2pApi p2p.SenderEncoder, pid peer.ID, req *pb.BlobSidecarsByRangeRequest) ([]*p2ptypes.BlobSidecarCount, error) {
+	req, err := NewBlobSidecarsByRangeRequest(req.StartSlot, req.Count)
+	if err != nil {
+		return nil, err
+	}
+	topic, err := p2p.TopicFromMessage(p2p.BlobSidecarCountsByRangeName, slots.ToEpoch(tor.CurrentSlot()))
+	if err != nil {
+		return nil, err
+	}
+	log.WithFields(logrus.Fields{
+		"topic":     topic,
+		"startSlot": req.StartSlot,
+		"count":     req.Count,
+	}).Debug("Sending blob sidecar counts by range request")
+	stream, err := p2pApi.Send(ctx, req, topic, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer closeStream(stream, log)
+	return readChunkEncodedBlobCounts(stream, p2pApi.Encoding(), req)
+}
+
+func readChunkEncodedBlobCounts(stream network.Stream, encoding encoder.NetworkEncoding, req *pb.BlobSidecarsByRangeRequest) ([]*p2ptypes.BlobSidecarCount, error) {
+	end := req.StartSlot + primitives.Slot(req.Count)
+	counts := make([]*p2ptypes.BlobSidecarCount, 0)
+	var total uint64
+	// Attempt an extra read beyond the requested count of slots to check that the peer sends at most one count per slot.
+	for i := uint64(0); i < req.Count+1; i++ {
+		code, msg, err := ReadStatusCode(stream, encoding)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if code != 0 {
+			return nil, errors.Wrap(errBlobChunkedReadFailure, msg)
+		}
+		if i == req.Count {
+			return nil, errMaxBlobCountsExceeded
+		}
+		c := &p2ptypes.BlobSidecarCount{}
+		if err := encoding.DecodeWithMaxLength(stream, c); err != nil {
+			return nil, errors.Wrap(err, "could not decode blob sidecar count")
+		}
+		if c.Slot < req.StartSlot || c.Slot >= end {
+			return nil, errors.Wrapf(errBlobResponseOutOfBounds, "req start,end:%d,%d, resp:%d", req.StartSlot, end, c.Slot)
+		}
+		if len(counts) > 0 && c.Slot <= counts[len(counts)-1].Slot {
+			return nil, errors.Wrapf(errChunkResponseSlotNotAsc, "slot %d after %d", c.Slot, counts[len(counts)-1].Slot)
+		}
+		if c.Count > fieldparams.MaxBlobsPerBlock {
+			return nil, errors.Wrapf(errBlobIndexOutOfBounds, "count %d for slot %d", c.Count, c.Slot)
+		}
+		total += c.Count
+		if total > params.BeaconConfig().MaxRequestBlobSidecars {
+			return nil, errMaxRequestBlobSidecarsExceeded
+		}
+		counts = append(counts, c)
+	}
+	return counts, nil
+}
+
 func SendBlobSidecarByRoot(
 	ctx context.Context, tor blockchain.TemporalOracle, p2pApi p2p.P2P, pid peer.ID,
 	ctxMap ContextByteVersions, req *p2ptypes.BlobSidecarsByRootReq,
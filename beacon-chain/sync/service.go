@@ -36,6 +36,7 @@ import (
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/backfill/coverage"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/verification"
 	lruwrpr "github.com/prysmaticlabs/prysm/v5/cache/lru"
+	"github.com/prysmaticlabs/prysm/v5/cmd/beacon-chain/flags"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/blocks"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/interfaces"
@@ -96,6 +97,7 @@ type config struct {
 	clock                   *startup.Clock
 	stateNotifier           statefeed.Notifier
 	blobStorage             *filesystem.BlobStorage
+	blobServingAccessList   *PeerAccessList
 }
 
 // This defines the interface for interacting with block chain service
@@ -157,9 +159,23 @@ type Service struct {
 	verifierWaiter                   *verification.InitializerWaiter
 	newBlobVerifier                  verification.NewBlobVerifier
 	availableBlocker                 coverage.AvailableBlocker
+	availableBlobber                 coverage.AvailableBlobber
+	proposalDuties                   ProposalDutyChecker
+	wall                             prysmTime.Clock
+	blobReads                        blobRangeCoalescer
+	blobServes                       blobServeDrain
+	blobStore                        blobStoreBreaker
 	ctxMap                           ContextByteVersions
 }
 
+// wallClock returns the clock set by WithWallClock, or the system clock.
+func (s *Service) wallClock() prysmTime.Clock {
+	if s.wall == nil {
+		return prysmTime.RealClock{}
+	}
+	return s.wall
+}
+
 // NewService initializes new regular sync service.
 func NewService(ctx context.Context, opts ...Option) *Service {
 	c := gcache.New(pendingBlockExpTime /* exp time */, 0 /* disable janitor */)
@@ -236,9 +252,15 @@ func (s *Service) Start() {
 	s.processPendingAttsQueue()
 	s.maintainPeerStatuses()
 	s.resyncIfBehind()
+	if flags.Get().ServeSelfTest {
+		go s.runBlobServeSelfTest()
+	}
 
 	// Update sync metrics.
 	async.RunEvery(s.ctx, syncMetricsInterval, s.updateMetrics)
+	async.RunEvery(s.ctx, chunkFailureLogInterval, func() {
+		chunkFailures.flush(time.Now())
+	})
 }
 
 // Stop the regular sync service.
@@ -252,6 +274,10 @@ func (s *Service) Stop() error {
 	for _, p := range s.cfg.p2p.Host().Mux().Protocols() {
 		s.cfg.p2p.Host().RemoveStreamHandler(p)
 	}
+	// Let blob sidecar range responses in flight end cleanly before the context of the handlers is canceled.
+	if !s.blobServes.drain(flags.Get().BlobServeDrainTimeout) {
+		log.Warn("Timed out waiting for blob sidecar range responses to end during shutdown")
+	}
 	// Deregister Topic Subscribers.
 	for _, t := range s.cfg.p2p.PubSub().GetTopics() {
 		s.unSubscribeFromTopic(t)
@@ -285,6 +311,10 @@ func (s *Service) initCaches() {
 	s.seenAttesterSlashingCache = make(map[uint64]bool)
 	s.seenProposerSlashingCache = lruwrpr.New(seenProposerSlashingSize)
 	s.badBlockCache = lruwrpr.New(badBlockSize)
+	s.blobReads.window = flags.Get().BlobServeCoalesceWindow
+	s.blobReads.maxRecent = flags.Get().BlobServeCoalesceCacheSize
+	s.blobStore.threshold = flags.Get().BlobServeBreakerThreshold
+	s.blobStore.cooldown = flags.Get().BlobServeBreakerCooldown
 }
 
 func (s *Service) waitForChainStart() {
@@ -339,6 +369,18 @@ func (s *Service) writeErrorResponseToStream(responseCode byte, reason string, s
 	writeErrorResponseToStream(responseCode, reason, stream, s.cfg.p2p)
 }
 
+// writeErrorResponseWithContext is like writeErrorResponseToStream, except that when ctx is already done, eg because
+// the response deadline passed while reading from the db, the error response is not written and the stream is only
+// closed. By then the peer has likely given up on the request, and a write to a peer that is gone can block.
+func (s *Service) writeErrorResponseWithContext(ctx context.Context, responseCode byte, reason string, stream libp2pcore.Stream) {
+	if err := ctx.Err(); err != nil {
+		log.WithError(err).WithField("protocol", stream.Protocol()).Debug("Not writing error response after request context is done")
+		closeStream(stream, log)
+		return
+	}
+	s.writeErrorResponseToStream(responseCode, reason, stream)
+}
+
 func (s *Service) setRateCollector(topic string, c *leakybucket.Collector) {
 	s.rateLimiter.limiterMap[topic] = c
 }
@@ -361,3 +403,9 @@ type Checker interface {
 	Status() error
 	Resync() error
 }
+
+// ProposalDutyChecker reports whether a validator attached to the node is about to propose a block. It is satisfied by
+// blockchain.Service.
+type ProposalDutyChecker interface {
+	ProposalImminent(within time.Duration) bool
+}
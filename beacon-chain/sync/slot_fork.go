@@ -0,0 +1,79 @@
+package sync
+
+import (
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
+	"github.com/prysmaticlabs/prysm/v5/network/forks"
+	"github.com/prysmaticlabs/prysm/v5/runtime/version"
+	"github.com/prysmaticlabs/prysm/v5/time/slots"
+)
+
+// slotForkVersion returns the version of the fork scheduled for the slot, eg version.Deneb. Handlers that treat
+// sidecars differently depending on the fork of their slot should resolve the fork with this helper.
+func slotForkVersion(slot primitives.Slot) (int, error) {
+	f, err := forks.Fork(slots.ToEpoch(slot))
+	if err != nil {
+		return 0, err
+	}
+	v, ok := params.ConfigForkVersions(params.BeaconConfig())[bytesutil.ToBytes4(f.CurrentVersion)]
+	if !ok {
+		return 0, errors.Errorf("fork version %#x is not mapped to a known version", f.CurrentVersion)
+	}
+	return v, nil
+}
+
+// slotForkLabel returns the name of the fork scheduled for the slot, for use as a metric label.
+func slotForkLabel(slot primitives.Slot) string {
+	v, err := slotForkVersion(slot)
+	if err != nil {
+		return "unknown"
+	}
+	return version.String(v)
+}
+
+// slotForkCache resolves the fork of slots in the order a range response visits them, remembering the fork of the
+// last epoch it resolved, so that a fork lookup is only made when the slots cross into another epoch. Forks are
+// scheduled at epoch boundaries, so every slot of an epoch has the same fork. The zero value is ready to use.
+type slotForkCache struct {
+	resolved bool
+	epoch    primitives.Epoch
+	v        int
+	err      error
+
+	digestResolved bool
+	digestEpoch    primitives.Epoch
+	digest         [4]byte
+	digestErr      error
+}
+
+// version returns the fork of the slot, like slotForkVersion.
+func (c *slotForkCache) version(slot primitives.Slot) (int, error) {
+	e := slots.ToEpoch(slot)
+	if !c.resolved || e != c.epoch {
+		c.v, c.err = slotForkVersion(slot)
+		c.epoch, c.resolved = e, true
+	}
+	return c.v, c.err
+}
+
+// label returns the name of the fork of the slot, like slotForkLabel.
+func (c *slotForkCache) label(slot primitives.Slot) string {
+	v, err := c.version(slot)
+	if err != nil {
+		return "unknown"
+	}
+	return version.String(v)
+}
+
+// blobDigest returns the fork digest for a blob sidecar at the slot, like blobForkDigest. The digest of the last epoch
+// is remembered separately from its fork, since fork digests are computed from the genesis validators root as well.
+func (c *slotForkCache) blobDigest(slot primitives.Slot, valRoot []byte) ([4]byte, error) {
+	e := slots.ToEpoch(slot)
+	if !c.digestResolved || e != c.digestEpoch {
+		c.digest, c.digestErr = blobForkDigest(slot, valRoot)
+		c.digestEpoch, c.digestResolved = e, true
+	}
+	return c.digest, c.digestErr
+}
@@ -0,0 +1,97 @@
+package sync
+
+import (
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/runtime/version"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/prysmaticlabs/prysm/v5/time/slots"
+)
+
+func TestSlotForkVersion(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.AltairForkEpoch = 1
+	cfg.BellatrixForkEpoch = 2
+	cfg.CapellaForkEpoch = 3
+	cfg.DenebForkEpoch = 4
+	cfg.ElectraForkEpoch = 6
+	cfg.InitializeForkSchedule()
+	params.OverrideBeaconConfig(cfg)
+
+	spe := params.BeaconConfig().SlotsPerEpoch
+	cases := []struct {
+		slot primitives.Slot
+		v    int
+	}{
+		{slot: 0, v: version.Phase0},
+		{slot: 4*spe - 1, v: version.Capella},
+		{slot: 4 * spe, v: version.Deneb},
+		{slot: 6*spe - 1, v: version.Deneb},
+		{slot: 6 * spe, v: version.Electra},
+	}
+	for _, c := range cases {
+		v, err := slotForkVersion(c.slot)
+		require.NoError(t, err)
+		require.Equal(t, version.String(c.v), version.String(v), "slot %d", c.slot)
+		require.Equal(t, version.String(c.v), slotForkLabel(c.slot))
+	}
+}
+
+func TestSlotForkCache(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.AltairForkEpoch = 1
+	cfg.BellatrixForkEpoch = 2
+	cfg.CapellaForkEpoch = 3
+	cfg.DenebForkEpoch = 4
+	cfg.ElectraForkEpoch = 6
+	cfg.InitializeForkSchedule()
+	params.OverrideBeaconConfig(cfg)
+
+	spe := params.BeaconConfig().SlotsPerEpoch
+	// Visit the slots around the Electra fork in both directions, as forward and reverse responses do.
+	forward := []primitives.Slot{5*spe + 1, 6*spe - 1, 6 * spe, 6*spe + 1}
+	reverse := []primitives.Slot{6*spe + 1, 6 * spe, 6*spe - 1, 5*spe + 1}
+	for _, visit := range [][]primitives.Slot{forward, reverse} {
+		c := &slotForkCache{}
+		for _, slot := range visit {
+			v, err := c.version(slot)
+			require.NoError(t, err)
+			want, err := slotForkVersion(slot)
+			require.NoError(t, err)
+			require.Equal(t, version.String(want), version.String(v), "slot %d", slot)
+			require.Equal(t, slotForkLabel(slot), c.label(slot))
+			require.Equal(t, slots.ToEpoch(slot), c.epoch)
+		}
+	}
+}
+
+func TestSlotForkCacheBlobDigest(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.AltairForkEpoch = 1
+	cfg.BellatrixForkEpoch = 2
+	cfg.CapellaForkEpoch = 3
+	cfg.DenebForkEpoch = 4
+	cfg.ElectraForkEpoch = 6
+	cfg.InitializeForkSchedule()
+	params.OverrideBeaconConfig(cfg)
+
+	spe := params.BeaconConfig().SlotsPerEpoch
+	valRoot := []byte{0x01}
+	c := &slotForkCache{}
+	for _, slot := range []primitives.Slot{4*spe - 1, 5*spe + 1, 6*spe - 1, 6 * spe, 6*spe + 1, 5 * spe} {
+		d, err := c.blobDigest(slot, valRoot)
+		want, wantErr := blobForkDigest(slot, valRoot)
+		require.Equal(t, slots.ToEpoch(slot), c.digestEpoch)
+		if wantErr != nil {
+			require.ErrorIs(t, err, ErrBlobForkSchedule)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, want, d, "slot %d", slot)
+	}
+}
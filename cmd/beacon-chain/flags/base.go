@@ -4,6 +4,7 @@ package flags
 
 import (
 	"strings"
+	"time"
 
 	"github.com/prysmaticlabs/prysm/v5/cmd"
 	"github.com/prysmaticlabs/prysm/v5/config/params"
@@ -204,6 +205,101 @@ var (
 		Usage: "The factor by which blob batch limit may increase on burst.",
 		Value: 2,
 	}
+	// BlobServingHotEpochs specifies the number of recent epochs of blob sidecars that are served with the normal blob budget.
+	BlobServingHotEpochs = &cli.Uint64Flag{
+		Name: "blob-serving-hot-epochs",
+		Usage: "The number of most recent epochs of blob sidecars served using the blob-batch-limit budget. " +
+			"Sidecars older than this window are also limited by blob-batch-limit-historical. 0 disables the separate historical limit.",
+	}
+	// BlobBatchLimitHistorical specifies the blob batch limit for serving sidecars older than the hot window.
+	BlobBatchLimitHistorical = &cli.IntFlag{
+		Name: "blob-batch-limit-historical",
+		Usage: "The amount of blobs older than blob-serving-hot-epochs the local peer is bounded to respond to in a batch, " +
+			"using the blob-batch-limit-burst-factor. Only used if blob-serving-hot-epochs is set.",
+		Value: 16,
+	}
+	// BlobServeFlushInterval specifies how often buffered blob sidecar responses are flushed to the requesting peer.
+	BlobServeFlushInterval = &cli.DurationFlag{
+		Name: "blob-serve-flush-interval",
+		Usage: "How long a response to a blob sidecars request may be buffered before it is flushed to the peer. " +
+			"0 flushes after every sidecar. Larger values send fewer, larger writes for dense ranges, at the cost of latency.",
+	}
+	// BlobServeCoalesceReads shares reads of blob storage between concurrent identical blob sidecar range requests.
+	BlobServeCoalesceReads = &cli.BoolFlag{
+		Name: "blob-serve-coalesce-reads",
+		Usage: "Concurrent blob sidecar range requests for the same range share a single read of blob storage. " +
+			"Reduces disk load when many peers request the same range at once, at the cost of holding a whole batch of sidecars in memory.",
+	}
+	// BlobServeCoalesceWindow specifies how long a coalesced blob sidecar range read is shared after it completes.
+	BlobServeCoalesceWindow = &cli.DurationFlag{
+		Name: "blob-serve-coalesce-window",
+		Usage: "How long the sidecars read for a blob sidecar range request are also used for identical requests that arrive " +
+			"after the read completed. Only used if blob-serve-coalesce-reads is set. 0 only shares reads that are in flight at the same time.",
+		Value: 2 * time.Second,
+	}
+	// BlobServeCoalesceCacheSize specifies how many coalesced blob sidecar range reads are kept for the coalescing window.
+	BlobServeCoalesceCacheSize = &cli.IntFlag{
+		Name: "blob-serve-coalesce-cache-size",
+		Usage: "The largest number of blob sidecar range reads kept for blob-serve-coalesce-window. " +
+			"Each read holds a batch of sidecars in memory.",
+		Value: 4,
+	}
+	// BlobServeBreakerThreshold specifies how many consecutive blob storage errors stop blob sidecar range serving.
+	BlobServeBreakerThreshold = &cli.IntFlag{
+		Name: "blob-serve-breaker-threshold",
+		Usage: "Number of consecutive blob storage errors while serving blob sidecar range requests after which requests are " +
+			"answered as resource unavailable, without reading blob storage, for blob-serve-breaker-cooldown. 0 disables the breaker.",
+		Value: 5,
+	}
+	// BlobServeBreakerCooldown specifies how long blob sidecar range serving stops after repeated blob storage errors.
+	BlobServeBreakerCooldown = &cli.DurationFlag{
+		Name:  "blob-serve-breaker-cooldown",
+		Usage: "How long blob sidecar range requests are answered as resource unavailable once blob-serve-breaker-threshold is reached.",
+		Value: 30 * time.Second,
+	}
+	// BlobServeProposalPause specifies how long before a local validator's proposal large blob sidecar range requests are turned away.
+	BlobServeProposalPause = &cli.DurationFlag{
+		Name: "blob-serve-proposal-pause",
+		Usage: "Blob sidecar range requests for more than an epoch of slots are answered as resource unavailable when a validator " +
+			"attached to this node is due to propose within this duration, leaving disk and network bandwidth for the proposal. " +
+			"Smaller requests are served normally. 0 disables the pause.",
+	}
+	// ServeWhileSyncing allows large blob sidecar range requests to be served while the node is catching up with the chain.
+	ServeWhileSyncing = &cli.BoolFlag{
+		Name: "serve-while-syncing",
+		Usage: "Serve blob sidecar range requests for more than an epoch of slots while the head of this node is more than " +
+			"serve-while-syncing-margin epochs behind the current slot. By default they are answered as resource unavailable, " +
+			"so that disk and network bandwidth go to catching up. Smaller requests are served if they start within the margin of the head.",
+	}
+	// ServeWhileSyncingMargin specifies how far the head can fall behind the current slot before large blob sidecar range requests are turned away.
+	ServeWhileSyncingMargin = &cli.Uint64Flag{
+		Name: "serve-while-syncing-margin",
+		Usage: "The number of epochs the head of this node can be behind the current slot before blob sidecar range requests " +
+			"for more than an epoch of slots are answered as resource unavailable, unless serve-while-syncing is set. " +
+			"0 disables the refusal.",
+		Value: 2,
+	}
+	// ServeSelfTest enables a periodic self-test of blob sidecar serving.
+	ServeSelfTest = &cli.BoolFlag{
+		Name: "serve-self-test",
+		Usage: "Periodically requests the blob sidecars of the latest epoch from this node itself over a loopback stream, " +
+			"and checks that the response decodes. A failure is logged and reported by the rpc_blob_serve_self_test_failing " +
+			"metric, so that broken blob serving is noticed before peers complain.",
+	}
+	// BlobServeDrainTimeout specifies how long shutdown waits for blob sidecar range responses in flight to end.
+	BlobServeDrainTimeout = &cli.DurationFlag{
+		Name: "blob-serve-drain-timeout",
+		Usage: "On shutdown, blob sidecar range responses that are being written finish their current chunk and close " +
+			"their streams, so that peers don't see a reset stream. This is the longest shutdown waits for them to end.",
+		Value: 5 * time.Second,
+	}
+	// ChunkSendFailureLogLevel specifies the level of the summary logged for failures to send chunked responses.
+	ChunkSendFailureLogLevel = &cli.StringFlag{
+		Name: "chunk-send-failure-log-level",
+		Usage: "Log level for failures to send a chunk of a response to a peer, usually because the peer disconnected. " +
+			"Failures are summarized at most once a minute. One of 'trace', 'debug', 'info', 'warn' or 'error'.",
+		Value: "debug",
+	}
 	// DisableDebugRPCEndpoints disables the debug Beacon API namespace.
 	DisableDebugRPCEndpoints = &cli.BoolFlag{
 		Name:  "disable-debug-rpc-endpoints",
@@ -1,7 +1,11 @@
 package flags
 
 import (
+	"time"
+
+	"github.com/pkg/errors"
 	"github.com/prysmaticlabs/prysm/v5/cmd"
+	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 )
 
@@ -16,6 +20,20 @@ type GlobalFlags struct {
 	BlockBatchLimitBurstFactor int
 	BlobBatchLimit             int
 	BlobBatchLimitBurstFactor  int
+	BlobServingHotEpochs       uint64
+	BlobBatchLimitHistorical   int
+	BlobServeFlushInterval     time.Duration
+	BlobServeCoalesceReads     bool
+	BlobServeCoalesceWindow    time.Duration
+	BlobServeCoalesceCacheSize int
+	BlobServeBreakerThreshold  int
+	BlobServeBreakerCooldown   time.Duration
+	BlobServeProposalPause     time.Duration
+	BlobServeDrainTimeout      time.Duration
+	ServeWhileSyncing          bool
+	ServeWhileSyncingMargin    uint64
+	ServeSelfTest              bool
+	ChunkSendFailureLogLevel   string
 }
 
 var globalConfig *GlobalFlags
@@ -34,8 +52,8 @@ func Init(c *GlobalFlags) {
 }
 
 // ConfigureGlobalFlags initializes the global config.
-// based on the provided cli context.
-func ConfigureGlobalFlags(ctx *cli.Context) {
+// based on the provided cli context. It returns an error if a flag has an invalid value.
+func ConfigureGlobalFlags(ctx *cli.Context) error {
 	cfg := &GlobalFlags{}
 	if ctx.Bool(SubscribeToAllSubnets.Name) {
 		log.Warn("Subscribing to All Attestation Subnets")
@@ -45,11 +63,29 @@ func ConfigureGlobalFlags(ctx *cli.Context) {
 	cfg.BlockBatchLimitBurstFactor = ctx.Int(BlockBatchLimitBurstFactor.Name)
 	cfg.BlobBatchLimit = ctx.Int(BlobBatchLimit.Name)
 	cfg.BlobBatchLimitBurstFactor = ctx.Int(BlobBatchLimitBurstFactor.Name)
+	cfg.BlobServingHotEpochs = ctx.Uint64(BlobServingHotEpochs.Name)
+	cfg.BlobBatchLimitHistorical = ctx.Int(BlobBatchLimitHistorical.Name)
+	cfg.BlobServeFlushInterval = ctx.Duration(BlobServeFlushInterval.Name)
+	cfg.BlobServeCoalesceReads = ctx.Bool(BlobServeCoalesceReads.Name)
+	cfg.BlobServeCoalesceWindow = ctx.Duration(BlobServeCoalesceWindow.Name)
+	cfg.BlobServeCoalesceCacheSize = ctx.Int(BlobServeCoalesceCacheSize.Name)
+	cfg.BlobServeBreakerThreshold = ctx.Int(BlobServeBreakerThreshold.Name)
+	cfg.BlobServeBreakerCooldown = ctx.Duration(BlobServeBreakerCooldown.Name)
+	cfg.BlobServeProposalPause = ctx.Duration(BlobServeProposalPause.Name)
+	cfg.BlobServeDrainTimeout = ctx.Duration(BlobServeDrainTimeout.Name)
+	cfg.ServeWhileSyncing = ctx.Bool(ServeWhileSyncing.Name)
+	cfg.ServeWhileSyncingMargin = ctx.Uint64(ServeWhileSyncingMargin.Name)
+	cfg.ServeSelfTest = ctx.Bool(ServeSelfTest.Name)
+	cfg.ChunkSendFailureLogLevel = ctx.String(ChunkSendFailureLogLevel.Name)
+	if _, err := logrus.ParseLevel(cfg.ChunkSendFailureLogLevel); err != nil {
+		return errors.Wrapf(err, "invalid value for --%s", ChunkSendFailureLogLevel.Name)
+	}
 	cfg.MinimumPeersPerSubnet = ctx.Int(MinPeersPerSubnet.Name)
 	cfg.MaxConcurrentDials = ctx.Int(MaxConcurrentDials.Name)
 	configureMinimumPeers(ctx, cfg)
 
 	Init(cfg)
+	return nil
 }
 
 // MaxDialIsActive checks if the user has enabled the max dial flag.
@@ -0,0 +1,23 @@
+package flags
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestConfigureGlobalFlags_ChunkSendFailureLogLevel(t *testing.T) {
+	resetCfg := Get()
+	defer Init(resetCfg)
+
+	set := flag.NewFlagSet("test", 0)
+	set.String(ChunkSendFailureLogLevel.Name, ChunkSendFailureLogLevel.Value, "")
+	require.NoError(t, set.Set(ChunkSendFailureLogLevel.Name, "warn"))
+	require.NoError(t, ConfigureGlobalFlags(cli.NewContext(&cli.App{}, set, nil)))
+	require.Equal(t, "warn", Get().ChunkSendFailureLogLevel)
+
+	require.NoError(t, set.Set(ChunkSendFailureLogLevel.Name, "loud"))
+	require.ErrorContains(t, "invalid value for --chunk-send-failure-log-level", ConfigureGlobalFlags(cli.NewContext(&cli.App{}, set, nil)))
+}
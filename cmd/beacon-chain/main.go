@@ -59,6 +59,20 @@ var appFlags = []cli.Flag{
 	flags.BlockBatchLimitBurstFactor,
 	flags.BlobBatchLimit,
 	flags.BlobBatchLimitBurstFactor,
+	flags.BlobServingHotEpochs,
+	flags.BlobBatchLimitHistorical,
+	flags.BlobServeFlushInterval,
+	flags.BlobServeCoalesceReads,
+	flags.BlobServeCoalesceWindow,
+	flags.BlobServeCoalesceCacheSize,
+	flags.BlobServeBreakerThreshold,
+	flags.BlobServeBreakerCooldown,
+	flags.BlobServeProposalPause,
+	flags.BlobServeDrainTimeout,
+	flags.ServeWhileSyncing,
+	flags.ServeWhileSyncingMargin,
+	flags.ServeSelfTest,
+	flags.ChunkSendFailureLogLevel,
 	flags.InteropMockEth1DataVotesFlag,
 	flags.InteropNumValidatorsFlag,
 	flags.InteropGenesisTimeFlag,
@@ -144,10 +158,25 @@ var appFlags = []cli.Flag{
 	flags.JwtId,
 	storage.BlobStoragePathFlag,
 	storage.BlobRetentionEpochFlag,
+	storage.CleanOrphanedBlobsFlag,
+	storage.CleanOrphanedBlobsDeleteFlag,
 	bflags.EnableExperimentalBackfill,
 	bflags.BackfillBatchSize,
 	bflags.BackfillWorkerCount,
 	bflags.BackfillOldestSlot,
+	bflags.ReadyRequiresBackfill,
+	bflags.ReadyBackfillSlot,
+	bflags.BackfillCoverageSampleInterval,
+	bflags.BackfillMaxBufferedBytes,
+	bflags.BackfillSkipBlobs,
+	bflags.BackfillBlobPruneMargin,
+	bflags.BackfillBytesPerSlotEstimate,
+	bflags.BackfillMaxPeers,
+	bflags.BackfillMaxRequestsPerPeer,
+	bflags.BackfillQuorum,
+	bflags.BackfillTrustedRoot,
+	bflags.BackfillTrustedSlot,
+	bflags.BackfillImportDataDir,
 }
 
 func init() {
@@ -24,6 +24,16 @@ var (
 		Value:   uint64(params.BeaconConfig().MinEpochsForBlobsSidecarsRequest),
 		Aliases: []string{"extend-blob-retention-epoch"},
 	}
+	// CleanOrphanedBlobsFlag enables a scan of blob storage at startup for sidecars whose block is not in the database.
+	CleanOrphanedBlobsFlag = &cli.BoolFlag{
+		Name:  "clean-orphaned-blobs",
+		Usage: "Scans blob storage at startup for blob sidecars whose block is not in the database, and reports them. The sidecars are only removed if --clean-orphaned-blobs-delete is also set.",
+	}
+	// CleanOrphanedBlobsDeleteFlag makes the startup scan enabled by CleanOrphanedBlobsFlag remove the orphaned sidecars.
+	CleanOrphanedBlobsDeleteFlag = &cli.BoolFlag{
+		Name:  "clean-orphaned-blobs-delete",
+		Usage: "Removes the orphaned blob sidecars found by --clean-orphaned-blobs, instead of only reporting them.",
+	}
 )
 
 // BeaconNodeOptions sets configuration values on the node.BeaconNode value at node startup.
@@ -38,6 +48,9 @@ func BeaconNodeOptions(c *cli.Context) ([]node.Option, error) {
 	opts := []node.Option{node.WithBlobStorageOptions(
 		filesystem.WithBlobRetentionEpochs(e), filesystem.WithBasePath(blobStoragePath(c)),
 	)}
+	if c.Bool(CleanOrphanedBlobsFlag.Name) {
+		opts = append(opts, node.WithBlobOrphanScan(c.Bool(CleanOrphanedBlobsDeleteFlag.Name)))
+	}
 	return opts, nil
 }
 
@@ -7,6 +7,9 @@ import (
 var (
 	backfillBatchSizeName   = "backfill-batch-size"
 	backfillWorkerCountName = "backfill-worker-count"
+	readyBackfillSlotName   = "ready-backfill-slot"
+	backfillTrustedRootName = "backfill-trusted-root"
+	backfillTrustedSlotName = "backfill-trusted-slot"
 
 	// EnableExperimentalBackfill enables backfill for checkpoint synced nodes.
 	// This flag will be removed once backfill is enabled by default.
@@ -35,9 +38,92 @@ var (
 			"This has a multiplicative effect with " + backfillBatchSizeName + ".",
 		Value: 2,
 	}
+	// BackfillCoverageSampleInterval enables sampled checks that backfilled history is actually present in the db.
+	BackfillCoverageSampleInterval = &cli.Uint64Flag{
+		Name: "backfill-coverage-sample-interval",
+		Usage: "After every N imported backfill batches, check that blocks for a randomly chosen range of the " +
+			"backfilled history are present in the db, logging and counting any discrepancies. 0 disables the check.",
+	}
+	// BackfillMaxBufferedBytes bounds the memory used by backfill batches that are waiting to be imported.
+	BackfillMaxBufferedBytes = &cli.Uint64Flag{
+		Name: "backfill-max-buffered-bytes",
+		Usage: "Approximate upper limit, in bytes, on the size of downloaded blocks and blobs that backfill holds in memory " +
+			"while waiting to import them. New batches are not requested while the limit is exceeded. 0 disables the limit.",
+	}
+	// BackfillSkipBlobs backfills blocks without downloading or storing their blob sidecars.
+	BackfillSkipBlobs = &cli.BoolFlag{
+		Name: "backfill-skip-blobs",
+		Usage: "Backfill downloads historical blocks, but not their blob sidecars, to save disk space. " +
+			"Blob sidecar range requests for the slots backfilled without blobs are answered as resource unavailable.",
+	}
+	// BackfillBlobPruneMargin enables pruning of blob sidecars that have fallen behind the blob retention floor.
+	BackfillBlobPruneMargin = &cli.Uint64Flag{
+		Name: "backfill-blob-prune-margin",
+		Usage: "Prune blob sidecars that are older than the blob retention period by more than this number of epochs. " +
+			"Blob sidecar range requests for pruned slots are answered as resource unavailable. " +
+			"Pruning is only enabled if this flag is specified.",
+	}
+	// BackfillBytesPerSlotEstimate is the average size of the data for a slot, used to estimate the remaining backfill work in bytes.
+	BackfillBytesPerSlotEstimate = &cli.Uint64Flag{
+		Name: "backfill-bytes-per-slot-estimate",
+		Usage: "Average number of bytes of blocks and blobs per slot, used to estimate how much data backfill still needs " +
+			"to download. This only affects reporting. 0 uses the default estimate.",
+	}
+	// BackfillMaxPeers bounds the number of peers that backfill requests batches from at the same time.
+	BackfillMaxPeers = &cli.IntFlag{
+		Name: "backfill-max-peers",
+		Usage: "Maximum number of peers that backfill requests batches from at the same time. " +
+			"0 means the number of backfill workers is the only limit.",
+		Value: 0,
+	}
+	// BackfillMaxRequestsPerPeer bounds the number of backfill batches requested from a single peer at the same time.
+	BackfillMaxRequestsPerPeer = &cli.IntFlag{
+		Name:  "backfill-max-requests-per-peer",
+		Usage: "Maximum number of backfill batches that can be requested from a single peer at the same time.",
+		Value: 1,
+	}
+	// BackfillQuorum is the number of peers that must agree on the blocks of a backfill batch.
+	BackfillQuorum = &cli.IntFlag{
+		Name: "backfill-quorum",
+		Usage: "Number of peers that must return the same blocks for a backfill batch before it is imported. " +
+			"Hardens backfill against a single peer serving a non-canonical chain, at the cost of downloading each batch " +
+			"several times. Values below 2 trust the first peer that serves a valid batch.",
+		Value: 1,
+	}
+	// BackfillTrustedRoot is the root of a block that backfilled history must contain, regardless of the checkpoint sync origin.
+	BackfillTrustedRoot = &cli.StringFlag{
+		Name: backfillTrustedRootName,
+		Usage: "Hex encoded root of a trusted block, such as a weak subjectivity checkpoint, that backfilled blocks must chain to. " +
+			"Defends against a compromised checkpoint sync source. Requires " + backfillTrustedSlotName + ".",
+	}
+	// BackfillTrustedSlot is the slot of the block given by BackfillTrustedRoot.
+	BackfillTrustedSlot = &cli.Uint64Flag{
+		Name:  backfillTrustedSlotName,
+		Usage: "Slot of the block given by " + backfillTrustedRootName + ".",
+	}
+	// BackfillImportDataDir is the data directory of another node that backfill reads history from instead of peers.
+	BackfillImportDataDir = &cli.StringFlag{
+		Name: "backfill-import-datadir",
+		Usage: "Data directory of another beacon node, such as a copy of an archive node, that backfill imports finalized " +
+			"blocks and blob sidecars from instead of downloading them from peers. The node that owns the directory must " +
+			"not be running.",
+	}
 	BackfillOldestSlot = &cli.Uint64Flag{
 		Name: "backfill-oldest-slot",
 		Usage: "Specifies the oldest slot that backfill should download. " +
 			"If this value is greater than current_slot - MIN_EPOCHS_FOR_BLOCK_REQUESTS, it will be ignored with a warning log.",
 	}
+	// ReadyRequiresBackfill holds back the readiness reported by the health endpoint until backfill has progressed
+	// far enough for the node to serve historical data.
+	ReadyRequiresBackfill = &cli.BoolFlag{
+		Name: "ready-requires-backfill",
+		Usage: "The node health endpoint reports the node as syncing, rather than ready, until backfill has downloaded " +
+			"history back to the slot given by " + readyBackfillSlotName + ", or has completed.",
+	}
+	// ReadyBackfillSlot is the slot that backfill needs to reach for the node to be ready, when ReadyRequiresBackfill is set.
+	ReadyBackfillSlot = &cli.Uint64Flag{
+		Name: readyBackfillSlotName,
+		Usage: "Slot that backfill needs to reach before the node is reported as ready, when ready-requires-backfill is set. " +
+			"0 means the node is ready only once backfill is complete.",
+	}
 )
@@ -1,10 +1,18 @@
 package backfill
 
 import (
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/filesystem"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/kv"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/node"
 	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/backfill"
 	"github.com/prysmaticlabs/prysm/v5/cmd/beacon-chain/sync/backfill/flags"
 	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/encoding/bytesutil"
+	"github.com/prysmaticlabs/prysm/v5/io/file"
 	"github.com/urfave/cli/v2"
 )
 
@@ -16,14 +24,73 @@ func BeaconNodeOptions(c *cli.Context) ([]node.Option, error) {
 			backfill.WithBatchSize(c.Uint64(flags.BackfillBatchSize.Name)),
 			backfill.WithWorkerCount(c.Int(flags.BackfillWorkerCount.Name)),
 			backfill.WithEnableBackfill(c.Bool(flags.EnableExperimentalBackfill.Name)),
+			backfill.WithCoverageSampling(c.Uint64(flags.BackfillCoverageSampleInterval.Name)),
+			backfill.WithMaxBufferedBytes(c.Uint64(flags.BackfillMaxBufferedBytes.Name)),
+			backfill.WithSkipBlobs(c.Bool(flags.BackfillSkipBlobs.Name)),
+			backfill.WithBytesPerSlotEstimate(c.Uint64(flags.BackfillBytesPerSlotEstimate.Name)),
+			backfill.WithMaxPeers(c.Int(flags.BackfillMaxPeers.Name)),
+			backfill.WithMaxRequestsPerPeer(c.Int(flags.BackfillMaxRequestsPerPeer.Name)),
+			backfill.WithQuorum(c.Int(flags.BackfillQuorum.Name)),
 		}
 		// The zero value of this uint flag would be genesis, so we use IsSet to differentiate nil from zero case.
 		if c.IsSet(flags.BackfillOldestSlot.Name) {
 			uv := c.Uint64(flags.BackfillOldestSlot.Name)
 			bno = append(bno, backfill.WithMinimumSlot(primitives.Slot(uv)))
 		}
+		// A margin of 0 is valid, so IsSet is also used to tell whether pruning is enabled.
+		if c.IsSet(flags.BackfillBlobPruneMargin.Name) {
+			margin := c.Uint64(flags.BackfillBlobPruneMargin.Name)
+			bno = append(bno, backfill.WithBlobPruning(primitives.Epoch(margin)))
+		}
+		if c.IsSet(flags.BackfillTrustedRoot.Name) {
+			anchor, err := trustedAnchorOption(c)
+			if err != nil {
+				return err
+			}
+			bno = append(bno, anchor)
+		}
+		if c.IsSet(flags.BackfillImportDataDir.Name) {
+			source, err := importSourceOption(c)
+			if err != nil {
+				return err
+			}
+			bno = append(bno, source)
+		}
 		node.BackfillOpts = bno
 		return nil
 	}
 	return []node.Option{opt}, nil
 }
+
+func trustedAnchorOption(c *cli.Context) (backfill.ServiceOption, error) {
+	// Slot 0 is genesis, which can't be a meaningful anchor, so the slot is required rather than defaulting to it.
+	if !c.IsSet(flags.BackfillTrustedSlot.Name) {
+		return nil, errors.Errorf("--%s requires --%s", flags.BackfillTrustedRoot.Name, flags.BackfillTrustedSlot.Name)
+	}
+	root, err := bytesutil.DecodeHexWithLength(c.String(flags.BackfillTrustedRoot.Name), 32)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid --%s", flags.BackfillTrustedRoot.Name)
+	}
+	slot := primitives.Slot(c.Uint64(flags.BackfillTrustedSlot.Name))
+	return backfill.WithTrustedAnchor(bytesutil.ToBytes32(root), slot), nil
+}
+
+func importSourceOption(c *cli.Context) (backfill.ServiceOption, error) {
+	dir, err := file.ExpandPath(c.String(flags.BackfillImportDataDir.Name))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid --%s", flags.BackfillImportDataDir.Name)
+	}
+	// The db belongs to another node, so it is opened read-only, which also fails if there is no db to import from.
+	// The backfill service closes it when it stops.
+	d, err := kv.NewReadOnlyKVStore(c.Context, filepath.Join(dir, kv.BeaconNodeDbDirName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open the beacon db in --%s", flags.BackfillImportDataDir.Name)
+	}
+	blobs, err := filesystem.NewBlobStorage(filesystem.WithBasePath(path.Join(dir, "blobs")))
+	if err != nil {
+		// Nothing was written to the db, so an error closing it adds nothing to the error opening the blobs.
+		_ = d.Close()
+		return nil, errors.Wrapf(err, "could not open the blob storage in --%s", flags.BackfillImportDataDir.Name)
+	}
+	return backfill.WithImportSource(backfill.NewArchiveSource(d, blobs)), nil
+}
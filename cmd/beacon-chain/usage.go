@@ -115,6 +115,20 @@ var appHelpFlagGroups = []flagGroup{
 			flags.BlockBatchLimitBurstFactor,
 			flags.BlobBatchLimit,
 			flags.BlobBatchLimitBurstFactor,
+			flags.BlobServingHotEpochs,
+			flags.BlobBatchLimitHistorical,
+			flags.BlobServeFlushInterval,
+			flags.BlobServeCoalesceReads,
+			flags.BlobServeCoalesceWindow,
+			flags.BlobServeCoalesceCacheSize,
+			flags.BlobServeBreakerThreshold,
+			flags.BlobServeBreakerCooldown,
+			flags.BlobServeProposalPause,
+			flags.BlobServeDrainTimeout,
+			flags.ServeWhileSyncing,
+			flags.ServeWhileSyncingMargin,
+			flags.ServeSelfTest,
+			flags.ChunkSendFailureLogLevel,
 			flags.DisableDebugRPCEndpoints,
 			flags.SubscribeToAllSubnets,
 			flags.HistoricalSlasherNode,
@@ -140,10 +154,25 @@ var appHelpFlagGroups = []flagGroup{
 			genesis.BeaconAPIURL,
 			storage.BlobStoragePathFlag,
 			storage.BlobRetentionEpochFlag,
+			storage.CleanOrphanedBlobsFlag,
+			storage.CleanOrphanedBlobsDeleteFlag,
 			backfill.EnableExperimentalBackfill,
 			backfill.BackfillWorkerCount,
 			backfill.BackfillBatchSize,
 			backfill.BackfillOldestSlot,
+			backfill.ReadyRequiresBackfill,
+			backfill.ReadyBackfillSlot,
+			backfill.BackfillCoverageSampleInterval,
+			backfill.BackfillMaxBufferedBytes,
+			backfill.BackfillSkipBlobs,
+			backfill.BackfillBlobPruneMargin,
+			backfill.BackfillBytesPerSlotEstimate,
+			backfill.BackfillMaxPeers,
+			backfill.BackfillMaxRequestsPerPeer,
+			backfill.BackfillQuorum,
+			backfill.BackfillTrustedRoot,
+			backfill.BackfillTrustedSlot,
+			backfill.BackfillImportDataDir,
 		},
 	},
 	{
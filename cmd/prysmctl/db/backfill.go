@@ -0,0 +1,52 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/db/kv"
+	"github.com/prysmaticlabs/prysm/v5/beacon-chain/sync/backfill"
+	"github.com/urfave/cli/v2"
+)
+
+var errBackfillStatusCorrupt = errors.New("backfill status is inconsistent with the db")
+
+var backfillStatusFlags = struct {
+	Path string
+}{}
+
+var backfillStatusCmd = &cli.Command{
+	Name:   "backfill-status",
+	Usage:  "verify the backfill status of a beacon db against the blocks it contains. The node must be stopped.",
+	Action: backfillStatusAction,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:        "path",
+			Usage:       "path to directory containing beaconchain.db",
+			Destination: &backfillStatusFlags.Path,
+			Required:    true,
+		},
+	},
+}
+
+func backfillStatusAction(cliCtx *cli.Context) error {
+	ctx := cliCtx.Context
+	d, err := kv.NewReadOnlyKVStore(ctx, backfillStatusFlags.Path)
+	if err != nil {
+		return errors.Wrapf(err, "could not open db at %s", backfillStatusFlags.Path)
+	}
+	defer func() {
+		if err := d.Close(); err != nil {
+			fmt.Printf("Could not close db: %v\n", err)
+		}
+	}()
+	report, err := backfill.VerifyStatus(ctx, d)
+	if err != nil {
+		return err
+	}
+	fmt.Print(report.String())
+	if report.Corrupt() {
+		return errBackfillStatusCorrupt
+	}
+	return nil
+}
@@ -80,6 +80,9 @@ type Flags struct {
 
 	EnableDiscoveryReboot bool // EnableDiscoveryReboot allows the node to have its local listener to be rebooted in the event of discovery issues.
 
+	VerifyServedBlobInclusionProofs bool // VerifyServedBlobInclusionProofs checks the inclusion proof of blob sidecars before serving them to peers.
+	ServeCanonicalBlobsOnly         bool // ServeCanonicalBlobsOnly skips blob sidecars for non-canonical blocks when serving by range.
+
 	// KeystoreImportDebounceInterval specifies the time duration the validator waits to reload new keys if they have
 	// changed on disk. This feature is for advanced use cases only.
 	KeystoreImportDebounceInterval time.Duration
@@ -266,6 +269,14 @@ func ConfigureBeaconChain(ctx *cli.Context) error {
 		logEnabled(EnableDiscoveryReboot)
 		cfg.EnableDiscoveryReboot = true
 	}
+	if ctx.IsSet(VerifyServedBlobInclusionProofs.Name) {
+		logEnabled(VerifyServedBlobInclusionProofs)
+		cfg.VerifyServedBlobInclusionProofs = true
+	}
+	if ctx.IsSet(ServeCanonicalBlobsOnly.Name) {
+		logEnabled(ServeCanonicalBlobsOnly)
+		cfg.ServeCanonicalBlobsOnly = true
+	}
 
 	cfg.AggregateIntervals = [3]time.Duration{aggregateFirstInterval.Value, aggregateSecondInterval.Value, aggregateThirdInterval.Value}
 	Init(cfg)
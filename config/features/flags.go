@@ -174,6 +174,18 @@ var (
 		Name:  "enable-discovery-reboot",
 		Usage: "Experimental: Enables the discovery listener to rebooted in the event of connectivity issues.",
 	}
+	// VerifyServedBlobInclusionProofs guards against serving blob sidecars that don't belong to the block they were stored under.
+	VerifyServedBlobInclusionProofs = &cli.BoolFlag{
+		Name: "verify-served-blob-inclusion-proofs",
+		Usage: "Verifies the KZG commitment inclusion proof of each blob sidecar before serving it to peers, " +
+			"skipping any sidecars that fail verification. This adds a small cost to serving blob sidecars.",
+	}
+	// ServeCanonicalBlobsOnly guards against serving blob sidecars for blocks that were reorged out of the canonical chain.
+	ServeCanonicalBlobsOnly = &cli.BoolFlag{
+		Name: "serve-canonical-blobs-only",
+		Usage: "Blob sidecars served by range are checked against the canonical chain when they are written, skipping " +
+			"sidecars for blocks that are no longer canonical. Sidecars requested by root are still served.",
+	}
 )
 
 // devModeFlags holds list of flags that are set when development mode is on.
@@ -232,6 +244,8 @@ var BeaconChainFlags = append(deprecatedBeaconFlags, append(deprecatedFlags, []c
 	EnableQUIC,
 	DisableCommitteeAwarePacking,
 	EnableDiscoveryReboot,
+	VerifyServedBlobInclusionProofs,
+	ServeCanonicalBlobsOnly,
 }...)...)
 
 // E2EBeaconChainFlags contains a list of the beacon chain feature flags to be tested in E2E.
@@ -0,0 +1,65 @@
+package params
+
+import (
+	"math"
+
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/runtime/version"
+)
+
+// BlobRetentionEpochs returns the number of epochs that blob sidecars must be kept for the blocks of the fork with
+// the given runtime/version identifier. This is the entry for the fork in MinEpochsForBlobsSidecarsRequestByFork,
+// or MinEpochsForBlobsSidecarsRequest if the fork has no entry.
+func (b *BeaconChainConfig) BlobRetentionEpochs(v int) primitives.Epoch {
+	if r, ok := b.MinEpochsForBlobsSidecarsRequestByFork[v]; ok {
+		return r
+	}
+	return b.MinEpochsForBlobsSidecarsRequest
+}
+
+// BlobRetentionFloor returns the lowest epoch that blob sidecars must be kept for at the current epoch. Each fork
+// with blobs is kept for its own retention period, see BlobRetentionEpochs, so the floor is the first epoch of the
+// oldest fork that still has epochs within its retention period. The floor is never lower than the deneb fork epoch.
+func (b *BeaconChainConfig) BlobRetentionFloor(current primitives.Epoch) primitives.Epoch {
+	forks := b.blobForks()
+	for i, f := range forks {
+		end := primitives.Epoch(math.MaxUint64)
+		if i+1 < len(forks) {
+			end = forks[i+1].epoch
+		}
+		floor := f.epoch
+		r := b.BlobRetentionEpochs(f.version)
+		if current > r && current-r > floor {
+			floor = current - r
+		}
+		if floor < end {
+			return floor
+		}
+	}
+	return b.DenebForkEpoch
+}
+
+type blobFork struct {
+	version int
+	epoch   primitives.Epoch
+}
+
+// blobForks returns the forks that have blob sidecars, in fork order.
+func (b *BeaconChainConfig) blobForks() []blobFork {
+	return []blobFork{
+		{version: version.Deneb, epoch: b.DenebForkEpoch},
+		{version: version.Electra, epoch: b.ElectraForkEpoch},
+	}
+}
+
+// blobForkAtEpoch returns the fork with blob sidecars that is active at the epoch. Epochs before the deneb fork are
+// reported as deneb, so that they use the deneb retention.
+func (b *BeaconChainConfig) blobForkAtEpoch(e primitives.Epoch) int {
+	v := version.Deneb
+	for _, f := range b.blobForks() {
+		if e >= f.epoch {
+			v = f.version
+		}
+	}
+	return v
+}
@@ -0,0 +1,86 @@
+package params_test
+
+import (
+	"testing"
+
+	"github.com/prysmaticlabs/prysm/v5/config/params"
+	"github.com/prysmaticlabs/prysm/v5/consensus-types/primitives"
+	"github.com/prysmaticlabs/prysm/v5/runtime/version"
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestBlobRetentionEpochs(t *testing.T) {
+	cfg := params.MainnetConfig().Copy()
+	cfg.MinEpochsForBlobsSidecarsRequest = 100
+	require.Equal(t, primitives.Epoch(100), cfg.BlobRetentionEpochs(version.Deneb))
+	require.Equal(t, primitives.Epoch(100), cfg.BlobRetentionEpochs(version.Electra))
+
+	cfg.MinEpochsForBlobsSidecarsRequestByFork = map[int]primitives.Epoch{version.Electra: 200}
+	require.Equal(t, primitives.Epoch(100), cfg.BlobRetentionEpochs(version.Deneb))
+	require.Equal(t, primitives.Epoch(200), cfg.BlobRetentionEpochs(version.Electra))
+}
+
+func TestBlobRetentionFloor(t *testing.T) {
+	cfg := params.MainnetConfig().Copy()
+	cfg.DenebForkEpoch = 1000
+	cfg.ElectraForkEpoch = 2000
+	cfg.MinEpochsForBlobsSidecarsRequest = 100
+
+	cases := []struct {
+		name    string
+		byFork  map[int]primitives.Epoch
+		current primitives.Epoch
+		floor   primitives.Epoch
+	}{
+		{name: "before deneb", current: 500, floor: 1000},
+		{name: "within deneb retention", current: 1050, floor: 1000},
+		{name: "deneb", current: 1500, floor: 1400},
+		{name: "electra", current: 2500, floor: 2400},
+		{name: "across the electra fork", current: 2050, floor: 1950},
+		{
+			name:    "longer electra retention",
+			byFork:  map[int]primitives.Epoch{version.Electra: 300},
+			current: 2050,
+			floor:   1950,
+		},
+		{
+			name:    "longer electra retention after deneb blobs expire",
+			byFork:  map[int]primitives.Epoch{version.Electra: 300},
+			current: 2200,
+			floor:   2000,
+		},
+		{
+			name:    "longer deneb retention",
+			byFork:  map[int]primitives.Epoch{version.Deneb: 1000},
+			current: 2500,
+			floor:   1500,
+		},
+		{
+			name:    "longer deneb retention has expired",
+			byFork:  map[int]primitives.Epoch{version.Deneb: 1000},
+			current: 3500,
+			floor:   3400,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg.MinEpochsForBlobsSidecarsRequestByFork = c.byFork
+			require.Equal(t, c.floor, cfg.BlobRetentionFloor(c.current))
+		})
+	}
+}
+
+func TestWithinDAPeriod_ByFork(t *testing.T) {
+	params.SetupTestConfigCleanup(t)
+	cfg := params.BeaconConfig().Copy()
+	cfg.DenebForkEpoch = 1000
+	cfg.ElectraForkEpoch = 2000
+	cfg.MinEpochsForBlobsSidecarsRequest = 100
+	cfg.MinEpochsForBlobsSidecarsRequestByFork = map[int]primitives.Epoch{version.Electra: 300}
+	params.OverrideBeaconConfig(cfg)
+
+	require.Equal(t, true, params.WithinDAPeriod(1900, 2000))
+	require.Equal(t, false, params.WithinDAPeriod(1899, 2000))
+	require.Equal(t, true, params.WithinDAPeriod(2000, 2300))
+	require.Equal(t, false, params.WithinDAPeriod(2000, 2301))
+}
@@ -237,6 +237,9 @@ type BeaconChainConfig struct {
 	MaxRequestBlobSidecars           uint64           `yaml:"MAX_REQUEST_BLOB_SIDECARS" spec:"true"`             // MaxRequestBlobSidecars is the maximum number of blobs to request in a single request.
 	MaxRequestBlocksDeneb            uint64           `yaml:"MAX_REQUEST_BLOCKS_DENEB" spec:"true"`              // MaxRequestBlocksDeneb is the maximum number of blocks in a single request after the deneb epoch.
 
+	// Blob retention
+	MinEpochsForBlobsSidecarsRequestByFork map[int]primitives.Epoch // MinEpochsForBlobsSidecarsRequestByFork overrides MinEpochsForBlobsSidecarsRequest for the blocks of a fork, keyed by the runtime/version identifier of the fork. Forks without an entry use MinEpochsForBlobsSidecarsRequest.
+
 	// Values introduce in Electra upgrade
 	DataColumnSidecarSubnetCount          uint64 `yaml:"DATA_COLUMN_SIDECAR_SUBNET_COUNT" spec:"true"`           // DataColumnSidecarSubnetCount is the number of data column sidecar subnets used in the gossipsub protocol
 	MaxPerEpochActivationExitChurnLimit   uint64 `yaml:"MAX_PER_EPOCH_ACTIVATION_EXIT_CHURN_LIMIT" spec:"true"`  // MaxPerEpochActivationExitChurnLimit represents the maximum combined activation and exit churn.
@@ -370,7 +373,9 @@ func PeerDASEnabled() bool {
 	return BeaconConfig().Eip7594ForkEpoch < math.MaxUint64
 }
 
-// WithinDAPeriod checks if the block epoch is within MIN_EPOCHS_FOR_BLOB_SIDECARS_REQUESTS of the given current epoch.
+// WithinDAPeriod checks if the block epoch is within MIN_EPOCHS_FOR_BLOB_SIDECARS_REQUESTS of the given current epoch,
+// using the retention of the fork of the block, see BlobRetentionEpochs.
 func WithinDAPeriod(block, current primitives.Epoch) bool {
-	return block+BeaconConfig().MinEpochsForBlobsSidecarsRequest >= current
+	b := BeaconConfig()
+	return block+b.BlobRetentionEpochs(b.blobForkAtEpoch(block)) >= current
 }
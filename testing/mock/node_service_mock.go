@@ -42,6 +42,26 @@ func (m *MockNodeClient) EXPECT() *MockNodeClientMockRecorder {
 	return m.recorder
 }
 
+// GetBackfillStatus mocks base method.
+func (m *MockNodeClient) GetBackfillStatus(arg0 context.Context, arg1 *emptypb.Empty, arg2 ...grpc.CallOption) (*eth.BackfillStatus, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetBackfillStatus", varargs...)
+	ret0, _ := ret[0].(*eth.BackfillStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBackfillStatus indicates an expected call of GetBackfillStatus.
+func (mr *MockNodeClientMockRecorder) GetBackfillStatus(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBackfillStatus", reflect.TypeOf((*MockNodeClient)(nil).GetBackfillStatus), varargs...)
+}
+
 // GetETH1ConnectionStatus mocks base method.
 func (m *MockNodeClient) GetETH1ConnectionStatus(arg0 context.Context, arg1 *emptypb.Empty, arg2 ...grpc.CallOption) (*eth.ETH1ConnectionStatus, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,121 @@
+// Package testing includes a simulated clock for unit tests of code that uses time.Clock.
+package testing
+
+import (
+	"sync"
+	"time"
+
+	prysmTime "github.com/prysmaticlabs/prysm/v5/time"
+)
+
+// Clock is a time.Clock that only moves when Advance is called. Timers created by the clock fire when the clock is
+// advanced to or past their deadline.
+type Clock struct {
+	sync.Mutex
+	now    time.Time
+	timers []*timer
+}
+
+var _ prysmTime.Clock = (*Clock)(nil)
+
+// NewClock returns a simulated clock set to now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the time the clock has been advanced to.
+func (c *Clock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+// NewTimer returns a timer that fires once the clock has been advanced by d.
+func (c *Clock) NewTimer(d time.Duration) prysmTime.Timer {
+	c.Lock()
+	defer c.Unlock()
+	t := &timer{clock: c, c: make(chan time.Time, 1)}
+	c.schedule(t, d)
+	return t
+}
+
+// After returns a channel that receives the current time once the clock has been advanced by d.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Advance moves the clock forward by d, firing every timer whose deadline has been reached.
+func (c *Clock) Advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.fire(c.now)
+	}
+	c.timers = pending
+}
+
+// Waiters returns the number of timers that have not fired or been stopped. Tests can poll it to know that the code
+// under test is waiting on the clock before advancing it.
+func (c *Clock) Waiters() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.timers)
+}
+
+func (c *Clock) schedule(t *timer, d time.Duration) {
+	t.deadline = c.now.Add(d)
+	if d <= 0 {
+		t.fire(c.now)
+		return
+	}
+	c.timers = append(c.timers, t)
+}
+
+// unschedule removes the timer from the pending timers, returning false if it had already fired or been stopped.
+func (c *Clock) unschedule(t *timer) bool {
+	for i := range c.timers {
+		if c.timers[i] == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type timer struct {
+	clock    *Clock
+	deadline time.Time
+	c        chan time.Time
+}
+
+func (t *timer) fire(now time.Time) {
+	// Like time.Timer, a tick that hasn't been received is not replaced.
+	select {
+	case t.c <- now:
+	default:
+	}
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *timer) Stop() bool {
+	t.clock.Lock()
+	defer t.clock.Unlock()
+	return t.clock.unschedule(t)
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	t.clock.Lock()
+	defer t.clock.Unlock()
+	active := t.clock.unschedule(t)
+	t.clock.schedule(t, d)
+	return active
+}
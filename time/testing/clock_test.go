@@ -0,0 +1,41 @@
+package testing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prysmaticlabs/prysm/v5/testing/require"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Unix(1000, 0)
+	c := NewClock(start)
+	tm := c.NewTimer(time.Second)
+	after := c.After(2 * time.Second)
+	require.Equal(t, 2, c.Waiters())
+
+	c.Advance(999 * time.Millisecond)
+	select {
+	case <-tm.C():
+		t.Fatal("timer fired before its deadline")
+	default:
+	}
+	c.Advance(time.Millisecond)
+	require.Equal(t, start.Add(time.Second), <-tm.C())
+	require.Equal(t, 1, c.Waiters())
+	// A timer that fired can't be stopped.
+	require.Equal(t, false, tm.Stop())
+
+	// Reset schedules the timer relative to the current time.
+	require.Equal(t, false, tm.Reset(time.Second))
+	require.Equal(t, true, tm.Stop())
+	c.Advance(time.Second)
+	require.Equal(t, start.Add(2*time.Second), <-after)
+	select {
+	case <-tm.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+	require.Equal(t, 0, c.Waiters())
+	require.Equal(t, start.Add(2*time.Second), c.Now())
+}